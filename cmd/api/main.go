@@ -7,6 +7,7 @@ import (
 
 	"github.com/AhmadAbdelrazik/showtime/internal/config"
 	"github.com/AhmadAbdelrazik/showtime/internal/controllers"
+	"github.com/AhmadAbdelrazik/showtime/internal/infrastructure/fakepay"
 	"github.com/AhmadAbdelrazik/showtime/internal/infrastructure/omdb"
 	"github.com/AhmadAbdelrazik/showtime/internal/models"
 	"github.com/AhmadAbdelrazik/showtime/internal/services"
@@ -51,8 +52,20 @@ func main() {
 
 	omdbClient := omdb.NewClient(cfg.OmdbApiKey)
 
+	paymentGateway, err := fakepay.New(fakepay.Outcome(cfg.PaymentOutcome))
+	if err != nil {
+		slog.Error("failed to create payment gateway", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
 	cache := cache.New()
-	service := services.New(models, omdbClient)
+	service := services.New(models, omdbClient, paymentGateway, cfg)
+
+	holdSweeper := services.NewHoldSweeper(service.Holds, cfg.Holds.SweepInterval)
+	defer holdSweeper.Stop()
+
+	membershipRenewer := services.NewMembershipRenewer(service.Members, cfg.Memberships.RenewalInterval)
+	defer membershipRenewer.Stop()
 
 	// 4. Initialize HTTP Server Dependencies
 	app := controllers.New(service, cache, cfg)
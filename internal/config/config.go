@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/AhmadAbdelrazik/showtime/pkg/money"
 	"github.com/joho/godotenv"
 )
 
@@ -25,6 +26,26 @@ type Config struct {
 		Burst           int
 		CleanupDuration time.Duration
 	}
+	Holds struct {
+		Duration      time.Duration
+		MaxDuration   time.Duration
+		SweepInterval time.Duration
+	}
+	Waitlist struct {
+		OfferDuration time.Duration
+	}
+	GiftCards struct {
+		Validity time.Duration
+	}
+	Memberships struct {
+		RenewalInterval time.Duration
+	}
+	Currencies struct {
+		Base string
+	}
+	PaymentOutcome   string
+	TicketPrice      int64
+	TicketSigningKey string
 }
 
 func Load() (*Config, error) {
@@ -48,6 +69,55 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("%w: failed to parse RATELIMIT_CLEANUP_DURATION)", ErrConfigError)
 	}
 
+	holdDuration, err := time.ParseDuration(os.Getenv("HOLD_DURATION"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to parse HOLD_DURATION)", ErrConfigError)
+	}
+	holdMaxDuration, err := time.ParseDuration(os.Getenv("HOLD_MAX_DURATION"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to parse HOLD_MAX_DURATION)", ErrConfigError)
+	}
+	holdSweepInterval, err := time.ParseDuration(os.Getenv("HOLD_SWEEP_INTERVAL"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to parse HOLD_SWEEP_INTERVAL)", ErrConfigError)
+	}
+	if holdSweepInterval <= 0 {
+		return nil, fmt.Errorf("%w: HOLD_SWEEP_INTERVAL must be positive)", ErrConfigError)
+	}
+
+	waitlistOfferDuration, err := time.ParseDuration(os.Getenv("WAITLIST_OFFER_DURATION"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to parse WAITLIST_OFFER_DURATION)", ErrConfigError)
+	}
+
+	giftCardValidity, err := time.ParseDuration(os.Getenv("GIFT_CARD_VALIDITY"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to parse GIFT_CARD_VALIDITY)", ErrConfigError)
+	}
+
+	membershipRenewalInterval, err := time.ParseDuration(os.Getenv("MEMBERSHIP_RENEWAL_INTERVAL"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to parse MEMBERSHIP_RENEWAL_INTERVAL)", ErrConfigError)
+	}
+	if membershipRenewalInterval <= 0 {
+		return nil, fmt.Errorf("%w: MEMBERSHIP_RENEWAL_INTERVAL must be positive)", ErrConfigError)
+	}
+
+	baseCurrency := os.Getenv("BASE_CURRENCY")
+	if !money.IsKnown(baseCurrency) {
+		return nil, fmt.Errorf("%w: BASE_CURRENCY must be a supported ISO 4217 code)", ErrConfigError)
+	}
+
+	ticketPrice, err := strconv.ParseInt(os.Getenv("TICKET_PRICE"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to parse TICKET_PRICE)", ErrConfigError)
+	}
+
+	ticketSigningKey := os.Getenv("TICKET_SIGNING_KEY")
+	if len(ticketSigningKey) < 32 {
+		return nil, fmt.Errorf("%w: TICKET_SIGNING_KEY must be at least 32 characters)", ErrConfigError)
+	}
+
 	return &Config{
 		DSN:         os.Getenv("DB_DSN"),
 		Port:        os.Getenv("PORT"),
@@ -64,5 +134,37 @@ func Load() (*Config, error) {
 			Burst:           rateLimitBurst,
 			CleanupDuration: rateLimitCleanupDuration,
 		},
+		Holds: struct {
+			Duration      time.Duration
+			MaxDuration   time.Duration
+			SweepInterval time.Duration
+		}{
+			Duration:      holdDuration,
+			MaxDuration:   holdMaxDuration,
+			SweepInterval: holdSweepInterval,
+		},
+		Waitlist: struct {
+			OfferDuration time.Duration
+		}{
+			OfferDuration: waitlistOfferDuration,
+		},
+		GiftCards: struct {
+			Validity time.Duration
+		}{
+			Validity: giftCardValidity,
+		},
+		Memberships: struct {
+			RenewalInterval time.Duration
+		}{
+			RenewalInterval: membershipRenewalInterval,
+		},
+		Currencies: struct {
+			Base string
+		}{
+			Base: baseCurrency,
+		},
+		PaymentOutcome:   os.Getenv("PAYMENT_OUTCOME"),
+		TicketPrice:      ticketPrice,
+		TicketSigningKey: ticketSigningKey,
 	}, nil
 }
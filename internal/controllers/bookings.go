@@ -0,0 +1,346 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/httputil"
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+	"github.com/AhmadAbdelrazik/showtime/internal/services"
+	"github.com/AhmadAbdelrazik/showtime/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+// checkout godoc
+//
+//	@Summary		Checkout
+//	@Description	Pay for the seats of a hold and create a booking
+//	@Tags			bookings
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		CheckoutInput	true	"checkout data"
+//	@Success		201		{object}	CheckoutResponse
+//	@Success		202		{object}	CheckoutResponse
+//	@Failure		400		{object}	httputil.ValidationError
+//	@Failure		401		{object}	httputil.HTTPError
+//	@Failure		402		{object}	httputil.HTTPError
+//	@Failure		403		{object}	httputil.HTTPError
+//	@Failure		404		{object}	httputil.HTTPError
+//	@Failure		409		{object}	httputil.HTTPError
+//	@Failure		422		{object}	httputil.HTTPError
+//	@Failure		500		{object}	httputil.HTTPError
+//	@Failure		504		{object}	httputil.HTTPError
+//	@Router			/api/bookings [post]
+func (h *Application) checkoutHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	var input CheckoutInput
+	if err := c.ShouldBind(&input); err != nil {
+		v := validator.New()
+		input.Validate(v)
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	v := validator.New()
+	if input.Validate(v); !v.Valid() {
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	checkoutInput := services.CheckoutInput{
+		HoldID:        input.HoldID,
+		PaymentToken:  input.PaymentToken,
+		TicketTypes:   make(map[int]string, len(input.TicketTypes)),
+		PromoCode:     strings.TrimSpace(input.PromoCode),
+		RedeemPoints:  input.RedeemPoints,
+		FreeTickets:   input.FreeTickets,
+		GiftCardCode:  strings.TrimSpace(input.GiftCardCode),
+		UseMembership: input.UseMembership,
+	}
+	for _, t := range input.TicketTypes {
+		checkoutInput.TicketTypes[t.SeatID] = t.Type
+	}
+	for _, o := range input.Concessions {
+		checkoutInput.Concessions = append(checkoutInput.Concessions, services.ConcessionOrderInput(o))
+	}
+
+	booking, err := h.services.Bookings.Checkout(user, checkoutInput)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrPaymentPending):
+			c.JSON(http.StatusAccepted, CheckoutResponse{
+				Message: "payment is being confirmed, check the booking again shortly",
+				Booking: *booking,
+			})
+		case errors.Is(err, services.ErrHoldNotFound),
+			errors.Is(err, services.ErrShowNotFound),
+			errors.Is(err, services.ErrPromoCodeNotFound),
+			errors.Is(err, services.ErrGiftCardNotFound),
+			errors.Is(err, services.ErrNoMembership),
+			errors.Is(err, services.ErrConcessionNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		case errors.Is(err, services.ErrInvalidTicketType),
+			errors.Is(err, services.ErrSeatNotHeld),
+			errors.Is(err, services.ErrInvalidLoyaltyRedemption):
+			httputil.NewError(c, http.StatusBadRequest, err)
+		case errors.Is(err, services.ErrPromoCodeNotApplicable),
+			errors.Is(err, services.ErrGiftCardExpired),
+			errors.Is(err, services.ErrGiftCardEmpty),
+			errors.Is(err, services.ErrGiftCardInactive),
+			errors.Is(err, services.ErrCurrencyMismatch),
+			errors.Is(err, services.ErrConcessionUnavailable):
+			httputil.NewError(c, http.StatusUnprocessableEntity, err)
+		case errors.Is(err, services.ErrUnauthorized),
+			errors.Is(err, services.ErrNotLoyaltyMember),
+			errors.Is(err, services.ErrMembershipInactive):
+			httputil.NewError(c, http.StatusForbidden, err)
+		case errors.Is(err, services.ErrHoldNotActive),
+			errors.Is(err, services.ErrPromoCodeExhausted),
+			errors.Is(err, services.ErrGiftCardInsufficient),
+			errors.Is(err, services.ErrInsufficientPoints),
+			errors.Is(err, services.ErrMembershipExhausted):
+			httputil.NewError(c, http.StatusConflict, err)
+		case errors.Is(err, services.ErrPaymentDeclined):
+			httputil.NewError(c, http.StatusPaymentRequired, err)
+		case errors.Is(err, services.ErrPaymentTimeout):
+			httputil.NewError(c, http.StatusGatewayTimeout, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, CheckoutResponse{
+		Message: "booking paid successfully",
+		Booking: *booking,
+	})
+}
+
+// listBookings godoc
+//
+//	@Summary		List Bookings
+//	@Description	List the bookings of the current user
+//	@Tags			bookings
+//	@Produce		json
+//	@Success		200	{object}	ListBookingsResponse
+//	@Failure		401	{object}	httputil.HTTPError
+//	@Failure		500	{object}	httputil.HTTPError
+//	@Router			/api/bookings [get]
+func (h *Application) listBookingsHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	bookings, err := h.services.Bookings.List(user)
+	if err != nil {
+		httputil.NewError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ListBookingsResponse{bookings})
+}
+
+// getBooking godoc
+//
+//	@Summary		Get Booking
+//	@Description	Get a booking of the current user
+//	@Tags			bookings
+//	@Produce		json
+//	@Param			id	path		int	true	"booking id"
+//	@Success		200	{object}	models.Booking
+//	@Failure		400	{object}	httputil.HTTPError
+//	@Failure		401	{object}	httputil.HTTPError
+//	@Failure		403	{object}	httputil.HTTPError
+//	@Failure		404	{object}	httputil.HTTPError
+//	@Failure		500	{object}	httputil.HTTPError
+//	@Router			/api/bookings/{id} [get]
+func (h *Application) getBookingHandler(c *gin.Context) {
+	bookingId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid booking id"))
+		return
+	}
+
+	user := c.MustGet("user").(*models.User)
+
+	booking, err := h.services.Bookings.Find(user, bookingId)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrBookingNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, booking)
+}
+
+// getInvoice godoc
+//
+//	@Summary		Get Invoice
+//	@Description	Download the invoice of a paid booking of the current user as a PDF document
+//	@Tags			bookings
+//	@Produce		application/pdf
+//	@Param			id	path		int	true	"booking id"
+//	@Success		200	{file}		file
+//	@Failure		400	{object}	httputil.HTTPError
+//	@Failure		401	{object}	httputil.HTTPError
+//	@Failure		403	{object}	httputil.HTTPError
+//	@Failure		404	{object}	httputil.HTTPError
+//	@Failure		409	{object}	httputil.HTTPError
+//	@Failure		500	{object}	httputil.HTTPError
+//	@Router			/api/bookings/{id}/invoice.pdf [get]
+func (h *Application) getInvoiceHandler(c *gin.Context) {
+	bookingId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid booking id"))
+		return
+	}
+
+	user := c.MustGet("user").(*models.User)
+
+	invoice, pdf, err := h.services.Invoices.Invoice(user, bookingId)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrBookingNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		case errors.Is(err, services.ErrNoInvoice):
+			httputil.NewError(c, http.StatusConflict, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%v.pdf"`, invoice.Code()))
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// cancelBooking godoc
+//
+//	@Summary		Cancel Booking
+//	@Description	Cancel a paid booking and get refunded according to the theater's refund policy
+//	@Tags			bookings
+//	@Produce		json
+//	@Param			id	path		int	true	"booking id"
+//	@Success		200	{object}	CancelBookingResponse
+//	@Failure		400	{object}	httputil.HTTPError
+//	@Failure		401	{object}	httputil.HTTPError
+//	@Failure		403	{object}	httputil.HTTPError
+//	@Failure		404	{object}	httputil.HTTPError
+//	@Failure		409	{object}	httputil.HTTPError
+//	@Failure		500	{object}	httputil.HTTPError
+//	@Failure		504	{object}	httputil.HTTPError
+//	@Router			/api/bookings/{id}/cancel [post]
+func (h *Application) cancelBookingHandler(c *gin.Context) {
+	bookingId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid booking id"))
+		return
+	}
+
+	user := c.MustGet("user").(*models.User)
+
+	booking, refund, err := h.services.Bookings.Cancel(user, bookingId)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrBookingNotFound),
+			errors.Is(err, services.ErrShowNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		case errors.Is(err, services.ErrBookingNotCancellable),
+			errors.Is(err, services.ErrShowStarted):
+			httputil.NewError(c, http.StatusConflict, err)
+		case errors.Is(err, services.ErrPaymentTimeout):
+			httputil.NewError(c, http.StatusGatewayTimeout, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, CancelBookingResponse{
+		Message: "booking cancelled successfully",
+		Booking: *booking,
+		Refund:  *refund,
+	})
+}
+
+type CheckoutInput struct {
+	HoldID        int                    `json:"hold_id"`
+	PaymentToken  string                 `json:"payment_token"`
+	TicketTypes   []TicketTypeInput      `json:"ticket_types"`
+	PromoCode     string                 `json:"promo_code"`
+	RedeemPoints  int                    `json:"redeem_points"`
+	FreeTickets   int                    `json:"free_tickets"`
+	GiftCardCode  string                 `json:"gift_card_code"`
+	UseMembership bool                   `json:"use_membership"`
+	Concessions   []ConcessionOrderInput `json:"concessions"`
+}
+
+// ConcessionOrderInput orders a concession to pick up at the show.
+type ConcessionOrderInput struct {
+	ItemID   int `json:"item_id"`
+	Quantity int `json:"quantity"`
+}
+
+// TicketTypeInput sets the ticket type of a held seat. Seats without one get
+// adult tickets.
+type TicketTypeInput struct {
+	SeatID int    `json:"seat_id"`
+	Type   string `json:"type"`
+}
+
+func (i *CheckoutInput) Validate(v *validator.Validator) {
+	v.Check(i.HoldID > 0, "hold_id", "required")
+
+	v.Check(len(strings.TrimSpace(i.PaymentToken)) > 0, "payment_token", "required")
+	v.Check(len(i.PaymentToken) <= 100, "payment_token", "must be at most 100 characters")
+
+	seen := make(map[int]bool, len(i.TicketTypes))
+	for _, t := range i.TicketTypes {
+		v.Check(t.SeatID > 0, "ticket_types", "seat_id is required")
+		v.Check(slices.Contains(models.TicketTypes, t.Type), "ticket_types", "type must be one of adult, child, senior or student")
+		v.Check(!seen[t.SeatID], "ticket_types", "seat_id must be unique")
+		seen[t.SeatID] = true
+	}
+
+	v.Check(len(i.PromoCode) <= 30, "promo_code", "must be at most 30 characters")
+	v.Check(i.RedeemPoints >= 0, "redeem_points", "must not be negative")
+	v.Check(i.FreeTickets >= 0, "free_tickets", "must not be negative")
+	v.Check(len(i.GiftCardCode) <= 30, "gift_card_code", "must be at most 30 characters")
+
+	v.Check(len(i.Concessions) <= 20, "concessions", "must have at most 20 lines")
+	ordered := make(map[int]bool, len(i.Concessions))
+	for _, o := range i.Concessions {
+		v.Check(o.ItemID > 0, "concessions", "item_id is required")
+		v.Check(o.Quantity > 0 && o.Quantity <= 20, "concessions", "quantity must be between 1 and 20")
+		v.Check(!ordered[o.ItemID], "concessions", "item_id must be unique")
+		ordered[o.ItemID] = true
+	}
+}
+
+type CheckoutResponse struct {
+	Message string         `json:"message"`
+	Booking models.Booking `json:"booking"`
+}
+
+type ListBookingsResponse struct {
+	Bookings []models.Booking `json:"bookings"`
+}
+
+type CancelBookingResponse struct {
+	Message string          `json:"message"`
+	Booking models.Booking  `json:"booking"`
+	Refund  services.Refund `json:"refund"`
+}
@@ -0,0 +1,421 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/httputil"
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+	"github.com/AhmadAbdelrazik/showtime/internal/services"
+	"github.com/AhmadAbdelrazik/showtime/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+// listConcessions godoc
+//
+//	@Summary		List Concessions
+//	@Description	List the snacks, drinks and combos a theater sells, with their prices and availability
+//	@Tags			concessions
+//	@Produce		json
+//	@Param			id	path		int	true	"theater id"
+//	@Success		200	{object}	ListConcessionsResponse
+//	@Failure		400	{object}	httputil.HTTPError
+//	@Failure		404	{object}	httputil.HTTPError
+//	@Failure		500	{object}	httputil.HTTPError
+//	@Router			/api/theaters/{id}/concessions [get]
+func (h *Application) listConcessionsHandler(c *gin.Context) {
+	theaterId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid theater id"))
+		return
+	}
+
+	items, err := h.services.Concessions.Menu(theaterId)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTheaterNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, ListConcessionsResponse{Concessions: items})
+}
+
+// createConcession godoc
+//
+//	@Summary		Create Concession
+//	@Description	Add an item or a combo of items to the concessions of a theater
+//	@Tags			concessions
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int						true	"theater id"
+//	@Param			input	body		CreateConcessionInput	true	"new concession"
+//	@Success		201		{object}	ConcessionResponse
+//	@Failure		400		{object}	httputil.ValidationError
+//	@Failure		401		{object}	httputil.HTTPError
+//	@Failure		403		{object}	httputil.HTTPError
+//	@Failure		404		{object}	httputil.HTTPError
+//	@Failure		409		{object}	httputil.HTTPError
+//	@Failure		500		{object}	httputil.HTTPError
+//	@Router			/api/theaters/{id}/concessions [post]
+func (h *Application) createConcessionHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	theaterId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid theater id"))
+		return
+	}
+
+	var input CreateConcessionInput
+	if err := c.ShouldBind(&input); err != nil {
+		v := validator.New()
+		input.Validate(v)
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	v := validator.New()
+	if input.Validate(v); !v.Valid() {
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	item, err := h.services.Concessions.Create(user, theaterId, services.CreateConcessionInput(input))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidCombo):
+			httputil.NewError(c, http.StatusBadRequest, err)
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		case errors.Is(err, services.ErrTheaterNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		case errors.Is(err, services.ErrDuplicate):
+			httputil.NewError(c, http.StatusConflict, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, ConcessionResponse{
+		Message:    "concession created successfully",
+		Concession: *item,
+	})
+}
+
+// updateConcession godoc
+//
+//	@Summary		Update Concession
+//	@Description	Update the price, description or items of a concession, or mark it unavailable
+//	@Tags			concessions
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int						true	"theater id"
+//	@Param			itemId	path		int						true	"concession id"
+//	@Param			input	body		UpdateConcessionInput	true	"updated concession"
+//	@Success		200		{object}	ConcessionResponse
+//	@Failure		400		{object}	httputil.ValidationError
+//	@Failure		401		{object}	httputil.HTTPError
+//	@Failure		403		{object}	httputil.HTTPError
+//	@Failure		404		{object}	httputil.HTTPError
+//	@Failure		409		{object}	httputil.HTTPError
+//	@Failure		500		{object}	httputil.HTTPError
+//	@Router			/api/theaters/{id}/concessions/{itemId} [patch]
+func (h *Application) updateConcessionHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	theaterId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid theater id"))
+		return
+	}
+
+	itemId, err := strconv.Atoi(c.Param("itemId"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid concession id"))
+		return
+	}
+
+	var input UpdateConcessionInput
+	if err := c.ShouldBind(&input); err != nil {
+		v := validator.New()
+		input.Validate(v)
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	v := validator.New()
+	if input.Validate(v); !v.Valid() {
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	item, err := h.services.Concessions.Update(user, theaterId, itemId, services.UpdateConcessionInput(input))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidCombo):
+			httputil.NewError(c, http.StatusBadRequest, err)
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		case errors.Is(err, services.ErrTheaterNotFound),
+			errors.Is(err, services.ErrConcessionNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		case errors.Is(err, services.ErrDuplicate),
+			errors.Is(err, services.ErrEditConflict):
+			httputil.NewError(c, http.StatusConflict, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, ConcessionResponse{
+		Message:    "concession updated successfully",
+		Concession: *item,
+	})
+}
+
+// deleteConcession godoc
+//
+//	@Summary		Delete Concession
+//	@Description	Remove a concession from the catalog of a theater. Items that are part of a combo can't be removed
+//	@Tags			concessions
+//	@Produce		json
+//	@Param			id		path		int	true	"theater id"
+//	@Param			itemId	path		int	true	"concession id"
+//	@Success		200		{object}	DeleteConcessionResponse
+//	@Failure		400		{object}	httputil.HTTPError
+//	@Failure		401		{object}	httputil.HTTPError
+//	@Failure		403		{object}	httputil.HTTPError
+//	@Failure		404		{object}	httputil.HTTPError
+//	@Failure		409		{object}	httputil.HTTPError
+//	@Failure		500		{object}	httputil.HTTPError
+//	@Router			/api/theaters/{id}/concessions/{itemId} [delete]
+func (h *Application) deleteConcessionHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	theaterId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid theater id"))
+		return
+	}
+
+	itemId, err := strconv.Atoi(c.Param("itemId"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid concession id"))
+		return
+	}
+
+	if err := h.services.Concessions.Delete(user, theaterId, itemId); err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		case errors.Is(err, services.ErrTheaterNotFound),
+			errors.Is(err, services.ErrConcessionNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		case errors.Is(err, services.ErrConcessionInUse):
+			httputil.NewError(c, http.StatusConflict, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, DeleteConcessionResponse{Message: "concession deleted successfully"})
+}
+
+// listConcessionOrders godoc
+//
+//	@Summary		List Concession Orders
+//	@Description	List the concession orders of a theater waiting to be picked up, by show start time. Defaults to the shows running in the next 24 hours
+//	@Tags			concessions
+//	@Produce		json
+//	@Param			id		path		int		true	"theater id"
+//	@Param			from	query		string	false	"shows running from (RFC 3339)"
+//	@Param			until	query		string	false	"shows starting before (RFC 3339)"
+//	@Success		200		{object}	ListConcessionOrdersResponse
+//	@Failure		400		{object}	httputil.HTTPError
+//	@Failure		401		{object}	httputil.HTTPError
+//	@Failure		403		{object}	httputil.HTTPError
+//	@Failure		404		{object}	httputil.HTTPError
+//	@Failure		500		{object}	httputil.HTTPError
+//	@Router			/api/theaters/{id}/concession-orders [get]
+func (h *Application) listConcessionOrdersHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	theaterId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid theater id"))
+		return
+	}
+
+	var query ConcessionOrdersQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		httputil.NewError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	v := validator.New()
+	if query.Validate(v); !v.Valid() {
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	orders, err := h.services.Concessions.Pending(user, theaterId, query.From, query.Until)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		case errors.Is(err, services.ErrTheaterNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, ListConcessionOrdersResponse{Orders: orders})
+}
+
+// collectConcessionOrder godoc
+//
+//	@Summary		Collect Concession Order
+//	@Description	Mark the concessions of a booking as picked up
+//	@Tags			concessions
+//	@Produce		json
+//	@Param			id			path		int	true	"theater id"
+//	@Param			bookingId	path		int	true	"booking id"
+//	@Success		200			{object}	CollectConcessionOrderResponse
+//	@Failure		400			{object}	httputil.HTTPError
+//	@Failure		401			{object}	httputil.HTTPError
+//	@Failure		403			{object}	httputil.HTTPError
+//	@Failure		404			{object}	httputil.HTTPError
+//	@Failure		500			{object}	httputil.HTTPError
+//	@Router			/api/theaters/{id}/concession-orders/{bookingId}/collect [post]
+func (h *Application) collectConcessionOrderHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	theaterId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid theater id"))
+		return
+	}
+
+	bookingId, err := strconv.Atoi(c.Param("bookingId"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid booking id"))
+		return
+	}
+
+	lines, err := h.services.Concessions.Collect(user, theaterId, bookingId)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		case errors.Is(err, services.ErrTheaterNotFound),
+			errors.Is(err, services.ErrNoConcessionOrder):
+			httputil.NewError(c, http.StatusNotFound, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, CollectConcessionOrderResponse{
+		Message: "concessions picked up successfully",
+		Lines:   lines,
+	})
+}
+
+type CreateConcessionInput struct {
+	Name        string                  `json:"name"`
+	Description string                  `json:"description"`
+	Kind        string                  `json:"kind"`
+	Price       int64                   `json:"price"`
+	Components  []models.ComboComponent `json:"components"`
+}
+
+func (i *CreateConcessionInput) Validate(v *validator.Validator) {
+	v.Check(len(strings.TrimSpace(i.Name)) > 0, "name", "required")
+	v.Check(len(i.Name) <= 50, "name", "must be at most 50 characters")
+	v.Check(len(i.Description) <= 200, "description", "must be at most 200 characters")
+	v.Check(i.Kind == models.ConcessionItem || i.Kind == models.ConcessionCombo, "kind", "must be item or combo")
+	v.Check(i.Price >= 0, "price", "must not be negative")
+
+	if i.Kind == models.ConcessionCombo {
+		v.Check(len(i.Components) > 0, "components", "a combo needs at least one item")
+	}
+	validateComboComponents(v, i.Components)
+}
+
+type UpdateConcessionInput struct {
+	Name        *string                 `json:"name"`
+	Description *string                 `json:"description"`
+	Price       *int64                  `json:"price"`
+	Available   *bool                   `json:"available"`
+	Components  []models.ComboComponent `json:"components"`
+}
+
+func (i *UpdateConcessionInput) Validate(v *validator.Validator) {
+	if i.Name != nil {
+		v.Check(len(strings.TrimSpace(*i.Name)) > 0, "name", "must not be empty")
+		v.Check(len(*i.Name) <= 50, "name", "must be at most 50 characters")
+	}
+	if i.Description != nil {
+		v.Check(len(*i.Description) <= 200, "description", "must be at most 200 characters")
+	}
+	if i.Price != nil {
+		v.Check(*i.Price >= 0, "price", "must not be negative")
+	}
+	validateComboComponents(v, i.Components)
+}
+
+func validateComboComponents(v *validator.Validator, components []models.ComboComponent) {
+	v.Check(len(components) <= 10, "components", "must have at most 10 items")
+	for _, component := range components {
+		v.Check(component.ItemID > 0, "components", "item_id is required")
+		v.Check(component.Quantity > 0 && component.Quantity <= 10, "components", "quantity must be between 1 and 10")
+	}
+}
+
+// ConcessionOrdersQuery limits the pending concession orders to the shows
+// running between From and Until.
+type ConcessionOrdersQuery struct {
+	From  *time.Time `form:"from"`
+	Until *time.Time `form:"until"`
+}
+
+func (q *ConcessionOrdersQuery) Validate(v *validator.Validator) {
+	if q.From != nil && q.Until != nil {
+		v.Check(q.Until.After(*q.From), "until", "must be after from")
+	}
+}
+
+type ListConcessionsResponse struct {
+	Concessions []models.Concession `json:"concessions"`
+}
+
+type ConcessionResponse struct {
+	Message    string            `json:"message"`
+	Concession models.Concession `json:"concession"`
+}
+
+type DeleteConcessionResponse struct {
+	Message string `json:"message"`
+}
+
+type ListConcessionOrdersResponse struct {
+	Orders []models.ConcessionOrder `json:"orders"`
+}
+
+type CollectConcessionOrderResponse struct {
+	Message string                  `json:"message"`
+	Lines   []models.ConcessionLine `json:"lines"`
+}
@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/httputil"
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+	"github.com/AhmadAbdelrazik/showtime/internal/services"
+	"github.com/AhmadAbdelrazik/showtime/pkg/money"
+	"github.com/AhmadAbdelrazik/showtime/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+// getExchangeRates godoc
+//
+//	@Summary		Get Exchange Rates
+//	@Description	Get the base currency and the exchange rates from it used by the reports and to price chain-wide amounts
+//	@Tags			currencies
+//	@Produce		json
+//	@Success		200	{object}	ExchangeRatesResponse
+//	@Failure		500	{object}	httputil.HTTPError
+//	@Router			/api/exchange-rates [get]
+func (h *Application) getExchangeRatesHandler(c *gin.Context) {
+	rates, err := h.services.Currencies.Rates()
+	if err != nil {
+		httputil.NewError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ExchangeRatesResponse{
+		Base:  h.services.Currencies.Base(),
+		Rates: rates,
+	})
+}
+
+// updateExchangeRates godoc
+//
+//	@Summary		Update Exchange Rates
+//	@Description	Replace the exchange rates from the base currency
+//	@Tags			currencies
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		UpdateExchangeRatesInput	true	"exchange rates"
+//	@Success		200		{object}	ExchangeRatesResponse
+//	@Failure		400		{object}	httputil.ValidationError
+//	@Failure		401		{object}	httputil.HTTPError
+//	@Failure		403		{object}	httputil.HTTPError
+//	@Failure		500		{object}	httputil.HTTPError
+//	@Router			/api/exchange-rates [put]
+func (h *Application) updateExchangeRatesHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	var input UpdateExchangeRatesInput
+	if err := c.ShouldBind(&input); err != nil {
+		v := validator.New()
+		input.Validate(v)
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	v := validator.New()
+	if input.Validate(v); !v.Valid() {
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	rates := make([]models.ExchangeRate, 0, len(input.Rates))
+	for _, rate := range input.Rates {
+		rates = append(rates, models.ExchangeRate{Currency: rate.Currency, Rate: rate.Rate})
+	}
+
+	rates, err := h.services.Currencies.UpdateRates(user, rates)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidExchangeRates):
+			httputil.NewError(c, http.StatusBadRequest, err)
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, ExchangeRatesResponse{
+		Message: "exchange rates updated successfully",
+		Base:    h.services.Currencies.Base(),
+		Rates:   rates,
+	})
+}
+
+// getSalesReport godoc
+//
+//	@Summary		Sales Report
+//	@Description	Sum up the sales of every theater in its currency and converted to a single one at the current exchange rates
+//	@Tags			reports
+//	@Produce		json
+//	@Param			from		query		string	false	"paid from, RFC 3339, 30 days before until by default"
+//	@Param			until		query		string	false	"paid until, RFC 3339, now by default"
+//	@Param			currency	query		string	false	"ISO 4217 code, the base currency by default"
+//	@Success		200			{object}	services.SalesReport
+//	@Failure		400			{object}	httputil.HTTPError
+//	@Failure		401			{object}	httputil.HTTPError
+//	@Failure		403			{object}	httputil.HTTPError
+//	@Failure		422			{object}	httputil.HTTPError
+//	@Failure		500			{object}	httputil.HTTPError
+//	@Router			/api/reports/sales [get]
+func (h *Application) getSalesReportHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	var query SalesReportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		httputil.NewError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	v := validator.New()
+	if query.Validate(v); !v.Valid() {
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	report, err := h.services.Reports.Sales(user, query.From, query.Until, query.Currency)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		case errors.Is(err, services.ErrNoExchangeRate):
+			httputil.NewError(c, http.StatusUnprocessableEntity, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+type UpdateExchangeRatesInput struct {
+	Rates []ExchangeRateInput `json:"rates"`
+}
+
+// ExchangeRateInput is how many units of Currency one unit of the base
+// currency is worth, as a decimal string, e.g. "48.35".
+type ExchangeRateInput struct {
+	Currency string `json:"currency"`
+	Rate     string `json:"rate"`
+}
+
+func (i *UpdateExchangeRatesInput) Validate(v *validator.Validator) {
+	v.Check(len(i.Rates) <= 100, "rates", "must have at most 100 rates")
+	for _, rate := range i.Rates {
+		v.Check(money.IsKnown(rate.Currency), "rates", "currency must be a supported ISO 4217 code")
+		_, err := money.ParseRate(rate.Rate)
+		v.Check(err == nil, "rates", "rate must be a positive decimal number")
+		v.Check(len(rate.Rate) <= 21, "rates", "rate must be at most 21 characters")
+	}
+}
+
+type ExchangeRatesResponse struct {
+	Message string                `json:"message,omitempty"`
+	Base    string                `json:"base"`
+	Rates   []models.ExchangeRate `json:"rates"`
+}
+
+type SalesReportQuery struct {
+	From     *time.Time `form:"from"`
+	Until    *time.Time `form:"until"`
+	Currency string     `form:"currency"`
+}
+
+func (q *SalesReportQuery) Validate(v *validator.Validator) {
+	if q.From != nil && q.Until != nil {
+		v.Check(q.Until.After(*q.From), "until", "must be after from")
+	}
+	v.Check(q.Currency == "" || money.IsKnown(q.Currency), "currency", "must be a supported ISO 4217 code")
+}
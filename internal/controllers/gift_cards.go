@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/httputil"
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+	"github.com/AhmadAbdelrazik/showtime/internal/services"
+	"github.com/AhmadAbdelrazik/showtime/pkg/money"
+	"github.com/AhmadAbdelrazik/showtime/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+// purchaseGiftCard godoc
+//
+//	@Summary		Purchase Gift Card
+//	@Description	Pay for a gift card with a stored balance to spend on bookings
+//	@Tags			gift cards
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		PurchaseGiftCardInput	true	"gift card amount and payment"
+//	@Success		201		{object}	GiftCardResponse
+//	@Success		202		{object}	GiftCardResponse
+//	@Failure		400		{object}	httputil.ValidationError
+//	@Failure		401		{object}	httputil.HTTPError
+//	@Failure		402		{object}	httputil.HTTPError
+//	@Failure		500		{object}	httputil.HTTPError
+//	@Failure		504		{object}	httputil.HTTPError
+//	@Router			/api/gift-cards [post]
+func (h *Application) purchaseGiftCardHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	var input PurchaseGiftCardInput
+	if err := c.ShouldBind(&input); err != nil {
+		v := validator.New()
+		input.Validate(v)
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	v := validator.New()
+	if input.Validate(v); !v.Valid() {
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	card, err := h.services.GiftCards.Purchase(user, services.PurchaseGiftCardInput(input))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrPaymentPending):
+			c.JSON(http.StatusAccepted, GiftCardResponse{
+				Message:  "payment is being confirmed, check the gift card again shortly",
+				GiftCard: *card,
+			})
+		case errors.Is(err, services.ErrPaymentDeclined):
+			httputil.NewError(c, http.StatusPaymentRequired, err)
+		case errors.Is(err, services.ErrPaymentTimeout):
+			httputil.NewError(c, http.StatusGatewayTimeout, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, GiftCardResponse{
+		Message:  "gift card purchased successfully",
+		GiftCard: *card,
+	})
+}
+
+// listGiftCards godoc
+//
+//	@Summary		List Gift Cards
+//	@Description	List the gift cards the current user purchased
+//	@Tags			gift cards
+//	@Produce		json
+//	@Success		200	{object}	ListGiftCardsResponse
+//	@Failure		401	{object}	httputil.HTTPError
+//	@Failure		500	{object}	httputil.HTTPError
+//	@Router			/api/gift-cards [get]
+func (h *Application) listGiftCardsHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	cards, err := h.services.GiftCards.List(user)
+	if err != nil {
+		httputil.NewError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ListGiftCardsResponse{GiftCards: cards})
+}
+
+// getGiftCardBalance godoc
+//
+//	@Summary		Get Gift Card Balance
+//	@Description	Get the balance, expiry date and ledger of a gift card by its code
+//	@Tags			gift cards
+//	@Produce		json
+//	@Param			code	path		string	true	"gift card code"
+//	@Success		200		{object}	GiftCardResponse
+//	@Failure		404		{object}	httputil.HTTPError
+//	@Failure		500		{object}	httputil.HTTPError
+//	@Router			/api/gift-cards/{code}/balance [get]
+func (h *Application) getGiftCardBalanceHandler(c *gin.Context) {
+	card, err := h.services.GiftCards.Balance(strings.TrimSpace(c.Param("code")))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrGiftCardNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, GiftCardResponse{GiftCard: *card})
+}
+
+type PurchaseGiftCardInput struct {
+	Amount       int64  `json:"amount"`
+	Currency     string `json:"currency"`
+	PaymentToken string `json:"payment_token"`
+}
+
+func (i *PurchaseGiftCardInput) Validate(v *validator.Validator) {
+	v.Check(i.Amount >= 100 && i.Amount <= 10_000_000, "amount", "must be between 100 and 10000000")
+	v.Check(i.Currency == "" || money.IsKnown(i.Currency), "currency", "must be a supported ISO 4217 code")
+
+	v.Check(len(strings.TrimSpace(i.PaymentToken)) > 0, "payment_token", "required")
+	v.Check(len(i.PaymentToken) <= 100, "payment_token", "must be at most 100 characters")
+}
+
+type GiftCardResponse struct {
+	Message  string          `json:"message,omitempty"`
+	GiftCard models.GiftCard `json:"gift_card"`
+}
+
+type ListGiftCardsResponse struct {
+	GiftCards []models.GiftCard `json:"gift_cards"`
+}
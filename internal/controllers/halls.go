@@ -3,6 +3,7 @@ package controllers
 import (
 	"errors"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -47,6 +48,42 @@ func (h *Application) getHallHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, GetHallResponse{*hall})
 }
 
+// getHallSeatMap godoc
+//
+//	@Summary		Get Hall Seat Map
+//	@Description	Get the seating of a hall as a grid of rows, seats, gaps and aisles, as JSON or as an SVG drawing
+//	@Tags			halls
+//	@Produce		json
+//	@Produce		image/svg+xml
+//	@Param			id		path		int		true	"theater id"
+//	@Param			code	path		string	true	"hall code"
+//	@Success		200		{object}	services.SeatMap
+//	@Failure		400		{object}	httputil.HTTPError
+//	@Failure		404		{object}	httputil.HTTPError
+//	@Failure		500		{object}	httputil.HTTPError
+//	@Router			/api/theaters/{id}/halls/{code}/seatmap [get]
+func (h *Application) getHallSeatMapHandler(c *gin.Context) {
+	hallCode := c.Param("code")
+	theaterId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid theater id"))
+		return
+	}
+
+	seatMap, err := h.services.Halls.SeatMap(theaterId, hallCode)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrHallNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	renderSeatMap(c, seatMap)
+}
+
 // CreateHall godoc
 //
 //	@Summary		Create Hall
@@ -98,7 +135,10 @@ func (h *Application) createHallHandler(c *gin.Context) {
 			Rows:        input.Rows,
 			SeatsPerRow: input.SeatsPerRow,
 		},
-		TheaterID: theaterId,
+		TurnaroundMinutes: input.TurnaroundMinutes,
+		Layout:            input.Layout.hallLayout(),
+		Categories:        seatCategoryAssignments(input.Categories),
+		TheaterID:         theaterId,
 	}
 
 	// fetch theater from db
@@ -111,6 +151,9 @@ func (h *Application) createHallHandler(c *gin.Context) {
 			httputil.NewError(c, http.StatusNotFound, err)
 		case errors.Is(err, services.ErrDuplicate):
 			httputil.NewError(c, http.StatusConflict, err)
+		case errors.Is(err, services.ErrInvalidSeatCategory),
+			errors.Is(err, services.ErrInvalidLayout):
+			httputil.NewError(c, http.StatusBadRequest, err)
 		default:
 			httputil.NewError(c, http.StatusInternalServerError, err)
 		}
@@ -161,10 +204,12 @@ func (h *Application) updateHallHandler(c *gin.Context) {
 	}
 
 	serviceInput := services.UpdateHallInput{
-		User:      user,
-		TheaterId: theaterId,
-		HallCode:  hallCode,
-		Name:      input.Name,
+		User:              user,
+		TheaterId:         theaterId,
+		HallCode:          hallCode,
+		Name:              input.Name,
+		TurnaroundMinutes: input.TurnaroundMinutes,
+		InheritTurnaround: input.InheritTurnaround,
 	}
 
 	// add hall
@@ -187,6 +232,237 @@ func (h *Application) updateHallHandler(c *gin.Context) {
 	c.JSON(http.StatusCreated, CreateHallResponse{"hall created successfully", *hall})
 }
 
+// updateHallSeats godoc
+//
+//	@Summary		Update Hall Seats
+//	@Description	Set the categories (standard, vip, wheelchair, companion, couple) of seats of a hall
+//	@Tags			halls
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int						true	"theater id"
+//	@Param			code	path		string					true	"hall code"
+//	@Param			input	body		UpdateHallSeatsInput	true	"seat categories"
+//	@Success		200		{object}	UpdateHallSeatsResponse
+//	@Failure		400		{object}	httputil.ValidationError
+//	@Failure		401		{object}	httputil.HTTPError
+//	@Failure		403		{object}	httputil.HTTPError
+//	@Failure		404		{object}	httputil.HTTPError
+//	@Failure		500		{object}	httputil.HTTPError
+//	@Router			/api/theaters/{id}/halls/{code}/seats [patch]
+func (h *Application) updateHallSeatsHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	hallCode := c.Param("code")
+	theaterId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid theater id"))
+		return
+	}
+
+	var input UpdateHallSeatsInput
+	if err := c.ShouldBind(&input); err != nil {
+		v := validator.New()
+		input.Validate(v)
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	v := validator.New()
+	if input.Validate(v); !v.Valid() {
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	seating, err := h.services.Halls.UpdateSeatCategories(user, theaterId, hallCode, seatCategoryAssignments(input.Categories))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		case errors.Is(err, services.ErrHallNotFound),
+			errors.Is(err, services.ErrTheaterNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		case errors.Is(err, services.ErrInvalidSeatCategory):
+			httputil.NewError(c, http.StatusBadRequest, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, UpdateHallSeatsResponse{
+		Message: "seats updated successfully",
+		Seating: *seating,
+	})
+}
+
+// listHallLayouts godoc
+//
+//	@Summary		List Hall Layouts
+//	@Description	List the published layout revisions of a hall, newest first, with the number of upcoming shows on each
+//	@Tags			halls
+//	@Produce		json
+//	@Param			id		path		int		true	"theater id"
+//	@Param			code	path		string	true	"hall code"
+//	@Success		200		{object}	ListHallLayoutsResponse
+//	@Failure		400		{object}	httputil.HTTPError
+//	@Failure		401		{object}	httputil.HTTPError
+//	@Failure		403		{object}	httputil.HTTPError
+//	@Failure		404		{object}	httputil.HTTPError
+//	@Failure		500		{object}	httputil.HTTPError
+//	@Router			/api/theaters/{id}/halls/{code}/layouts [get]
+func (h *Application) listHallLayoutsHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	hallCode := c.Param("code")
+	theaterId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid theater id"))
+		return
+	}
+
+	revisions, err := h.services.Halls.Layouts(user, theaterId, hallCode)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		case errors.Is(err, services.ErrHallNotFound),
+			errors.Is(err, services.ErrTheaterNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, ListHallLayoutsResponse{Layouts: revisions})
+}
+
+// publishHallLayout godoc
+//
+//	@Summary		Publish Hall Layout
+//	@Description	Publish a new layout revision of a hall. Shows scheduled afterwards use it; existing shows keep the layout they were sold against
+//	@Tags			halls
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int					true	"theater id"
+//	@Param			code	path		string				true	"hall code"
+//	@Param			input	body		PublishLayoutInput	true	"new layout"
+//	@Success		201		{object}	PublishLayoutResponse
+//	@Failure		400		{object}	httputil.ValidationError
+//	@Failure		401		{object}	httputil.HTTPError
+//	@Failure		403		{object}	httputil.HTTPError
+//	@Failure		404		{object}	httputil.HTTPError
+//	@Failure		500		{object}	httputil.HTTPError
+//	@Router			/api/theaters/{id}/halls/{code}/layouts [post]
+func (h *Application) publishHallLayoutHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	hallCode := c.Param("code")
+	theaterId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid theater id"))
+		return
+	}
+
+	var input PublishLayoutInput
+	if err := c.ShouldBind(&input); err != nil {
+		v := validator.New()
+		input.Validate(v)
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	v := validator.New()
+	if input.Validate(v); !v.Valid() {
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	revision, err := h.services.Halls.PublishLayout(services.PublishLayoutInput{
+		User:        user,
+		TheaterID:   theaterId,
+		HallCode:    hallCode,
+		Rows:        input.Rows,
+		SeatsPerRow: input.SeatsPerRow,
+		Layout:      input.Layout.hallLayout(),
+		Categories:  seatCategoryAssignments(input.Categories),
+		Note:        input.Note,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		case errors.Is(err, services.ErrHallNotFound),
+			errors.Is(err, services.ErrTheaterNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		case errors.Is(err, services.ErrInvalidSeatCategory),
+			errors.Is(err, services.ErrInvalidLayout):
+			httputil.NewError(c, http.StatusBadRequest, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, PublishLayoutResponse{
+		Message: "layout published successfully",
+		Layout:  *revision,
+	})
+}
+
+// getHallLayoutImpact godoc
+//
+//	@Summary		Hall Layout Migration Report
+//	@Description	List the upcoming shows on other layout versions and the held seats and bookings that would be affected by moving them onto the given version (the current one by default)
+//	@Tags			halls
+//	@Produce		json
+//	@Param			id		path		int		true	"theater id"
+//	@Param			code	path		string	true	"hall code"
+//	@Param			version	query		int		false	"target layout version"
+//	@Success		200		{object}	services.LayoutImpact
+//	@Failure		400		{object}	httputil.HTTPError
+//	@Failure		401		{object}	httputil.HTTPError
+//	@Failure		403		{object}	httputil.HTTPError
+//	@Failure		404		{object}	httputil.HTTPError
+//	@Failure		500		{object}	httputil.HTTPError
+//	@Router			/api/theaters/{id}/halls/{code}/layouts/impact [get]
+func (h *Application) getHallLayoutImpactHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	hallCode := c.Param("code")
+	theaterId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid theater id"))
+		return
+	}
+
+	version := 0
+	if param := c.Query("version"); param != "" {
+		version, err = strconv.Atoi(param)
+		if err != nil || version < 1 {
+			httputil.NewError(c, http.StatusBadRequest, errors.New("invalid layout version"))
+			return
+		}
+	}
+
+	impact, err := h.services.Halls.LayoutImpact(user, theaterId, hallCode, version)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		case errors.Is(err, services.ErrHallNotFound),
+			errors.Is(err, services.ErrTheaterNotFound),
+			errors.Is(err, services.ErrLayoutNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, impact)
+}
+
 // deleteHall godoc
 //
 //	@Summary		Delete Hall
@@ -232,10 +508,14 @@ type GetHallResponse struct {
 }
 
 type CreateHallInput struct {
-	Name        string `json:"name"`
-	Code        string `json:"code"`
-	Rows        int    `json:"rows"`
-	SeatsPerRow int    `json:"seats_per_row"`
+	Name        string              `json:"name"`
+	Code        string              `json:"code"`
+	Rows        int                 `json:"rows"`
+	SeatsPerRow int                 `json:"seats_per_row"`
+	Layout      *HallLayoutInput    `json:"layout"`
+	Categories  []SeatCategoryInput `json:"categories"`
+
+	TurnaroundMinutes *int `json:"turnaround_minutes"`
 }
 
 func (i *CreateHallInput) Validate(v *validator.Validator) {
@@ -246,6 +526,19 @@ func (i *CreateHallInput) Validate(v *validator.Validator) {
 	v.Check(len(strings.TrimSpace(i.Code)) > 0, "code", "required")
 	v.Check(validator.AlphanumRX.MatchString(i.Code), "code", "must not contain any spaces or special characters")
 	v.Check(len(i.Code) <= 10, "code", "must be at most 50 characters")
+
+	if i.Layout == nil {
+		v.Check(i.Rows > 0 && i.Rows <= 100, "rows", "must be between 1 and 100")
+		v.Check(i.SeatsPerRow > 0 && i.SeatsPerRow <= 100, "seats_per_row", "must be between 1 and 100")
+	} else {
+		i.Layout.Validate(v)
+	}
+
+	validateSeatCategories(v, i.Categories)
+
+	if i.TurnaroundMinutes != nil {
+		validateTurnaround(v, *i.TurnaroundMinutes)
+	}
 }
 
 func (i *CreateHallInput) Errors() map[string]string {
@@ -260,8 +553,12 @@ type CreateHallResponse struct {
 	Hall    models.Hall `json:"hall"`
 }
 
+// UpdateHallInput changes a hall's name or turnaround. InheritTurnaround
+// drops the hall's own turnaround for its theater's.
 type UpdateHallInput struct {
-	Name *string `json:"name"`
+	Name              *string `json:"name"`
+	TurnaroundMinutes *int    `json:"turnaround_minutes"`
+	InheritTurnaround bool    `json:"inherit_turnaround"`
 }
 
 func (i *UpdateHallInput) Validate(v *validator.Validator) {
@@ -270,6 +567,11 @@ func (i *UpdateHallInput) Validate(v *validator.Validator) {
 		v.Check(len(*i.Name) <= 30, "name", "must be at most 50 characters")
 		v.Check(len(*i.Name) > 5, "name", "must be at least 5 characters")
 	}
+
+	if i.TurnaroundMinutes != nil {
+		validateTurnaround(v, *i.TurnaroundMinutes)
+		v.Check(!i.InheritTurnaround, "inherit_turnaround", "must not be set along with turnaround_minutes")
+	}
 }
 
 func (i *UpdateHallInput) Errors() map[string]string {
@@ -287,3 +589,112 @@ type UpdateHallResponse struct {
 type DeleteHallResponse struct {
 	Message string `json:"message"`
 }
+
+// SeatCategoryInput sets the category of some seats of a row.
+type SeatCategoryInput struct {
+	Row      string `json:"row"`
+	Seats    []int  `json:"seats"`
+	Category string `json:"category"`
+}
+
+func validateSeatCategories(v *validator.Validator, categories []SeatCategoryInput) {
+	for _, c := range categories {
+		v.Check(len(strings.TrimSpace(c.Row)) > 0, "categories", "row is required")
+		v.Check(len(c.Seats) > 0, "categories", "seats are required")
+		v.Check(slices.Contains(models.SeatCategories, c.Category), "categories", "invalid category")
+	}
+}
+
+func seatCategoryAssignments(categories []SeatCategoryInput) []services.SeatCategoryAssignment {
+	assignments := make([]services.SeatCategoryAssignment, len(categories))
+	for i, c := range categories {
+		assignments[i] = services.SeatCategoryAssignment{
+			Row:         c.Row,
+			SeatNumbers: c.Seats,
+			Category:    c.Category,
+		}
+	}
+	return assignments
+}
+
+type UpdateHallSeatsInput struct {
+	Categories []SeatCategoryInput `json:"categories"`
+}
+
+func (i *UpdateHallSeatsInput) Validate(v *validator.Validator) {
+	v.Check(len(i.Categories) > 0, "categories", "required")
+	validateSeatCategories(v, i.Categories)
+}
+
+type UpdateHallSeatsResponse struct {
+	Message string         `json:"message"`
+	Seating models.Seating `json:"seating"`
+}
+
+type ListHallLayoutsResponse struct {
+	Layouts []models.HallLayoutRevision `json:"layouts"`
+}
+
+// PublishLayoutInput describes a new hall layout the same way hall creation
+// does: either a plain grid of rows or an explicit layout.
+type PublishLayoutInput struct {
+	Rows        int                 `json:"rows"`
+	SeatsPerRow int                 `json:"seats_per_row"`
+	Layout      *HallLayoutInput    `json:"layout"`
+	Categories  []SeatCategoryInput `json:"categories"`
+	Note        string              `json:"note"`
+}
+
+func (i *PublishLayoutInput) Validate(v *validator.Validator) {
+	if i.Layout == nil {
+		v.Check(i.Rows > 0 && i.Rows <= 100, "rows", "must be between 1 and 100")
+		v.Check(i.SeatsPerRow > 0 && i.SeatsPerRow <= 100, "seats_per_row", "must be between 1 and 100")
+	} else {
+		i.Layout.Validate(v)
+	}
+
+	validateSeatCategories(v, i.Categories)
+	v.Check(len(i.Note) <= 200, "note", "must be at most 200 characters")
+}
+
+type PublishLayoutResponse struct {
+	Message string                    `json:"message"`
+	Layout  models.HallLayoutRevision `json:"layout"`
+}
+
+// HallLayoutInput is an explicit hall layout, given either as a textual grid
+// or as a list of rows. See services.HallLayout for the grid syntax.
+type HallLayoutInput struct {
+	Grid string           `json:"grid"`
+	Rows []LayoutRowInput `json:"rows"`
+}
+
+type LayoutRowInput struct {
+	Label       string `json:"label"`
+	Seats       int    `json:"seats"`
+	Offset      int    `json:"offset"`
+	AislesAfter []int  `json:"aisles_after"`
+}
+
+func (i *HallLayoutInput) Validate(v *validator.Validator) {
+	v.Check(i.Grid != "" || len(i.Rows) > 0, "layout", "grid or rows required")
+	v.Check(i.Grid == "" || len(i.Rows) == 0, "layout", "must have either grid or rows, not both")
+	v.Check(len(i.Grid) <= 20000, "layout", "grid must be at most 20000 characters")
+	v.Check(len(i.Rows) <= 100, "layout", "must have at most 100 rows")
+}
+
+func (i *HallLayoutInput) hallLayout() *services.HallLayout {
+	if i == nil {
+		return nil
+	}
+
+	layout := &services.HallLayout{
+		Grid: i.Grid,
+		Rows: make([]services.LayoutRow, len(i.Rows)),
+	}
+	for j, row := range i.Rows {
+		layout.Rows[j] = services.LayoutRow(row)
+	}
+
+	return layout
+}
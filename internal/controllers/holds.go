@@ -0,0 +1,236 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/httputil"
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+	"github.com/AhmadAbdelrazik/showtime/internal/services"
+	"github.com/AhmadAbdelrazik/showtime/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+// createHold godoc
+//
+//	@Summary		Hold Seats
+//	@Description	Hold specific seats of a show for the current user until checkout
+//	@Tags			holds
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int				true	"theater id"
+//	@Param			show_id	path		int				true	"show id"
+//	@Param			input	body		CreateHoldInput	true	"selected seats"
+//	@Success		201		{object}	CreateHoldResponse
+//	@Failure		400		{object}	httputil.ValidationError
+//	@Failure		401		{object}	httputil.HTTPError
+//	@Failure		404		{object}	httputil.HTTPError
+//	@Failure		409		{object}	httputil.HTTPError
+//	@Failure		500		{object}	httputil.HTTPError
+//	@Router			/api/theaters/{id}/shows/{show_id}/seats [post]
+func (h *Application) createHoldHandler(c *gin.Context) {
+	theaterId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid theater id"))
+		return
+	}
+	showId, err := strconv.Atoi(c.Param("showId"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid show id"))
+		return
+	}
+
+	user := c.MustGet("user").(*models.User)
+
+	var input CreateHoldInput
+	if err := c.ShouldBind(&input); err != nil {
+		v := validator.New()
+		input.Validate(v)
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	v := validator.New()
+	if input.Validate(v); !v.Valid() {
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	hold, err := h.services.Holds.Create(user, theaterId, showId, input.SeatIDs)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrShowNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		case errors.Is(err, services.ErrSeatUnavailable),
+			errors.Is(err, services.ErrShowStarted):
+			httputil.NewError(c, http.StatusConflict, err)
+		case errors.Is(err, services.ErrSeatCategoryRule):
+			httputil.NewError(c, http.StatusBadRequest, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateHoldResponse{
+		Message: "seats held successfully",
+		Hold:    *hold,
+	})
+}
+
+// getHold godoc
+//
+//	@Summary		Get Hold
+//	@Description	Get a seat hold of the current user
+//	@Tags			holds
+//	@Produce		json
+//	@Param			id	path		int	true	"hold id"
+//	@Success		200	{object}	models.Hold
+//	@Failure		400	{object}	httputil.HTTPError
+//	@Failure		401	{object}	httputil.HTTPError
+//	@Failure		403	{object}	httputil.HTTPError
+//	@Failure		404	{object}	httputil.HTTPError
+//	@Failure		500	{object}	httputil.HTTPError
+//	@Router			/api/holds/{id} [get]
+func (h *Application) getHoldHandler(c *gin.Context) {
+	holdId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid hold id"))
+		return
+	}
+
+	user := c.MustGet("user").(*models.User)
+
+	hold, err := h.services.Holds.Find(user, holdId)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrHoldNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, hold)
+}
+
+// extendHold godoc
+//
+//	@Summary		Extend Hold
+//	@Description	Restart the countdown of an active seat hold
+//	@Tags			holds
+//	@Produce		json
+//	@Param			id	path		int	true	"hold id"
+//	@Success		200	{object}	ExtendHoldResponse
+//	@Failure		400	{object}	httputil.HTTPError
+//	@Failure		401	{object}	httputil.HTTPError
+//	@Failure		403	{object}	httputil.HTTPError
+//	@Failure		404	{object}	httputil.HTTPError
+//	@Failure		409	{object}	httputil.HTTPError
+//	@Failure		500	{object}	httputil.HTTPError
+//	@Router			/api/holds/{id}/extend [post]
+func (h *Application) extendHoldHandler(c *gin.Context) {
+	holdId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid hold id"))
+		return
+	}
+
+	user := c.MustGet("user").(*models.User)
+
+	hold, err := h.services.Holds.Extend(user, holdId)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrHoldNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		case errors.Is(err, services.ErrHoldNotActive):
+			httputil.NewError(c, http.StatusConflict, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, ExtendHoldResponse{
+		Message: "hold extended successfully",
+		Hold:    *hold,
+	})
+}
+
+// releaseHold godoc
+//
+//	@Summary		Release Hold
+//	@Description	Release the seats of an active seat hold
+//	@Tags			holds
+//	@Produce		json
+//	@Param			id	path		int	true	"hold id"
+//	@Success		200	{object}	ReleaseHoldResponse
+//	@Failure		400	{object}	httputil.HTTPError
+//	@Failure		401	{object}	httputil.HTTPError
+//	@Failure		403	{object}	httputil.HTTPError
+//	@Failure		404	{object}	httputil.HTTPError
+//	@Failure		409	{object}	httputil.HTTPError
+//	@Failure		500	{object}	httputil.HTTPError
+//	@Router			/api/holds/{id} [delete]
+func (h *Application) releaseHoldHandler(c *gin.Context) {
+	holdId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid hold id"))
+		return
+	}
+
+	user := c.MustGet("user").(*models.User)
+
+	if err := h.services.Holds.Release(user, holdId); err != nil {
+		switch {
+		case errors.Is(err, services.ErrHoldNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		case errors.Is(err, services.ErrHoldNotActive):
+			httputil.NewError(c, http.StatusConflict, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, ReleaseHoldResponse{Message: "Released Successfully"})
+}
+
+type CreateHoldInput struct {
+	SeatIDs []int `json:"seat_ids"`
+}
+
+func (i *CreateHoldInput) Validate(v *validator.Validator) {
+	v.Check(len(i.SeatIDs) > 0, "seat_ids", "required")
+	v.Check(len(i.SeatIDs) <= 10, "seat_ids", "must be at most 10 seats")
+
+	seen := make(map[int]bool, len(i.SeatIDs))
+	for _, id := range i.SeatIDs {
+		v.Check(id > 0, "seat_ids", fmt.Sprintf("invalid seat id %v", id))
+		v.Check(!seen[id], "seat_ids", fmt.Sprintf("seat %v is selected more than once", id))
+		seen[id] = true
+	}
+}
+
+type CreateHoldResponse struct {
+	Message string      `json:"message"`
+	Hold    models.Hold `json:"hold"`
+}
+
+type ExtendHoldResponse struct {
+	Message string      `json:"message"`
+	Hold    models.Hold `json:"hold"`
+}
+
+type ReleaseHoldResponse struct {
+	Message string `json:"message"`
+}
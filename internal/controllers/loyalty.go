@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/httputil"
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+	"github.com/AhmadAbdelrazik/showtime/internal/services"
+	"github.com/AhmadAbdelrazik/showtime/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+// listLoyaltyTransactions godoc
+//
+//	@Summary		List Loyalty Transactions
+//	@Description	List the points earned, redeemed and given back of the current customer
+//	@Tags			loyalty
+//	@Produce		json
+//	@Success		200	{object}	ListLoyaltyTransactionsResponse
+//	@Failure		401	{object}	httputil.HTTPError
+//	@Failure		403	{object}	httputil.HTTPError
+//	@Failure		500	{object}	httputil.HTTPError
+//	@Router			/api/loyalty/transactions [get]
+func (h *Application) listLoyaltyTransactionsHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	transactions, err := h.services.Loyalty.Transactions(user)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNotLoyaltyMember):
+			httputil.NewError(c, http.StatusForbidden, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, ListLoyaltyTransactionsResponse{Transactions: transactions})
+}
+
+// getLoyaltyRules godoc
+//
+//	@Summary		Get Loyalty Rules
+//	@Description	Get the earn and burn rules and the tiers of the loyalty program
+//	@Tags			loyalty
+//	@Produce		json
+//	@Success		200	{object}	LoyaltyRulesResponse
+//	@Failure		500	{object}	httputil.HTTPError
+//	@Router			/api/loyalty/rules [get]
+func (h *Application) getLoyaltyRulesHandler(c *gin.Context) {
+	rules, err := h.services.Loyalty.Rules()
+	if err != nil {
+		httputil.NewError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, LoyaltyRulesResponse{Rules: *rules})
+}
+
+// updateLoyaltyRules godoc
+//
+//	@Summary		Update Loyalty Rules
+//	@Description	Replace the earn and burn rules and the tiers of the loyalty program
+//	@Tags			loyalty
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		UpdateLoyaltyRulesInput	true	"loyalty rules"
+//	@Success		200		{object}	LoyaltyRulesResponse
+//	@Failure		400		{object}	httputil.ValidationError
+//	@Failure		401		{object}	httputil.HTTPError
+//	@Failure		403		{object}	httputil.HTTPError
+//	@Failure		500		{object}	httputil.HTTPError
+//	@Router			/api/loyalty/rules [put]
+func (h *Application) updateLoyaltyRulesHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	var input UpdateLoyaltyRulesInput
+	if err := c.ShouldBind(&input); err != nil {
+		v := validator.New()
+		input.Validate(v)
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	v := validator.New()
+	if input.Validate(v); !v.Valid() {
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	rules, err := h.services.Loyalty.UpdateRules(user, models.LoyaltyRules{
+		PointsPerTicket:  input.PointsPerTicket,
+		PointValue:       input.PointValue,
+		FreeTicketPoints: input.FreeTicketPoints,
+		Tiers:            input.Tiers,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidLoyaltyRules):
+			httputil.NewError(c, http.StatusBadRequest, err)
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, LoyaltyRulesResponse{
+		Message: "loyalty rules updated successfully",
+		Rules:   *rules,
+	})
+}
+
+type UpdateLoyaltyRulesInput struct {
+	PointsPerTicket  int                  `json:"points_per_ticket"`
+	PointValue       int64                `json:"point_value"`
+	FreeTicketPoints int                  `json:"free_ticket_points"`
+	Tiers            []models.LoyaltyTier `json:"tiers"`
+}
+
+func (i *UpdateLoyaltyRulesInput) Validate(v *validator.Validator) {
+	v.Check(i.PointsPerTicket >= 0 && i.PointsPerTicket <= 10_000, "points_per_ticket", "must be between 0 and 10000")
+	v.Check(i.PointValue >= 0 && i.PointValue <= 100_000, "point_value", "must be between 0 and 100000")
+	v.Check(i.FreeTicketPoints > 0 && i.FreeTicketPoints <= 1_000_000, "free_ticket_points", "must be between 1 and 1000000")
+
+	seen := make(map[string]bool, len(i.Tiers))
+	for _, tier := range i.Tiers {
+		v.Check(slices.Contains(models.LoyaltyTiers, tier.Name), "tiers", "name must be silver or gold")
+		v.Check(!seen[tier.Name], "tiers", "name must be unique")
+		v.Check(tier.MinPoints > 0, "tiers", "min_points must be positive")
+		v.Check(tier.EarnBonus >= 0 && tier.EarnBonus <= 1000, "tiers", "earn_bonus must be between 0 and 1000")
+		v.Check(tier.Discount >= 0 && tier.Discount <= 100, "tiers", "discount must be between 0 and 100")
+		seen[tier.Name] = true
+	}
+}
+
+type LoyaltyRulesResponse struct {
+	Message string              `json:"message,omitempty"`
+	Rules   models.LoyaltyRules `json:"rules"`
+}
+
+type ListLoyaltyTransactionsResponse struct {
+	Transactions []models.LoyaltyTransaction `json:"transactions"`
+}
@@ -0,0 +1,341 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/httputil"
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+	"github.com/AhmadAbdelrazik/showtime/internal/services"
+	"github.com/AhmadAbdelrazik/showtime/pkg/money"
+	"github.com/AhmadAbdelrazik/showtime/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+// listMembershipPlans godoc
+//
+//	@Summary		List Membership Plans
+//	@Description	List the membership plans on sale
+//	@Tags			memberships
+//	@Produce		json
+//	@Success		200	{object}	ListMembershipPlansResponse
+//	@Failure		500	{object}	httputil.HTTPError
+//	@Router			/api/membership-plans [get]
+func (h *Application) listMembershipPlansHandler(c *gin.Context) {
+	plans, err := h.services.Members.Plans()
+	if err != nil {
+		httputil.NewError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ListMembershipPlansResponse{Plans: plans})
+}
+
+// createMembershipPlan godoc
+//
+//	@Summary		Create Membership Plan
+//	@Description	Create a membership plan with free tickets per period, or unlimited with one active booking at a time
+//	@Tags			memberships
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		CreateMembershipPlanInput	true	"membership plan"
+//	@Success		201		{object}	MembershipPlanResponse
+//	@Failure		400		{object}	httputil.ValidationError
+//	@Failure		401		{object}	httputil.HTTPError
+//	@Failure		403		{object}	httputil.HTTPError
+//	@Failure		409		{object}	httputil.HTTPError
+//	@Failure		500		{object}	httputil.HTTPError
+//	@Router			/api/membership-plans [post]
+func (h *Application) createMembershipPlanHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	var input CreateMembershipPlanInput
+	if err := c.ShouldBind(&input); err != nil {
+		v := validator.New()
+		input.Validate(v)
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	v := validator.New()
+	if input.Validate(v); !v.Valid() {
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	plan, err := h.services.Members.CreatePlan(user, services.CreatePlanInput(input))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		case errors.Is(err, services.ErrDuplicate):
+			httputil.NewError(c, http.StatusConflict, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, MembershipPlanResponse{
+		Message: "membership plan created successfully",
+		Plan:    *plan,
+	})
+}
+
+// updateMembershipPlan godoc
+//
+//	@Summary		Update Membership Plan
+//	@Description	Rename or reprice a membership plan, or stop selling it. Members pay the new price from their next renewal
+//	@Tags			memberships
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int							true	"membership plan id"
+//	@Param			input	body		UpdateMembershipPlanInput	true	"updated membership plan"
+//	@Success		200		{object}	MembershipPlanResponse
+//	@Failure		400		{object}	httputil.ValidationError
+//	@Failure		401		{object}	httputil.HTTPError
+//	@Failure		403		{object}	httputil.HTTPError
+//	@Failure		404		{object}	httputil.HTTPError
+//	@Failure		409		{object}	httputil.HTTPError
+//	@Failure		500		{object}	httputil.HTTPError
+//	@Router			/api/membership-plans/{id} [patch]
+func (h *Application) updateMembershipPlanHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	planId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid membership plan id"))
+		return
+	}
+
+	var input UpdateMembershipPlanInput
+	if err := c.ShouldBind(&input); err != nil {
+		v := validator.New()
+		input.Validate(v)
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	v := validator.New()
+	if input.Validate(v); !v.Valid() {
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	plan, err := h.services.Members.UpdatePlan(user, planId, services.UpdatePlanInput(input))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		case errors.Is(err, services.ErrPlanNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		case errors.Is(err, services.ErrDuplicate),
+			errors.Is(err, services.ErrEditConflict):
+			httputil.NewError(c, http.StatusConflict, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, MembershipPlanResponse{
+		Message: "membership plan updated successfully",
+		Plan:    *plan,
+	})
+}
+
+// subscribe godoc
+//
+//	@Summary		Subscribe
+//	@Description	Pay for the first period of a membership plan. The payment token is charged again on every renewal
+//	@Tags			memberships
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		SubscribeInput	true	"membership plan and payment"
+//	@Success		201		{object}	SubscriptionResponse
+//	@Success		202		{object}	SubscriptionResponse
+//	@Failure		400		{object}	httputil.ValidationError
+//	@Failure		401		{object}	httputil.HTTPError
+//	@Failure		402		{object}	httputil.HTTPError
+//	@Failure		403		{object}	httputil.HTTPError
+//	@Failure		404		{object}	httputil.HTTPError
+//	@Failure		409		{object}	httputil.HTTPError
+//	@Failure		500		{object}	httputil.HTTPError
+//	@Failure		504		{object}	httputil.HTTPError
+//	@Router			/api/subscription [post]
+func (h *Application) subscribeHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	var input SubscribeInput
+	if err := c.ShouldBind(&input); err != nil {
+		v := validator.New()
+		input.Validate(v)
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	v := validator.New()
+	if input.Validate(v); !v.Valid() {
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	sub, err := h.services.Members.Subscribe(user, services.SubscribeInput(input))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrPaymentPending):
+			c.JSON(http.StatusAccepted, SubscriptionResponse{
+				Message:      "payment is being confirmed, check the membership again shortly",
+				Subscription: *sub,
+			})
+		case errors.Is(err, services.ErrMembershipsCustomers):
+			httputil.NewError(c, http.StatusForbidden, err)
+		case errors.Is(err, services.ErrPlanNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		case errors.Is(err, services.ErrAlreadySubscribed):
+			httputil.NewError(c, http.StatusConflict, err)
+		case errors.Is(err, services.ErrPaymentDeclined):
+			httputil.NewError(c, http.StatusPaymentRequired, err)
+		case errors.Is(err, services.ErrPaymentTimeout):
+			httputil.NewError(c, http.StatusGatewayTimeout, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, SubscriptionResponse{
+		Message:      "subscribed successfully",
+		Subscription: *sub,
+	})
+}
+
+// getSubscription godoc
+//
+//	@Summary		Get Subscription
+//	@Description	Get the membership of the current user with its billing history
+//	@Tags			memberships
+//	@Produce		json
+//	@Success		200	{object}	SubscriptionResponse
+//	@Failure		401	{object}	httputil.HTTPError
+//	@Failure		404	{object}	httputil.HTTPError
+//	@Failure		500	{object}	httputil.HTTPError
+//	@Router			/api/subscription [get]
+func (h *Application) getSubscriptionHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	sub, payments, err := h.services.Members.Current(user)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNoMembership):
+			httputil.NewError(c, http.StatusNotFound, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, SubscriptionResponse{
+		Subscription: *sub,
+		Payments:     payments,
+	})
+}
+
+// cancelSubscription godoc
+//
+//	@Summary		Cancel Subscription
+//	@Description	Stop renewing the membership of the current user. Its entitlements stay until the end of the paid period
+//	@Tags			memberships
+//	@Produce		json
+//	@Success		200	{object}	SubscriptionResponse
+//	@Failure		401	{object}	httputil.HTTPError
+//	@Failure		404	{object}	httputil.HTTPError
+//	@Failure		409	{object}	httputil.HTTPError
+//	@Failure		500	{object}	httputil.HTTPError
+//	@Router			/api/subscription [delete]
+func (h *Application) cancelSubscriptionHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	sub, err := h.services.Members.Cancel(user)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNoMembership):
+			httputil.NewError(c, http.StatusNotFound, err)
+		case errors.Is(err, services.ErrEditConflict):
+			httputil.NewError(c, http.StatusConflict, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, SubscriptionResponse{
+		Message:      "subscription cancelled successfully",
+		Subscription: *sub,
+	})
+}
+
+type CreateMembershipPlanInput struct {
+	Name             string `json:"name"`
+	Kind             string `json:"kind"`
+	TicketsPerPeriod *int   `json:"tickets_per_period"`
+	Price            int64  `json:"price"`
+	Currency         string `json:"currency"`
+	PeriodDays       int    `json:"period_days"`
+}
+
+func (i *CreateMembershipPlanInput) Validate(v *validator.Validator) {
+	v.Check(len(strings.TrimSpace(i.Name)) > 0, "name", "required")
+	v.Check(len(i.Name) <= 50, "name", "must be at most 50 characters")
+	v.Check(i.Kind == models.PlanTickets || i.Kind == models.PlanUnlimited, "kind", "must be tickets or unlimited")
+	if i.Kind == models.PlanTickets {
+		v.Check(i.TicketsPerPeriod != nil && *i.TicketsPerPeriod > 0 && *i.TicketsPerPeriod <= 100, "tickets_per_period", "must be between 1 and 100")
+	}
+	v.Check(i.Price > 0, "price", "must be positive")
+	v.Check(i.Currency == "" || money.IsKnown(i.Currency), "currency", "must be a supported ISO 4217 code")
+	v.Check(i.PeriodDays > 0 && i.PeriodDays <= 366, "period_days", "must be between 1 and 366")
+}
+
+type UpdateMembershipPlanInput struct {
+	Name   *string `json:"name"`
+	Price  *int64  `json:"price"`
+	Active *bool   `json:"active"`
+}
+
+func (i *UpdateMembershipPlanInput) Validate(v *validator.Validator) {
+	if i.Name != nil {
+		v.Check(len(strings.TrimSpace(*i.Name)) > 0, "name", "must not be empty")
+		v.Check(len(*i.Name) <= 50, "name", "must be at most 50 characters")
+	}
+	if i.Price != nil {
+		v.Check(*i.Price > 0, "price", "must be positive")
+	}
+}
+
+type SubscribeInput struct {
+	PlanID       int    `json:"plan_id"`
+	PaymentToken string `json:"payment_token"`
+}
+
+func (i *SubscribeInput) Validate(v *validator.Validator) {
+	v.Check(i.PlanID > 0, "plan_id", "required")
+	v.Check(len(strings.TrimSpace(i.PaymentToken)) > 0, "payment_token", "required")
+	v.Check(len(i.PaymentToken) <= 100, "payment_token", "must be at most 100 characters")
+}
+
+type ListMembershipPlansResponse struct {
+	Plans []models.MembershipPlan `json:"plans"`
+}
+
+type MembershipPlanResponse struct {
+	Message string                `json:"message"`
+	Plan    models.MembershipPlan `json:"plan"`
+}
+
+type SubscriptionResponse struct {
+	Message      string                       `json:"message,omitempty"`
+	Subscription models.Subscription          `json:"subscription"`
+	Payments     []models.SubscriptionPayment `json:"payments,omitempty"`
+}
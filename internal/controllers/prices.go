@@ -0,0 +1,431 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/httputil"
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+	"github.com/AhmadAbdelrazik/showtime/internal/services"
+	"github.com/AhmadAbdelrazik/showtime/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+// getPriceList godoc
+//
+//	@Summary		Get Price List
+//	@Description	Get the ticket prices of a theater: base prices per seat category, and percentages of them per show tier and ticket type
+//	@Tags			theaters
+//	@Produce		json
+//	@Param			id	path		int	true	"theater id"
+//	@Success		200	{object}	PriceListResponse
+//	@Failure		400	{object}	httputil.HTTPError
+//	@Failure		404	{object}	httputil.HTTPError
+//	@Failure		500	{object}	httputil.HTTPError
+//	@Router			/api/theaters/{id}/prices [get]
+func (h *Application) getPriceListHandler(c *gin.Context) {
+	theaterId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid theater id"))
+		return
+	}
+
+	list, err := h.services.Prices.PriceList(theaterId)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTheaterNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, PriceListResponse{Prices: *list})
+}
+
+// updatePriceList godoc
+//
+//	@Summary		Update Price List
+//	@Description	Replace the ticket prices of a theater. Anything left out falls back to the defaults
+//	@Tags			theaters
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int				true	"theater id"
+//	@Param			input	body		UpdatePriceListInput	true	"prices"
+//	@Success		200		{object}	PriceListResponse
+//	@Failure		400		{object}	httputil.ValidationError
+//	@Failure		401		{object}	httputil.HTTPError
+//	@Failure		403		{object}	httputil.HTTPError
+//	@Failure		404		{object}	httputil.HTTPError
+//	@Failure		500		{object}	httputil.HTTPError
+//	@Router			/api/theaters/{id}/prices [put]
+func (h *Application) updatePriceListHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	theaterId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid theater id"))
+		return
+	}
+
+	var input UpdatePriceListInput
+	if err := c.ShouldBind(&input); err != nil {
+		v := validator.New()
+		input.Validate(v)
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	v := validator.New()
+	if input.Validate(v); !v.Valid() {
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	list, err := h.services.Prices.UpdatePriceList(user, theaterId, models.PriceList{
+		Categories:  input.Categories,
+		Tiers:       input.Tiers,
+		TicketTypes: input.TicketTypes,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		case errors.Is(err, services.ErrTheaterNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, PriceListResponse{
+		Message: "prices updated successfully",
+		Prices:  *list,
+	})
+}
+
+type UpdatePriceListInput struct {
+	Categories  map[string]int64 `json:"categories"`
+	Tiers       map[string]int   `json:"tiers"`
+	TicketTypes map[string]int   `json:"ticket_types"`
+}
+
+func (i *UpdatePriceListInput) Validate(v *validator.Validator) {
+	for category, price := range i.Categories {
+		v.Check(slices.Contains(models.SeatCategories, category), "categories", "invalid category")
+		v.Check(price >= 0 && price <= 10_000_000, "categories", "price must be between 0 and 10000000")
+	}
+
+	for tier, percent := range i.Tiers {
+		v.Check(slices.Contains(models.PriceTiers, tier), "tiers", "invalid tier")
+		v.Check(percent >= 0 && percent <= 1000, "tiers", "percent must be between 0 and 1000")
+	}
+
+	for ticketType, percent := range i.TicketTypes {
+		v.Check(slices.Contains(models.TicketTypes, ticketType), "ticket_types", "invalid ticket type")
+		v.Check(percent >= 0 && percent <= 1000, "ticket_types", "percent must be between 0 and 1000")
+	}
+}
+
+type PriceListResponse struct {
+	Message string           `json:"message,omitempty"`
+	Prices  models.PriceList `json:"prices"`
+}
+
+// getPriceCurve godoc
+//
+//	@Summary		Get Price Curve
+//	@Description	Get the dynamic pricing curve of a theater
+//	@Tags			theaters
+//	@Produce		json
+//	@Param			id	path		int	true	"theater id"
+//	@Success		200	{object}	PriceCurveResponse
+//	@Failure		400	{object}	httputil.HTTPError
+//	@Failure		401	{object}	httputil.HTTPError
+//	@Failure		403	{object}	httputil.HTTPError
+//	@Failure		404	{object}	httputil.HTTPError
+//	@Failure		500	{object}	httputil.HTTPError
+//	@Router			/api/theaters/{id}/price-curve [get]
+func (h *Application) getPriceCurveHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	theaterId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid theater id"))
+		return
+	}
+
+	curve, err := h.services.Prices.PriceCurve(user, theaterId)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		case errors.Is(err, services.ErrTheaterNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, PriceCurveResponse{Curve: *curve})
+}
+
+// updatePriceCurve godoc
+//
+//	@Summary		Update Price Curve
+//	@Description	Replace the dynamic pricing curve of a theater. Prices follow occupancy and time to showtime within per-category floors and ceilings; an empty curve keeps prices static
+//	@Tags			theaters
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int					true	"theater id"
+//	@Param			input	body		PriceCurveInput	true	"price curve"
+//	@Success		200		{object}	PriceCurveResponse
+//	@Failure		400		{object}	httputil.ValidationError
+//	@Failure		401		{object}	httputil.HTTPError
+//	@Failure		403		{object}	httputil.HTTPError
+//	@Failure		404		{object}	httputil.HTTPError
+//	@Failure		500		{object}	httputil.HTTPError
+//	@Router			/api/theaters/{id}/price-curve [put]
+func (h *Application) updatePriceCurveHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	theaterId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid theater id"))
+		return
+	}
+
+	var input PriceCurveInput
+	if err := c.ShouldBind(&input); err != nil {
+		v := validator.New()
+		input.Validate(v)
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	v := validator.New()
+	if input.Validate(v); !v.Valid() {
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	curve, err := h.services.Prices.UpdatePriceCurve(user, theaterId, models.PriceCurve(input))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		case errors.Is(err, services.ErrTheaterNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, PriceCurveResponse{
+		Message: "price curve updated successfully",
+		Curve:   *curve,
+	})
+}
+
+// simulatePriceCurve godoc
+//
+//	@Summary		Simulate Price Curve
+//	@Description	Preview the adult price of a seat category for a grid of occupancies and hours before the show, under the given curve or the theater's own one
+//	@Tags			theaters
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int						true	"theater id"
+//	@Param			input	body		SimulatePricingInput	true	"simulation"
+//	@Success		200		{object}	services.PriceSimulation
+//	@Failure		400		{object}	httputil.ValidationError
+//	@Failure		401		{object}	httputil.HTTPError
+//	@Failure		403		{object}	httputil.HTTPError
+//	@Failure		404		{object}	httputil.HTTPError
+//	@Failure		500		{object}	httputil.HTTPError
+//	@Router			/api/theaters/{id}/price-curve/simulate [post]
+func (h *Application) simulatePriceCurveHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	theaterId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid theater id"))
+		return
+	}
+
+	var input SimulatePricingInput
+	if err := c.ShouldBind(&input); err != nil {
+		v := validator.New()
+		input.Validate(v)
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	if input.Category == "" {
+		input.Category = models.CategoryStandard
+	}
+	if input.Tier == "" {
+		input.Tier = models.TierEvening
+	}
+
+	v := validator.New()
+	if input.Validate(v); !v.Valid() {
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	simulateInput := services.SimulatePricingInput{
+		Category:    input.Category,
+		Tier:        input.Tier,
+		Occupancies: input.Occupancies,
+		HoursBefore: input.HoursBefore,
+	}
+	if input.Curve != nil {
+		curve := models.PriceCurve(*input.Curve)
+		simulateInput.Curve = &curve
+	}
+
+	simulation, err := h.services.Prices.Simulate(user, theaterId, simulateInput)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		case errors.Is(err, services.ErrTheaterNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, simulation)
+}
+
+// listPriceChanges godoc
+//
+//	@Summary		List Price Changes
+//	@Description	List the audit trail of the dynamic prices a show was sold at
+//	@Tags			shows
+//	@Produce		json
+//	@Param			id		path		int	true	"theater id"
+//	@Param			showId	path		int	true	"show id"
+//	@Success		200		{object}	ListPriceChangesResponse
+//	@Failure		400		{object}	httputil.HTTPError
+//	@Failure		401		{object}	httputil.HTTPError
+//	@Failure		403		{object}	httputil.HTTPError
+//	@Failure		404		{object}	httputil.HTTPError
+//	@Failure		500		{object}	httputil.HTTPError
+//	@Router			/api/theaters/{id}/shows/{showId}/price-changes [get]
+func (h *Application) listPriceChangesHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	theaterId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid theater id"))
+		return
+	}
+
+	showId, err := strconv.Atoi(c.Param("showId"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid show id"))
+		return
+	}
+
+	changes, err := h.services.Prices.PriceChanges(user, theaterId, showId)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		case errors.Is(err, services.ErrTheaterNotFound),
+			errors.Is(err, services.ErrShowNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, ListPriceChangesResponse{Changes: changes})
+}
+
+type PriceCurveInput struct {
+	Occupancy []models.CurvePoint `json:"occupancy"`
+	LeadTime  []models.CurvePoint `json:"lead_time"`
+	Floors    map[string]int64    `json:"floors"`
+	Ceilings  map[string]int64    `json:"ceilings"`
+}
+
+func (i *PriceCurveInput) Validate(v *validator.Validator) {
+	v.Check(len(i.Occupancy) <= 20, "occupancy", "must have at most 20 points")
+	v.Check(len(i.LeadTime) <= 20, "lead_time", "must have at most 20 points")
+
+	seen := make(map[int]bool, len(i.Occupancy))
+	for _, p := range i.Occupancy {
+		v.Check(p.Threshold >= 0 && p.Threshold <= 100, "occupancy", "threshold must be between 0 and 100")
+		v.Check(p.Percent >= 0 && p.Percent <= 1000, "occupancy", "percent must be between 0 and 1000")
+		v.Check(!seen[p.Threshold], "occupancy", "threshold must be unique")
+		seen[p.Threshold] = true
+	}
+
+	seen = make(map[int]bool, len(i.LeadTime))
+	for _, p := range i.LeadTime {
+		v.Check(p.Threshold > 0 && p.Threshold <= 24*90, "lead_time", "threshold must be between 1 hour and 90 days")
+		v.Check(p.Percent >= 0 && p.Percent <= 1000, "lead_time", "percent must be between 0 and 1000")
+		v.Check(!seen[p.Threshold], "lead_time", "threshold must be unique")
+		seen[p.Threshold] = true
+	}
+
+	for category, floor := range i.Floors {
+		v.Check(slices.Contains(models.SeatCategories, category), "floors", "invalid category")
+		v.Check(floor >= 0, "floors", "must not be negative")
+	}
+
+	for category, ceiling := range i.Ceilings {
+		v.Check(slices.Contains(models.SeatCategories, category), "ceilings", "invalid category")
+		v.Check(ceiling >= 0, "ceilings", "must not be negative")
+		if floor, ok := i.Floors[category]; ok {
+			v.Check(floor <= ceiling, "ceilings", "must not be below the floor")
+		}
+	}
+}
+
+type PriceCurveResponse struct {
+	Message string            `json:"message,omitempty"`
+	Curve   models.PriceCurve `json:"curve"`
+}
+
+type SimulatePricingInput struct {
+	Curve       *PriceCurveInput `json:"curve"`
+	Category    string           `json:"category"`
+	Tier        string           `json:"tier"`
+	Occupancies []int            `json:"occupancies"`
+	HoursBefore []int            `json:"hours_before"`
+}
+
+func (i *SimulatePricingInput) Validate(v *validator.Validator) {
+	if i.Curve != nil {
+		i.Curve.Validate(v)
+	}
+
+	v.Check(slices.Contains(models.SeatCategories, i.Category), "category", "invalid category")
+	v.Check(slices.Contains(models.PriceTiers, i.Tier), "tier", "invalid tier")
+
+	v.Check(len(i.Occupancies) <= 101, "occupancies", "must have at most 101 values")
+	for _, o := range i.Occupancies {
+		v.Check(o >= 0 && o <= 100, "occupancies", "must be between 0 and 100")
+	}
+
+	v.Check(len(i.HoursBefore) <= 50, "hours_before", "must have at most 50 values")
+	for _, h := range i.HoursBefore {
+		v.Check(h >= 0 && h <= 24*90, "hours_before", "must be between 0 and 90 days")
+	}
+}
+
+type ListPriceChangesResponse struct {
+	Changes []models.PriceChange `json:"changes"`
+}
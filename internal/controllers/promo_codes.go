@@ -0,0 +1,353 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/httputil"
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+	"github.com/AhmadAbdelrazik/showtime/internal/services"
+	"github.com/AhmadAbdelrazik/showtime/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+var promoCodeRX = regexp.MustCompile(`^[A-Za-z0-9_-]{3,30}$`)
+
+// listPromoCodes godoc
+//
+//	@Summary		List Promo Codes
+//	@Description	List the promo codes of a theater, or the promo codes valid at every theater when no theater is given (admins only)
+//	@Tags			promo codes
+//	@Produce		json
+//	@Param			theater_id	query		int	false	"theater id"
+//	@Success		200			{object}	ListPromoCodesResponse
+//	@Failure		400			{object}	httputil.HTTPError
+//	@Failure		401			{object}	httputil.HTTPError
+//	@Failure		403			{object}	httputil.HTTPError
+//	@Failure		404			{object}	httputil.HTTPError
+//	@Failure		500			{object}	httputil.HTTPError
+//	@Router			/api/promo-codes [get]
+func (h *Application) listPromoCodesHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	var theaterId *int
+	if param := c.Query("theater_id"); param != "" {
+		id, err := strconv.Atoi(param)
+		if err != nil {
+			httputil.NewError(c, http.StatusBadRequest, errors.New("invalid theater id"))
+			return
+		}
+		theaterId = &id
+	}
+
+	promos, err := h.services.Promos.List(user, theaterId)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		case errors.Is(err, services.ErrTheaterNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, ListPromoCodesResponse{PromoCodes: promos})
+}
+
+// createPromoCode godoc
+//
+//	@Summary		Create Promo Code
+//	@Description	Create a percent or fixed discount code for a theater, or for every theater (admins only)
+//	@Tags			promo codes
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		CreatePromoCodeInput	true	"promo code"
+//	@Success		201		{object}	PromoCodeResponse
+//	@Failure		400		{object}	httputil.ValidationError
+//	@Failure		401		{object}	httputil.HTTPError
+//	@Failure		403		{object}	httputil.HTTPError
+//	@Failure		404		{object}	httputil.HTTPError
+//	@Failure		409		{object}	httputil.HTTPError
+//	@Failure		500		{object}	httputil.HTTPError
+//	@Router			/api/promo-codes [post]
+func (h *Application) createPromoCodeHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	var input CreatePromoCodeInput
+	if err := c.ShouldBind(&input); err != nil {
+		v := validator.New()
+		input.Validate(v)
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	v := validator.New()
+	if input.Validate(v); !v.Valid() {
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	promo, err := h.services.Promos.Create(user, services.CreatePromoCodeInput(input))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidPromoCode):
+			httputil.NewError(c, http.StatusBadRequest, err)
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		case errors.Is(err, services.ErrTheaterNotFound),
+			errors.Is(err, services.ErrMovieNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		case errors.Is(err, services.ErrDuplicate):
+			httputil.NewError(c, http.StatusConflict, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, PromoCodeResponse{
+		Message:   "promo code created successfully",
+		PromoCode: *promo,
+	})
+}
+
+// getPromoCode godoc
+//
+//	@Summary		Get Promo Code
+//	@Description	Get a promo code with its number of redemptions
+//	@Tags			promo codes
+//	@Produce		json
+//	@Param			id	path		int	true	"promo code id"
+//	@Success		200	{object}	PromoCodeResponse
+//	@Failure		400	{object}	httputil.HTTPError
+//	@Failure		401	{object}	httputil.HTTPError
+//	@Failure		403	{object}	httputil.HTTPError
+//	@Failure		404	{object}	httputil.HTTPError
+//	@Failure		500	{object}	httputil.HTTPError
+//	@Router			/api/promo-codes/{id} [get]
+func (h *Application) getPromoCodeHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	promoId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid promo code id"))
+		return
+	}
+
+	promo, err := h.services.Promos.Find(user, promoId)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		case errors.Is(err, services.ErrPromoCodeNotFound),
+			errors.Is(err, services.ErrTheaterNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, PromoCodeResponse{PromoCode: *promo})
+}
+
+// updatePromoCode godoc
+//
+//	@Summary		Update Promo Code
+//	@Description	Update the discount, restrictions and limits of a promo code, or deactivate it
+//	@Tags			promo codes
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int						true	"promo code id"
+//	@Param			input	body		UpdatePromoCodeInput	true	"updated promo code"
+//	@Success		200		{object}	PromoCodeResponse
+//	@Failure		400		{object}	httputil.ValidationError
+//	@Failure		401		{object}	httputil.HTTPError
+//	@Failure		403		{object}	httputil.HTTPError
+//	@Failure		404		{object}	httputil.HTTPError
+//	@Failure		409		{object}	httputil.HTTPError
+//	@Failure		500		{object}	httputil.HTTPError
+//	@Router			/api/promo-codes/{id} [patch]
+func (h *Application) updatePromoCodeHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	promoId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid promo code id"))
+		return
+	}
+
+	var input UpdatePromoCodeInput
+	if err := c.ShouldBind(&input); err != nil {
+		v := validator.New()
+		input.Validate(v)
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	v := validator.New()
+	if input.Validate(v); !v.Valid() {
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	promo, err := h.services.Promos.Update(user, promoId, services.UpdatePromoCodeInput(input))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidPromoCode):
+			httputil.NewError(c, http.StatusBadRequest, err)
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		case errors.Is(err, services.ErrPromoCodeNotFound),
+			errors.Is(err, services.ErrTheaterNotFound),
+			errors.Is(err, services.ErrMovieNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		case errors.Is(err, services.ErrEditConflict):
+			httputil.NewError(c, http.StatusConflict, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, PromoCodeResponse{
+		Message:   "promo code updated successfully",
+		PromoCode: *promo,
+	})
+}
+
+// deletePromoCode godoc
+//
+//	@Summary		Delete Promo Code
+//	@Description	Delete a promo code. Bookings that redeemed it keep their discount
+//	@Tags			promo codes
+//	@Produce		json
+//	@Param			id	path		int	true	"promo code id"
+//	@Success		200	{object}	DeletePromoCodeResponse
+//	@Failure		400	{object}	httputil.HTTPError
+//	@Failure		401	{object}	httputil.HTTPError
+//	@Failure		403	{object}	httputil.HTTPError
+//	@Failure		404	{object}	httputil.HTTPError
+//	@Failure		500	{object}	httputil.HTTPError
+//	@Router			/api/promo-codes/{id} [delete]
+func (h *Application) deletePromoCodeHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	promoId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid promo code id"))
+		return
+	}
+
+	if err := h.services.Promos.Delete(user, promoId); err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		case errors.Is(err, services.ErrPromoCodeNotFound),
+			errors.Is(err, services.ErrTheaterNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, DeletePromoCodeResponse{Message: "promo code deleted successfully"})
+}
+
+type CreatePromoCodeInput struct {
+	TheaterID      *int       `json:"theater_id"`
+	Code           string     `json:"code"`
+	Kind           string     `json:"kind"`
+	Value          int64      `json:"value"`
+	MovieID        *string    `json:"movie_id"`
+	ShowsFrom      *time.Time `json:"shows_from"`
+	ShowsUntil     *time.Time `json:"shows_until"`
+	TicketTypes    []string   `json:"ticket_types"`
+	MinSeats       int        `json:"min_seats"`
+	MaxRedemptions *int       `json:"max_redemptions"`
+	MaxPerUser     *int       `json:"max_per_user"`
+}
+
+func (i *CreatePromoCodeInput) Validate(v *validator.Validator) {
+	v.Check(promoCodeRX.MatchString(i.Code), "code", "must be 3 to 30 letters, digits, dashes or underscores")
+
+	if i.TheaterID != nil {
+		v.Check(*i.TheaterID > 0, "theater_id", "invalid theater id")
+	}
+
+	v.Check(i.MinSeats >= 0 && i.MinSeats <= 50, "min_seats", "must be between 0 and 50")
+
+	validatePromoCodeFields(v, &i.Kind, &i.Value, i.MovieID, i.TicketTypes, i.MaxRedemptions, i.MaxPerUser)
+}
+
+type UpdatePromoCodeInput struct {
+	Kind           *string    `json:"kind"`
+	Value          *int64     `json:"value"`
+	MovieID        *string    `json:"movie_id"`
+	ShowsFrom      *time.Time `json:"shows_from"`
+	ShowsUntil     *time.Time `json:"shows_until"`
+	TicketTypes    []string   `json:"ticket_types"`
+	MinSeats       *int       `json:"min_seats"`
+	MaxRedemptions *int       `json:"max_redemptions"`
+	MaxPerUser     *int       `json:"max_per_user"`
+	Active         *bool      `json:"active"`
+}
+
+func (i *UpdatePromoCodeInput) Validate(v *validator.Validator) {
+	if i.MinSeats != nil {
+		v.Check(*i.MinSeats >= 1 && *i.MinSeats <= 50, "min_seats", "must be between 1 and 50")
+	}
+
+	validatePromoCodeFields(v, i.Kind, i.Value, i.MovieID, i.TicketTypes, i.MaxRedemptions, i.MaxPerUser)
+}
+
+// validatePromoCodeFields checks the fields shared by promo code creation
+// and updates. Nil fields are left out.
+func validatePromoCodeFields(v *validator.Validator, kind *string, value *int64, movieID *string, ticketTypes []string, maxRedemptions, maxPerUser *int) {
+	if kind != nil {
+		v.Check(*kind == models.PromoPercent || *kind == models.PromoFixed, "kind", "must be percent or fixed")
+	}
+
+	if value != nil {
+		v.Check(*value > 0, "value", "must be positive")
+		if kind != nil && *kind == models.PromoPercent {
+			v.Check(*value <= 100, "value", "percent must be at most 100")
+		}
+	}
+
+	if movieID != nil {
+		v.Check(len(*movieID) > 0 && len(*movieID) <= 12, "movie_id", "invalid movie id")
+	}
+
+	for _, ticketType := range ticketTypes {
+		v.Check(slices.Contains(models.TicketTypes, ticketType), "ticket_types", "must be one of adult, child, senior or student")
+	}
+
+	if maxRedemptions != nil {
+		v.Check(*maxRedemptions > 0, "max_redemptions", "must be positive")
+	}
+
+	if maxPerUser != nil {
+		v.Check(*maxPerUser > 0, "max_per_user", "must be positive")
+	}
+}
+
+type ListPromoCodesResponse struct {
+	PromoCodes []models.PromoCode `json:"promo_codes"`
+}
+
+type PromoCodeResponse struct {
+	Message   string           `json:"message,omitempty"`
+	PromoCode models.PromoCode `json:"promo_code"`
+}
+
+type DeletePromoCodeResponse struct {
+	Message string `json:"message"`
+}
@@ -28,16 +28,28 @@ func (a *Application) Routes(r *gin.Engine) {
 	// theaters
 	api.GET("/theaters", a.searchTheatersHandler)
 	api.GET("/theaters/:id", a.getTheaterHandler)
+	api.GET("/theaters/:id/prices", a.getPriceListHandler)
 
 	auth.POST("/theaters", a.createTheaterHandler)
 	auth.PATCH("/theaters/:id", a.updateTheaterHandler)
 	auth.DELETE("/theaters/:id", a.deleteTheaterHandler)
+	auth.PUT("/theaters/:id/refund-policy", a.updateRefundPolicyHandler)
+	auth.PUT("/theaters/:id/hours", a.updateOperatingHoursHandler)
+	auth.PUT("/theaters/:id/prices", a.updatePriceListHandler)
+	auth.GET("/theaters/:id/price-curve", a.getPriceCurveHandler)
+	auth.PUT("/theaters/:id/price-curve", a.updatePriceCurveHandler)
+	auth.POST("/theaters/:id/price-curve/simulate", a.simulatePriceCurveHandler)
 
 	// halls
 	api.GET("/theaters/:id/halls/:code", a.getHallHandler)
+	api.GET("/theaters/:id/halls/:code/seatmap", a.getHallSeatMapHandler)
 
 	auth.POST("/theaters/:id/halls", a.createHallHandler)
 	auth.PATCH("/theaters/:id/halls/:code", a.updateHallHandler)
+	auth.PATCH("/theaters/:id/halls/:code/seats", a.updateHallSeatsHandler)
+	auth.GET("/theaters/:id/halls/:code/layouts", a.listHallLayoutsHandler)
+	auth.POST("/theaters/:id/halls/:code/layouts", a.publishHallLayoutHandler)
+	auth.GET("/theaters/:id/halls/:code/layouts/impact", a.getHallLayoutImpactHandler)
 	auth.DELETE("/theaters/:id/halls/:code", a.deleteHallResponse)
 
 	// movies
@@ -51,5 +63,84 @@ func (a *Application) Routes(r *gin.Engine) {
 	api.GET("/theaters/:id/shows/:showId", a.getShowHandler)
 
 	auth.POST("/theaters/:id/shows", a.createShowHandler)
+	auth.POST("/theaters/:id/shows/recurring", a.createRecurringShowsHandler)
+	auth.POST("/theaters/:id/schedule/preview", a.previewScheduleHandler)
+	auth.POST("/theaters/:id/schedule", a.commitScheduleHandler)
 	auth.DELETE("/theaters/:id/shows/:showId", a.deleteShowHandler)
+	auth.GET("/theaters/:id/shows/:showId/price-changes", a.listPriceChangesHandler)
+
+	// seats
+	api.GET("/theaters/:id/shows/:showId/seats", a.getShowSeatsHandler)
+	api.GET("/theaters/:id/shows/:showId/seats/suggest", a.suggestShowSeatsHandler)
+	api.GET("/theaters/:id/shows/:showId/seatmap", a.getShowSeatMapHandler)
+
+	// holds
+	auth.POST("/theaters/:id/shows/:showId/seats", a.createHoldHandler)
+	auth.GET("/holds/:id", a.getHoldHandler)
+	auth.POST("/holds/:id/extend", a.extendHoldHandler)
+	auth.DELETE("/holds/:id", a.releaseHoldHandler)
+
+	// bookings
+	auth.GET("/bookings", a.listBookingsHandler)
+	auth.GET("/bookings/:id", a.getBookingHandler)
+	auth.GET("/bookings/:id/invoice.pdf", a.getInvoiceHandler)
+	auth.POST("/bookings", a.checkoutHandler)
+	auth.POST("/bookings/:id/cancel", a.cancelBookingHandler)
+
+	// waitlist
+	auth.POST("/theaters/:id/shows/:showId/waitlist", a.joinWaitlistHandler)
+	auth.GET("/waitlist", a.listWaitlistHandler)
+	auth.DELETE("/waitlist/:id", a.leaveWaitlistHandler)
+
+	// promo codes
+	auth.GET("/promo-codes", a.listPromoCodesHandler)
+	auth.POST("/promo-codes", a.createPromoCodeHandler)
+	auth.GET("/promo-codes/:id", a.getPromoCodeHandler)
+	auth.PATCH("/promo-codes/:id", a.updatePromoCodeHandler)
+	auth.DELETE("/promo-codes/:id", a.deletePromoCodeHandler)
+
+	// gift cards
+	api.GET("/gift-cards/:code/balance", a.getGiftCardBalanceHandler)
+
+	auth.GET("/gift-cards", a.listGiftCardsHandler)
+	auth.POST("/gift-cards", a.purchaseGiftCardHandler)
+
+	// loyalty
+	api.GET("/loyalty/rules", a.getLoyaltyRulesHandler)
+
+	auth.PUT("/loyalty/rules", a.updateLoyaltyRulesHandler)
+	auth.GET("/loyalty/transactions", a.listLoyaltyTransactionsHandler)
+
+	// memberships
+	api.GET("/membership-plans", a.listMembershipPlansHandler)
+
+	auth.POST("/membership-plans", a.createMembershipPlanHandler)
+	auth.PATCH("/membership-plans/:id", a.updateMembershipPlanHandler)
+	auth.GET("/subscription", a.getSubscriptionHandler)
+	auth.POST("/subscription", a.subscribeHandler)
+	auth.DELETE("/subscription", a.cancelSubscriptionHandler)
+
+	// concessions
+	api.GET("/theaters/:id/concessions", a.listConcessionsHandler)
+
+	auth.POST("/theaters/:id/concessions", a.createConcessionHandler)
+	auth.PATCH("/theaters/:id/concessions/:itemId", a.updateConcessionHandler)
+	auth.DELETE("/theaters/:id/concessions/:itemId", a.deleteConcessionHandler)
+	auth.GET("/theaters/:id/concession-orders", a.listConcessionOrdersHandler)
+	auth.POST("/theaters/:id/concession-orders/:bookingId/collect", a.collectConcessionOrderHandler)
+
+	// taxes
+	api.GET("/tax-rules/:city", a.getTaxRulesHandler)
+
+	auth.PUT("/tax-rules/:city", a.updateTaxRulesHandler)
+
+	// currencies
+	api.GET("/exchange-rates", a.getExchangeRatesHandler)
+
+	auth.PUT("/exchange-rates", a.updateExchangeRatesHandler)
+	auth.GET("/reports/sales", a.getSalesReportHandler)
+
+	// tickets
+	auth.GET("/bookings/:id/tickets/:ticketId/qr", a.getTicketQRHandler)
+	auth.POST("/theaters/:id/checkin", a.checkInHandler)
 }
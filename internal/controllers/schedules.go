@@ -0,0 +1,255 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/httputil"
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+	"github.com/AhmadAbdelrazik/showtime/internal/services"
+	"github.com/AhmadAbdelrazik/showtime/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+// previewSchedule godoc
+//
+//	@Summary		Preview Schedule
+//	@Description	Propose a conflict-free week of shows for the theater's halls, giving prime time to the most popular movies. Nothing is scheduled.
+//	@Tags			shows
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int						true	"theater id"
+//	@Param			input	body		PreviewScheduleInput	true	"movies and their screenings"
+//	@Success		200		{object}	services.ProposedSchedule
+//	@Failure		400		{object}	httputil.ValidationError
+//	@Failure		401		{object}	httputil.HTTPError
+//	@Failure		403		{object}	httputil.HTTPError
+//	@Failure		404		{object}	httputil.HTTPError
+//	@Failure		500		{object}	httputil.HTTPError
+//	@Router			/api/theaters/{id}/schedule/preview [post]
+func (h *Application) previewScheduleHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	theaterId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid theater id"))
+		return
+	}
+
+	var input PreviewScheduleInput
+	if err := c.ShouldBind(&input); err != nil {
+		v := validator.New()
+		input.Validate(v)
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	v := validator.New()
+	if input.Validate(v); !v.Valid() {
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	schedule, err := h.services.Shows.ProposeSchedule(user, theaterId, input.request())
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTheaterNotFound),
+			errors.Is(err, services.ErrHallNotFound),
+			errors.Is(err, services.ErrMovieNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		case errors.Is(err, services.ErrInvalidScheduleRequest):
+			httputil.NewError(c, http.StatusBadRequest, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// commitSchedule godoc
+//
+//	@Summary		Commit Schedule
+//	@Description	Schedule a set of shows, such as a previewed schedule, all at once. A single conflict schedules no show.
+//	@Tags			shows
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int						true	"theater id"
+//	@Param			input	body		CommitScheduleInput		true	"shows"
+//	@Success		201		{object}	services.ScheduleReport
+//	@Failure		400		{object}	httputil.ValidationError
+//	@Failure		401		{object}	httputil.HTTPError
+//	@Failure		403		{object}	httputil.HTTPError
+//	@Failure		404		{object}	httputil.HTTPError
+//	@Failure		409		{object}	services.ScheduleReport
+//	@Failure		500		{object}	httputil.HTTPError
+//	@Router			/api/theaters/{id}/schedule [post]
+func (h *Application) commitScheduleHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	theaterId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid theater id"))
+		return
+	}
+
+	var input CommitScheduleInput
+	if err := c.ShouldBind(&input); err != nil {
+		v := validator.New()
+		input.Validate(v)
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	v := validator.New()
+	if input.Validate(v); !v.Valid() {
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	shows := make([]services.CreateShowInput, 0, len(input.Shows))
+	for _, show := range input.Shows {
+		shows = append(shows, services.CreateShowInput{
+			MovieID:   show.MovieID,
+			HallCode:  show.HallCode,
+			StartTime: show.StartTime,
+			EndTime:   show.EndTime,
+			PriceTier: show.PriceTier,
+		})
+	}
+
+	report, err := h.services.Shows.CommitSchedule(user, theaterId, shows)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrScheduleConflict):
+			c.JSON(http.StatusConflict, report)
+		case errors.Is(err, services.ErrTheaterNotFound),
+			errors.Is(err, services.ErrHallNotFound),
+			errors.Is(err, services.ErrMovieNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		case errors.Is(err, services.ErrInvalidShowDuration):
+			v := validator.New()
+			v.AddError("duration", err.Error())
+			httputil.NewValidationError(c, v.Errors)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, report)
+}
+
+// PreviewScheduleInput asks for the week starting on WeekStart, a
+// "2006-01-02" date, with the hours as "15:04" times in Timezone (the
+// theater's by default).
+type PreviewScheduleInput struct {
+	WeekStart      string                `json:"week_start"`
+	Timezone       string                `json:"timezone"`
+	Movies         []ScheduleTargetInput `json:"movies"`
+	OpeningTime    string                `json:"opening_time"`
+	ClosingTime    string                `json:"closing_time"`
+	PrimeTimeFrom  string                `json:"prime_time_from"`
+	PrimeTimeUntil string                `json:"prime_time_until"`
+}
+
+type ScheduleTargetInput struct {
+	MovieID    string `json:"movie_id"`
+	Screenings int    `json:"screenings"`
+	Priority   int    `json:"priority"`
+}
+
+func (i *PreviewScheduleInput) Validate(v *validator.Validator) {
+	_, err := time.Parse(time.DateOnly, i.WeekStart)
+	v.Check(err == nil, "week_start", "must be a date, e.g. 2026-01-31")
+
+	if i.Timezone != "" {
+		_, err := time.LoadLocation(i.Timezone)
+		v.Check(err == nil, "timezone", "must be an IANA time zone, e.g. Africa/Cairo")
+	}
+
+	v.Check(len(i.Movies) > 0, "movies", "required")
+	v.Check(len(i.Movies) <= 30, "movies", "must have at most 30 movies")
+	seen := make(map[string]bool, len(i.Movies))
+	for _, movie := range i.Movies {
+		v.Check(len(strings.TrimSpace(movie.MovieID)) > 0, "movies", "movie_id is required")
+		v.Check(len(movie.MovieID) <= 12, "movies", "movie_id must be at most 12 characters")
+		v.Check(!seen[movie.MovieID], "movies", "must not repeat a movie")
+		v.Check(movie.Screenings > 0 && movie.Screenings <= 100, "movies", "screenings must be between 1 and 100")
+		v.Check(movie.Priority >= 0 && movie.Priority <= 100, "movies", "priority must be between 0 and 100")
+		seen[movie.MovieID] = true
+	}
+
+	for key, clock := range map[string]string{
+		"opening_time":     i.OpeningTime,
+		"closing_time":     i.ClosingTime,
+		"prime_time_from":  i.PrimeTimeFrom,
+		"prime_time_until": i.PrimeTimeUntil,
+	} {
+		if clock != "" {
+			_, err := time.Parse("15:04", clock)
+			v.Check(err == nil, key, "must be a time of day, e.g. 19:30")
+		}
+	}
+}
+
+// request converts a validated input to the service's schedule request.
+func (i *PreviewScheduleInput) request() services.ScheduleRequest {
+	r := services.ScheduleRequest{
+		Timezone:       i.Timezone,
+		OpeningTime:    i.OpeningTime,
+		ClosingTime:    i.ClosingTime,
+		PrimeTimeFrom:  i.PrimeTimeFrom,
+		PrimeTimeUntil: i.PrimeTimeUntil,
+	}
+
+	r.WeekStart, _ = time.Parse(time.DateOnly, i.WeekStart)
+
+	for _, movie := range i.Movies {
+		r.Movies = append(r.Movies, services.ScheduleTarget{
+			MovieID:    movie.MovieID,
+			Screenings: movie.Screenings,
+			Priority:   movie.Priority,
+		})
+	}
+
+	return r
+}
+
+type CommitScheduleInput struct {
+	Shows []ScheduledShowInput `json:"shows"`
+}
+
+type ScheduledShowInput struct {
+	MovieID   string    `json:"movie_id"`
+	HallCode  string    `json:"hall_code"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	PriceTier string    `json:"price_tier"`
+}
+
+func (i *CommitScheduleInput) Validate(v *validator.Validator) {
+	v.Check(len(i.Shows) > 0, "shows", "required")
+	v.Check(len(i.Shows) <= 500, "shows", "must have at most 500 shows")
+
+	for _, show := range i.Shows {
+		v.Check(len(strings.TrimSpace(show.MovieID)) > 0, "shows", "movie_id is required")
+		v.Check(len(show.MovieID) <= 12, "shows", "movie_id must be at most 12 characters")
+		v.Check(validator.AlphanumRX.MatchString(show.HallCode), "shows", "hall_code must not contain any spaces or special characters")
+		v.Check(len(show.HallCode) > 0 && len(show.HallCode) <= 10, "shows", "hall_code must be between 1 and 10 characters")
+		v.Check(show.StartTime.Before(show.EndTime), "shows", "start_time must be before end_time")
+
+		if show.PriceTier != "" {
+			v.Check(slices.Contains(models.PriceTiers, show.PriceTier), "shows", "price_tier must be one of matinee, evening, weekend or holiday")
+		}
+	}
+}
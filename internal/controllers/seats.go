@@ -0,0 +1,173 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/httputil"
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+	"github.com/AhmadAbdelrazik/showtime/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+const mimeSVG = "image/svg+xml"
+
+// getShowSeats godoc
+//
+//	@Summary		Get Show Seats
+//	@Description	List the seats of a show with their availability
+//	@Tags			seats
+//	@Produce		json
+//	@Param			id		path		int	true	"theater id"
+//	@Param			show_id	path		int	true	"show id"
+//	@Success		200		{object}	GetShowSeatsResponse
+//	@Failure		400		{object}	httputil.HTTPError
+//	@Failure		404		{object}	httputil.HTTPError
+//	@Failure		500		{object}	httputil.HTTPError
+//	@Router			/api/theaters/{id}/shows/{show_id}/seats [get]
+func (h *Application) getShowSeatsHandler(c *gin.Context) {
+	theaterId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid theater id"))
+		return
+	}
+	showId, err := strconv.Atoi(c.Param("showId"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid show id"))
+		return
+	}
+
+	seats, err := h.services.Seats.List(theaterId, showId)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrShowNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	available := 0
+	for _, seat := range seats {
+		if seat.IsAvailable() {
+			available++
+		}
+	}
+
+	c.JSON(http.StatusOK, GetShowSeatsResponse{
+		Capacity:  len(seats),
+		Available: available,
+		Seats:     seats,
+	})
+}
+
+// suggestShowSeats godoc
+//
+//	@Summary		Suggest Show Seats
+//	@Description	Suggest the best block of adjacent available seats for a group
+//	@Tags			seats
+//	@Produce		json
+//	@Param			id		path		int	true	"theater id"
+//	@Param			show_id	path		int	true	"show id"
+//	@Param			count	query		int	true	"group size, 2 to 10"
+//	@Success		200		{object}	SuggestShowSeatsResponse
+//	@Failure		400		{object}	httputil.HTTPError
+//	@Failure		404		{object}	httputil.HTTPError
+//	@Failure		409		{object}	httputil.HTTPError
+//	@Failure		500		{object}	httputil.HTTPError
+//	@Router			/api/theaters/{id}/shows/{show_id}/seats/suggest [get]
+func (h *Application) suggestShowSeatsHandler(c *gin.Context) {
+	theaterId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid theater id"))
+		return
+	}
+	showId, err := strconv.Atoi(c.Param("showId"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid show id"))
+		return
+	}
+	count, err := strconv.Atoi(c.Query("count"))
+	if err != nil || count < 2 || count > 10 {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("count must be between 2 and 10"))
+		return
+	}
+
+	seats, err := h.services.Seats.Suggest(theaterId, showId, count)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrShowNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		case errors.Is(err, services.ErrNoAdjacentSeats):
+			httputil.NewError(c, http.StatusConflict, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, SuggestShowSeatsResponse{seats})
+}
+
+// getShowSeatMap godoc
+//
+//	@Summary		Get Show Seat Map
+//	@Description	Get the seats of a show as a grid with their status, as JSON or as an SVG drawing
+//	@Tags			seats
+//	@Produce		json
+//	@Produce		image/svg+xml
+//	@Param			id		path		int	true	"theater id"
+//	@Param			show_id	path		int	true	"show id"
+//	@Success		200		{object}	services.SeatMap
+//	@Failure		400		{object}	httputil.HTTPError
+//	@Failure		404		{object}	httputil.HTTPError
+//	@Failure		500		{object}	httputil.HTTPError
+//	@Router			/api/theaters/{id}/shows/{show_id}/seatmap [get]
+func (h *Application) getShowSeatMapHandler(c *gin.Context) {
+	theaterId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid theater id"))
+		return
+	}
+	showId, err := strconv.Atoi(c.Param("showId"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid show id"))
+		return
+	}
+
+	seatMap, err := h.services.Seats.SeatMap(theaterId, showId)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrShowNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	renderSeatMap(c, seatMap)
+}
+
+// renderSeatMap responds with the seat map drawn as SVG if the client asks
+// for it, and as JSON otherwise.
+func renderSeatMap(c *gin.Context, seatMap *services.SeatMap) {
+	switch c.NegotiateFormat(gin.MIMEJSON, mimeSVG) {
+	case mimeSVG:
+		c.Data(http.StatusOK, mimeSVG, seatMap.SVG())
+	default:
+		c.JSON(http.StatusOK, seatMap)
+	}
+}
+
+type GetShowSeatsResponse struct {
+	Capacity  int               `json:"capacity"`
+	Available int               `json:"available"`
+	Seats     []models.ShowSeat `json:"seats"`
+}
+
+type SuggestShowSeatsResponse struct {
+	Seats []models.ShowSeat `json:"seats"`
+}
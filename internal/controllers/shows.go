@@ -3,6 +3,7 @@ package controllers
 import (
 	"errors"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -58,7 +59,7 @@ func (h *Application) searchShowsHandler(c *gin.Context) {
 // CreateShow godoc
 //
 //	@Summary		Create Show
-//	@Description	Creates a new theater's show
+//	@Description	Creates a new theater's show. A show must run within the theater's operating hours and may not overlap another show of the hall or the hall's turnaround after it.
 //	@Tags			shows
 //	@Accept			json
 //	@Produce		json
@@ -68,6 +69,7 @@ func (h *Application) searchShowsHandler(c *gin.Context) {
 //	@Failure		400		{object}	httputil.ValidationError
 //	@Failure		401		{object}	httputil.HTTPError
 //	@Failure		403		{object}	httputil.HTTPError
+//	@Failure		409		{object}	ShowConflictResponse
 //	@Failure		500		{object}	httputil.HTTPError
 //	@Router			/api/theaters/{id}/shows [post]
 func (h *Application) createShowHandler(c *gin.Context) {
@@ -94,7 +96,21 @@ func (h *Application) createShowHandler(c *gin.Context) {
 	}
 
 	if err := h.services.Shows.Create(user, int(theaterId), services.CreateShowInput(input)); err != nil {
+		var conflict *models.ScheduleConflictError
+
 		switch {
+		case errors.As(err, &conflict):
+			c.JSON(http.StatusConflict, ShowConflictResponse{
+				Code:          http.StatusConflict,
+				Message:       err.Error(),
+				EarliestStart: conflict.EarliestStart,
+			})
+		case errors.Is(err, models.ErrOutsideOperatingHours):
+			v := validator.New()
+			v.AddError("start_time", err.Error())
+			httputil.NewValidationError(c, v.Errors)
+		case errors.Is(err, models.ErrInvalidSchedule):
+			httputil.NewError(c, http.StatusBadRequest, err)
 		case errors.Is(err, services.ErrHallNotFound),
 			errors.Is(err, services.ErrMovieNotFound):
 			httputil.NewError(c, http.StatusNotFound, err)
@@ -115,6 +131,73 @@ func (h *Application) createShowHandler(c *gin.Context) {
 	})
 }
 
+// createRecurringShows godoc
+//
+//	@Summary		Create Recurring Shows
+//	@Description	Schedule a movie in a hall at the same times on every matching day of a date range, checking every occurrence for conflicts
+//	@Tags			shows
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int							true	"theater id"
+//	@Param			input	body		CreateRecurringShowsInput	true	"recurrence"
+//	@Success		201		{object}	services.ScheduleReport
+//	@Failure		400		{object}	httputil.ValidationError
+//	@Failure		401		{object}	httputil.HTTPError
+//	@Failure		403		{object}	httputil.HTTPError
+//	@Failure		404		{object}	httputil.HTTPError
+//	@Failure		409		{object}	services.ScheduleReport
+//	@Failure		500		{object}	httputil.HTTPError
+//	@Router			/api/theaters/{id}/shows/recurring [post]
+func (h *Application) createRecurringShowsHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	theaterId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid theater id"))
+		return
+	}
+
+	var input CreateRecurringShowsInput
+	if err := c.ShouldBind(&input); err != nil {
+		v := validator.New()
+		input.Validate(v)
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	v := validator.New()
+	if input.Validate(v); !v.Valid() {
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	report, err := h.services.Shows.CreateRecurring(user, theaterId, input.recurrence())
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrScheduleConflict):
+			c.JSON(http.StatusConflict, report)
+		case errors.Is(err, services.ErrHallNotFound),
+			errors.Is(err, services.ErrMovieNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		case errors.Is(err, services.ErrInvalidShowDuration):
+			v := validator.New()
+			v.AddError("duration", err.Error())
+			httputil.NewValidationError(c, v.Errors)
+		case errors.Is(err, services.ErrInvalidRecurrence):
+			v := validator.New()
+			v.AddError("recurrence", err.Error())
+			httputil.NewValidationError(c, v.Errors)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, report)
+}
+
 // getShow godoc
 //
 //	@Summary		Get Show
@@ -205,6 +288,7 @@ type CreateShowInput struct {
 	HallCode  string
 	StartTime time.Time
 	EndTime   time.Time
+	PriceTier string `json:"price_tier"`
 }
 
 func (i *CreateShowInput) Validate(v *validator.Validator) {
@@ -214,6 +298,122 @@ func (i *CreateShowInput) Validate(v *validator.Validator) {
 
 	v.Check(i.StartTime.Before(i.EndTime), "start_time", "can't be after end_time")
 	v.Check(i.EndTime.Sub(i.StartTime).Minutes() == 0, "duration", "duration difference must be in hours e.g. 1h, 3h, etc...")
+
+	if i.PriceTier != "" {
+		v.Check(slices.Contains(models.PriceTiers, i.PriceTier), "price_tier", "must be one of matinee, evening, weekend or holiday")
+	}
+}
+
+// CreateRecurringShowsInput schedules a movie at Times, "15:04" in Timezone
+// (the theater's by default), on every day from From to Until, "2006-01-02"
+// dates, falling on one of Weekdays ("mon" to "sun", every day if empty) and
+// not listed in ExceptDates. DurationMinutes defaults to the movie's runtime.
+type CreateRecurringShowsInput struct {
+	MovieID         string   `json:"movie_id"`
+	HallCode        string   `json:"hall_code"`
+	From            string   `json:"from"`
+	Until           string   `json:"until"`
+	Times           []string `json:"times"`
+	Weekdays        []string `json:"weekdays"`
+	ExceptDates     []string `json:"except_dates"`
+	Timezone        string   `json:"timezone"`
+	DurationMinutes int      `json:"duration_minutes"`
+	PriceTier       string   `json:"price_tier"`
+	Mode            string   `json:"mode"`
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+func (i *CreateRecurringShowsInput) Validate(v *validator.Validator) {
+	v.Check(len(strings.TrimSpace(i.MovieID)) > 0, "movie_id", "required")
+	v.Check(len(i.MovieID) <= 12, "movie_id", "must be at most 12 characters")
+
+	v.Check(len(strings.TrimSpace(i.HallCode)) > 0, "hall_code", "required")
+	v.Check(validator.AlphanumRX.MatchString(i.HallCode), "hall_code", "must not contain any spaces or special characters")
+	v.Check(len(i.HallCode) <= 10, "hall_code", "must be at most 10 characters")
+
+	from, fromErr := time.Parse(time.DateOnly, i.From)
+	v.Check(fromErr == nil, "from", "must be a date, e.g. 2026-01-31")
+	until, untilErr := time.Parse(time.DateOnly, i.Until)
+	v.Check(untilErr == nil, "until", "must be a date, e.g. 2026-01-31")
+	if fromErr == nil && untilErr == nil {
+		v.Check(!until.Before(from), "until", "must not be before from")
+		v.Check(until.Sub(from) <= 366*24*time.Hour, "until", "must be at most a year after from")
+	}
+
+	v.Check(len(i.Times) > 0, "times", "required")
+	v.Check(len(i.Times) <= 12, "times", "must have at most 12 times")
+	seen := make(map[string]bool, len(i.Times))
+	for _, t := range i.Times {
+		_, err := time.Parse("15:04", t)
+		v.Check(err == nil, "times", "must be times of day, e.g. 19:30")
+		v.Check(!seen[t], "times", "must not repeat a time")
+		seen[t] = true
+	}
+
+	for _, day := range i.Weekdays {
+		_, ok := weekdays[day]
+		v.Check(ok, "weekdays", "must be one of sun, mon, tue, wed, thu, fri or sat")
+	}
+
+	v.Check(len(i.ExceptDates) <= 366, "except_dates", "must have at most 366 dates")
+	for _, date := range i.ExceptDates {
+		_, err := time.Parse(time.DateOnly, date)
+		v.Check(err == nil, "except_dates", "must be dates, e.g. 2026-01-31")
+	}
+
+	if i.Timezone != "" {
+		_, err := time.LoadLocation(i.Timezone)
+		v.Check(err == nil, "timezone", "must be an IANA time zone, e.g. Africa/Cairo")
+	}
+
+	v.Check(i.DurationMinutes >= 0 && i.DurationMinutes <= 600, "duration_minutes", "must be between 0 and 600")
+
+	if i.PriceTier != "" {
+		v.Check(slices.Contains(models.PriceTiers, i.PriceTier), "price_tier", "must be one of matinee, evening, weekend or holiday")
+	}
+
+	if i.Mode != "" {
+		v.Check(i.Mode == services.ScheduleAllOrNothing || i.Mode == services.ScheduleBestEffort, "mode", "must be all_or_nothing or best_effort")
+	}
+}
+
+// recurrence converts a validated input to the service's recurrence.
+func (i *CreateRecurringShowsInput) recurrence() services.ShowRecurrence {
+	r := services.ShowRecurrence{
+		MovieID:   i.MovieID,
+		HallCode:  i.HallCode,
+		Times:     i.Times,
+		Timezone:  i.Timezone,
+		Duration:  time.Duration(i.DurationMinutes) * time.Minute,
+		PriceTier: i.PriceTier,
+		Mode:      i.Mode,
+	}
+
+	if r.Mode == "" {
+		r.Mode = services.ScheduleAllOrNothing
+	}
+
+	r.From, _ = time.Parse(time.DateOnly, i.From)
+	r.Until, _ = time.Parse(time.DateOnly, i.Until)
+
+	for _, day := range i.Weekdays {
+		r.Weekdays = append(r.Weekdays, weekdays[day])
+	}
+	for _, date := range i.ExceptDates {
+		d, _ := time.Parse(time.DateOnly, date)
+		r.ExceptDates = append(r.ExceptDates, d)
+	}
+
+	return r
 }
 
 type CreateShowResponse struct {
@@ -221,6 +421,14 @@ type CreateShowResponse struct {
 	Show    models.Show `json:"show"`
 }
 
+// ShowConflictResponse tells why a show can't be scheduled and the earliest
+// it could start in the same hall instead.
+type ShowConflictResponse struct {
+	Code          int       `json:"code" example:"409"`
+	Message       string    `json:"message"`
+	EarliestStart time.Time `json:"earliest_start"`
+}
+
 type DeleteShowResponse struct {
 	Message string `json:"message"`
 }
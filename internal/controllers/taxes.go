@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/httputil"
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+	"github.com/AhmadAbdelrazik/showtime/internal/services"
+	"github.com/AhmadAbdelrazik/showtime/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+// getTaxRules godoc
+//
+//	@Summary		Get Tax Rules
+//	@Description	Get the taxes and booking fees charged on the bookings of a city
+//	@Tags			taxes
+//	@Produce		json
+//	@Param			city	path		string	true	"city"
+//	@Success		200		{object}	TaxRulesResponse
+//	@Failure		500		{object}	httputil.HTTPError
+//	@Router			/api/tax-rules/{city} [get]
+func (h *Application) getTaxRulesHandler(c *gin.Context) {
+	rules, err := h.services.Taxes.Rules(c.Param("city"))
+	if err != nil {
+		httputil.NewError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, TaxRulesResponse{Rules: rules})
+}
+
+// updateTaxRules godoc
+//
+//	@Summary		Update Tax Rules
+//	@Description	Replace the taxes and booking fees charged on the bookings of a city
+//	@Tags			taxes
+//	@Accept			json
+//	@Produce		json
+//	@Param			city	path		string				true	"city"
+//	@Param			input	body		UpdateTaxRulesInput	true	"tax rules"
+//	@Success		200		{object}	TaxRulesResponse
+//	@Failure		400		{object}	httputil.ValidationError
+//	@Failure		401		{object}	httputil.HTTPError
+//	@Failure		403		{object}	httputil.HTTPError
+//	@Failure		500		{object}	httputil.HTTPError
+//	@Router			/api/tax-rules/{city} [put]
+func (h *Application) updateTaxRulesHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	var input UpdateTaxRulesInput
+	if err := c.ShouldBind(&input); err != nil {
+		v := validator.New()
+		input.Validate(v)
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	v := validator.New()
+	if input.Validate(v); !v.Valid() {
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	city := strings.TrimSpace(c.Param("city"))
+	if city == "" || len(city) > 30 {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid city"))
+		return
+	}
+
+	rules := make([]models.TaxRule, 0, len(input.Rules))
+	for _, rule := range input.Rules {
+		rules = append(rules, models.TaxRule{
+			Name:      rule.Name,
+			Kind:      rule.Kind,
+			Rate:      rule.Rate,
+			Amount:    rule.Amount,
+			Per:       rule.Per,
+			AppliesTo: rule.AppliesTo,
+			Inclusive: rule.Inclusive,
+		})
+	}
+
+	rules, err := h.services.Taxes.ReplaceRules(user, city, rules)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidTaxRules):
+			httputil.NewError(c, http.StatusBadRequest, err)
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, TaxRulesResponse{
+		Message: "tax rules updated successfully",
+		Rules:   rules,
+	})
+}
+
+type UpdateTaxRulesInput struct {
+	Rules []TaxRuleInput `json:"rules"`
+}
+
+type TaxRuleInput struct {
+	Name      string   `json:"name"`
+	Kind      string   `json:"kind"`
+	Rate      int      `json:"rate"`
+	Amount    int64    `json:"amount"`
+	Per       string   `json:"per"`
+	AppliesTo []string `json:"applies_to"`
+	Inclusive bool     `json:"inclusive"`
+}
+
+func (i *UpdateTaxRulesInput) Validate(v *validator.Validator) {
+	v.Check(len(i.Rules) <= 20, "rules", "must have at most 20 rules")
+
+	seen := make(map[string]bool, len(i.Rules))
+	for _, rule := range i.Rules {
+		name := strings.ToLower(strings.TrimSpace(rule.Name))
+		v.Check(name != "", "rules", "name is required")
+		v.Check(len(rule.Name) <= 50, "rules", "name must be at most 50 characters")
+		v.Check(!seen[name], "rules", "name must be unique")
+		v.Check(rule.Kind == models.TaxKindTax || rule.Kind == models.TaxKindFee, "rules", "kind must be tax or fee")
+		v.Check(rule.Rate >= 0 && rule.Rate <= 10_000, "rules", "rate must be between 0 and 10000")
+		v.Check(rule.Amount >= 0, "rules", "amount must not be negative")
+		v.Check(rule.Per == "" || rule.Per == models.TaxPerTicket || rule.Per == models.TaxPerBooking, "rules", "per must be ticket or booking")
+		for j, part := range rule.AppliesTo {
+			v.Check(slices.Contains(models.TaxParts, part), "rules", "applies_to must be tickets or concessions")
+			v.Check(!slices.Contains(rule.AppliesTo[:j], part), "rules", "applies_to must not repeat a part")
+		}
+		seen[name] = true
+	}
+}
+
+type TaxRulesResponse struct {
+	Message string           `json:"message,omitempty"`
+	Rules   []models.TaxRule `json:"rules"`
+}
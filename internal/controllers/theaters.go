@@ -3,12 +3,15 @@ package controllers
 import (
 	"errors"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/AhmadAbdelrazik/showtime/internal/httputil"
 	"github.com/AhmadAbdelrazik/showtime/internal/models"
 	"github.com/AhmadAbdelrazik/showtime/internal/services"
+	"github.com/AhmadAbdelrazik/showtime/pkg/money"
 	"github.com/AhmadAbdelrazik/showtime/pkg/validator"
 	"github.com/gin-gonic/gin"
 )
@@ -117,11 +120,16 @@ func (h *Application) createTheaterHandler(c *gin.Context) {
 	}
 
 	theater := &models.Theater{
-		Name:      input.Name,
-		City:      input.City,
-		Address:   input.Address,
-		ManagerID: user.ID,
-		Halls:     []models.Hall{},
+		Name:              input.Name,
+		City:              input.City,
+		Address:           input.Address,
+		Currency:          input.Currency,
+		TurnaroundMinutes: models.DefaultTurnaroundMinutes,
+		ManagerID:         user.ID,
+		Halls:             []models.Hall{},
+	}
+	if input.TurnaroundMinutes != nil {
+		theater.TurnaroundMinutes = *input.TurnaroundMinutes
 	}
 
 	if err := h.services.Theaters.Create(user, theater); err != nil {
@@ -187,7 +195,8 @@ func (h *Application) updateTheaterHandler(c *gin.Context) {
 			httputil.NewError(c, http.StatusForbidden, err)
 		case errors.Is(err, services.ErrTheaterNotFound):
 			httputil.NewError(c, http.StatusNotFound, err)
-		case errors.Is(err, services.ErrEditConflict):
+		case errors.Is(err, services.ErrEditConflict),
+			errors.Is(err, services.ErrCurrencyInUse):
 			httputil.NewError(c, http.StatusConflict, err)
 		default:
 			httputil.NewError(c, http.StatusInternalServerError, err)
@@ -238,14 +247,134 @@ func (h *Application) deleteTheaterHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, DeleteTheaterResponse{Message: "Deleted Successfully"})
 }
 
+// updateRefundPolicy godoc
+//
+//	@Summary		Update Refund Policy
+//	@Description	Replace the refund rules applied when customers cancel bookings of the theater
+//	@Tags			theaters
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int						true	"theater id"
+//	@Param			input	body		UpdateRefundPolicyInput	true	"refund rules"
+//	@Success		200		{object}	UpdateTheaterResponse
+//	@Failure		400		{object}	httputil.ValidationError
+//	@Failure		401		{object}	httputil.HTTPError
+//	@Failure		403		{object}	httputil.HTTPError
+//	@Failure		404		{object}	httputil.HTTPError
+//	@Failure		500		{object}	httputil.HTTPError
+//	@Router			/api/theaters/{id}/refund-policy [put]
+func (h *Application) updateRefundPolicyHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	theaterId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid theater id"))
+		return
+	}
+
+	var input UpdateRefundPolicyInput
+
+	if err := c.ShouldBind(&input); err != nil {
+		v := validator.New()
+		input.Validate(v)
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	v := validator.New()
+	if input.Validate(v); !v.Valid() {
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	theater, err := h.services.Theaters.UpdateRefundPolicy(user, theaterId, input.Rules)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		case errors.Is(err, services.ErrTheaterNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, UpdateTheaterResponse{
+		Message: "refund policy updated sucessfully",
+		Theater: *theater,
+	})
+}
+
+// UpdateOperatingHours godoc
+//
+//	@Summary		Update Operating Hours
+//	@Description	Replace the opening hours per weekday, holiday hours and closure dates shows of the theater must be scheduled within
+//	@Tags			theaters
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int							true	"theater id"
+//	@Param			input	body		UpdateOperatingHoursInput	true	"operating hours"
+//	@Success		200		{object}	UpdateTheaterResponse
+//	@Failure		400		{object}	httputil.ValidationError
+//	@Failure		401		{object}	httputil.HTTPError
+//	@Failure		403		{object}	httputil.HTTPError
+//	@Failure		404		{object}	httputil.HTTPError
+//	@Failure		500		{object}	httputil.HTTPError
+//	@Router			/api/theaters/{id}/hours [put]
+func (h *Application) updateOperatingHoursHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	theaterId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid theater id"))
+		return
+	}
+
+	var input UpdateOperatingHoursInput
+
+	if err := c.ShouldBind(&input); err != nil {
+		v := validator.New()
+		input.Validate(v)
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	v := validator.New()
+	if input.Validate(v); !v.Valid() {
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	theater, err := h.services.Theaters.UpdateOperatingHours(user, theaterId, input.operatingHours())
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		case errors.Is(err, services.ErrTheaterNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, UpdateTheaterResponse{
+		Message: "operating hours updated sucessfully",
+		Theater: *theater,
+	})
+}
+
 type SearchTheatersResponse struct {
 	Theaters []models.Theater `json:"theaters"`
 }
 
 type CreateTheaterInput struct {
-	Name    string `json:"name"`
-	City    string `json:"city"`
-	Address string `json:"address"`
+	Name              string `json:"name"`
+	City              string `json:"city"`
+	Address           string `json:"address"`
+	Currency          string `json:"currency"`
+	TurnaroundMinutes *int   `json:"turnaround_minutes"`
 }
 
 func (i *CreateTheaterInput) Validate(v *validator.Validator) {
@@ -260,6 +389,12 @@ func (i *CreateTheaterInput) Validate(v *validator.Validator) {
 	v.Check(len(strings.TrimSpace(i.Address)) > 0, "address", "required")
 	v.Check(len(i.Address) <= 100, "address", "must be at most 100 characters")
 	v.Check(len(i.Address) > 5, "address", "must be at least 5 characters")
+
+	v.Check(i.Currency == "" || money.IsKnown(i.Currency), "currency", "must be a supported ISO 4217 code")
+
+	if i.TurnaroundMinutes != nil {
+		validateTurnaround(v, *i.TurnaroundMinutes)
+	}
 }
 
 type CreateTheaterResponse struct {
@@ -268,9 +403,11 @@ type CreateTheaterResponse struct {
 }
 
 type UpdateTheaterInput struct {
-	Name    *string `json:"name"`
-	City    *string `json:"city"`
-	Address *string `json:"address"`
+	Name              *string `json:"name"`
+	City              *string `json:"city"`
+	Address           *string `json:"address"`
+	Currency          *string `json:"currency"`
+	TurnaroundMinutes *int    `json:"turnaround_minutes"`
 }
 
 func (i *UpdateTheaterInput) Validate(v *validator.Validator) {
@@ -291,6 +428,20 @@ func (i *UpdateTheaterInput) Validate(v *validator.Validator) {
 		v.Check(len(*i.Address) <= 100, "address", "must be at most 100 characters")
 		v.Check(len(*i.Address) > 5, "address", "must be at least 5 characters")
 	}
+
+	if i.Currency != nil {
+		v.Check(money.IsKnown(*i.Currency), "currency", "must be a supported ISO 4217 code")
+	}
+
+	if i.TurnaroundMinutes != nil {
+		validateTurnaround(v, *i.TurnaroundMinutes)
+	}
+}
+
+// validateTurnaround checks the minutes kept free between two shows of a
+// hall.
+func validateTurnaround(v *validator.Validator, minutes int) {
+	v.Check(minutes >= 0 && minutes <= 120, "turnaround_minutes", "must be between 0 and 120")
 }
 
 type UpdateTheaterResponse struct {
@@ -301,3 +452,87 @@ type UpdateTheaterResponse struct {
 type DeleteTheaterResponse struct {
 	Message string `json:"message"`
 }
+
+type UpdateRefundPolicyInput struct {
+	Rules []models.RefundRule `json:"rules"`
+}
+
+func (i *UpdateRefundPolicyInput) Validate(v *validator.Validator) {
+	v.Check(len(i.Rules) <= 10, "rules", "must be at most 10 rules")
+
+	seen := make(map[int]bool, len(i.Rules))
+	for _, rule := range i.Rules {
+		v.Check(rule.MinHoursBefore >= 0, "rules", "min_hours_before must not be negative")
+		v.Check(rule.MinHoursBefore <= 24*90, "rules", "min_hours_before must be at most 90 days")
+		v.Check(rule.Percent >= 0 && rule.Percent <= 100, "rules", "percent must be between 0 and 100")
+		v.Check(!seen[rule.MinHoursBefore], "rules", "min_hours_before must be unique")
+		seen[rule.MinHoursBefore] = true
+	}
+}
+
+// UpdateOperatingHoursInput gives the theater's hours as "15:04" times in
+// Timezone, UTC by default, and dates as "2006-01-02" dates.
+type UpdateOperatingHoursInput struct {
+	Timezone string                `json:"timezone"`
+	Weekly   []models.OpeningHours `json:"weekly"`
+	Holidays []models.HolidayHours `json:"holidays"`
+	Closures []models.Closure      `json:"closures"`
+}
+
+func (i *UpdateOperatingHoursInput) Validate(v *validator.Validator) {
+	if i.Timezone != "" {
+		_, err := time.LoadLocation(i.Timezone)
+		v.Check(err == nil, "timezone", "must be an IANA time zone, e.g. Africa/Cairo")
+	}
+
+	v.Check(len(i.Weekly) <= 7, "weekly", "must have at most 7 days")
+	seen := make(map[string]bool, len(i.Weekly))
+	for _, hours := range i.Weekly {
+		v.Check(slices.Contains(models.Weekdays, hours.Weekday), "weekly", "weekday must be one of sun, mon, tue, wed, thu, fri or sat")
+		v.Check(!seen[hours.Weekday], "weekly", "must not repeat a weekday")
+		v.Check(isClock(hours.Opens) && isClock(hours.Closes), "weekly", "opens and closes must be times of day, e.g. 19:30")
+		seen[hours.Weekday] = true
+	}
+
+	v.Check(len(i.Holidays) <= 100, "holidays", "must have at most 100 holidays")
+	seen = make(map[string]bool, len(i.Holidays))
+	for _, holiday := range i.Holidays {
+		v.Check(isDate(holiday.Date), "holidays", "date must be a date, e.g. 2026-01-31")
+		v.Check(!seen[holiday.Date], "holidays", "must not repeat a date")
+		v.Check(len(holiday.Name) <= 50, "holidays", "name must be at most 50 characters")
+		v.Check(isClock(holiday.Opens) && isClock(holiday.Closes), "holidays", "opens and closes must be times of day, e.g. 19:30")
+		seen[holiday.Date] = true
+	}
+
+	v.Check(len(i.Closures) <= 100, "closures", "must have at most 100 closures")
+	for _, closure := range i.Closures {
+		v.Check(isDate(closure.From) && isDate(closure.Until), "closures", "from and until must be dates, e.g. 2026-01-31")
+		v.Check(closure.From <= closure.Until, "closures", "from must not be after until")
+		v.Check(len(closure.Reason) <= 100, "closures", "reason must be at most 100 characters")
+	}
+}
+
+// operatingHours converts a validated input to the theater's operating hours.
+func (i *UpdateOperatingHoursInput) operatingHours() models.OperatingHours {
+	hours := models.OperatingHours{
+		Timezone: i.Timezone,
+		Weekly:   i.Weekly,
+		Holidays: i.Holidays,
+		Closures: i.Closures,
+	}
+	if hours.Timezone == "" {
+		hours.Timezone = "UTC"
+	}
+
+	return hours
+}
+
+func isClock(s string) bool {
+	_, err := time.Parse("15:04", s)
+	return err == nil
+}
+
+func isDate(s string) bool {
+	_, err := time.Parse(time.DateOnly, s)
+	return err == nil
+}
@@ -0,0 +1,166 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/httputil"
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+	"github.com/AhmadAbdelrazik/showtime/internal/services"
+	"github.com/AhmadAbdelrazik/showtime/pkg/qr"
+	"github.com/AhmadAbdelrazik/showtime/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+const ticketQRSize = 256
+
+// getTicketQR godoc
+//
+//	@Summary		Get Ticket QR Code
+//	@Description	Get the QR code of a ticket, presented at the door for check-in
+//	@Tags			tickets
+//	@Produce		png
+//	@Produce		image/svg+xml
+//	@Param			id			path		int	true	"booking id"
+//	@Param			ticketId	path		int	true	"ticket id"
+//	@Success		200			{file}		binary
+//	@Failure		400			{object}	httputil.HTTPError
+//	@Failure		401			{object}	httputil.HTTPError
+//	@Failure		403			{object}	httputil.HTTPError
+//	@Failure		404			{object}	httputil.HTTPError
+//	@Failure		409			{object}	httputil.HTTPError
+//	@Failure		500			{object}	httputil.HTTPError
+//	@Router			/api/bookings/{id}/tickets/{ticketId}/qr [get]
+func (h *Application) getTicketQRHandler(c *gin.Context) {
+	bookingId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid booking id"))
+		return
+	}
+
+	ticketId, err := strconv.Atoi(c.Param("ticketId"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid ticket id"))
+		return
+	}
+
+	user := c.MustGet("user").(*models.User)
+
+	ticket, err := h.services.Tickets.Find(user, bookingId, ticketId)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrBookingNotFound),
+			errors.Is(err, services.ErrTicketNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		case errors.Is(err, services.ErrTicketNotValid):
+			httputil.NewError(c, http.StatusConflict, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	var (
+		image       []byte
+		contentType = c.NegotiateFormat("image/png", "image/svg+xml")
+	)
+
+	switch contentType {
+	case "image/svg+xml":
+		image, err = qr.SVG(ticket.Token)
+	default:
+		contentType = "image/png"
+		image, err = qr.PNG(ticket.Token, ticketQRSize)
+	}
+	if err != nil {
+		httputil.NewError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, image)
+}
+
+// checkIn godoc
+//
+//	@Summary		Check In
+//	@Description	Admit a ticket holder to a show of the theater by the ticket's QR token
+//	@Tags			tickets
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int				true	"theater id"
+//	@Param			input	body		CheckInInput	true	"scanned ticket token"
+//	@Success		200		{object}	CheckInResponse
+//	@Failure		400		{object}	httputil.HTTPError
+//	@Failure		401		{object}	httputil.HTTPError
+//	@Failure		403		{object}	httputil.HTTPError
+//	@Failure		404		{object}	httputil.HTTPError
+//	@Failure		409		{object}	httputil.HTTPError
+//	@Failure		500		{object}	httputil.HTTPError
+//	@Router			/api/theaters/{id}/checkin [post]
+func (h *Application) checkInHandler(c *gin.Context) {
+	theaterId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid theater id"))
+		return
+	}
+
+	user := c.MustGet("user").(*models.User)
+
+	var input CheckInInput
+	if err := c.ShouldBind(&input); err != nil {
+		v := validator.New()
+		input.Validate(v)
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	v := validator.New()
+	if input.Validate(v); !v.Valid() {
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	ticket, err := h.services.Tickets.CheckIn(user, theaterId, input.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidTicketToken):
+			httputil.NewError(c, http.StatusBadRequest, err)
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		case errors.Is(err, services.ErrTheaterNotFound),
+			errors.Is(err, services.ErrTicketNotFound),
+			errors.Is(err, services.ErrShowNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		case errors.Is(err, services.ErrTicketUsed),
+			errors.Is(err, services.ErrTicketNotValid),
+			errors.Is(err, services.ErrOutsideCheckinWindow):
+			httputil.NewError(c, http.StatusConflict, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, CheckInResponse{
+		Message: "ticket admitted",
+		Ticket:  *ticket,
+	})
+}
+
+type CheckInInput struct {
+	Token string `json:"token"`
+}
+
+func (i *CheckInInput) Validate(v *validator.Validator) {
+	v.Check(len(strings.TrimSpace(i.Token)) > 0, "token", "required")
+	v.Check(len(i.Token) <= 200, "token", "must be at most 200 characters")
+}
+
+type CheckInResponse struct {
+	Message string        `json:"message"`
+	Ticket  models.Ticket `json:"ticket"`
+}
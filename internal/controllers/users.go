@@ -144,17 +144,29 @@ func (h *Application) userLogoutHandler(c *gin.Context) {
 // UserDetailsHandler godoc
 //
 //	@Summary		User Details
-//	@Description	Get details of the current user
+//	@Description	Get details of the current user, with the loyalty points balance and tier of customers
 //	@Tags			auth
 //	@Produce		json
-//	@Success		200	{object}	models.User
+//	@Success		200	{object}	UserDetailsResponse
+//	@Failure		500	{object}	httputil.HTTPError
 //	@Router			/api/user-info [get]
 func (a *Application) UserDetailsHandler(c *gin.Context) {
 	slog.Debug("retreiving user model from the request key-value")
 	user := c.MustGet("user").(*models.User)
 	slog.Debug("retreived successfully")
 
-	c.JSON(http.StatusOK, user)
+	response := UserDetailsResponse{User: *user}
+
+	if user.Role == "customer" {
+		account, err := a.services.Loyalty.Account(user)
+		if err != nil {
+			httputil.NewError(c, http.StatusInternalServerError, err)
+			return
+		}
+		response.Loyalty = account
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
 func (h *Application) addAuthSessionId(user *models.User, c *gin.Context) {
@@ -175,6 +187,12 @@ func (h *Application) addAuthSessionId(user *models.User, c *gin.Context) {
 	http.SetCookie(c.Writer, cookie)
 }
 
+// UserDetailsResponse is the user with the loyalty account of customers.
+type UserDetailsResponse struct {
+	models.User
+	Loyalty *services.LoyaltyAccount `json:"loyalty,omitempty"`
+}
+
 type SignupInput struct {
 	Username string `json:"username"`
 	Email    string `json:"email"`
@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/httputil"
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+	"github.com/AhmadAbdelrazik/showtime/internal/services"
+	"github.com/AhmadAbdelrazik/showtime/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+// joinWaitlist godoc
+//
+//	@Summary		Join Waitlist
+//	@Description	Wait in line for seats of a sold out show; released seats are offered as a time-limited hold
+//	@Tags			waitlist
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int					true	"theater id"
+//	@Param			show_id	path		int					true	"show id"
+//	@Param			input	body		JoinWaitlistInput	true	"desired seat count"
+//	@Success		201		{object}	JoinWaitlistResponse
+//	@Failure		400		{object}	httputil.ValidationError
+//	@Failure		401		{object}	httputil.HTTPError
+//	@Failure		404		{object}	httputil.HTTPError
+//	@Failure		409		{object}	httputil.HTTPError
+//	@Failure		500		{object}	httputil.HTTPError
+//	@Router			/api/theaters/{id}/shows/{show_id}/waitlist [post]
+func (h *Application) joinWaitlistHandler(c *gin.Context) {
+	theaterId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid theater id"))
+		return
+	}
+	showId, err := strconv.Atoi(c.Param("showId"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid show id"))
+		return
+	}
+
+	user := c.MustGet("user").(*models.User)
+
+	var input JoinWaitlistInput
+	if err := c.ShouldBind(&input); err != nil {
+		v := validator.New()
+		input.Validate(v)
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	v := validator.New()
+	if input.Validate(v); !v.Valid() {
+		httputil.NewValidationError(c, v.Errors)
+		return
+	}
+
+	entry, err := h.services.Waitlist.Join(user, theaterId, showId, input.SeatCount)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrShowNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		case errors.Is(err, services.ErrShowStarted),
+			errors.Is(err, services.ErrSeatsAvailable),
+			errors.Is(err, services.ErrAlreadyWaitlisted):
+			httputil.NewError(c, http.StatusConflict, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, JoinWaitlistResponse{
+		Message: "joined the waitlist successfully",
+		Entry:   *entry,
+	})
+}
+
+// listWaitlist godoc
+//
+//	@Summary		List Waitlist Entries
+//	@Description	List the open waitlist entries of the current user with their positions
+//	@Tags			waitlist
+//	@Produce		json
+//	@Success		200	{object}	ListWaitlistResponse
+//	@Failure		401	{object}	httputil.HTTPError
+//	@Failure		500	{object}	httputil.HTTPError
+//	@Router			/api/waitlist [get]
+func (h *Application) listWaitlistHandler(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	entries, err := h.services.Waitlist.List(user)
+	if err != nil {
+		httputil.NewError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ListWaitlistResponse{entries})
+}
+
+// leaveWaitlist godoc
+//
+//	@Summary		Leave Waitlist
+//	@Description	Leave a waitlist, declining any pending offer
+//	@Tags			waitlist
+//	@Produce		json
+//	@Param			id	path		int	true	"waitlist entry id"
+//	@Success		200	{object}	LeaveWaitlistResponse
+//	@Failure		400	{object}	httputil.HTTPError
+//	@Failure		401	{object}	httputil.HTTPError
+//	@Failure		403	{object}	httputil.HTTPError
+//	@Failure		404	{object}	httputil.HTTPError
+//	@Failure		409	{object}	httputil.HTTPError
+//	@Failure		500	{object}	httputil.HTTPError
+//	@Router			/api/waitlist/{id} [delete]
+func (h *Application) leaveWaitlistHandler(c *gin.Context) {
+	entryId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httputil.NewError(c, http.StatusBadRequest, errors.New("invalid waitlist entry id"))
+		return
+	}
+
+	user := c.MustGet("user").(*models.User)
+
+	if err := h.services.Waitlist.Leave(user, entryId); err != nil {
+		switch {
+		case errors.Is(err, services.ErrWaitlistEntryNotFound):
+			httputil.NewError(c, http.StatusNotFound, err)
+		case errors.Is(err, services.ErrUnauthorized):
+			httputil.NewError(c, http.StatusForbidden, err)
+		case errors.Is(err, services.ErrWaitlistEntryNotWaiting):
+			httputil.NewError(c, http.StatusConflict, err)
+		default:
+			httputil.NewError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, LeaveWaitlistResponse{Message: "left the waitlist successfully"})
+}
+
+type JoinWaitlistInput struct {
+	SeatCount int `json:"seat_count"`
+}
+
+func (i *JoinWaitlistInput) Validate(v *validator.Validator) {
+	v.Check(i.SeatCount > 0, "seat_count", "must be at least 1")
+	v.Check(i.SeatCount <= 10, "seat_count", "must be at most 10")
+}
+
+type JoinWaitlistResponse struct {
+	Message string               `json:"message"`
+	Entry   models.WaitlistEntry `json:"entry"`
+}
+
+type ListWaitlistResponse struct {
+	Entries []models.WaitlistEntry `json:"entries"`
+}
+
+type LeaveWaitlistResponse struct {
+	Message string `json:"message"`
+}
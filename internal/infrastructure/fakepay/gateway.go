@@ -0,0 +1,150 @@
+package fakepay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/services"
+)
+
+var (
+	ErrUnknownOutcome = errors.New("unknown payment outcome")
+	ErrUnknownCharge  = errors.New("unknown charge")
+	ErrRefundTooLarge = errors.New("refund exceeds the charged amount")
+)
+
+type Outcome string
+
+const (
+	Success Outcome = "success"
+	Decline Outcome = "decline"
+	Timeout Outcome = "timeout"
+)
+
+// Tokens that force an outcome regardless of the gateway's default one, so
+// every path of the purchase flow can be exercised against the same gateway.
+const (
+	SuccessToken = "tok_success"
+	DeclineToken = "tok_decline"
+	TimeoutToken = "tok_timeout"
+)
+
+// Gateway is an in-process payment gateway with deterministic outcomes. A
+// timed out charge is captured all the same, as a real gateway may do, so
+// callers have to look it up before giving up on it.
+type Gateway struct {
+	outcome Outcome
+	mu      sync.Mutex
+	seq     int
+	charges map[string]*charge
+	keys    map[string]string
+}
+
+type charge struct {
+	amount   int64
+	refunded int64
+}
+
+func New(outcome Outcome) (*Gateway, error) {
+	switch outcome {
+	case Success, Decline, Timeout:
+	default:
+		return nil, fmt.Errorf("%w: %v", ErrUnknownOutcome, outcome)
+	}
+
+	return &Gateway{
+		outcome: outcome,
+		charges: make(map[string]*charge),
+		keys:    make(map[string]string),
+	}, nil
+}
+
+func (g *Gateway) Charge(ctx context.Context, c services.Charge) (*services.PaymentReceipt, error) {
+	outcome := g.outcome
+	switch c.Token {
+	case SuccessToken:
+		outcome = Success
+	case DeclineToken:
+		outcome = Decline
+	case TimeoutToken:
+		outcome = Timeout
+	}
+
+	if outcome == Decline {
+		return nil, fmt.Errorf("%w: card declined by issuer", services.ErrPaymentDeclined)
+	}
+
+	receipt := g.capture(c)
+
+	if outcome == Timeout {
+		select {
+		case <-ctx.Done():
+		case <-time.After(10 * time.Millisecond):
+		}
+		return nil, services.ErrPaymentTimeout
+	}
+
+	return receipt, nil
+}
+
+// capture records a charge, or returns the one already captured with the
+// same idempotency key.
+func (g *Gateway) capture(c services.Charge) *services.PaymentReceipt {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if reference, ok := g.keys[c.IdempotencyKey]; ok && c.IdempotencyKey != "" {
+		return &services.PaymentReceipt{
+			Reference: reference,
+			Amount:    g.charges[reference].amount,
+		}
+	}
+
+	g.seq++
+	reference := fmt.Sprintf("fake_ch_%06d", g.seq)
+	g.charges[reference] = &charge{amount: c.Amount}
+	if c.IdempotencyKey != "" {
+		g.keys[c.IdempotencyKey] = reference
+	}
+
+	return &services.PaymentReceipt{
+		Reference: reference,
+		Amount:    c.Amount,
+	}
+}
+
+func (g *Gateway) Lookup(_ context.Context, idempotencyKey string) (*services.PaymentReceipt, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	reference, ok := g.keys[idempotencyKey]
+	if !ok {
+		return nil, fmt.Errorf("%w: %v", services.ErrPaymentNotFound, idempotencyKey)
+	}
+
+	return &services.PaymentReceipt{
+		Reference: reference,
+		Amount:    g.charges[reference].amount,
+	}, nil
+}
+
+func (g *Gateway) Refund(_ context.Context, reference string, amount int64) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ch, ok := g.charges[reference]
+	if !ok {
+		return fmt.Errorf("%w: %v", ErrUnknownCharge, reference)
+	}
+
+	if ch.refunded+amount > ch.amount {
+		return ErrRefundTooLarge
+	}
+
+	ch.refunded += amount
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package fakepay_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/infrastructure/fakepay"
+	"github.com/AhmadAbdelrazik/showtime/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGateway_Charge(t *testing.T) {
+	tests := []struct {
+		name    string
+		outcome fakepay.Outcome
+		token   string
+		want    error
+	}{
+		{
+			name:    "default success",
+			outcome: fakepay.Success,
+			token:   "tok_visa",
+			want:    nil,
+		},
+		{
+			name:    "default decline",
+			outcome: fakepay.Decline,
+			token:   "tok_visa",
+			want:    services.ErrPaymentDeclined,
+		},
+		{
+			name:    "default timeout",
+			outcome: fakepay.Timeout,
+			token:   "tok_visa",
+			want:    services.ErrPaymentTimeout,
+		},
+		{
+			name:    "decline token overrides success",
+			outcome: fakepay.Success,
+			token:   fakepay.DeclineToken,
+			want:    services.ErrPaymentDeclined,
+		},
+		{
+			name:    "success token overrides decline",
+			outcome: fakepay.Decline,
+			token:   fakepay.SuccessToken,
+			want:    nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g, err := fakepay.New(tc.outcome)
+			assert.Nil(t, err)
+
+			receipt, err := g.Charge(context.Background(), services.Charge{
+				Token:  tc.token,
+				Amount: 15000,
+			})
+			if tc.want != nil {
+				assert.ErrorIs(t, err, tc.want)
+				assert.Nil(t, receipt)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, int64(15000), receipt.Amount)
+				assert.NotEmpty(t, receipt.Reference)
+			}
+		})
+	}
+}
+
+func TestGateway_Refund(t *testing.T) {
+	g, err := fakepay.New(fakepay.Success)
+	assert.Nil(t, err)
+
+	receipt, err := g.Charge(context.Background(), services.Charge{Token: "tok_visa", Amount: 10000})
+	assert.Nil(t, err)
+
+	assert.Nil(t, g.Refund(context.Background(), receipt.Reference, 6000))
+	assert.ErrorIs(t, g.Refund(context.Background(), receipt.Reference, 6000), fakepay.ErrRefundTooLarge)
+	assert.Nil(t, g.Refund(context.Background(), receipt.Reference, 4000))
+	assert.ErrorIs(t, g.Refund(context.Background(), "fake_ch_missing", 100), fakepay.ErrUnknownCharge)
+}
+
+func TestGateway_Lookup(t *testing.T) {
+	g, err := fakepay.New(fakepay.Success)
+	assert.Nil(t, err)
+
+	charge := services.Charge{Token: fakepay.TimeoutToken, Amount: 10000, IdempotencyKey: "booking-1"}
+
+	// a timed out charge is captured all the same.
+	_, err = g.Charge(context.Background(), charge)
+	assert.ErrorIs(t, err, services.ErrPaymentTimeout)
+
+	receipt, err := g.Lookup(context.Background(), "booking-1")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10000), receipt.Amount)
+
+	// retrying with the same key doesn't charge twice.
+	charge.Token = fakepay.SuccessToken
+	retry, err := g.Charge(context.Background(), charge)
+	assert.Nil(t, err)
+	assert.Equal(t, receipt.Reference, retry.Reference)
+
+	_, err = g.Lookup(context.Background(), "booking-2")
+	assert.ErrorIs(t, err, services.ErrPaymentNotFound)
+}
@@ -0,0 +1,755 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+var (
+	ErrInvalidTransition = errors.New("invalid booking status transition")
+)
+
+const (
+	BookingPending   = "pending"
+	BookingPaid      = "paid"
+	BookingCancelled = "cancelled"
+	BookingRefunded  = "refunded"
+)
+
+// A refunded booking's refund is pending until the payment gateway has paid
+// it back, then settled.
+const (
+	RefundPending = "pending"
+	RefundSettled = "settled"
+)
+
+// bookingTransitions lists the statuses a booking may move to from each
+// status. Cancelled and refunded bookings are final.
+var bookingTransitions = map[string][]string{
+	BookingPending: {BookingPaid, BookingCancelled},
+	BookingPaid:    {BookingCancelled, BookingRefunded},
+}
+
+// Booking is a customer's order for the seats of a hold, and the concessions
+// to pick up at its show. Amounts are in the minor unit of Currency, the
+// theater's currency at checkout; Amount is what's left to pay after the
+// discounts, the taxes and fees added on top and the gift card amount.
+type Booking struct {
+	ID                 int              `json:"id"`
+	UserID             int              `json:"user_id"`
+	ShowID             int              `json:"show_id"`
+	HoldID             int              `json:"hold_id"`
+	Status             string           `json:"status"`
+	Amount             int64            `json:"amount"`
+	Currency           string           `json:"currency"`
+	SubscriptionID     *int             `json:"subscription_id,omitempty"`
+	MembershipTickets  int              `json:"membership_tickets"`
+	MembershipDiscount int64            `json:"membership_discount"`
+	Discount           int64            `json:"discount"`
+	PromoCodeID        *int             `json:"promo_code_id,omitempty"`
+	LoyaltyDiscount    int64            `json:"loyalty_discount"`
+	PointsRedeemed     int              `json:"points_redeemed"`
+	PointsEarned       int              `json:"points_earned"`
+	GiftCardID         *int             `json:"gift_card_id,omitempty"`
+	GiftCardAmount     int64            `json:"gift_card_amount"`
+	PaymentRef         string           `json:"payment_reference,omitempty"`
+	Refunded           int64            `json:"refund_amount"`
+	RefundStatus       string           `json:"refund_status,omitempty"`
+	Tickets            []Ticket         `json:"tickets"`
+	Concessions        []ConcessionLine `json:"concessions"`
+	Taxes              []TaxLine        `json:"taxes"`
+	CreatedAt          time.Time        `json:"created_at"`
+	UpdatedAt          time.Time        `json:"updated_at"`
+}
+
+func (b Booking) CanTransition(to string) bool {
+	return slices.Contains(bookingTransitions[b.Status], to)
+}
+
+type BookingModel struct {
+	db *sql.DB
+}
+
+// Create stores a pending booking for the seats of an active hold. The hold
+// is marked converted so that it doesn't expire while the payment is being
+// processed. The booking's membership, promo code, loyalty points and gift
+// card, if any, are redeemed along with it.
+func (m *BookingModel) Create(booking *Booking) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	query := `UPDATE seat_holds
+	SET status = 'converted', updated_at = NOW()
+	WHERE id = $1 AND user_id = $2 AND status = 'active' AND expires_at > NOW()`
+
+	result, err := tx.Exec(query, booking.HoldID, booking.UserID)
+	if err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	if rows, err := result.RowsAffected(); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	} else if rows == 0 {
+		tx.Rollback()
+		return ErrHoldNotActive
+	}
+
+	query = `INSERT INTO bookings(user_id, show_id, hold_id, amount, currency,
+	subscription_id, membership_tickets, membership_discount, discount,
+	loyalty_discount, points_redeemed, points_earned, gift_card_id,
+	gift_card_amount)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	RETURNING id, status, created_at, updated_at`
+	args := []any{
+		booking.UserID,
+		booking.ShowID,
+		booking.HoldID,
+		booking.Amount,
+		booking.Currency,
+		booking.SubscriptionID,
+		booking.MembershipTickets,
+		booking.MembershipDiscount,
+		booking.Discount,
+		booking.LoyaltyDiscount,
+		booking.PointsRedeemed,
+		booking.PointsEarned,
+		booking.GiftCardID,
+		booking.GiftCardAmount,
+	}
+
+	err = tx.QueryRow(query, args...).Scan(
+		&booking.ID,
+		&booking.Status,
+		&booking.CreatedAt,
+		&booking.UpdatedAt,
+	)
+	if err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	// tickets keep the price, category and ticket type they were sold at,
+	// whatever happens to the theater's prices later on.
+	query = `INSERT INTO tickets(booking_id, show_seat_id, price, category, ticket_type)
+	SELECT $1, s.id, $3, s.category, $5
+	FROM show_seats AS s
+	WHERE s.id = $2 AND s.hold_id = $4 AND s.status = 'held'
+	RETURNING id, category, created_at`
+
+	for i := range booking.Tickets {
+		ticket := &booking.Tickets[i]
+		ticket.BookingID = booking.ID
+		ticket.ShowID = booking.ShowID
+
+		args := []any{booking.ID, ticket.ShowSeatID, ticket.Price, booking.HoldID, ticket.TicketType}
+		err := tx.QueryRow(query, args...).Scan(
+			&ticket.ID,
+			&ticket.Category,
+			&ticket.CreatedAt,
+		)
+		if err != nil {
+			tx.Rollback()
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				return fmt.Errorf("%w: seat %v is not held", ErrSeatUnavailable, ticket.ShowSeatID)
+			default:
+				slog.Error("SQL Database Failure", "error", err)
+				return err
+			}
+		}
+	}
+
+	if err := insertConcessionLines(tx, booking); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := insertTaxLines(tx, booking); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if booking.SubscriptionID != nil {
+		if err := useMembership(tx, booking); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if booking.PromoCodeID != nil {
+		if err := redeemPromoCode(tx, booking); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if booking.PointsRedeemed > 0 {
+		if err := redeemLoyaltyPoints(tx, booking); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if booking.GiftCardID != nil {
+		if err := redeemGiftCard(tx, booking); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := recordBookingEvent(tx, booking.ID, "", BookingPending, "checkout started"); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (m *BookingModel) Find(id int) (*Booking, error) {
+	query := `SELECT b.user_id, b.show_id, b.hold_id, b.status, b.amount,
+	b.currency, b.subscription_id, b.membership_tickets, b.membership_discount,
+	b.discount, r.promo_code_id, b.loyalty_discount, b.points_redeemed,
+	b.points_earned, b.gift_card_id, b.gift_card_amount,
+	COALESCE(b.payment_reference, ''),
+	b.refund_amount, COALESCE(b.refund_status, ''), b.created_at, b.updated_at
+	FROM bookings AS b
+	LEFT JOIN promo_redemptions AS r ON r.booking_id = b.id
+	WHERE b.id = $1`
+
+	booking := &Booking{ID: id}
+
+	err := m.db.QueryRow(query, id).Scan(
+		&booking.UserID,
+		&booking.ShowID,
+		&booking.HoldID,
+		&booking.Status,
+		&booking.Amount,
+		&booking.Currency,
+		&booking.SubscriptionID,
+		&booking.MembershipTickets,
+		&booking.MembershipDiscount,
+		&booking.Discount,
+		&booking.PromoCodeID,
+		&booking.LoyaltyDiscount,
+		&booking.PointsRedeemed,
+		&booking.PointsEarned,
+		&booking.GiftCardID,
+		&booking.GiftCardAmount,
+		&booking.PaymentRef,
+		&booking.Refunded,
+		&booking.RefundStatus,
+		&booking.CreatedAt,
+		&booking.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNotFound
+		default:
+			slog.Error("SQL Database Failure", "error", err)
+			return nil, err
+		}
+	}
+
+	booking.Tickets, err = m.findTickets(id)
+	if err != nil {
+		return nil, err
+	}
+
+	booking.Concessions, err = m.findConcessions(id)
+	if err != nil {
+		return nil, err
+	}
+
+	booking.Taxes, err = m.findTaxes(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return booking, nil
+}
+
+func (m *BookingModel) FindByUser(userID int) ([]Booking, error) {
+	query := `SELECT b.id, b.user_id, b.show_id, b.hold_id, b.status, b.amount,
+	b.currency, b.subscription_id, b.membership_tickets, b.membership_discount,
+	b.discount, r.promo_code_id, b.loyalty_discount, b.points_redeemed,
+	b.points_earned, b.gift_card_id, b.gift_card_amount,
+	COALESCE(b.payment_reference, ''),
+	b.refund_amount, COALESCE(b.refund_status, ''), b.created_at, b.updated_at
+	FROM bookings AS b
+	LEFT JOIN promo_redemptions AS r ON r.booking_id = b.id
+	WHERE b.user_id = $1
+	ORDER BY b.created_at DESC`
+
+	rows, err := m.db.Query(query, userID)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	bookings := []Booking{}
+	for rows.Next() {
+		var booking Booking
+		err := rows.Scan(
+			&booking.ID,
+			&booking.UserID,
+			&booking.ShowID,
+			&booking.HoldID,
+			&booking.Status,
+			&booking.Amount,
+			&booking.Currency,
+			&booking.SubscriptionID,
+			&booking.MembershipTickets,
+			&booking.MembershipDiscount,
+			&booking.Discount,
+			&booking.PromoCodeID,
+			&booking.LoyaltyDiscount,
+			&booking.PointsRedeemed,
+			&booking.PointsEarned,
+			&booking.GiftCardID,
+			&booking.GiftCardAmount,
+			&booking.PaymentRef,
+			&booking.Refunded,
+			&booking.RefundStatus,
+			&booking.CreatedAt,
+			&booking.UpdatedAt,
+		)
+		if err != nil {
+			slog.Error("Scan Failure", "error", err)
+			return nil, err
+		}
+
+		bookings = append(bookings, booking)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Scan Failure", "error", err)
+		return nil, err
+	}
+
+	return bookings, nil
+}
+
+// MarkPaid completes a pending booking: its held seats become sold, its
+// loyalty points are earned and it gets the next invoice number of its
+// theater.
+func (m *BookingModel) MarkPaid(booking *Booking) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	if err := transitionBooking(tx, booking, BookingPaid, "payment captured"); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	query := `UPDATE bookings SET payment_reference = $2 WHERE id = $1`
+	if _, err := tx.Exec(query, booking.ID, booking.PaymentRef); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	query = `UPDATE show_seats
+	SET status = 'sold', updated_at = NOW()
+	WHERE hold_id = $1 AND status = 'held'`
+	if _, err := tx.Exec(query, booking.HoldID); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	query = `UPDATE waitlist_entries
+	SET status = 'fulfilled', updated_at = NOW()
+	WHERE hold_id = $1 AND status = 'offered'`
+	if _, err := tx.Exec(query, booking.HoldID); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	if err := earnLoyaltyPoints(tx, booking); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := issueInvoice(tx, booking.ID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// Abandon cancels a pending booking whose payment didn't go through. The
+// hold becomes active again so the customer may retry until it expires, and
+// the membership tickets, promo code redemption, loyalty points and gift card
+// balance are given back.
+func (m *BookingModel) Abandon(booking *Booking, reason string) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	if err := transitionBooking(tx, booking, BookingCancelled, reason); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	query := `UPDATE seat_holds
+	SET status = 'active', updated_at = NOW()
+	WHERE id = $1 AND status = 'converted'`
+	if _, err := tx.Exec(query, booking.HoldID); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	if err := releaseMembership(tx, booking); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := releasePromoRedemption(tx, booking.ID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := restoreLoyaltyPoints(tx, booking); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := restoreGiftCard(tx, booking, booking.GiftCardAmount); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// Cancel cancels a paid booking, puts its seats back on sale, gives back its
+// membership tickets and the loyalty points redeemed on it, and takes back
+// the loyalty points it earned. refund is paid back to the customer and
+// giftCardRefund to the booking's gift card, both recorded as the booking's
+// refund amount. A booking with a refund is marked refunded, otherwise
+// cancelled; a refund to the customer is pending until SettleRefund.
+// Bookings with a checked in ticket fail with ErrTicketUsed.
+func (m *BookingModel) Cancel(booking *Booking, refund, giftCardRefund int64, reason string) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	if err := lockUnusedTickets(tx, booking.ID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	to, status := BookingCancelled, ""
+	switch {
+	case refund > 0:
+		to, status = BookingRefunded, RefundPending
+	case giftCardRefund > 0:
+		to, status = BookingRefunded, RefundSettled
+	}
+
+	if err := transitionBooking(tx, booking, to, reason); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	query := `UPDATE bookings SET refund_amount = $2, refund_status = NULLIF($3, '')
+	WHERE id = $1`
+	if _, err := tx.Exec(query, booking.ID, refund+giftCardRefund, status); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	query = `UPDATE show_seats
+	SET status = 'available', user_id = NULL, hold_id = NULL, updated_at = NOW()
+	WHERE status = 'sold' AND id IN (
+		SELECT show_seat_id FROM tickets WHERE booking_id = $1
+	)`
+	if _, err := tx.Exec(query, booking.ID); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	if err := releaseMembership(tx, booking); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := reverseLoyaltyPoints(tx, booking); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := restoreLoyaltyPoints(tx, booking); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := restoreGiftCard(tx, booking, giftCardRefund); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	booking.Refunded = refund + giftCardRefund
+	booking.RefundStatus = status
+
+	return nil
+}
+
+// lockUnusedTickets locks the tickets of a booking for the rest of the
+// transaction, failing with ErrTicketUsed if any was checked in already, so
+// that none can be checked in while the booking is cancelled.
+func lockUnusedTickets(tx *sql.Tx, bookingID int) error {
+	query := `SELECT used_at IS NOT NULL FROM tickets
+	WHERE booking_id = $1
+	FOR UPDATE`
+
+	rows, err := tx.Query(query, bookingID)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+	defer rows.Close()
+
+	checkedIn := false
+	for rows.Next() {
+		var used bool
+		if err := rows.Scan(&used); err != nil {
+			slog.Error("Scan Failure", "error", err)
+			return err
+		}
+		checkedIn = checkedIn || used
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Scan Failure", "error", err)
+		return err
+	}
+
+	if checkedIn {
+		return ErrTicketUsed
+	}
+
+	return nil
+}
+
+// SettleRefund records that the pending refund of a booking was paid back.
+func (m *BookingModel) SettleRefund(booking *Booking) error {
+	query := `UPDATE bookings SET refund_status = 'settled', updated_at = NOW()
+	WHERE id = $1 AND refund_status = 'pending'`
+
+	result, err := m.db.Exec(query, booking.ID)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	if rows, err := result.RowsAffected(); err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	} else if rows == 0 {
+		return ErrEditConflict
+	}
+
+	booking.RefundStatus = RefundSettled
+
+	return nil
+}
+
+func (m *BookingModel) findTickets(bookingID int) ([]Ticket, error) {
+	query := `SELECT t.id, t.booking_id, s.show_id, t.show_seat_id, s.row,
+	s.seat_number, t.category, t.ticket_type, t.price, t.used_at, t.created_at
+	FROM tickets AS t
+	JOIN show_seats AS s ON s.id = t.show_seat_id
+	WHERE t.booking_id = $1
+	ORDER BY LENGTH(s.row), s.row, s.seat_number`
+
+	rows, err := m.db.Query(query, bookingID)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	tickets := []Ticket{}
+	for rows.Next() {
+		var ticket Ticket
+		err := rows.Scan(
+			&ticket.ID,
+			&ticket.BookingID,
+			&ticket.ShowID,
+			&ticket.ShowSeatID,
+			&ticket.Row,
+			&ticket.SeatNumber,
+			&ticket.Category,
+			&ticket.TicketType,
+			&ticket.Price,
+			&ticket.UsedAt,
+			&ticket.CreatedAt,
+		)
+		if err != nil {
+			slog.Error("Scan Failure", "error", err)
+			return nil, err
+		}
+
+		tickets = append(tickets, ticket)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Scan Failure", "error", err)
+		return nil, err
+	}
+
+	return tickets, nil
+}
+
+func (m *BookingModel) findConcessions(bookingID int) ([]ConcessionLine, error) {
+	query := `SELECT id, booking_id, show_id, item_id, name, quantity, unit_price,
+	collected_at, created_at
+	FROM booking_concessions
+	WHERE booking_id = $1
+	ORDER BY id`
+
+	rows, err := m.db.Query(query, bookingID)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanConcessionLines(rows)
+}
+
+func (m *BookingModel) findTaxes(bookingID int) ([]TaxLine, error) {
+	query := `SELECT name, kind, rate, base, amount, inclusive
+	FROM booking_taxes
+	WHERE booking_id = $1
+	ORDER BY id`
+
+	rows, err := m.db.Query(query, bookingID)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	taxes := []TaxLine{}
+	for rows.Next() {
+		var line TaxLine
+		err := rows.Scan(
+			&line.Name,
+			&line.Kind,
+			&line.Rate,
+			&line.Base,
+			&line.Amount,
+			&line.Inclusive,
+		)
+		if err != nil {
+			slog.Error("Scan Failure", "error", err)
+			return nil, err
+		}
+
+		taxes = append(taxes, line)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Scan Failure", "error", err)
+		return nil, err
+	}
+
+	return taxes, nil
+}
+
+// transitionBooking moves the booking to the given status as part of an
+// ongoing transaction and records the transition in the booking's history.
+// The update only succeeds if nobody changed the booking's status meanwhile.
+func transitionBooking(tx *sql.Tx, booking *Booking, to, reason string) error {
+	if !booking.CanTransition(to) {
+		return fmt.Errorf("%w: %v to %v", ErrInvalidTransition, booking.Status, to)
+	}
+
+	query := `UPDATE bookings
+	SET status = $3, updated_at = NOW()
+	WHERE id = $1 AND status = $2
+	RETURNING updated_at`
+
+	err := tx.QueryRow(query, booking.ID, booking.Status, to).Scan(&booking.UpdatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+	}
+
+	if err := recordBookingEvent(tx, booking.ID, booking.Status, to, reason); err != nil {
+		return err
+	}
+
+	booking.Status = to
+
+	return nil
+}
+
+func recordBookingEvent(tx *sql.Tx, bookingID int, from, to, reason string) error {
+	query := `INSERT INTO booking_events(booking_id, from_status, to_status, reason)
+	VALUES ($1, NULLIF($2, ''), $3, $4)`
+
+	if _, err := tx.Exec(query, bookingID, from, to, reason); err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBooking_CanTransition(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		want     bool
+	}{
+		{name: "pending to paid", from: BookingPending, to: BookingPaid, want: true},
+		{name: "pending to cancelled", from: BookingPending, to: BookingCancelled, want: true},
+		{name: "pending to refunded", from: BookingPending, to: BookingRefunded, want: false},
+		{name: "paid to refunded", from: BookingPaid, to: BookingRefunded, want: true},
+		{name: "paid to cancelled", from: BookingPaid, to: BookingCancelled, want: true},
+		{name: "paid to pending", from: BookingPaid, to: BookingPending, want: false},
+		{name: "cancelled is final", from: BookingCancelled, to: BookingPaid, want: false},
+		{name: "refunded is final", from: BookingRefunded, to: BookingCancelled, want: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			booking := Booking{Status: tc.from}
+			assert.Equal(t, tc.want, booking.CanTransition(tc.to))
+		})
+	}
+}
@@ -0,0 +1,486 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+var (
+	ErrConcessionInUse = errors.New("concession item is part of a combo")
+)
+
+const (
+	ConcessionItem  = "item"
+	ConcessionCombo = "combo"
+)
+
+// Concession is a snack or drink a theater sells, or a combo of several of
+// them sold together at its own price. Prices are in the currency's minor
+// unit.
+type Concession struct {
+	ID          int              `json:"id"`
+	TheaterID   int              `json:"theater_id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Kind        string           `json:"kind"`
+	Price       int64            `json:"price"`
+	Available   bool             `json:"available"`
+	Components  []ComboComponent `json:"components,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// ComboComponent is an item of a combo.
+type ComboComponent struct {
+	ItemID   int    `json:"item_id"`
+	Name     string `json:"name,omitempty"`
+	Quantity int    `json:"quantity"`
+}
+
+// ConcessionLine is a concession ordered along with a booking, to be picked
+// up at the booking's show. It keeps the name and price it was sold at.
+type ConcessionLine struct {
+	ID          int        `json:"id"`
+	BookingID   int        `json:"booking_id"`
+	ShowID      int        `json:"show_id"`
+	ItemID      *int       `json:"item_id"`
+	Name        string     `json:"name"`
+	Quantity    int        `json:"quantity"`
+	UnitPrice   int64      `json:"unit_price"`
+	CollectedAt *time.Time `json:"collected_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// ConcessionOrder is the concessions of a paid booking waiting to be picked
+// up at its show.
+type ConcessionOrder struct {
+	BookingID  int              `json:"booking_id"`
+	Username   string           `json:"username"`
+	ShowID     int              `json:"show_id"`
+	HallCode   string           `json:"hall_code"`
+	MovieTitle string           `json:"movie_title"`
+	StartTime  time.Time        `json:"start_time"`
+	Lines      []ConcessionLine `json:"lines"`
+}
+
+type ConcessionModel struct {
+	db *sql.DB
+}
+
+// Create stores a concession along with the items of a combo.
+func (m *ConcessionModel) Create(item *Concession) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	query := `INSERT INTO concession_items(theater_id, name, description, kind, price, available)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	RETURNING id, created_at, updated_at`
+	args := []any{item.TheaterID, item.Name, item.Description, item.Kind, item.Price, item.Available}
+
+	err = tx.QueryRow(query, args...).Scan(&item.ID, &item.CreatedAt, &item.UpdatedAt)
+	if err != nil {
+		tx.Rollback()
+		switch {
+		case strings.Contains(err.Error(), "concession_items_theater_id_name_key"):
+			return fmt.Errorf("%w: concession name", ErrDuplicate)
+		default:
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+	}
+
+	if err := insertComboComponents(tx, item); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (m *ConcessionModel) Find(id int) (*Concession, error) {
+	query := `SELECT id, theater_id, name, description, kind, price, available,
+	created_at, updated_at
+	FROM concession_items
+	WHERE id = $1`
+
+	var item Concession
+	err := m.db.QueryRow(query, id).Scan(
+		&item.ID,
+		&item.TheaterID,
+		&item.Name,
+		&item.Description,
+		&item.Kind,
+		&item.Price,
+		&item.Available,
+		&item.CreatedAt,
+		&item.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNotFound
+		default:
+			slog.Error("SQL Database Failure", "error", err)
+			return nil, err
+		}
+	}
+
+	components, err := m.findComponents(`c.combo_id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	item.Components = components[item.ID]
+
+	return &item, nil
+}
+
+// FindByTheater returns the concessions catalog of a theater, items first.
+func (m *ConcessionModel) FindByTheater(theaterID int) ([]Concession, error) {
+	query := `SELECT id, theater_id, name, description, kind, price, available,
+	created_at, updated_at
+	FROM concession_items
+	WHERE theater_id = $1
+	ORDER BY kind DESC, name`
+
+	rows, err := m.db.Query(query, theaterID)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []Concession{}
+	for rows.Next() {
+		var item Concession
+		err := rows.Scan(
+			&item.ID,
+			&item.TheaterID,
+			&item.Name,
+			&item.Description,
+			&item.Kind,
+			&item.Price,
+			&item.Available,
+			&item.CreatedAt,
+			&item.UpdatedAt,
+		)
+		if err != nil {
+			slog.Error("Scan Failure", "error", err)
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Scan Failure", "error", err)
+		return nil, err
+	}
+
+	components, err := m.findComponents(`i.theater_id = $1`, theaterID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range items {
+		items[i].Components = components[items[i].ID]
+	}
+
+	return items, nil
+}
+
+// Update saves the concession and replaces the items of a combo. The update
+// only succeeds if nobody changed the concession meanwhile.
+func (m *ConcessionModel) Update(item *Concession) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	query := `UPDATE concession_items
+	SET name = $1, description = $2, price = $3, available = $4, updated_at = NOW()
+	WHERE id = $5 AND updated_at = $6
+	RETURNING updated_at`
+	args := []any{item.Name, item.Description, item.Price, item.Available, item.ID, item.UpdatedAt}
+
+	err = tx.QueryRow(query, args...).Scan(&item.UpdatedAt)
+	if err != nil {
+		tx.Rollback()
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		case strings.Contains(err.Error(), "concession_items_theater_id_name_key"):
+			return fmt.Errorf("%w: concession name", ErrDuplicate)
+		default:
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+	}
+
+	if item.Kind == ConcessionCombo {
+		query = `DELETE FROM concession_combo_items WHERE combo_id = $1`
+		if _, err := tx.Exec(query, item.ID); err != nil {
+			tx.Rollback()
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+
+		if err := insertComboComponents(tx, item); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// Delete removes a concession from the catalog. Items that are part of a
+// combo can't be deleted; bookings that ordered the concession keep their
+// lines.
+func (m *ConcessionModel) Delete(id int) error {
+	query := `DELETE FROM concession_items WHERE id = $1`
+
+	result, err := m.db.Exec(query, id)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "concession_combo_items_item_id_fkey"):
+			return ErrConcessionInUse
+		default:
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+	}
+
+	if rows, err := result.RowsAffected(); err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	} else if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// FindPending lists the concession orders of a theater's paid bookings not
+// picked up yet, for the shows running between from and until, by show start
+// time.
+func (m *ConcessionModel) FindPending(theaterID int, from, until time.Time) ([]ConcessionOrder, error) {
+	query := `SELECT c.id, c.booking_id, c.show_id, c.item_id, c.name, c.quantity,
+	c.unit_price, c.collected_at, c.created_at, u.username, h.code, mv.title,
+	s.start_time
+	FROM booking_concessions AS c
+	JOIN bookings AS b ON b.id = c.booking_id
+	JOIN users AS u ON u.id = b.user_id
+	JOIN shows AS s ON s.id = c.show_id
+	JOIN halls AS h ON h.id = s.hall_id
+	JOIN movies AS mv ON mv.imdb_id = s.movie_id
+	WHERE h.theater_id = $1 AND b.status = 'paid' AND c.collected_at IS NULL
+	AND s.end_time > $2 AND s.start_time < $3
+	ORDER BY s.start_time, c.booking_id, c.id`
+
+	rows, err := m.db.Query(query, theaterID, from, until)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := []ConcessionOrder{}
+	for rows.Next() {
+		var line ConcessionLine
+		var order ConcessionOrder
+		err := rows.Scan(
+			&line.ID,
+			&line.BookingID,
+			&line.ShowID,
+			&line.ItemID,
+			&line.Name,
+			&line.Quantity,
+			&line.UnitPrice,
+			&line.CollectedAt,
+			&line.CreatedAt,
+			&order.Username,
+			&order.HallCode,
+			&order.MovieTitle,
+			&order.StartTime,
+		)
+		if err != nil {
+			slog.Error("Scan Failure", "error", err)
+			return nil, err
+		}
+
+		if n := len(orders); n > 0 && orders[n-1].BookingID == line.BookingID {
+			orders[n-1].Lines = append(orders[n-1].Lines, line)
+			continue
+		}
+
+		order.BookingID = line.BookingID
+		order.ShowID = line.ShowID
+		order.Lines = []ConcessionLine{line}
+		orders = append(orders, order)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Scan Failure", "error", err)
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+// Collect marks the concessions of a theater's paid booking as picked up.
+func (m *ConcessionModel) Collect(theaterID, bookingID int) ([]ConcessionLine, error) {
+	query := `UPDATE booking_concessions AS c
+	SET collected_at = NOW()
+	FROM bookings AS b, shows AS s, halls AS h
+	WHERE c.booking_id = $2 AND c.collected_at IS NULL
+	AND b.id = c.booking_id AND b.status = 'paid'
+	AND s.id = c.show_id AND h.id = s.hall_id AND h.theater_id = $1
+	RETURNING c.id, c.booking_id, c.show_id, c.item_id, c.name, c.quantity,
+	c.unit_price, c.collected_at, c.created_at`
+
+	rows, err := m.db.Query(query, theaterID, bookingID)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines, err := scanConcessionLines(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(lines) == 0 {
+		return nil, ErrNotFound
+	}
+
+	return lines, nil
+}
+
+// findComponents returns the items of the combos matching the condition, by
+// combo.
+func (m *ConcessionModel) findComponents(condition string, arg any) (map[int][]ComboComponent, error) {
+	query := `SELECT c.combo_id, c.item_id, i.name, c.quantity
+	FROM concession_combo_items AS c
+	JOIN concession_items AS i ON i.id = c.item_id
+	WHERE ` + condition + `
+	ORDER BY c.combo_id, i.name`
+
+	rows, err := m.db.Query(query, arg)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	components := map[int][]ComboComponent{}
+	for rows.Next() {
+		var comboID int
+		var component ComboComponent
+		if err := rows.Scan(&comboID, &component.ItemID, &component.Name, &component.Quantity); err != nil {
+			slog.Error("Scan Failure", "error", err)
+			return nil, err
+		}
+
+		components[comboID] = append(components[comboID], component)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Scan Failure", "error", err)
+		return nil, err
+	}
+
+	return components, nil
+}
+
+func insertComboComponents(tx *sql.Tx, item *Concession) error {
+	query := `INSERT INTO concession_combo_items(combo_id, item_id, quantity)
+	VALUES ($1, $2, $3)`
+
+	for _, component := range item.Components {
+		if _, err := tx.Exec(query, item.ID, component.ItemID, component.Quantity); err != nil {
+			switch {
+			case strings.Contains(err.Error(), "concession_combo_items_item_id_fkey"):
+				return fmt.Errorf("%w: concession item %v", ErrNotFound, component.ItemID)
+			default:
+				slog.Error("SQL Database Failure", "error", err)
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// insertConcessionLines stores the concessions ordered with a booking as
+// part of an ongoing transaction.
+func insertConcessionLines(tx *sql.Tx, booking *Booking) error {
+	query := `INSERT INTO booking_concessions(booking_id, show_id, item_id, name, quantity, unit_price)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	RETURNING id, created_at`
+
+	for i := range booking.Concessions {
+		line := &booking.Concessions[i]
+		line.BookingID = booking.ID
+		line.ShowID = booking.ShowID
+
+		args := []any{line.BookingID, line.ShowID, line.ItemID, line.Name, line.Quantity, line.UnitPrice}
+		if err := tx.QueryRow(query, args...).Scan(&line.ID, &line.CreatedAt); err != nil {
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func scanConcessionLines(rows *sql.Rows) ([]ConcessionLine, error) {
+	lines := []ConcessionLine{}
+	for rows.Next() {
+		var line ConcessionLine
+		err := rows.Scan(
+			&line.ID,
+			&line.BookingID,
+			&line.ShowID,
+			&line.ItemID,
+			&line.Name,
+			&line.Quantity,
+			&line.UnitPrice,
+			&line.CollectedAt,
+			&line.CreatedAt,
+		)
+		if err != nil {
+			slog.Error("Scan Failure", "error", err)
+			return nil, err
+		}
+
+		lines = append(lines, line)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Scan Failure", "error", err)
+		return nil, err
+	}
+
+	return lines, nil
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"database/sql"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// ExchangeRate is how many units of Currency one unit of the base currency
+// is worth. Rate is a decimal number, e.g. "48.35", kept as text so that it
+// is never rounded by floating point arithmetic.
+type ExchangeRate struct {
+	Currency  string    `json:"currency"`
+	Rate      string    `json:"rate"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ExchangeRateModel struct {
+	db *sql.DB
+}
+
+func (m *ExchangeRateModel) FindAll() ([]ExchangeRate, error) {
+	query := `SELECT currency, rate, updated_at
+	FROM exchange_rates
+	ORDER BY currency`
+
+	rows, err := m.db.Query(query)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := []ExchangeRate{}
+	for rows.Next() {
+		var rate ExchangeRate
+		if err := rows.Scan(&rate.Currency, &rate.Rate, &rate.UpdatedAt); err != nil {
+			slog.Error("Scan Failure", "error", err)
+			return nil, err
+		}
+
+		// NUMERIC columns are padded to their scale.
+		if strings.Contains(rate.Rate, ".") {
+			rate.Rate = strings.TrimSuffix(strings.TrimRight(rate.Rate, "0"), ".")
+		}
+
+		rates = append(rates, rate)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Scan Failure", "error", err)
+		return nil, err
+	}
+
+	return rates, nil
+}
+
+// Replace swaps the exchange rates for the given ones. Bookings keep the
+// currency they were paid in, so only reports are affected.
+func (m *ExchangeRateModel) Replace(rates []ExchangeRate) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM exchange_rates`); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	query := `INSERT INTO exchange_rates(currency, rate)
+	VALUES ($1, $2)
+	RETURNING updated_at`
+
+	for i := range rates {
+		rate := &rates[i]
+		if err := tx.QueryRow(query, rate.Currency, rate.Rate).Scan(&rate.UpdatedAt); err != nil {
+			tx.Rollback()
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	return nil
+}
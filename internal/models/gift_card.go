@@ -0,0 +1,348 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+var (
+	ErrGiftCardInsufficient = errors.New("gift card balance insufficient")
+)
+
+const (
+	GiftCardIssue   = "issue"
+	GiftCardRedeem  = "redeem"
+	GiftCardRestore = "restore"
+)
+
+// A gift card is pending until its purchase is paid for, and void if the
+// payment never went through. Only active cards can be spent.
+const (
+	GiftCardPending = "pending"
+	GiftCardActive  = "active"
+	GiftCardVoid    = "void"
+)
+
+// GiftCard holds a stored balance that can be spent on bookings until it
+// expires, at the theaters selling in its Currency. Amounts are in the
+// currency's minor unit.
+type GiftCard struct {
+	ID             int                   `json:"id"`
+	Code           string                `json:"code"`
+	PurchasedBy    *int                  `json:"purchased_by,omitempty"`
+	InitialBalance int64                 `json:"initial_balance"`
+	Balance        int64                 `json:"balance"`
+	Currency       string                `json:"currency"`
+	Status         string                `json:"status"`
+	PaymentRef     string                `json:"payment_reference,omitempty"`
+	ExpiresAt      time.Time             `json:"expires_at"`
+	Transactions   []GiftCardTransaction `json:"transactions,omitempty"`
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+}
+
+func (g GiftCard) IsExpired(now time.Time) bool {
+	return !now.Before(g.ExpiresAt)
+}
+
+// GiftCardTransaction is an entry of a gift card's balance ledger. Issues and
+// restores are positive, redemptions negative.
+type GiftCardTransaction struct {
+	ID         int       `json:"id"`
+	GiftCardID int       `json:"gift_card_id"`
+	BookingID  *int      `json:"booking_id,omitempty"`
+	Kind       string    `json:"kind"`
+	Amount     int64     `json:"amount"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type GiftCardModel struct {
+	db *sql.DB
+}
+
+// Create stores a gift card with its initial balance in the ledger.
+func (m *GiftCardModel) Create(card *GiftCard) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	query := `INSERT INTO gift_cards(code, purchased_by, initial_balance, balance,
+	currency, status, payment_reference, expires_at)
+	VALUES ($1, $2, $3, $3, $4, $5, NULLIF($6, ''), $7)
+	RETURNING id, balance, created_at, updated_at`
+	args := []any{
+		card.Code,
+		card.PurchasedBy,
+		card.InitialBalance,
+		card.Currency,
+		card.Status,
+		card.PaymentRef,
+		card.ExpiresAt,
+	}
+
+	err = tx.QueryRow(query, args...).Scan(
+		&card.ID,
+		&card.Balance,
+		&card.CreatedAt,
+		&card.UpdatedAt,
+	)
+	if err != nil {
+		tx.Rollback()
+		switch {
+		case strings.Contains(err.Error(), "gift_cards_code_key"):
+			return fmt.Errorf("%w: gift card code", ErrDuplicate)
+		default:
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+	}
+
+	issue := GiftCardTransaction{GiftCardID: card.ID, Kind: GiftCardIssue, Amount: card.InitialBalance}
+	if err := insertGiftCardTransaction(tx, &issue); err != nil {
+		tx.Rollback()
+		return err
+	}
+	card.Transactions = []GiftCardTransaction{issue}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// Activate makes a pending gift card spendable once its purchase is paid for,
+// recording the charge that paid for it.
+func (m *GiftCardModel) Activate(card *GiftCard) error {
+	return m.transition(card, GiftCardActive, card.PaymentRef)
+}
+
+// Void marks a pending gift card whose purchase wasn't paid for, so that it
+// can never be spent.
+func (m *GiftCardModel) Void(card *GiftCard) error {
+	return m.transition(card, GiftCardVoid, "")
+}
+
+func (m *GiftCardModel) transition(card *GiftCard, status, paymentRef string) error {
+	query := `UPDATE gift_cards
+	SET status = $2, payment_reference = NULLIF($3, ''), updated_at = NOW()
+	WHERE id = $1 AND status = 'pending'
+	RETURNING updated_at`
+
+	err := m.db.QueryRow(query, card.ID, status, paymentRef).Scan(&card.UpdatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return fmt.Errorf("%w: gift card isn't pending", ErrEditConflict)
+		default:
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+	}
+
+	card.Status = status
+
+	return nil
+}
+
+// FindByCode returns a gift card along with its ledger.
+func (m *GiftCardModel) FindByCode(code string) (*GiftCard, error) {
+	query := `SELECT id, code, purchased_by, initial_balance, balance, currency,
+	status, COALESCE(payment_reference, ''), expires_at, created_at, updated_at
+	FROM gift_cards
+	WHERE code = $1`
+
+	var card GiftCard
+	err := m.db.QueryRow(query, code).Scan(
+		&card.ID,
+		&card.Code,
+		&card.PurchasedBy,
+		&card.InitialBalance,
+		&card.Balance,
+		&card.Currency,
+		&card.Status,
+		&card.PaymentRef,
+		&card.ExpiresAt,
+		&card.CreatedAt,
+		&card.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNotFound
+		default:
+			slog.Error("SQL Database Failure", "error", err)
+			return nil, err
+		}
+	}
+
+	card.Transactions, err = m.findTransactions(card.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &card, nil
+}
+
+// FindByUser lists the gift cards a user purchased, newest first.
+func (m *GiftCardModel) FindByUser(userID int) ([]GiftCard, error) {
+	query := `SELECT id, code, purchased_by, initial_balance, balance, currency,
+	status, COALESCE(payment_reference, ''), expires_at, created_at, updated_at
+	FROM gift_cards
+	WHERE purchased_by = $1
+	ORDER BY created_at DESC`
+
+	rows, err := m.db.Query(query, userID)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	cards := []GiftCard{}
+	for rows.Next() {
+		var card GiftCard
+		err := rows.Scan(
+			&card.ID,
+			&card.Code,
+			&card.PurchasedBy,
+			&card.InitialBalance,
+			&card.Balance,
+			&card.Currency,
+			&card.Status,
+			&card.PaymentRef,
+			&card.ExpiresAt,
+			&card.CreatedAt,
+			&card.UpdatedAt,
+		)
+		if err != nil {
+			slog.Error("Scan Failure", "error", err)
+			return nil, err
+		}
+
+		cards = append(cards, card)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Scan Failure", "error", err)
+		return nil, err
+	}
+
+	return cards, nil
+}
+
+func (m *GiftCardModel) findTransactions(giftCardID int) ([]GiftCardTransaction, error) {
+	query := `SELECT id, gift_card_id, booking_id, kind, amount, created_at
+	FROM gift_card_transactions
+	WHERE gift_card_id = $1
+	ORDER BY created_at, id`
+
+	rows, err := m.db.Query(query, giftCardID)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	transactions := []GiftCardTransaction{}
+	for rows.Next() {
+		var t GiftCardTransaction
+		err := rows.Scan(
+			&t.ID,
+			&t.GiftCardID,
+			&t.BookingID,
+			&t.Kind,
+			&t.Amount,
+			&t.CreatedAt,
+		)
+		if err != nil {
+			slog.Error("Scan Failure", "error", err)
+			return nil, err
+		}
+
+		transactions = append(transactions, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Scan Failure", "error", err)
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+func insertGiftCardTransaction(tx *sql.Tx, t *GiftCardTransaction) error {
+	query := `INSERT INTO gift_card_transactions(gift_card_id, booking_id, kind, amount)
+	VALUES ($1, $2, $3, $4)
+	RETURNING id, created_at`
+
+	err := tx.QueryRow(query, t.GiftCardID, t.BookingID, t.Kind, t.Amount).Scan(&t.ID, &t.CreatedAt)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// redeemGiftCard takes the booking's gift card amount off the card's balance
+// as part of an ongoing transaction. The balance is only taken if it covers
+// the amount and the card is active and hasn't expired, so concurrent
+// checkouts can't overspend it.
+func redeemGiftCard(tx *sql.Tx, booking *Booking) error {
+	query := `UPDATE gift_cards
+	SET balance = balance - $2, updated_at = NOW()
+	WHERE id = $1 AND status = 'active' AND balance >= $2 AND expires_at > NOW()`
+
+	result, err := tx.Exec(query, *booking.GiftCardID, booking.GiftCardAmount)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	if rows, err := result.RowsAffected(); err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	} else if rows == 0 {
+		return ErrGiftCardInsufficient
+	}
+
+	return insertGiftCardTransaction(tx, &GiftCardTransaction{
+		GiftCardID: *booking.GiftCardID,
+		BookingID:  &booking.ID,
+		Kind:       GiftCardRedeem,
+		Amount:     -booking.GiftCardAmount,
+	})
+}
+
+// restoreGiftCard gives amount of the gift card balance spent on a booking
+// back to the card, as part of an ongoing transaction.
+func restoreGiftCard(tx *sql.Tx, booking *Booking, amount int64) error {
+	if booking.GiftCardID == nil || amount == 0 {
+		return nil
+	}
+
+	query := `UPDATE gift_cards
+	SET balance = balance + $2, updated_at = NOW()
+	WHERE id = $1`
+
+	if _, err := tx.Exec(query, *booking.GiftCardID, amount); err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	return insertGiftCardTransaction(tx, &GiftCardTransaction{
+		GiftCardID: *booking.GiftCardID,
+		BookingID:  &booking.ID,
+		Kind:       GiftCardRestore,
+		Amount:     amount,
+	})
+}
@@ -5,20 +5,24 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
 	"time"
 )
 
+// Hall is a screen of a theater. TurnaroundMinutes are kept free between two
+// of its shows for cleaning; nil leaves it to the theater.
 type Hall struct {
-	ID        int       `json:"id"`
-	TheaterID int       `json:"theater_id"`
-	ManagerID int       `json:"manager_id"`
-	Name      string    `json:"name"`
-	Code      string    `json:"code"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Schedule  *Schedule `json:"schedule"`
-	Seats     *Seating  `json:"seating"`
+	ID                int       `json:"id"`
+	TheaterID         int       `json:"theater_id"`
+	ManagerID         int       `json:"manager_id"`
+	Name              string    `json:"name"`
+	Code              string    `json:"code"`
+	TurnaroundMinutes *int      `json:"turnaround_minutes"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	Schedule          *Schedule `json:"schedule"`
+	Seats             *Seating  `json:"seating"`
 }
 
 type HallModel struct {
@@ -29,10 +33,38 @@ var (
 	ErrInvalidSchedule = errors.New("invalid schedule")
 )
 
+// Schedule is the shows of a hall from From to To. Turnaround is kept free
+// between two shows.
 type Schedule struct {
-	From  time.Time
-	To    time.Time
-	Shows []Show
+	From       time.Time
+	To         time.Time
+	Turnaround time.Duration `json:"-"`
+	Shows      []Show
+}
+
+// ScheduleConflictError is a show overlapping another show of the schedule,
+// or its turnaround. EarliestStart is the earliest the show could start
+// instead.
+type ScheduleConflictError struct {
+	Show          Show
+	Turnaround    time.Duration
+	EarliestStart time.Time
+}
+
+func (e *ScheduleConflictError) Error() string {
+	return fmt.Sprintf(
+		"%v: contradiction with screening of %v from %v to %v, %v turnaround included; earliest valid start is %v",
+		ErrInvalidSchedule,
+		e.Show.MovieTitle,
+		e.Show.StartTime,
+		e.Show.EndTime,
+		e.Turnaround,
+		e.EarliestStart,
+	)
+}
+
+func (e *ScheduleConflictError) Unwrap() error {
+	return ErrInvalidSchedule
 }
 
 func (s Schedule) IsFree(show Show) error {
@@ -41,32 +73,72 @@ func (s Schedule) IsFree(show Show) error {
 	}
 
 	for _, sh := range s.Shows {
-		if sh.StartTime.Before(show.EndTime) && show.StartTime.Before(sh.EndTime) {
-			return fmt.Errorf(
-				"%w: contradiction with screening of %v from %v to %v",
-				ErrInvalidSchedule,
-				sh.MovieTitle,
-				sh.StartTime,
-				sh.EndTime,
-			)
+		if s.overlaps(sh, show.StartTime, show.EndTime) {
+			return &ScheduleConflictError{
+				Show:          sh,
+				Turnaround:    s.Turnaround,
+				EarliestStart: s.EarliestStart(show),
+			}
 		}
 	}
 
 	return nil
 }
 
+// EarliestStart returns the earliest start, not before the show's own, at
+// which the show fits in the schedule. Only the show's start and the end of
+// the turnaround after each show can be the earliest; the latest of these
+// always fits.
+func (s Schedule) EarliestStart(show Show) time.Time {
+	length := show.EndTime.Sub(show.StartTime)
+
+	candidates := []time.Time{show.StartTime}
+	for _, sh := range s.Shows {
+		if free := sh.EndTime.Add(s.Turnaround); free.After(show.StartTime) {
+			candidates = append(candidates, free)
+		}
+	}
+	slices.SortFunc(candidates, time.Time.Compare)
+
+	for _, start := range candidates {
+		fits := !slices.ContainsFunc(s.Shows, func(sh Show) bool {
+			return s.overlaps(sh, start, start.Add(length))
+		})
+		if fits {
+			return start
+		}
+	}
+
+	return candidates[len(candidates)-1]
+}
+
+// overlaps reports whether a show, with its turnaround on either side,
+// overlaps the time from start to end.
+func (s Schedule) overlaps(show Show, start, end time.Time) bool {
+	return show.StartTime.Before(end.Add(s.Turnaround)) && start.Before(show.EndTime.Add(s.Turnaround))
+}
+
 func (m *HallModel) Create(hall *Hall) error {
-	query := `INSERT INTO halls(theater_id, name, code)
-	VALUES ($1, $2, $3)
-	RETURNING id, created_at, updated_at`
-	args := []any{hall.TheaterID, hall.Name, hall.Code}
+	tx, err := m.db.Begin()
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	query := `INSERT INTO halls(theater_id, name, code, turnaround_minutes)
+	VALUES ($1, $2, $3, $4)
+	RETURNING id, seats_version, created_at, updated_at`
+	args := []any{hall.TheaterID, hall.Name, hall.Code, hall.TurnaroundMinutes}
 
-	err := m.db.QueryRow(query, args...).Scan(
+	var version int
+	err = tx.QueryRow(query, args...).Scan(
 		&hall.ID,
+		&version,
 		&hall.CreatedAt,
 		&hall.UpdatedAt,
 	)
 	if err != nil {
+		tx.Rollback()
 		switch {
 		case strings.Contains(err.Error(), "halls_theater_id_code_key"):
 			return fmt.Errorf("%w: hall with code %v already exists", ErrDuplicate, hall.Code)
@@ -78,6 +150,33 @@ func (m *HallModel) Create(hall *Hall) error {
 		}
 	}
 
+	revision := &HallLayoutRevision{
+		HallID:      hall.ID,
+		Version:     version,
+		Note:        "initial layout",
+		PublishedBy: &hall.ManagerID,
+	}
+
+	if hall.Seats != nil {
+		if err := insertSeats(tx, hall.ID, version, hall.Seats.Seats); err != nil {
+			tx.Rollback()
+			return err
+		}
+		hall.Seats.Version = version
+		revision.Capacity = hall.Seats.Capacity()
+	}
+
+	if err := insertLayoutRevision(tx, revision); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
 	return nil
 }
 
@@ -91,15 +190,17 @@ func (m *HallModel) FindByCode(theaterID int, code string) (*Hall, error) {
 
 func (m *HallModel) FindByCodeWithSchedule(theaterID int, code string, from, to time.Time) (*Hall, error) {
 	query := `SELECT h.theater_id, h.name, h.id, h.manager_id,
+	h.turnaround_minutes, COALESCE(h.turnaround_minutes, t.turnaround_minutes),
 	h.created_at, h.updated_at, s.id, h.theater_id, h.id,
-	h.code, m.imdb_id, m.title, m.imdb_link, s.start_time,
+	h.code, m.imdb_id, m.title, s.start_time,
 	s.end_time, s.created_at, s.updated_at
 	FROM halls AS h
-	JOIN shows AS s on s.hall_id = h.id
 	JOIN theaters AS t on h.theater_id = t.id
-	JOIN movies AS m on s.movie_id = m.imdb_id
-	WHERE h.theater_id = $1 AND h.code = $2 AND s.end_time >= $3
-	AND s.start_time <= $4 AND h.deleted_at IS NULL AND t.deleted_at IS NULL`
+	LEFT JOIN shows AS s on s.hall_id = h.id AND s.end_time >= $3
+	AND s.start_time <= $4
+	LEFT JOIN movies AS m on s.movie_id = m.imdb_id
+	WHERE h.theater_id = $1 AND h.code = $2
+	AND h.deleted_at IS NULL AND t.deleted_at IS NULL`
 
 	args := []any{theaterID, code, from, to}
 
@@ -120,17 +221,16 @@ func (m *HallModel) FindByCodeWithSchedule(theaterID int, code string, from, to
 	}
 
 	type ShowDB struct {
-		ID            sql.NullInt32
-		TheaterID     sql.NullInt32
-		HallID        sql.NullInt32
-		HallCode      sql.NullString
-		MovieID       sql.NullString
-		MovieTitle    sql.NullString
-		MovieIMDBLink sql.NullString
-		StartTime     sql.NullTime
-		EndTime       sql.NullTime
-		CreatedAt     sql.NullTime
-		UpdatedAt     sql.NullTime
+		ID         sql.NullInt32
+		TheaterID  sql.NullInt32
+		HallID     sql.NullInt32
+		HallCode   sql.NullString
+		MovieID    sql.NullString
+		MovieTitle sql.NullString
+		StartTime  sql.NullTime
+		EndTime    sql.NullTime
+		CreatedAt  sql.NullTime
+		UpdatedAt  sql.NullTime
 	}
 
 	first := true
@@ -138,12 +238,16 @@ func (m *HallModel) FindByCodeWithSchedule(theaterID int, code string, from, to
 		first = false
 		var s ShowDB
 		var show Show
+		var hallTurnaround sql.NullInt32
+		var turnaround int
 
 		err := rows.Scan(
 			&hall.TheaterID,
 			&hall.Name,
 			&hall.ID,
 			&hall.ManagerID,
+			&hallTurnaround,
+			&turnaround,
 			&hall.CreatedAt,
 			&hall.UpdatedAt,
 			&s.ID,
@@ -152,7 +256,6 @@ func (m *HallModel) FindByCodeWithSchedule(theaterID int, code string, from, to
 			&s.HallCode,
 			&s.MovieID,
 			&s.MovieTitle,
-			&s.MovieIMDBLink,
 			&s.StartTime,
 			&s.EndTime,
 			&s.CreatedAt,
@@ -164,6 +267,12 @@ func (m *HallModel) FindByCodeWithSchedule(theaterID int, code string, from, to
 			return nil, err
 		}
 
+		if hallTurnaround.Valid {
+			minutes := int(hallTurnaround.Int32)
+			hall.TurnaroundMinutes = &minutes
+		}
+		hall.Schedule.Turnaround = time.Duration(turnaround) * time.Minute
+
 		if s.ID.Valid {
 			show.ID = int(s.ID.Int32)
 			show.TheaterID = int(s.TheaterID.Int32)
@@ -171,7 +280,6 @@ func (m *HallModel) FindByCodeWithSchedule(theaterID int, code string, from, to
 			show.HallCode = s.HallCode.String
 			show.MovieID = s.MovieID.String
 			show.MovieTitle = s.MovieTitle.String
-			show.MovieIMDBLink = s.MovieIMDBLink.String
 			show.StartTime = s.StartTime.Time
 			show.EndTime = s.EndTime.Time
 			show.CreatedAt = s.CreatedAt.Time
@@ -191,15 +299,16 @@ func (m *HallModel) FindByCodeWithSchedule(theaterID int, code string, from, to
 
 func (m *HallModel) FindWithSchedule(id int, from, to time.Time) (*Hall, error) {
 	query := `SELECT h.theater_id, h.name, h.code, h.manager_id,
+	h.turnaround_minutes, COALESCE(h.turnaround_minutes, t.turnaround_minutes),
 	h.created_at, h.updated_at, s.id, h.theater_id, h.id,
-	h.code, m.imdb_id, m.title, m.imdb_link, s.start_time,
+	h.code, m.imdb_id, m.title, s.start_time,
 	s.end_time, s.created_at, s.updated_at
 	FROM halls AS h
 	JOIN theaters AS t on t.id = h.theater_id
-	JOIN shows AS s on s.hall_id = h.id
-	JOIN movies AS m on s.movie_id = m.imdb_id
-	WHERE h.id = $1 AND s.end_time >= $2 AND s.start_time <= $3 
-	AND h.deleted_at IS NULL AND t.deleted_at IS NULL`
+	LEFT JOIN shows AS s on s.hall_id = h.id AND s.end_time >= $2
+	AND s.start_time <= $3
+	LEFT JOIN movies AS m on s.movie_id = m.imdb_id
+	WHERE h.id = $1 AND h.deleted_at IS NULL AND t.deleted_at IS NULL`
 
 	rows, err := m.db.Query(query, id, from, to)
 	if err != nil {
@@ -218,17 +327,16 @@ func (m *HallModel) FindWithSchedule(id int, from, to time.Time) (*Hall, error)
 	}
 
 	type ShowDB struct {
-		ID            sql.NullInt32
-		TheaterID     sql.NullInt32
-		HallID        sql.NullInt32
-		HallCode      sql.NullString
-		MovieID       sql.NullString
-		MovieTitle    sql.NullString
-		MovieIMDBLink sql.NullString
-		StartTime     sql.NullTime
-		EndTime       sql.NullTime
-		CreatedAt     sql.NullTime
-		UpdatedAt     sql.NullTime
+		ID         sql.NullInt32
+		TheaterID  sql.NullInt32
+		HallID     sql.NullInt32
+		HallCode   sql.NullString
+		MovieID    sql.NullString
+		MovieTitle sql.NullString
+		StartTime  sql.NullTime
+		EndTime    sql.NullTime
+		CreatedAt  sql.NullTime
+		UpdatedAt  sql.NullTime
 	}
 
 	first := true
@@ -236,12 +344,16 @@ func (m *HallModel) FindWithSchedule(id int, from, to time.Time) (*Hall, error)
 		first = false
 		var s ShowDB
 		var show Show
+		var hallTurnaround sql.NullInt32
+		var turnaround int
 
 		err := rows.Scan(
 			&hall.TheaterID,
 			&hall.Name,
 			&hall.Code,
 			&hall.ManagerID,
+			&hallTurnaround,
+			&turnaround,
 			&hall.CreatedAt,
 			&hall.UpdatedAt,
 			&s.ID,
@@ -250,7 +362,6 @@ func (m *HallModel) FindWithSchedule(id int, from, to time.Time) (*Hall, error)
 			&s.HallCode,
 			&s.MovieID,
 			&s.MovieTitle,
-			&s.MovieIMDBLink,
 			&s.StartTime,
 			&s.EndTime,
 			&s.CreatedAt,
@@ -262,6 +373,12 @@ func (m *HallModel) FindWithSchedule(id int, from, to time.Time) (*Hall, error)
 			return nil, err
 		}
 
+		if hallTurnaround.Valid {
+			minutes := int(hallTurnaround.Int32)
+			hall.TurnaroundMinutes = &minutes
+		}
+		hall.Schedule.Turnaround = time.Duration(turnaround) * time.Minute
+
 		if s.ID.Valid {
 			show.ID = int(s.ID.Int32)
 			show.TheaterID = int(s.TheaterID.Int32)
@@ -269,7 +386,6 @@ func (m *HallModel) FindWithSchedule(id int, from, to time.Time) (*Hall, error)
 			show.HallCode = s.HallCode.String
 			show.MovieID = s.MovieID.String
 			show.MovieTitle = s.MovieTitle.String
-			show.MovieIMDBLink = s.MovieIMDBLink.String
 			show.StartTime = s.StartTime.Time
 			show.EndTime = s.EndTime.Time
 			show.CreatedAt = s.CreatedAt.Time
@@ -289,10 +405,10 @@ func (m *HallModel) FindWithSchedule(id int, from, to time.Time) (*Hall, error)
 
 func (m *HallModel) Update(hall *Hall) error {
 	query := `UPDATE halls
-	SET name = $1, code = $2, updated_at = NOW()
-	WHERE id = $3 AND updated_at = $4 AND deleted_at IS NULL
+	SET name = $1, code = $2, turnaround_minutes = $3, updated_at = NOW()
+	WHERE id = $4 AND updated_at = $5 AND deleted_at IS NULL
 	RETURNING updated_at`
-	args := []any{hall.Name, hall.Code, hall.ID, hall.UpdatedAt}
+	args := []any{hall.Name, hall.Code, hall.TurnaroundMinutes, hall.ID, hall.UpdatedAt}
 
 	err := m.db.QueryRow(query, args...).Scan(&hall.UpdatedAt)
 	if err != nil {
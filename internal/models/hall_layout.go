@@ -0,0 +1,260 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// HallLayoutRevision is a published version of a hall's seating. Shows are
+// sold against the revision that was current when they were scheduled and
+// keep it when a newer one is published.
+type HallLayoutRevision struct {
+	HallID        int       `json:"hall_id"`
+	Version       int       `json:"version"`
+	Capacity      int       `json:"capacity"`
+	Note          string    `json:"note"`
+	PublishedBy   *int      `json:"published_by"`
+	Current       bool      `json:"current"`
+	UpcomingShows int       `json:"upcoming_shows"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// ShowLayoutUsage is an upcoming show of a hall along with the seats that are
+// held or sold for it.
+type ShowLayoutUsage struct {
+	ShowID        int
+	MovieTitle    string
+	StartTime     time.Time
+	LayoutVersion int
+	TakenSeats    []TakenSeat
+}
+
+// TakenSeat is a held or sold seat of a show. BookingID is zero for seats
+// that are only held.
+type TakenSeat struct {
+	Row        string
+	SeatNumber int
+	Category   string
+	Status     string
+	UserID     int
+	BookingID  int
+}
+
+type HallLayoutModel struct {
+	db *sql.DB
+}
+
+// FindByHallCode returns the layout revisions of a theater's hall, newest
+// first.
+func (m *HallLayoutModel) FindByHallCode(theaterID int, code string) ([]HallLayoutRevision, error) {
+	query := `SELECT l.hall_id, l.version, l.capacity, l.note, l.published_by,
+	l.version = h.seats_version,
+	(SELECT COUNT(*) FROM shows AS sh
+		WHERE sh.hall_id = h.id AND sh.layout_version = l.version
+		AND sh.start_time > NOW()),
+	l.created_at
+	FROM hall_layouts AS l
+	JOIN halls AS h ON h.id = l.hall_id
+	JOIN theaters AS t ON t.id = h.theater_id
+	WHERE h.theater_id = $1 AND h.code = $2
+	AND h.deleted_at IS NULL AND t.deleted_at IS NULL
+	ORDER BY l.version DESC`
+
+	rows, err := m.db.Query(query, theaterID, code)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []HallLayoutRevision{}
+	for rows.Next() {
+		var revision HallLayoutRevision
+		var publishedBy sql.NullInt32
+		err := rows.Scan(
+			&revision.HallID,
+			&revision.Version,
+			&revision.Capacity,
+			&revision.Note,
+			&publishedBy,
+			&revision.Current,
+			&revision.UpcomingShows,
+			&revision.CreatedAt,
+		)
+		if err != nil {
+			slog.Error("Scan Failure", "error", err)
+			return nil, err
+		}
+
+		if publishedBy.Valid {
+			id := int(publishedBy.Int32)
+			revision.PublishedBy = &id
+		}
+
+		revisions = append(revisions, revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Scan Failure", "error", err)
+		return nil, err
+	}
+
+	if len(revisions) == 0 {
+		return nil, ErrNotFound
+	}
+
+	return revisions, nil
+}
+
+// Publish makes the given seats the hall's current layout under the next
+// version number. Existing shows keep the seats they were created with; only
+// shows scheduled afterwards use the new layout.
+func (m *HallLayoutModel) Publish(revision *HallLayoutRevision, seats []Seat) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	// bumping the version locks the hall's row, so concurrent publishes get
+	// consecutive versions.
+	query := `UPDATE halls
+	SET seats_version = seats_version + 1, updated_at = NOW()
+	WHERE id = $1 AND deleted_at IS NULL
+	RETURNING seats_version`
+
+	err = tx.QueryRow(query, revision.HallID).Scan(&revision.Version)
+	if err != nil {
+		tx.Rollback()
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrNotFound
+		default:
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+	}
+
+	if err := insertSeats(tx, revision.HallID, revision.Version, seats); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	revision.Capacity = len(seats)
+	if err := insertLayoutRevision(tx, revision); err != nil {
+		tx.Rollback()
+		return err
+	}
+	revision.Current = true
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// FindUpgradeImpact returns the upcoming shows of a hall that run on a layout
+// other than the given version, with their held and sold seats. Seats of
+// cancelled bookings aren't reported.
+func (m *HallLayoutModel) FindUpgradeImpact(hallID, version int) ([]ShowLayoutUsage, error) {
+	query := `SELECT sh.id, m.title, sh.start_time, sh.layout_version,
+	ss.row, ss.seat_number, ss.category, ss.status, ss.user_id, tk.booking_id
+	FROM shows AS sh
+	JOIN movies AS m ON m.imdb_id = sh.movie_id
+	LEFT JOIN show_seats AS ss ON ss.show_id = sh.id AND ss.status <> 'available'
+	LEFT JOIN tickets AS tk ON tk.show_seat_id = ss.id AND EXISTS (
+		SELECT 1 FROM bookings AS b
+		WHERE b.id = tk.booking_id AND b.status IN ('pending', 'paid')
+	)
+	WHERE sh.hall_id = $1 AND sh.layout_version <> $2 AND sh.start_time > NOW()
+	ORDER BY sh.start_time, sh.id, LENGTH(ss.row), ss.row, ss.seat_number`
+
+	rows, err := m.db.Query(query, hallID, version)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	usages := []ShowLayoutUsage{}
+	for rows.Next() {
+		var usage ShowLayoutUsage
+		var (
+			row        sql.NullString
+			seatNumber sql.NullInt32
+			category   sql.NullString
+			status     sql.NullString
+			userID     sql.NullInt32
+			bookingID  sql.NullInt32
+		)
+
+		err := rows.Scan(
+			&usage.ShowID,
+			&usage.MovieTitle,
+			&usage.StartTime,
+			&usage.LayoutVersion,
+			&row,
+			&seatNumber,
+			&category,
+			&status,
+			&userID,
+			&bookingID,
+		)
+		if err != nil {
+			slog.Error("Scan Failure", "error", err)
+			return nil, err
+		}
+
+		if n := len(usages); n == 0 || usages[n-1].ShowID != usage.ShowID {
+			usage.TakenSeats = []TakenSeat{}
+			usages = append(usages, usage)
+		}
+
+		if row.Valid {
+			last := &usages[len(usages)-1]
+			last.TakenSeats = append(last.TakenSeats, TakenSeat{
+				Row:        row.String,
+				SeatNumber: int(seatNumber.Int32),
+				Category:   category.String,
+				Status:     status.String,
+				UserID:     int(userID.Int32),
+				BookingID:  int(bookingID.Int32),
+			})
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Scan Failure", "error", err)
+		return nil, err
+	}
+
+	return usages, nil
+}
+
+// insertLayoutRevision records a published layout as part of an ongoing
+// transaction.
+func insertLayoutRevision(tx *sql.Tx, revision *HallLayoutRevision) error {
+	query := `INSERT INTO hall_layouts(hall_id, version, capacity, note, published_by)
+	VALUES ($1, $2, $3, $4, $5)
+	RETURNING created_at`
+
+	args := []any{
+		revision.HallID,
+		revision.Version,
+		revision.Capacity,
+		revision.Note,
+		revision.PublishedBy,
+	}
+
+	if err := tx.QueryRow(query, args...).Scan(&revision.CreatedAt); err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,316 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrHoldNotActive = errors.New("hold is not active")
+)
+
+const (
+	HoldActive    = "active"
+	HoldReleased  = "released"
+	HoldExpired   = "expired"
+	HoldConverted = "converted"
+)
+
+// Hold keeps a set of show seats reserved for a user until it expires, is
+// released, or is converted into a booking.
+type Hold struct {
+	ID        int        `json:"id"`
+	ShowID    int        `json:"show_id"`
+	UserID    int        `json:"user_id"`
+	Status    string     `json:"status"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	Seats     []ShowSeat `json:"seats"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// IsActive reports whether the hold still keeps its seats at the given time.
+func (h Hold) IsActive(now time.Time) bool {
+	return h.Status == HoldActive && now.Before(h.ExpiresAt)
+}
+
+type HoldModel struct {
+	db *sql.DB
+}
+
+// Create places a hold on the given seats. Either all the seats are held or
+// none of them; a seat that is no longer available makes the whole hold fail
+// with ErrSeatUnavailable.
+func (m *HoldModel) Create(hold *Hold, seatIDs []int) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	if err := createHold(tx, hold, seatIDs); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (m *HoldModel) Find(id int) (*Hold, error) {
+	query := `SELECT show_id, user_id, status, expires_at, created_at, updated_at
+	FROM seat_holds
+	WHERE id = $1`
+
+	hold := &Hold{ID: id}
+
+	err := m.db.QueryRow(query, id).Scan(
+		&hold.ShowID,
+		&hold.UserID,
+		&hold.Status,
+		&hold.ExpiresAt,
+		&hold.CreatedAt,
+		&hold.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNotFound
+		default:
+			slog.Error("SQL Database Failure", "error", err)
+			return nil, err
+		}
+	}
+
+	query = `SELECT id, show_id, seat_id, row, seat_number, grid_column, category,
+	status, user_id, hold_id, created_at, updated_at
+	FROM show_seats
+	WHERE hold_id = $1
+	ORDER BY LENGTH(row), row, seat_number`
+
+	rows, err := m.db.Query(query, id)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	hold.Seats, err = scanShowSeats(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+// Extend moves the expiry of an active hold to hold.ExpiresAt.
+func (m *HoldModel) Extend(hold *Hold) error {
+	query := `UPDATE seat_holds
+	SET expires_at = $2, updated_at = NOW()
+	WHERE id = $1 AND status = 'active' AND expires_at > NOW()
+	RETURNING updated_at`
+
+	err := m.db.QueryRow(query, hold.ID, hold.ExpiresAt).Scan(&hold.UpdatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrHoldNotActive
+		default:
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Release ends an active hold and returns its seats to the show's inventory.
+func (m *HoldModel) Release(id int) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	query := `UPDATE seat_holds
+	SET status = 'released', updated_at = NOW()
+	WHERE id = $1 AND status = 'active'`
+
+	result, err := tx.Exec(query, id)
+	if err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	if rows, err := result.RowsAffected(); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	} else if rows == 0 {
+		tx.Rollback()
+		return ErrHoldNotActive
+	}
+
+	if err := releaseHeldSeats(tx, []int{id}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := lapseWaitlistOffers(tx, []int{id}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// ReleaseExpired expires every active hold whose time is up, returns their
+// seats to the inventory and reports the holds that were expired.
+func (m *HoldModel) ReleaseExpired() ([]Hold, error) {
+	tx, err := m.db.Begin()
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+
+	query := `UPDATE seat_holds
+	SET status = 'expired', updated_at = NOW()
+	WHERE status = 'active' AND expires_at <= NOW()
+	RETURNING id, show_id, user_id, status, expires_at, created_at, updated_at`
+
+	rows, err := tx.Query(query)
+	if err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+
+	holds := []Hold{}
+	ids := []int{}
+	for rows.Next() {
+		var hold Hold
+		err := rows.Scan(
+			&hold.ID,
+			&hold.ShowID,
+			&hold.UserID,
+			&hold.Status,
+			&hold.ExpiresAt,
+			&hold.CreatedAt,
+			&hold.UpdatedAt,
+		)
+		if err != nil {
+			rows.Close()
+			tx.Rollback()
+			slog.Error("Scan Failure", "error", err)
+			return nil, err
+		}
+
+		holds = append(holds, hold)
+		ids = append(ids, hold.ID)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		tx.Rollback()
+		slog.Error("Scan Failure", "error", err)
+		return nil, err
+	}
+
+	if err := releaseHeldSeats(tx, ids); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := lapseWaitlistOffers(tx, ids); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+
+	return holds, nil
+}
+
+// createHold inserts the hold and takes its seats as part of an ongoing
+// transaction.
+func createHold(tx *sql.Tx, hold *Hold, seatIDs []int) error {
+	query := `INSERT INTO seat_holds(show_id, user_id, expires_at)
+	VALUES ($1, $2, $3)
+	RETURNING id, status, created_at, updated_at`
+
+	err := tx.QueryRow(query, hold.ShowID, hold.UserID, hold.ExpiresAt).Scan(
+		&hold.ID,
+		&hold.Status,
+		&hold.CreatedAt,
+		&hold.UpdatedAt,
+	)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	// The status condition is re-evaluated after any concurrent update on the
+	// same rows commits, so two requests can never both take a seat.
+	query = `UPDATE show_seats
+	SET status = 'held', user_id = $3, hold_id = $4, updated_at = NOW()
+	WHERE show_id = $1 AND id = ANY($2) AND status = 'available'
+	RETURNING id, show_id, seat_id, row, seat_number, grid_column, category,
+	status, user_id, hold_id, created_at, updated_at`
+
+	rows, err := tx.Query(query, hold.ShowID, pq.Array(seatIDs), hold.UserID, hold.ID)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	seats, err := scanShowSeats(rows)
+	rows.Close()
+	if err != nil {
+		return err
+	}
+
+	if len(seats) != len(seatIDs) {
+		return ErrSeatUnavailable
+	}
+
+	hold.Seats = seats
+
+	return nil
+}
+
+func releaseHeldSeats(tx *sql.Tx, holdIDs []int) error {
+	if len(holdIDs) == 0 {
+		return nil
+	}
+
+	query := `UPDATE show_seats
+	SET status = 'available', user_id = NULL, hold_id = NULL, updated_at = NOW()
+	WHERE hold_id = ANY($1) AND status = 'held'`
+
+	if _, err := tx.Exec(query, pq.Array(holdIDs)); err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	return nil
+}
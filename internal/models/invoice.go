@@ -0,0 +1,132 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Invoice numbers a paid booking. Invoices are numbered sequentially per
+// theater, without gaps, in the order the bookings were paid.
+type Invoice struct {
+	ID        int       `json:"id"`
+	BookingID int       `json:"booking_id"`
+	TheaterID int       `json:"theater_id"`
+	Number    int       `json:"number"`
+	IssuedAt  time.Time `json:"issued_at"`
+}
+
+// Code is the invoice number as printed on the invoice.
+func (i Invoice) Code() string {
+	return fmt.Sprintf("INV-%d-%06d", i.TheaterID, i.Number)
+}
+
+type InvoiceModel struct {
+	db *sql.DB
+}
+
+func (m *InvoiceModel) FindByBooking(bookingID int) (*Invoice, error) {
+	query := `SELECT id, booking_id, theater_id, number, issued_at
+	FROM invoices
+	WHERE booking_id = $1`
+
+	var invoice Invoice
+	err := m.db.QueryRow(query, bookingID).Scan(
+		&invoice.ID,
+		&invoice.BookingID,
+		&invoice.TheaterID,
+		&invoice.Number,
+		&invoice.IssuedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNotFound
+		default:
+			slog.Error("SQL Database Failure", "error", err)
+			return nil, err
+		}
+	}
+
+	return &invoice, nil
+}
+
+// Issue numbers a paid booking that has no invoice yet, such as the bookings
+// paid before invoices were introduced. Bookings with an invoice keep it.
+func (m *InvoiceModel) Issue(bookingID int) (*Invoice, error) {
+	tx, err := m.db.Begin()
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+
+	invoice, err := issueInvoice(tx, bookingID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+
+	return invoice, nil
+}
+
+// issueInvoice numbers a paid booking as part of an ongoing transaction. The
+// booking's row and its theater's sequence stay locked until the
+// transaction ends, so a booking gets a single number and no number is used
+// twice or skipped.
+func issueInvoice(tx *sql.Tx, bookingID int) (*Invoice, error) {
+	query := `SELECT h.theater_id
+	FROM bookings AS b
+	JOIN shows AS s ON s.id = b.show_id
+	JOIN halls AS h ON h.id = s.hall_id
+	WHERE b.id = $1 AND b.status = 'paid'
+	FOR UPDATE OF b`
+
+	invoice := &Invoice{BookingID: bookingID}
+	if err := tx.QueryRow(query, bookingID).Scan(&invoice.TheaterID); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNotFound
+		default:
+			slog.Error("SQL Database Failure", "error", err)
+			return nil, err
+		}
+	}
+
+	query = `SELECT id, number, issued_at FROM invoices WHERE booking_id = $1`
+	err := tx.QueryRow(query, bookingID).Scan(&invoice.ID, &invoice.Number, &invoice.IssuedAt)
+	if err == nil {
+		return invoice, nil
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+
+	query = `INSERT INTO invoice_sequences(theater_id, last_number)
+	VALUES ($1, 1)
+	ON CONFLICT (theater_id)
+	DO UPDATE SET last_number = invoice_sequences.last_number + 1
+	RETURNING last_number`
+	if err := tx.QueryRow(query, invoice.TheaterID).Scan(&invoice.Number); err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+
+	query = `INSERT INTO invoices(booking_id, theater_id, number)
+	VALUES ($1, $2, $3)
+	RETURNING id, issued_at`
+	err = tx.QueryRow(query, bookingID, invoice.TheaterID, invoice.Number).Scan(&invoice.ID, &invoice.IssuedAt)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+
+	return invoice, nil
+}
@@ -0,0 +1,326 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+var (
+	ErrInsufficientPoints = errors.New("insufficient loyalty points")
+)
+
+const (
+	LoyaltyBasic  = "basic"
+	LoyaltySilver = "silver"
+	LoyaltyGold   = "gold"
+)
+
+// LoyaltyTiers lists the tiers above basic, lowest first.
+var LoyaltyTiers = []string{
+	LoyaltySilver,
+	LoyaltyGold,
+}
+
+const (
+	PointsEarn     = "earn"
+	PointsRedeem   = "redeem"
+	PointsRestore  = "restore"
+	PointsReversal = "reversal"
+)
+
+// LoyaltyRules are the earn and burn rules of the loyalty program. Customers
+// earn PointsPerTicket for every ticket they pay for; a point spent at
+// checkout is worth PointValue in the currency's minor unit, and a free
+// ticket costs FreeTicketPoints.
+type LoyaltyRules struct {
+	PointsPerTicket  int           `json:"points_per_ticket"`
+	PointValue       int64         `json:"point_value"`
+	FreeTicketPoints int           `json:"free_ticket_points"`
+	Tiers            []LoyaltyTier `json:"tiers"`
+	UpdatedAt        time.Time     `json:"updated_at"`
+}
+
+// LoyaltyTier is reached by earning MinPoints within a year. Its members earn
+// EarnBonus percent more points and get Discount percent off their bookings.
+type LoyaltyTier struct {
+	Name      string `json:"name"`
+	MinPoints int    `json:"min_points"`
+	EarnBonus int    `json:"earn_bonus"`
+	Discount  int    `json:"discount"`
+}
+
+// LoyaltyTransaction is an entry of a user's points ledger. Earned and
+// restored points are positive, redeemed and reversed points negative.
+type LoyaltyTransaction struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"user_id"`
+	BookingID *int      `json:"booking_id,omitempty"`
+	Kind      string    `json:"kind"`
+	Points    int       `json:"points"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type LoyaltyModel struct {
+	db *sql.DB
+}
+
+// FindRules returns the loyalty rules with their tiers, lowest tier first.
+func (m *LoyaltyModel) FindRules() (*LoyaltyRules, error) {
+	query := `SELECT points_per_ticket, point_value, free_ticket_points, updated_at
+	FROM loyalty_rules
+	WHERE id = 1`
+
+	var rules LoyaltyRules
+	err := m.db.QueryRow(query).Scan(
+		&rules.PointsPerTicket,
+		&rules.PointValue,
+		&rules.FreeTicketPoints,
+		&rules.UpdatedAt,
+	)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+
+	query = `SELECT name, min_points, earn_bonus, discount
+	FROM loyalty_tiers
+	ORDER BY min_points`
+
+	rows, err := m.db.Query(query)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules.Tiers = []LoyaltyTier{}
+	for rows.Next() {
+		var tier LoyaltyTier
+		err := rows.Scan(
+			&tier.Name,
+			&tier.MinPoints,
+			&tier.EarnBonus,
+			&tier.Discount,
+		)
+		if err != nil {
+			slog.Error("Scan Failure", "error", err)
+			return nil, err
+		}
+
+		rules.Tiers = append(rules.Tiers, tier)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Scan Failure", "error", err)
+		return nil, err
+	}
+
+	return &rules, nil
+}
+
+// ReplaceRules swaps the loyalty rules and tiers for the given ones. Points
+// already earned or redeemed are left as they are.
+func (m *LoyaltyModel) ReplaceRules(rules *LoyaltyRules) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	query := `UPDATE loyalty_rules
+	SET points_per_ticket = $1, point_value = $2, free_ticket_points = $3,
+	updated_at = NOW()
+	WHERE id = 1
+	RETURNING updated_at`
+	args := []any{rules.PointsPerTicket, rules.PointValue, rules.FreeTicketPoints}
+
+	if err := tx.QueryRow(query, args...).Scan(&rules.UpdatedAt); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM loyalty_tiers`); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	query = `INSERT INTO loyalty_tiers(name, min_points, earn_bonus, discount)
+	VALUES ($1, $2, $3, $4)`
+
+	for _, tier := range rules.Tiers {
+		args := []any{tier.Name, tier.MinPoints, tier.EarnBonus, tier.Discount}
+		if _, err := tx.Exec(query, args...); err != nil {
+			tx.Rollback()
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// Balance returns the points a user may spend.
+func (m *LoyaltyModel) Balance(userID int) (int, error) {
+	query := `SELECT COALESCE(SUM(points), 0)
+	FROM loyalty_transactions
+	WHERE user_id = $1`
+
+	var balance int
+	if err := m.db.QueryRow(query, userID).Scan(&balance); err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return 0, err
+	}
+
+	return balance, nil
+}
+
+// TierPoints returns the points a user earned since the given time, less
+// those reversed by cancellations.
+func (m *LoyaltyModel) TierPoints(userID int, since time.Time) (int, error) {
+	query := `SELECT COALESCE(SUM(points), 0)
+	FROM loyalty_transactions
+	WHERE user_id = $1 AND kind IN ('earn', 'reversal') AND created_at >= $2`
+
+	var points int
+	if err := m.db.QueryRow(query, userID, since).Scan(&points); err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return 0, err
+	}
+
+	return max(points, 0), nil
+}
+
+// FindTransactions returns the points ledger of a user, newest first.
+func (m *LoyaltyModel) FindTransactions(userID int) ([]LoyaltyTransaction, error) {
+	query := `SELECT id, user_id, booking_id, kind, points, created_at
+	FROM loyalty_transactions
+	WHERE user_id = $1
+	ORDER BY created_at DESC, id DESC`
+
+	rows, err := m.db.Query(query, userID)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	transactions := []LoyaltyTransaction{}
+	for rows.Next() {
+		var t LoyaltyTransaction
+		err := rows.Scan(
+			&t.ID,
+			&t.UserID,
+			&t.BookingID,
+			&t.Kind,
+			&t.Points,
+			&t.CreatedAt,
+		)
+		if err != nil {
+			slog.Error("Scan Failure", "error", err)
+			return nil, err
+		}
+
+		transactions = append(transactions, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Scan Failure", "error", err)
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+func insertLoyaltyTransaction(tx *sql.Tx, userID, bookingID int, kind string, points int) error {
+	query := `INSERT INTO loyalty_transactions(user_id, booking_id, kind, points)
+	VALUES ($1, $2, $3, $4)`
+
+	if _, err := tx.Exec(query, userID, bookingID, kind, points); err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// redeemLoyaltyPoints spends the points redeemed on a booking as part of an
+// ongoing transaction. The user's row stays locked until the transaction
+// ends, so concurrent checkouts can't spend the same points twice.
+func redeemLoyaltyPoints(tx *sql.Tx, booking *Booking) error {
+	query := `SELECT id FROM users WHERE id = $1 FOR UPDATE`
+	if _, err := tx.Exec(query, booking.UserID); err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	query = `SELECT COALESCE(SUM(points), 0)
+	FROM loyalty_transactions
+	WHERE user_id = $1`
+
+	var balance int
+	if err := tx.QueryRow(query, booking.UserID).Scan(&balance); err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	if balance < booking.PointsRedeemed {
+		return fmt.Errorf("%w: %v points left", ErrInsufficientPoints, balance)
+	}
+
+	return insertLoyaltyTransaction(tx, booking.UserID, booking.ID, PointsRedeem, -booking.PointsRedeemed)
+}
+
+// restoreLoyaltyPoints gives back the points redeemed on an abandoned or
+// cancelled booking, as part of an ongoing transaction.
+func restoreLoyaltyPoints(tx *sql.Tx, booking *Booking) error {
+	if booking.PointsRedeemed == 0 {
+		return nil
+	}
+
+	return insertLoyaltyTransaction(tx, booking.UserID, booking.ID, PointsRestore, booking.PointsRedeemed)
+}
+
+// earnLoyaltyPoints credits the points earned by a paid booking, as part of
+// an ongoing transaction.
+func earnLoyaltyPoints(tx *sql.Tx, booking *Booking) error {
+	if booking.PointsEarned == 0 {
+		return nil
+	}
+
+	return insertLoyaltyTransaction(tx, booking.UserID, booking.ID, PointsEarn, booking.PointsEarned)
+}
+
+// reverseLoyaltyPoints takes back the points earned by a cancelled booking as
+// part of an ongoing transaction, short of what the user already spent.
+func reverseLoyaltyPoints(tx *sql.Tx, booking *Booking) error {
+	if booking.PointsEarned == 0 {
+		return nil
+	}
+
+	query := `INSERT INTO loyalty_transactions(user_id, booking_id, kind, points)
+	SELECT $1, $2, 'reversal', -LEAST($3, balance)
+	FROM (
+		SELECT COALESCE(SUM(points), 0) AS balance
+		FROM loyalty_transactions
+		WHERE user_id = $1
+	) AS b
+	WHERE balance > 0`
+
+	if _, err := tx.Exec(query, booking.UserID, booking.ID, booking.PointsEarned); err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	return nil
+}
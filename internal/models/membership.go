@@ -0,0 +1,550 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMembershipInactive  = errors.New("membership inactive")
+	ErrMembershipExhausted = errors.New("membership entitlement used up")
+)
+
+const (
+	PlanTickets   = "tickets"
+	PlanUnlimited = "unlimited"
+)
+
+const (
+	SubscriptionPending   = "pending"
+	SubscriptionActive    = "active"
+	SubscriptionPastDue   = "past_due"
+	SubscriptionCancelled = "cancelled"
+)
+
+// MembershipPlan is a paid membership renewed every PeriodDays. Members of a
+// tickets plan get TicketsPerPeriod free tickets per period; members of an
+// unlimited plan get a free ticket on one booking at a time.
+type MembershipPlan struct {
+	ID               int       `json:"id"`
+	Name             string    `json:"name"`
+	Kind             string    `json:"kind"`
+	TicketsPerPeriod *int      `json:"tickets_per_period,omitempty"`
+	Price            int64     `json:"price"`
+	Currency         string    `json:"currency"`
+	PeriodDays       int       `json:"period_days"`
+	Active           bool      `json:"active"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+func (p MembershipPlan) Period() time.Duration {
+	return time.Duration(p.PeriodDays) * 24 * time.Hour
+}
+
+// Subscription is a user's membership of a plan. The payment token is kept to
+// charge the renewals.
+type Subscription struct {
+	ID                 int            `json:"id"`
+	UserID             int            `json:"user_id"`
+	PlanID             int            `json:"plan_id"`
+	Plan               MembershipPlan `json:"plan"`
+	Status             string         `json:"status"`
+	PaymentToken       string         `json:"-"`
+	CurrentPeriodStart time.Time      `json:"current_period_start"`
+	CurrentPeriodEnd   time.Time      `json:"current_period_end"`
+	TicketsUsed        int            `json:"tickets_used"`
+	CancelAtPeriodEnd  bool           `json:"cancel_at_period_end"`
+	FailedPayments     int            `json:"failed_payments"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+}
+
+// IsActive reports whether the subscription's entitlements may be used.
+func (s Subscription) IsActive(now time.Time) bool {
+	return s.Status == SubscriptionActive && now.Before(s.CurrentPeriodEnd)
+}
+
+// SubscriptionPayment is a charge, or a failed attempt at one, for a billing
+// period of a subscription.
+type SubscriptionPayment struct {
+	ID             int       `json:"id"`
+	SubscriptionID int       `json:"subscription_id"`
+	PeriodStart    time.Time `json:"period_start"`
+	PeriodEnd      time.Time `json:"period_end"`
+	Amount         int64     `json:"amount"`
+	Status         string    `json:"status"`
+	PaymentRef     string    `json:"payment_reference,omitempty"`
+	FailureReason  string    `json:"failure_reason,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type MembershipPlanModel struct {
+	db *sql.DB
+}
+
+func (m *MembershipPlanModel) Create(plan *MembershipPlan) error {
+	query := `INSERT INTO membership_plans(name, kind, tickets_per_period, price,
+	currency, period_days, active)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	RETURNING id, created_at, updated_at`
+	args := []any{plan.Name, plan.Kind, plan.TicketsPerPeriod, plan.Price, plan.Currency, plan.PeriodDays, plan.Active}
+
+	err := m.db.QueryRow(query, args...).Scan(&plan.ID, &plan.CreatedAt, &plan.UpdatedAt)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "membership_plans_name_key"):
+			return fmt.Errorf("%w: plan %v already exists", ErrDuplicate, plan.Name)
+		default:
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *MembershipPlanModel) Find(id int) (*MembershipPlan, error) {
+	query := `SELECT id, name, kind, tickets_per_period, price, currency, period_days,
+	active, created_at, updated_at
+	FROM membership_plans
+	WHERE id = $1`
+
+	var plan MembershipPlan
+	err := m.db.QueryRow(query, id).Scan(
+		&plan.ID,
+		&plan.Name,
+		&plan.Kind,
+		&plan.TicketsPerPeriod,
+		&plan.Price,
+		&plan.Currency,
+		&plan.PeriodDays,
+		&plan.Active,
+		&plan.CreatedAt,
+		&plan.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNotFound
+		default:
+			slog.Error("SQL Database Failure", "error", err)
+			return nil, err
+		}
+	}
+
+	return &plan, nil
+}
+
+// FindAll lists the membership plans, cheapest first. Plans no longer sold
+// are left out unless inactive is set.
+func (m *MembershipPlanModel) FindAll(inactive bool) ([]MembershipPlan, error) {
+	query := `SELECT id, name, kind, tickets_per_period, price, currency, period_days,
+	active, created_at, updated_at
+	FROM membership_plans
+	WHERE active OR $1
+	ORDER BY price, id`
+
+	rows, err := m.db.Query(query, inactive)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	plans := []MembershipPlan{}
+	for rows.Next() {
+		var plan MembershipPlan
+		err := rows.Scan(
+			&plan.ID,
+			&plan.Name,
+			&plan.Kind,
+			&plan.TicketsPerPeriod,
+			&plan.Price,
+			&plan.Currency,
+			&plan.PeriodDays,
+			&plan.Active,
+			&plan.CreatedAt,
+			&plan.UpdatedAt,
+		)
+		if err != nil {
+			slog.Error("Scan Failure", "error", err)
+			return nil, err
+		}
+
+		plans = append(plans, plan)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Scan Failure", "error", err)
+		return nil, err
+	}
+
+	return plans, nil
+}
+
+// Update saves the name, price and availability of a plan. Its entitlements
+// can't change once members subscribed to it; new prices apply from the next
+// renewal.
+func (m *MembershipPlanModel) Update(plan *MembershipPlan) error {
+	query := `UPDATE membership_plans
+	SET name = $1, price = $2, active = $3, updated_at = NOW()
+	WHERE id = $4 AND updated_at = $5
+	RETURNING updated_at`
+	args := []any{plan.Name, plan.Price, plan.Active, plan.ID, plan.UpdatedAt}
+
+	err := m.db.QueryRow(query, args...).Scan(&plan.UpdatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		case strings.Contains(err.Error(), "membership_plans_name_key"):
+			return fmt.Errorf("%w: plan %v already exists", ErrDuplicate, plan.Name)
+		default:
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+type SubscriptionModel struct {
+	db *sql.DB
+}
+
+const subscriptionColumns = `s.id, s.user_id, s.plan_id, s.status, s.payment_token,
+	s.current_period_start, s.current_period_end, s.tickets_used,
+	s.cancel_at_period_end, s.failed_payments, s.created_at, s.updated_at,
+	p.id, p.name, p.kind, p.tickets_per_period, p.price, p.currency, p.period_days, p.active,
+	p.created_at, p.updated_at`
+
+// Create stores a new subscription. Its first period is recorded with Update
+// once it's paid for.
+func (m *SubscriptionModel) Create(sub *Subscription) error {
+	query := `INSERT INTO subscriptions(user_id, plan_id, status, payment_token,
+	current_period_start, current_period_end)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	RETURNING id, created_at, updated_at`
+	args := []any{
+		sub.UserID,
+		sub.PlanID,
+		sub.Status,
+		sub.PaymentToken,
+		sub.CurrentPeriodStart,
+		sub.CurrentPeriodEnd,
+	}
+
+	err := m.db.QueryRow(query, args...).Scan(&sub.ID, &sub.CreatedAt, &sub.UpdatedAt)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "subscriptions_user_id_current_idx"):
+			return fmt.Errorf("%w: user already has a membership", ErrDuplicate)
+		default:
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// FindCurrent returns the subscription of a user that isn't cancelled.
+func (m *SubscriptionModel) FindCurrent(userID int) (*Subscription, error) {
+	query := `SELECT ` + subscriptionColumns + `
+	FROM subscriptions AS s
+	JOIN membership_plans AS p ON p.id = s.plan_id
+	WHERE s.user_id = $1 AND s.status <> 'cancelled'`
+
+	return scanSubscription(m.db.QueryRow(query, userID))
+}
+
+// FindDue lists the subscriptions whose billing period ended by the given
+// time. Pending subscriptions are left to their first payment.
+func (m *SubscriptionModel) FindDue(now time.Time) ([]Subscription, error) {
+	query := `SELECT ` + subscriptionColumns + `
+	FROM subscriptions AS s
+	JOIN membership_plans AS p ON p.id = s.plan_id
+	WHERE s.status IN ('active', 'past_due') AND s.current_period_end <= $1
+	ORDER BY s.current_period_end`
+
+	rows, err := m.db.Query(query, now)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	subs := []Subscription{}
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		subs = append(subs, *sub)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Scan Failure", "error", err)
+		return nil, err
+	}
+
+	return subs, nil
+}
+
+// FindPayments returns the billing history of a subscription, newest first.
+func (m *SubscriptionModel) FindPayments(subscriptionID int) ([]SubscriptionPayment, error) {
+	query := `SELECT id, subscription_id, period_start, period_end, amount, status,
+	COALESCE(payment_reference, ''), COALESCE(failure_reason, ''), created_at
+	FROM subscription_payments
+	WHERE subscription_id = $1
+	ORDER BY created_at DESC, id DESC`
+
+	rows, err := m.db.Query(query, subscriptionID)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := []SubscriptionPayment{}
+	for rows.Next() {
+		var p SubscriptionPayment
+		err := rows.Scan(
+			&p.ID,
+			&p.SubscriptionID,
+			&p.PeriodStart,
+			&p.PeriodEnd,
+			&p.Amount,
+			&p.Status,
+			&p.PaymentRef,
+			&p.FailureReason,
+			&p.CreatedAt,
+		)
+		if err != nil {
+			slog.Error("Scan Failure", "error", err)
+			return nil, err
+		}
+
+		payments = append(payments, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Scan Failure", "error", err)
+		return nil, err
+	}
+
+	return payments, nil
+}
+
+// Update saves the status, billing period and renewal settings of a
+// subscription, along with the payment of the period if given. The update
+// only succeeds if nobody changed the subscription meanwhile.
+func (m *SubscriptionModel) Update(sub *Subscription, payment *SubscriptionPayment) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	query := `UPDATE subscriptions
+	SET status = $1, current_period_start = $2, current_period_end = $3,
+	tickets_used = $4, cancel_at_period_end = $5, failed_payments = $6,
+	updated_at = NOW()
+	WHERE id = $7 AND updated_at = $8
+	RETURNING updated_at`
+	args := []any{
+		sub.Status,
+		sub.CurrentPeriodStart,
+		sub.CurrentPeriodEnd,
+		sub.TicketsUsed,
+		sub.CancelAtPeriodEnd,
+		sub.FailedPayments,
+		sub.ID,
+		sub.UpdatedAt,
+	}
+
+	err = tx.QueryRow(query, args...).Scan(&sub.UpdatedAt)
+	if err != nil {
+		tx.Rollback()
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+	}
+
+	if payment != nil {
+		payment.SubscriptionID = sub.ID
+		if err := insertSubscriptionPayment(tx, payment); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func scanSubscription(row rowScanner) (*Subscription, error) {
+	var sub Subscription
+	err := row.Scan(
+		&sub.ID,
+		&sub.UserID,
+		&sub.PlanID,
+		&sub.Status,
+		&sub.PaymentToken,
+		&sub.CurrentPeriodStart,
+		&sub.CurrentPeriodEnd,
+		&sub.TicketsUsed,
+		&sub.CancelAtPeriodEnd,
+		&sub.FailedPayments,
+		&sub.CreatedAt,
+		&sub.UpdatedAt,
+		&sub.Plan.ID,
+		&sub.Plan.Name,
+		&sub.Plan.Kind,
+		&sub.Plan.TicketsPerPeriod,
+		&sub.Plan.Price,
+		&sub.Plan.Currency,
+		&sub.Plan.PeriodDays,
+		&sub.Plan.Active,
+		&sub.Plan.CreatedAt,
+		&sub.Plan.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNotFound
+		default:
+			slog.Error("Scan Failure", "error", err)
+			return nil, err
+		}
+	}
+
+	return &sub, nil
+}
+
+func insertSubscriptionPayment(tx *sql.Tx, p *SubscriptionPayment) error {
+	query := `INSERT INTO subscription_payments(subscription_id, period_start,
+	period_end, amount, status, payment_reference, failure_reason)
+	VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''))
+	RETURNING id, created_at`
+	args := []any{
+		p.SubscriptionID,
+		p.PeriodStart,
+		p.PeriodEnd,
+		p.Amount,
+		p.Status,
+		p.PaymentRef,
+		p.FailureReason,
+	}
+
+	if err := tx.QueryRow(query, args...).Scan(&p.ID, &p.CreatedAt); err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// useMembership takes the tickets a booking's membership covers off the
+// subscription's entitlements as part of an ongoing transaction. The
+// subscription's row stays locked until the transaction ends, so concurrent
+// checkouts can't use the same entitlement twice.
+func useMembership(tx *sql.Tx, booking *Booking) error {
+	query := `SELECT s.status, s.current_period_end, s.tickets_used, p.kind,
+	p.tickets_per_period
+	FROM subscriptions AS s
+	JOIN membership_plans AS p ON p.id = s.plan_id
+	WHERE s.id = $1
+	FOR UPDATE OF s`
+
+	var sub Subscription
+	err := tx.QueryRow(query, *booking.SubscriptionID).Scan(
+		&sub.Status,
+		&sub.CurrentPeriodEnd,
+		&sub.TicketsUsed,
+		&sub.Plan.Kind,
+		&sub.Plan.TicketsPerPeriod,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrMembershipInactive
+		default:
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+	}
+
+	if !sub.IsActive(time.Now()) {
+		return ErrMembershipInactive
+	}
+
+	switch sub.Plan.Kind {
+	case PlanTickets:
+		if sub.TicketsUsed+booking.MembershipTickets > *sub.Plan.TicketsPerPeriod {
+			return fmt.Errorf("%w: %v of %v tickets used", ErrMembershipExhausted, sub.TicketsUsed, *sub.Plan.TicketsPerPeriod)
+		}
+	case PlanUnlimited:
+		query = `SELECT COUNT(*)
+		FROM bookings AS b
+		JOIN shows AS sh ON sh.id = b.show_id
+		WHERE b.subscription_id = $1 AND b.id <> $2
+		AND b.status IN ('pending', 'paid') AND sh.end_time > NOW()`
+
+		var open int
+		if err := tx.QueryRow(query, *booking.SubscriptionID, booking.ID).Scan(&open); err != nil {
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+
+		if open > 0 {
+			return fmt.Errorf("%w: another booking is still active", ErrMembershipExhausted)
+		}
+	}
+
+	query = `UPDATE subscriptions
+	SET tickets_used = tickets_used + $2, updated_at = NOW()
+	WHERE id = $1`
+	if _, err := tx.Exec(query, *booking.SubscriptionID, booking.MembershipTickets); err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// releaseMembership gives back the tickets a booking's membership covered,
+// as part of an ongoing transaction. Tickets of past billing periods are
+// gone with their period.
+func releaseMembership(tx *sql.Tx, booking *Booking) error {
+	if booking.SubscriptionID == nil || booking.MembershipTickets == 0 {
+		return nil
+	}
+
+	query := `UPDATE subscriptions
+	SET tickets_used = GREATEST(tickets_used - $2, 0), updated_at = NOW()
+	WHERE id = $1 AND current_period_start <= $3`
+
+	args := []any{*booking.SubscriptionID, booking.MembershipTickets, booking.CreatedAt}
+	if _, err := tx.Exec(query, args...); err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	return nil
+}
@@ -15,11 +15,32 @@ var (
 )
 
 type Model struct {
-	Users    *UserModel
-	Theaters *TheaterModel
-	Halls    *HallModel
-	Movies   *MovieModel
-	Shows    *ShowModel
+	Users          *UserModel
+	Theaters       *TheaterModel
+	Halls          *HallModel
+	Movies         *MovieModel
+	Shows          *ShowModel
+	Seats          *SeatModel
+	ShowSeats      *ShowSeatModel
+	Holds          *HoldModel
+	Bookings       *BookingModel
+	Tickets        *TicketModel
+	RefundPolicies *RefundPolicyModel
+	Waitlist       *WaitlistModel
+	HallLayouts    *HallLayoutModel
+	PriceLists     *PriceListModel
+	PriceCurves    *PriceCurveModel
+	PriceChanges   *PriceChangeModel
+	PromoCodes     *PromoCodeModel
+	GiftCards      *GiftCardModel
+	Loyalty        *LoyaltyModel
+	Plans          *MembershipPlanModel
+	Subscriptions  *SubscriptionModel
+	Concessions    *ConcessionModel
+	Invoices       *InvoiceModel
+	TaxRules       *TaxRuleModel
+	ExchangeRates  *ExchangeRateModel
+	OperatingHours *OperatingHoursModel
 }
 
 // New creates a new model with the given database dsn
@@ -45,10 +66,31 @@ func New(dsn string) (*Model, error) {
 	}
 
 	return &Model{
-		Users:    &UserModel{db},
-		Theaters: &TheaterModel{db},
-		Halls:    &HallModel{db},
-		Movies:   &MovieModel{db},
-		Shows:    &ShowModel{db},
+		Users:          &UserModel{db},
+		Theaters:       &TheaterModel{db},
+		Halls:          &HallModel{db},
+		Movies:         &MovieModel{db},
+		Shows:          &ShowModel{db},
+		Seats:          &SeatModel{db},
+		ShowSeats:      &ShowSeatModel{db},
+		Holds:          &HoldModel{db},
+		Bookings:       &BookingModel{db},
+		Tickets:        &TicketModel{db},
+		RefundPolicies: &RefundPolicyModel{db},
+		Waitlist:       &WaitlistModel{db},
+		HallLayouts:    &HallLayoutModel{db},
+		PriceLists:     &PriceListModel{db},
+		PriceCurves:    &PriceCurveModel{db},
+		PriceChanges:   &PriceChangeModel{db},
+		PromoCodes:     &PromoCodeModel{db},
+		GiftCards:      &GiftCardModel{db},
+		Loyalty:        &LoyaltyModel{db},
+		Plans:          &MembershipPlanModel{db},
+		Subscriptions:  &SubscriptionModel{db},
+		Concessions:    &ConcessionModel{db},
+		Invoices:       &InvoiceModel{db},
+		TaxRules:       &TaxRuleModel{db},
+		ExchangeRates:  &ExchangeRateModel{db},
+		OperatingHours: &OperatingHoursModel{db},
 	}, nil
 }
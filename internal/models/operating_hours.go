@@ -0,0 +1,385 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+var ErrOutsideOperatingHours = errors.New("outside operating hours")
+
+// Weekdays names the days of the week, indexed by time.Weekday.
+var Weekdays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// OperatingHours is when a theater is open, in its Timezone. On a day of the
+// week without OpeningHours the theater is closed, unless it sets no weekly
+// hours at all, in which case it is open all day. HolidayHours replace the
+// weekly hours of their date and Closures close the theater on every date
+// from From to Until.
+type OperatingHours struct {
+	Timezone string         `json:"timezone"`
+	Weekly   []OpeningHours `json:"weekly"`
+	Holidays []HolidayHours `json:"holidays"`
+	Closures []Closure      `json:"closures"`
+}
+
+// OpeningHours opens a theater on a weekday, "sun" to "sat", from Opens to
+// Closes, "15:04" times. Closing at or before opening is on the next day.
+type OpeningHours struct {
+	Weekday string `json:"weekday"`
+	Opens   string `json:"opens"`
+	Closes  string `json:"closes"`
+}
+
+// HolidayHours open a theater on a "2006-01-02" date at other hours than
+// the weekly ones.
+type HolidayHours struct {
+	Date   string `json:"date"`
+	Name   string `json:"name"`
+	Opens  string `json:"opens"`
+	Closes string `json:"closes"`
+}
+
+// Closure closes a theater on the "2006-01-02" dates from From to Until,
+// both included.
+type Closure struct {
+	From   string `json:"from"`
+	Until  string `json:"until"`
+	Reason string `json:"reason"`
+}
+
+// Check returns an ErrOutsideOperatingHours error telling why unless the
+// theater is open from start to end. Opening hours running into those of the
+// next day count as one.
+func (h OperatingHours) Check(start, end time.Time) error {
+	loc := h.Location()
+	start, end = start.In(loc), end.In(loc)
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+
+	type span struct{ open, close time.Time }
+
+	var spans []span
+	for d := -1; d <= 1; d++ {
+		opens, closes, ok := h.On(day.AddDate(0, 0, d))
+		if !ok {
+			continue
+		}
+
+		if n := len(spans); n > 0 && !opens.After(spans[n-1].close) {
+			spans[n-1].close = latest(spans[n-1].close, closes)
+			continue
+		}
+		spans = append(spans, span{opens, closes})
+	}
+
+	for _, s := range spans {
+		if start.Before(s.open) || !start.Before(s.close) {
+			continue
+		}
+		if end.After(s.close) {
+			return fmt.Errorf(
+				"%w: show ends at %v, after the theater closes at %v",
+				ErrOutsideOperatingHours,
+				end.Format("2006-01-02 15:04"),
+				s.close.Format("2006-01-02 15:04"),
+			)
+		}
+		return nil
+	}
+
+	date := day.Format(time.DateOnly)
+
+	if closure, ok := h.closure(date); ok {
+		if closure.Reason != "" {
+			return fmt.Errorf("%w: theater is closed on %v (%v)", ErrOutsideOperatingHours, date, closure.Reason)
+		}
+		return fmt.Errorf("%w: theater is closed on %v", ErrOutsideOperatingHours, date)
+	}
+
+	opens, closes, ok := h.On(day)
+	if !ok {
+		return fmt.Errorf("%w: theater is closed on %v, a %v", ErrOutsideOperatingHours, date, day.Weekday())
+	}
+
+	return fmt.Errorf(
+		"%w: show starts at %v, but the theater is open from %v to %v on %v",
+		ErrOutsideOperatingHours,
+		start.Format("15:04"),
+		opens.Format("15:04"),
+		closes.Format("15:04"),
+		date,
+	)
+}
+
+// On returns when the theater opens and closes on the date of day, and
+// false if it is closed that day.
+func (h OperatingHours) On(day time.Time) (time.Time, time.Time, bool) {
+	loc := h.Location()
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
+	date := day.Format(time.DateOnly)
+
+	if _, ok := h.closure(date); ok {
+		return time.Time{}, time.Time{}, false
+	}
+
+	i := slices.IndexFunc(h.Holidays, func(holiday HolidayHours) bool {
+		return holiday.Date == date
+	})
+	if i >= 0 {
+		return openingSpan(day, h.Holidays[i].Opens, h.Holidays[i].Closes)
+	}
+
+	if len(h.Weekly) == 0 {
+		return day, day.AddDate(0, 0, 1), true
+	}
+
+	i = slices.IndexFunc(h.Weekly, func(hours OpeningHours) bool {
+		return hours.Weekday == Weekdays[day.Weekday()]
+	})
+	if i < 0 {
+		return time.Time{}, time.Time{}, false
+	}
+
+	return openingSpan(day, h.Weekly[i].Opens, h.Weekly[i].Closes)
+}
+
+// Location is the theater's time zone, UTC if unknown.
+func (h OperatingHours) Location() *time.Location {
+	loc, err := time.LoadLocation(h.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+func (h OperatingHours) closure(date string) (Closure, bool) {
+	for _, closure := range h.Closures {
+		if closure.From <= date && date <= closure.Until {
+			return closure, true
+		}
+	}
+	return Closure{}, false
+}
+
+// openingSpan returns the time from opens to closes, "15:04" times, on day.
+func openingSpan(day time.Time, opens, closes string) (time.Time, time.Time, bool) {
+	o, err := time.Parse("15:04", opens)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	c, err := time.Parse("15:04", closes)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+
+	open := time.Date(day.Year(), day.Month(), day.Day(), o.Hour(), o.Minute(), 0, 0, day.Location())
+	closing := time.Date(day.Year(), day.Month(), day.Day(), c.Hour(), c.Minute(), 0, 0, day.Location())
+	if !closing.After(open) {
+		closing = closing.AddDate(0, 0, 1)
+	}
+
+	return open, closing, true
+}
+
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+type OperatingHoursModel struct {
+	db *sql.DB
+}
+
+// Find returns the operating hours of a theater.
+func (m *OperatingHoursModel) Find(theaterID int) (OperatingHours, error) {
+	return findOperatingHours(m.db, theaterID)
+}
+
+// Replace swaps the operating hours of a theater for the given ones.
+func (m *OperatingHoursModel) Replace(theaterID int, hours OperatingHours) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	query := `UPDATE theaters SET timezone = $1, updated_at = NOW()
+	WHERE id = $2 AND deleted_at IS NULL`
+
+	result, err := tx.Exec(query, hours.Timezone, theaterID)
+	if err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	if rows, err := result.RowsAffected(); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	} else if rows == 0 {
+		tx.Rollback()
+		return ErrNotFound
+	}
+
+	for _, table := range []string{"opening_hours", "holiday_hours", "closures"} {
+		query := `DELETE FROM ` + table + ` WHERE theater_id = $1`
+		if _, err := tx.Exec(query, theaterID); err != nil {
+			tx.Rollback()
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+	}
+
+	query = `INSERT INTO opening_hours(theater_id, weekday, opens_at, closes_at)
+	VALUES ($1, $2, $3, $4)`
+
+	for _, weekly := range hours.Weekly {
+		weekday := slices.Index(Weekdays, weekly.Weekday)
+		if _, err := tx.Exec(query, theaterID, weekday, weekly.Opens, weekly.Closes); err != nil {
+			tx.Rollback()
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+	}
+
+	query = `INSERT INTO holiday_hours(theater_id, date, name, opens_at, closes_at)
+	VALUES ($1, $2, $3, $4, $5)`
+
+	for _, holiday := range hours.Holidays {
+		if _, err := tx.Exec(query, theaterID, holiday.Date, holiday.Name, holiday.Opens, holiday.Closes); err != nil {
+			tx.Rollback()
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+	}
+
+	query = `INSERT INTO closures(theater_id, from_date, until_date, reason)
+	VALUES ($1, $2, $3, $4)`
+
+	for _, closure := range hours.Closures {
+		if _, err := tx.Exec(query, theaterID, closure.From, closure.Until, closure.Reason); err != nil {
+			tx.Rollback()
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func findOperatingHours(db *sql.DB, theaterID int) (OperatingHours, error) {
+	hours := OperatingHours{
+		Weekly:   []OpeningHours{},
+		Holidays: []HolidayHours{},
+		Closures: []Closure{},
+	}
+
+	query := `SELECT timezone FROM theaters WHERE id = $1 AND deleted_at IS NULL`
+	if err := db.QueryRow(query, theaterID).Scan(&hours.Timezone); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return hours, ErrNotFound
+		default:
+			slog.Error("SQL Database Failure", "error", err)
+			return hours, err
+		}
+	}
+
+	query = `SELECT weekday, to_char(opens_at, 'HH24:MI'), to_char(closes_at, 'HH24:MI')
+	FROM opening_hours
+	WHERE theater_id = $1
+	ORDER BY weekday`
+
+	rows, err := db.Query(query, theaterID)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return hours, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var weekday int
+		var weekly OpeningHours
+		if err := rows.Scan(&weekday, &weekly.Opens, &weekly.Closes); err != nil {
+			slog.Error("Scan Failure", "error", err)
+			return hours, err
+		}
+
+		weekly.Weekday = Weekdays[weekday]
+		hours.Weekly = append(hours.Weekly, weekly)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Scan Failure", "error", err)
+		return hours, err
+	}
+
+	query = `SELECT to_char(date, 'YYYY-MM-DD'), name, to_char(opens_at, 'HH24:MI'),
+	to_char(closes_at, 'HH24:MI')
+	FROM holiday_hours
+	WHERE theater_id = $1
+	ORDER BY date`
+
+	rows, err = db.Query(query, theaterID)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return hours, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var holiday HolidayHours
+		if err := rows.Scan(&holiday.Date, &holiday.Name, &holiday.Opens, &holiday.Closes); err != nil {
+			slog.Error("Scan Failure", "error", err)
+			return hours, err
+		}
+
+		hours.Holidays = append(hours.Holidays, holiday)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Scan Failure", "error", err)
+		return hours, err
+	}
+
+	query = `SELECT to_char(from_date, 'YYYY-MM-DD'), to_char(until_date, 'YYYY-MM-DD'), reason
+	FROM closures
+	WHERE theater_id = $1
+	ORDER BY from_date`
+
+	rows, err = db.Query(query, theaterID)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return hours, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var closure Closure
+		if err := rows.Scan(&closure.From, &closure.Until, &closure.Reason); err != nil {
+			slog.Error("Scan Failure", "error", err)
+			return hours, err
+		}
+
+		hours.Closures = append(hours.Closures, closure)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Scan Failure", "error", err)
+		return hours, err
+	}
+
+	return hours, nil
+}
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperatingHours_Check(t *testing.T) {
+	hours := OperatingHours{
+		Timezone: "UTC",
+		Weekly: []OpeningHours{
+			{Weekday: "mon", Opens: "12:00", Closes: "23:00"},
+			{Weekday: "fri", Opens: "12:00", Closes: "02:00"},
+			{Weekday: "sat", Opens: "10:00", Closes: "02:00"},
+		},
+		Holidays: []HolidayHours{
+			{Date: "2026-11-09", Name: "Founders Day", Opens: "16:00", Closes: "23:30"},
+		},
+		Closures: []Closure{
+			{From: "2026-11-16", Until: "2026-11-17", Reason: "renovation"},
+		},
+	}
+
+	cairo := hours
+	cairo.Timezone = "Africa/Cairo"
+
+	// November 2, 2026 is a Monday.
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, time.November, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name       string
+		hours      OperatingHours
+		start, end time.Time
+		wantErr    string
+	}{
+		{
+			name:  "within opening hours",
+			hours: hours,
+			start: at(2, 19, 0),
+			end:   at(2, 21, 0),
+		},
+		{
+			name:    "before opening",
+			hours:   hours,
+			start:   at(2, 10, 0),
+			end:     at(2, 12, 0),
+			wantErr: "open from 12:00 to 23:00",
+		},
+		{
+			name:    "ends after closing",
+			hours:   hours,
+			start:   at(2, 22, 0),
+			end:     at(2, 23, 30),
+			wantErr: "after the theater closes at 2026-11-02 23:00",
+		},
+		{
+			name:  "past midnight",
+			hours: hours,
+			start: at(6, 23, 30),
+			end:   at(7, 1, 30),
+		},
+		{
+			name:  "within the previous day's hours",
+			hours: hours,
+			start: at(7, 1, 0),
+			end:   at(7, 1, 45),
+		},
+		{
+			name:    "closed weekday",
+			hours:   hours,
+			start:   at(3, 19, 0),
+			end:     at(3, 21, 0),
+			wantErr: "closed on 2026-11-03, a Tuesday",
+		},
+		{
+			name:    "holiday hours replace the weekly ones",
+			hours:   hours,
+			start:   at(9, 13, 0),
+			end:     at(9, 15, 0),
+			wantErr: "open from 16:00 to 23:30",
+		},
+		{
+			name:  "within holiday hours",
+			hours: hours,
+			start: at(9, 23, 0),
+			end:   at(9, 23, 30),
+		},
+		{
+			name:    "closure",
+			hours:   hours,
+			start:   at(16, 19, 0),
+			end:     at(16, 21, 0),
+			wantErr: "closed on 2026-11-16 (renovation)",
+		},
+		{
+			name:  "in the theater's time zone",
+			hours: cairo,
+			start: at(2, 10, 30),
+			end:   at(2, 12, 30),
+		},
+		{
+			name:  "open all day without weekly hours",
+			hours: OperatingHours{},
+			start: at(3, 23, 0),
+			end:   at(4, 2, 0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.hours.Check(tt.start, tt.end)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorIs(t, err, ErrOutsideOperatingHours)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
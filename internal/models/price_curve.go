@@ -0,0 +1,289 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// PriceCurve adjusts a theater's prices with demand. Occupancy points apply
+// Percent of the static price once at least Threshold percent of a show's
+// seats of the category are held or sold; lead time points apply when the
+// show starts within Threshold hours. Floors and Ceilings bound the adjusted
+// price per seat category. A theater without curve points has static prices.
+type PriceCurve struct {
+	Occupancy []CurvePoint     `json:"occupancy"`
+	LeadTime  []CurvePoint     `json:"lead_time"`
+	Floors    map[string]int64 `json:"floors"`
+	Ceilings  map[string]int64 `json:"ceilings"`
+}
+
+type CurvePoint struct {
+	Threshold int `json:"threshold"`
+	Percent   int `json:"percent"`
+}
+
+// IsDynamic reports whether the curve adjusts prices at all.
+func (c PriceCurve) IsDynamic() bool {
+	return len(c.Occupancy) > 0 || len(c.LeadTime) > 0
+}
+
+// PriceChange is an entry of a show's price audit trail.
+type PriceChange struct {
+	ID          int       `json:"id"`
+	ShowID      int       `json:"show_id"`
+	Category    string    `json:"category"`
+	OldPrice    *int64    `json:"old_price"`
+	NewPrice    int64     `json:"new_price"`
+	Occupancy   int       `json:"occupancy"`
+	HoursBefore int       `json:"hours_before"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type PriceCurveModel struct {
+	db *sql.DB
+}
+
+// Find returns the price curve of a theater, points in ascending threshold
+// order.
+func (m *PriceCurveModel) Find(theaterID int) (*PriceCurve, error) {
+	curve := &PriceCurve{
+		Occupancy: []CurvePoint{},
+		LeadTime:  []CurvePoint{},
+		Floors:    map[string]int64{},
+		Ceilings:  map[string]int64{},
+	}
+
+	query := `SELECT curve, threshold, percent
+	FROM price_curve_points
+	WHERE theater_id = $1
+	ORDER BY threshold`
+
+	rows, err := m.db.Query(query, theaterID)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		var point CurvePoint
+		if err := rows.Scan(&name, &point.Threshold, &point.Percent); err != nil {
+			slog.Error("Scan Failure", "error", err)
+			return nil, err
+		}
+
+		switch name {
+		case "occupancy":
+			curve.Occupancy = append(curve.Occupancy, point)
+		case "lead_time":
+			curve.LeadTime = append(curve.LeadTime, point)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Scan Failure", "error", err)
+		return nil, err
+	}
+
+	query = `SELECT category, floor_price, ceiling_price
+	FROM price_bounds
+	WHERE theater_id = $1`
+
+	rows, err = m.db.Query(query, theaterID)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var category string
+		var floor, ceiling sql.NullInt64
+		if err := rows.Scan(&category, &floor, &ceiling); err != nil {
+			slog.Error("Scan Failure", "error", err)
+			return nil, err
+		}
+
+		if floor.Valid {
+			curve.Floors[category] = floor.Int64
+		}
+		if ceiling.Valid {
+			curve.Ceilings[category] = ceiling.Int64
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Scan Failure", "error", err)
+		return nil, err
+	}
+
+	return curve, nil
+}
+
+// Replace swaps the price curve of a theater for the given one.
+func (m *PriceCurveModel) Replace(theaterID int, curve PriceCurve) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	for _, query := range []string{
+		`DELETE FROM price_curve_points WHERE theater_id = $1`,
+		`DELETE FROM price_bounds WHERE theater_id = $1`,
+	} {
+		if _, err := tx.Exec(query, theaterID); err != nil {
+			tx.Rollback()
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+	}
+
+	query := `INSERT INTO price_curve_points(theater_id, curve, threshold, percent)
+	VALUES ($1, $2, $3, $4)`
+
+	points := map[string][]CurvePoint{
+		"occupancy": curve.Occupancy,
+		"lead_time": curve.LeadTime,
+	}
+	for name, points := range points {
+		for _, point := range points {
+			if _, err := tx.Exec(query, theaterID, name, point.Threshold, point.Percent); err != nil {
+				tx.Rollback()
+				slog.Error("SQL Database Failure", "error", err)
+				return err
+			}
+		}
+	}
+
+	query = `INSERT INTO price_bounds(theater_id, category, floor_price, ceiling_price)
+	VALUES ($1, $2, $3, $4)`
+
+	bounded := make(map[string]bool)
+	for category := range curve.Floors {
+		bounded[category] = true
+	}
+	for category := range curve.Ceilings {
+		bounded[category] = true
+	}
+
+	for category := range bounded {
+		var floor, ceiling sql.NullInt64
+		floor.Int64, floor.Valid = curve.Floors[category]
+		ceiling.Int64, ceiling.Valid = curve.Ceilings[category]
+
+		if _, err := tx.Exec(query, theaterID, category, floor, ceiling); err != nil {
+			tx.Rollback()
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+type PriceChangeModel struct {
+	db *sql.DB
+}
+
+// Record adds change to the show's price audit trail unless the price of the
+// category is unchanged since the last entry. It reports whether an entry
+// was added.
+func (m *PriceChangeModel) Record(change *PriceChange) (bool, error) {
+	query := `INSERT INTO price_changes(show_id, category, old_price, new_price,
+	occupancy, hours_before)
+	SELECT $1, $2, last.price, $3, $4, $5
+	FROM (
+		SELECT (
+			SELECT new_price FROM price_changes
+			WHERE show_id = $1 AND category = $2
+			ORDER BY id DESC LIMIT 1
+		) AS price
+	) AS last
+	WHERE last.price IS DISTINCT FROM $3
+	RETURNING id, old_price, created_at`
+
+	args := []any{
+		change.ShowID,
+		change.Category,
+		change.NewPrice,
+		change.Occupancy,
+		change.HoursBefore,
+	}
+
+	var oldPrice sql.NullInt64
+	err := m.db.QueryRow(query, args...).Scan(&change.ID, &oldPrice, &change.CreatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return false, nil
+		default:
+			slog.Error("SQL Database Failure", "error", err)
+			return false, err
+		}
+	}
+
+	if oldPrice.Valid {
+		change.OldPrice = &oldPrice.Int64
+	}
+
+	return true, nil
+}
+
+// FindByShow returns the price audit trail of a show, oldest first.
+func (m *PriceChangeModel) FindByShow(showID int) ([]PriceChange, error) {
+	query := `SELECT id, show_id, category, old_price, new_price, occupancy,
+	hours_before, created_at
+	FROM price_changes
+	WHERE show_id = $1
+	ORDER BY id`
+
+	rows, err := m.db.Query(query, showID)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []PriceChange{}
+	for rows.Next() {
+		var change PriceChange
+		var oldPrice sql.NullInt64
+		err := rows.Scan(
+			&change.ID,
+			&change.ShowID,
+			&change.Category,
+			&oldPrice,
+			&change.NewPrice,
+			&change.Occupancy,
+			&change.HoursBefore,
+			&change.CreatedAt,
+		)
+		if err != nil {
+			slog.Error("Scan Failure", "error", err)
+			return nil, err
+		}
+
+		if oldPrice.Valid {
+			change.OldPrice = &oldPrice.Int64
+		}
+
+		changes = append(changes, change)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Scan Failure", "error", err)
+		return nil, err
+	}
+
+	return changes, nil
+}
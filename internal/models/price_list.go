@@ -0,0 +1,159 @@
+package models
+
+import (
+	"database/sql"
+	"log/slog"
+)
+
+const (
+	TierMatinee = "matinee"
+	TierEvening = "evening"
+	TierWeekend = "weekend"
+	TierHoliday = "holiday"
+)
+
+// PriceTiers lists every price tier a show may have.
+var PriceTiers = []string{
+	TierMatinee,
+	TierEvening,
+	TierWeekend,
+	TierHoliday,
+}
+
+const (
+	TicketAdult   = "adult"
+	TicketChild   = "child"
+	TicketSenior  = "senior"
+	TicketStudent = "student"
+)
+
+// TicketTypes lists every type of ticket a customer may buy.
+var TicketTypes = []string{
+	TicketAdult,
+	TicketChild,
+	TicketSenior,
+	TicketStudent,
+}
+
+// PriceList holds a theater's ticket prices. Categories maps seat categories
+// to their base price in the minor unit of the theater's Currency; Tiers and
+// TicketTypes map show price tiers and ticket types to a percentage of that
+// base price.
+type PriceList struct {
+	Currency    string           `json:"currency"`
+	Categories  map[string]int64 `json:"categories"`
+	Tiers       map[string]int   `json:"tiers"`
+	TicketTypes map[string]int   `json:"ticket_types"`
+}
+
+// PriceRange is the cheapest and the most expensive ticket of a show.
+type PriceRange struct {
+	Min int64 `json:"min"`
+	Max int64 `json:"max"`
+}
+
+type PriceListModel struct {
+	db *sql.DB
+}
+
+// Find returns the prices a theater has set. Entries the theater didn't set
+// are missing from the maps.
+func (m *PriceListModel) Find(theaterID int) (*PriceList, error) {
+	query := `SELECT kind, name, value
+	FROM theater_prices
+	WHERE theater_id = $1`
+
+	rows, err := m.db.Query(query, theaterID)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := &PriceList{
+		Categories:  map[string]int64{},
+		Tiers:       map[string]int{},
+		TicketTypes: map[string]int{},
+	}
+
+	for rows.Next() {
+		var kind, name string
+		var value int64
+		if err := rows.Scan(&kind, &name, &value); err != nil {
+			slog.Error("Scan Failure", "error", err)
+			return nil, err
+		}
+
+		switch kind {
+		case "category":
+			list.Categories[name] = value
+		case "tier":
+			list.Tiers[name] = int(value)
+		case "ticket_type":
+			list.TicketTypes[name] = int(value)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Scan Failure", "error", err)
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// Replace swaps the prices of a theater for the given ones.
+func (m *PriceListModel) Replace(theaterID int, list PriceList) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	query := `DELETE FROM theater_prices WHERE theater_id = $1`
+	if _, err := tx.Exec(query, theaterID); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	query = `INSERT INTO theater_prices(theater_id, kind, name, value)
+	VALUES ($1, $2, $3, $4)`
+
+	insert := func(kind, name string, value int64) error {
+		if _, err := tx.Exec(query, theaterID, kind, name, value); err != nil {
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+		return nil
+	}
+
+	for name, price := range list.Categories {
+		if err := insert("category", name, price); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	for name, percent := range list.Tiers {
+		if err := insert("tier", name, int64(percent)); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	for name, percent := range list.TicketTypes {
+		if err := insert("ticket_type", name, int64(percent)); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,323 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrPromoCodeExhausted = errors.New("promo code redemption limit reached")
+	ErrPromoCodeNotFound  = errors.New("promo code not found")
+)
+
+const (
+	PromoPercent = "percent"
+	PromoFixed   = "fixed"
+)
+
+// PromoCode discounts bookings by a percentage of the eligible tickets or by
+// a fixed amount. A promo code without a theater applies to every theater.
+// Empty restrictions don't restrict anything.
+type PromoCode struct {
+	ID             int        `json:"id"`
+	Code           string     `json:"code"`
+	TheaterID      *int       `json:"theater_id"`
+	CreatedBy      *int       `json:"created_by"`
+	Kind           string     `json:"kind"`
+	Value          int64      `json:"value"`
+	MovieID        *string    `json:"movie_id"`
+	ShowsFrom      *time.Time `json:"shows_from"`
+	ShowsUntil     *time.Time `json:"shows_until"`
+	TicketTypes    []string   `json:"ticket_types"`
+	MinSeats       int        `json:"min_seats"`
+	MaxRedemptions *int       `json:"max_redemptions"`
+	MaxPerUser     *int       `json:"max_per_user"`
+	Redemptions    int        `json:"redemptions"`
+	Active         bool       `json:"active"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+type PromoCodeModel struct {
+	db *sql.DB
+}
+
+const promoCodeColumns = `id, code, theater_id, created_by, kind, value, movie_id,
+	shows_from, shows_until, ticket_types, min_seats, max_redemptions,
+	max_per_user, redemptions, active, created_at, updated_at`
+
+func (m *PromoCodeModel) Create(promo *PromoCode) error {
+	query := `INSERT INTO promo_codes(code, theater_id, created_by, kind, value,
+	movie_id, shows_from, shows_until, ticket_types, min_seats, max_redemptions,
+	max_per_user, active)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	RETURNING id, redemptions, created_at, updated_at`
+
+	args := []any{
+		promo.Code,
+		promo.TheaterID,
+		promo.CreatedBy,
+		promo.Kind,
+		promo.Value,
+		promo.MovieID,
+		promo.ShowsFrom,
+		promo.ShowsUntil,
+		pq.Array(promo.TicketTypes),
+		promo.MinSeats,
+		promo.MaxRedemptions,
+		promo.MaxPerUser,
+		promo.Active,
+	}
+
+	err := m.db.QueryRow(query, args...).Scan(
+		&promo.ID,
+		&promo.Redemptions,
+		&promo.CreatedAt,
+		&promo.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "promo_codes_code_key"):
+			return fmt.Errorf("%w: promo code %v already exists", ErrDuplicate, promo.Code)
+		case strings.Contains(err.Error(), "promo_codes_movie_id_fkey"):
+			return fmt.Errorf("%w: movie %v doesn't exist", ErrNotFound, *promo.MovieID)
+		default:
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *PromoCodeModel) Find(id int) (*PromoCode, error) {
+	query := `SELECT ` + promoCodeColumns + ` FROM promo_codes WHERE id = $1`
+	return scanPromoCode(m.db.QueryRow(query, id))
+}
+
+// FindByCode looks a promo code up by its code, which is case insensitive.
+func (m *PromoCodeModel) FindByCode(code string) (*PromoCode, error) {
+	query := `SELECT ` + promoCodeColumns + ` FROM promo_codes WHERE code = UPPER($1)`
+	return scanPromoCode(m.db.QueryRow(query, code))
+}
+
+// FindByTheater lists the promo codes of a theater, or the promo codes valid
+// at every theater when theaterID is nil.
+func (m *PromoCodeModel) FindByTheater(theaterID *int) ([]PromoCode, error) {
+	query := `SELECT ` + promoCodeColumns + `
+	FROM promo_codes
+	WHERE theater_id IS NOT DISTINCT FROM $1
+	ORDER BY created_at DESC`
+
+	rows, err := m.db.Query(query, theaterID)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	promos := []PromoCode{}
+	for rows.Next() {
+		promo, err := scanPromoCode(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		promos = append(promos, *promo)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Scan Failure", "error", err)
+		return nil, err
+	}
+
+	return promos, nil
+}
+
+// Update saves the promo code's restrictions and limits. The code, theater
+// and redemption count can't be changed.
+func (m *PromoCodeModel) Update(promo *PromoCode) error {
+	query := `UPDATE promo_codes
+	SET kind = $1, value = $2, movie_id = $3, shows_from = $4, shows_until = $5,
+	ticket_types = $6, min_seats = $7, max_redemptions = $8, max_per_user = $9,
+	active = $10, updated_at = NOW()
+	WHERE id = $11 AND updated_at = $12
+	RETURNING updated_at`
+
+	args := []any{
+		promo.Kind,
+		promo.Value,
+		promo.MovieID,
+		promo.ShowsFrom,
+		promo.ShowsUntil,
+		pq.Array(promo.TicketTypes),
+		promo.MinSeats,
+		promo.MaxRedemptions,
+		promo.MaxPerUser,
+		promo.Active,
+		promo.ID,
+		promo.UpdatedAt,
+	}
+
+	err := m.db.QueryRow(query, args...).Scan(&promo.UpdatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		case strings.Contains(err.Error(), "promo_codes_redemptions_check"):
+			return fmt.Errorf("%w: promo code was redeemed %v times already", ErrEditConflict, promo.Redemptions)
+		case strings.Contains(err.Error(), "promo_codes_movie_id_fkey"):
+			return fmt.Errorf("%w: movie %v doesn't exist", ErrNotFound, *promo.MovieID)
+		default:
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *PromoCodeModel) Delete(id int) error {
+	query := `DELETE FROM promo_codes WHERE id = $1`
+
+	result, err := m.db.Exec(query, id)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	if rows, err := result.RowsAffected(); err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	} else if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanPromoCode(row rowScanner) (*PromoCode, error) {
+	var promo PromoCode
+	var ticketTypes pq.StringArray
+
+	err := row.Scan(
+		&promo.ID,
+		&promo.Code,
+		&promo.TheaterID,
+		&promo.CreatedBy,
+		&promo.Kind,
+		&promo.Value,
+		&promo.MovieID,
+		&promo.ShowsFrom,
+		&promo.ShowsUntil,
+		&ticketTypes,
+		&promo.MinSeats,
+		&promo.MaxRedemptions,
+		&promo.MaxPerUser,
+		&promo.Redemptions,
+		&promo.Active,
+		&promo.CreatedAt,
+		&promo.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNotFound
+		default:
+			slog.Error("Scan Failure", "error", err)
+			return nil, err
+		}
+	}
+
+	promo.TicketTypes = ticketTypes
+
+	return &promo, nil
+}
+
+// redeemPromoCode records the redemption of the booking's promo code as part
+// of an ongoing transaction. The promo code's row stays locked until the
+// transaction ends, so concurrent checkouts can't redeem it past its limits.
+func redeemPromoCode(tx *sql.Tx, booking *Booking) error {
+	query := `SELECT max_redemptions, max_per_user, redemptions, active
+	FROM promo_codes
+	WHERE id = $1
+	FOR UPDATE`
+
+	var maxRedemptions, maxPerUser sql.NullInt32
+	var redemptions int
+	var active bool
+
+	err := tx.QueryRow(query, *booking.PromoCodeID).Scan(&maxRedemptions, &maxPerUser, &redemptions, &active)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrPromoCodeNotFound
+		default:
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+	}
+
+	if !active || (maxRedemptions.Valid && redemptions >= int(maxRedemptions.Int32)) {
+		return ErrPromoCodeExhausted
+	}
+
+	if maxPerUser.Valid {
+		query = `SELECT COUNT(*) FROM promo_redemptions
+		WHERE promo_code_id = $1 AND user_id = $2`
+
+		var used int
+		if err := tx.QueryRow(query, *booking.PromoCodeID, booking.UserID).Scan(&used); err != nil {
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+
+		if used >= int(maxPerUser.Int32) {
+			return fmt.Errorf("%w: already used %v times", ErrPromoCodeExhausted, used)
+		}
+	}
+
+	query = `INSERT INTO promo_redemptions(promo_code_id, booking_id, user_id, discount)
+	VALUES ($1, $2, $3, $4)`
+	args := []any{*booking.PromoCodeID, booking.ID, booking.UserID, booking.Discount}
+	if _, err := tx.Exec(query, args...); err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	query = `UPDATE promo_codes SET redemptions = redemptions + 1 WHERE id = $1`
+	if _, err := tx.Exec(query, *booking.PromoCodeID); err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// releasePromoRedemption gives back the promo code redemption of a booking
+// that was never paid, as part of an ongoing transaction.
+func releasePromoRedemption(tx *sql.Tx, bookingID int) error {
+	query := `WITH released AS (
+		DELETE FROM promo_redemptions WHERE booking_id = $1
+		RETURNING promo_code_id
+	)
+	UPDATE promo_codes SET redemptions = redemptions - 1
+	WHERE id IN (SELECT promo_code_id FROM released)`
+
+	if _, err := tx.Exec(query, bookingID); err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"database/sql"
+	"log/slog"
+)
+
+// RefundRule grants a refund of Percent of the booking's amount when the
+// booking is cancelled at least MinHoursBefore hours before the show starts.
+type RefundRule struct {
+	MinHoursBefore int `json:"min_hours_before"`
+	Percent        int `json:"percent"`
+}
+
+type RefundPolicyModel struct {
+	db *sql.DB
+}
+
+// Find returns the refund rules of a theater, most generous notice first.
+func (m *RefundPolicyModel) Find(theaterID int) ([]RefundRule, error) {
+	return findRefundRules(m.db, theaterID)
+}
+
+// Replace swaps the refund rules of a theater for the given ones.
+func (m *RefundPolicyModel) Replace(theaterID int, rules []RefundRule) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	query := `DELETE FROM refund_rules WHERE theater_id = $1`
+	if _, err := tx.Exec(query, theaterID); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	query = `INSERT INTO refund_rules(theater_id, min_hours_before, percent)
+	VALUES ($1, $2, $3)`
+
+	for _, rule := range rules {
+		if _, err := tx.Exec(query, theaterID, rule.MinHoursBefore, rule.Percent); err != nil {
+			tx.Rollback()
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func findRefundRules(db *sql.DB, theaterID int) ([]RefundRule, error) {
+	query := `SELECT min_hours_before, percent
+	FROM refund_rules
+	WHERE theater_id = $1
+	ORDER BY min_hours_before DESC`
+
+	rows, err := db.Query(query, theaterID)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []RefundRule{}
+	for rows.Next() {
+		var rule RefundRule
+		if err := rows.Scan(&rule.MinHoursBefore, &rule.Percent); err != nil {
+			slog.Error("Scan Failure", "error", err)
+			return nil, err
+		}
+
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Scan Failure", "error", err)
+		return nil, err
+	}
+
+	return rules, nil
+}
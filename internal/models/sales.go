@@ -0,0 +1,72 @@
+package models
+
+import (
+	"log/slog"
+	"time"
+)
+
+// TheaterSales is what the bookings of a theater paid for within a period,
+// in the currency they were paid in. Gross counts the gift card amounts.
+type TheaterSales struct {
+	TheaterID   int
+	TheaterName string
+	Currency    string
+	Bookings    int
+	Tickets     int
+	Gross       int64
+	Refunded    int64
+}
+
+// SalesByTheater sums up the bookings paid between from and until by theater
+// and currency, whatever became of them since. A theater whose currency
+// changed within the period has a row per currency.
+func (m *BookingModel) SalesByTheater(from, until time.Time) ([]TheaterSales, error) {
+	query := `SELECT t.id, t.name, b.currency, COUNT(*),
+	COALESCE(SUM(k.tickets), 0), SUM(b.amount + b.gift_card_amount),
+	SUM(b.refund_amount)
+	FROM bookings AS b
+	JOIN booking_events AS e ON e.booking_id = b.id AND e.to_status = 'paid'
+	JOIN shows AS s ON s.id = b.show_id
+	JOIN halls AS h ON h.id = s.hall_id
+	JOIN theaters AS t ON t.id = h.theater_id
+	LEFT JOIN (
+		SELECT booking_id, COUNT(*) AS tickets FROM tickets GROUP BY booking_id
+	) AS k ON k.booking_id = b.id
+	WHERE e.created_at >= $1 AND e.created_at < $2
+	GROUP BY t.id, t.name, b.currency
+	ORDER BY t.name, b.currency`
+
+	rows, err := m.db.Query(query, from, until)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	sales := []TheaterSales{}
+	for rows.Next() {
+		var s TheaterSales
+		err := rows.Scan(
+			&s.TheaterID,
+			&s.TheaterName,
+			&s.Currency,
+			&s.Bookings,
+			&s.Tickets,
+			&s.Gross,
+			&s.Refunded,
+		)
+		if err != nil {
+			slog.Error("Scan Failure", "error", err)
+			return nil, err
+		}
+
+		sales = append(sales, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Scan Failure", "error", err)
+		return nil, err
+	}
+
+	return sales, nil
+}
@@ -1,6 +1,30 @@
 package models
 
-import "time"
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	CategoryStandard   = "standard"
+	CategoryVIP        = "vip"
+	CategoryWheelchair = "wheelchair"
+	CategoryCompanion  = "companion"
+	CategoryCouple     = "couple"
+)
+
+// SeatCategories lists every category a seat may have.
+var SeatCategories = []string{
+	CategoryStandard,
+	CategoryVIP,
+	CategoryWheelchair,
+	CategoryCompanion,
+	CategoryCouple,
+}
 
 type Seating struct {
 	Version int    `json:"version"`
@@ -11,11 +35,187 @@ func (s Seating) Capacity() int {
 	return len(s.Seats)
 }
 
+// Seat is a seat of a hall's layout. Seats are numbered from 1 within their
+// row; Column places the seat on the hall's grid, so seats of different rows
+// sharing a column are behind each other and an empty column is an aisle.
 type Seat struct {
 	ID         int       `json:"id"`
 	Row        string    `json:"row"`
 	SeatNumber int       `json:"seat_number"`
+	Column     int       `json:"column"`
+	Category   string    `json:"category"`
 	HallID     int       `json:"hall_id"`
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
 }
+
+type SeatModel struct {
+	db *sql.DB
+}
+
+// FindByHallCode returns the current seating of a theater's hall.
+func (m *SeatModel) FindByHallCode(theaterID int, code string) (*Seating, error) {
+	query := `SELECT h.id, h.seats_version
+	FROM halls AS h
+	JOIN theaters AS t ON t.id = h.theater_id
+	WHERE h.theater_id = $1 AND h.code = $2
+	AND h.deleted_at IS NULL AND t.deleted_at IS NULL`
+
+	var hallID int
+	seating := &Seating{Seats: []Seat{}}
+
+	err := m.db.QueryRow(query, theaterID, code).Scan(&hallID, &seating.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNotFound
+		default:
+			slog.Error("SQL Database Failure", "error", err)
+			return nil, err
+		}
+	}
+
+	seats, err := m.FindByHallVersion(hallID, seating.Version)
+	if err != nil {
+		return nil, err
+	}
+	seating.Seats = seats
+
+	return seating, nil
+}
+
+// FindByHallVersion returns the seats of the given layout version of a hall.
+func (m *SeatModel) FindByHallVersion(hallID, version int) ([]Seat, error) {
+	query := `SELECT id, row, seat_number, grid_column, category, hall_id,
+	created_at, updated_at
+	FROM seats
+	WHERE hall_id = $1 AND version = $2 AND deleted_at IS NULL
+	ORDER BY LENGTH(row), row, seat_number`
+
+	rows, err := m.db.Query(query, hallID, version)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	seats := []Seat{}
+	for rows.Next() {
+		var seat Seat
+		err := rows.Scan(
+			&seat.ID,
+			&seat.Row,
+			&seat.SeatNumber,
+			&seat.Column,
+			&seat.Category,
+			&seat.HallID,
+			&seat.CreatedAt,
+			&seat.UpdatedAt,
+		)
+		if err != nil {
+			slog.Error("Scan Failure", "error", err)
+			return nil, err
+		}
+
+		seats = append(seats, seat)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Scan Failure", "error", err)
+		return nil, err
+	}
+
+	return seats, nil
+}
+
+// UpdateCategories sets the category of the given seats, identified by their
+// IDs, along with their copies in shows that haven't started yet. Copies that
+// are held or sold keep the category they were taken in.
+func (m *SeatModel) UpdateCategories(seats []Seat) error {
+	ids := make([]int64, len(seats))
+	categories := make([]string, len(seats))
+	for i, seat := range seats {
+		ids[i] = int64(seat.ID)
+		categories[i] = seat.Category
+	}
+
+	tx, err := m.db.Begin()
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	query := `UPDATE seats AS s
+	SET category = c.category, updated_at = NOW()
+	FROM unnest($1::int[], $2::text[]) AS c(id, category)
+	WHERE s.id = c.id`
+
+	if _, err := tx.Exec(query, pq.Array(ids), pq.Array(categories)); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	query = `UPDATE show_seats AS ss
+	SET category = s.category, updated_at = NOW()
+	FROM seats AS s, shows AS sh
+	WHERE ss.seat_id = s.id AND sh.id = ss.show_id
+	AND s.id = ANY($1) AND sh.start_time > NOW() AND ss.status = 'available'`
+
+	if _, err := tx.Exec(query, pq.Array(ids)); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// insertSeats writes the hall's seats for the given layout version as part of
+// an ongoing transaction.
+func insertSeats(tx *sql.Tx, hallID, version int, seats []Seat) error {
+	if len(seats) == 0 {
+		return nil
+	}
+
+	rows := make([]string, len(seats))
+	numbers := make([]int64, len(seats))
+	columns := make([]int64, len(seats))
+	categories := make([]string, len(seats))
+	for i, seat := range seats {
+		rows[i] = seat.Row
+		numbers[i] = int64(seat.SeatNumber)
+		columns[i] = int64(seat.Column)
+		categories[i] = seat.Category
+		if categories[i] == "" {
+			categories[i] = CategoryStandard
+		}
+	}
+
+	query := `INSERT INTO seats(hall_id, version, row, seat_number, grid_column, category)
+	SELECT $1, $2, r.row, r.seat_number, r.grid_column, r.category
+	FROM unnest($3::text[], $4::int[], $5::int[], $6::text[])
+	AS r(row, seat_number, grid_column, category)`
+
+	args := []any{
+		hallID,
+		version,
+		pq.Array(rows),
+		pq.Array(numbers),
+		pq.Array(columns),
+		pq.Array(categories),
+	}
+
+	if _, err := tx.Exec(query, args...); err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	return nil
+}
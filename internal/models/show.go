@@ -13,17 +13,19 @@ import (
 )
 
 type Show struct {
-	ID            int       `json:"id"`
-	TheaterID     int       `json:"theater_id,omitempty"`
-	HallID        int       `json:"hall_id"`
-	HallCode      string    `json:"hall_code,omitempty"`
-	MovieID       string    `json:"movie_id"`
-	MovieTitle    string    `json:"movie_title,omitempty"`
-	MovieIMDBLink string    `json:"movie_imdb_link,omitempty"`
-	StartTime     time.Time `json:"start_time"`
-	EndTime       time.Time `json:"end_time"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID            int         `json:"id"`
+	TheaterID     int         `json:"theater_id,omitempty"`
+	HallID        int         `json:"hall_id"`
+	HallCode      string      `json:"hall_code,omitempty"`
+	MovieID       string      `json:"movie_id"`
+	MovieTitle    string      `json:"movie_title,omitempty"`
+	StartTime     time.Time   `json:"start_time"`
+	EndTime       time.Time   `json:"end_time"`
+	LayoutVersion int         `json:"layout_version,omitempty"`
+	PriceTier     string      `json:"price_tier"`
+	PriceRange    *PriceRange `json:"price_range,omitempty"`
+	CreatedAt     time.Time   `json:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at"`
 }
 
 type ShowModel struct {
@@ -48,14 +50,15 @@ func (m *ShowModel) Search(f ShowFilter) ([]Show, error) {
 	for rows.Next() {
 		var show Show
 		err := rows.Scan(
+			&show.ID,
 			&show.TheaterID,
 			&show.HallID,
 			&show.HallCode,
 			&show.MovieID,
 			&show.MovieTitle,
-			&show.MovieIMDBLink,
 			&show.StartTime,
 			&show.EndTime,
+			&show.PriceTier,
 			&show.CreatedAt,
 			&show.UpdatedAt,
 		)
@@ -77,24 +80,68 @@ func (m *ShowModel) Search(f ShowFilter) ([]Show, error) {
 }
 
 func (m *ShowModel) Create(show *Show) error {
-	query := `INSERT INTO shows(movie_id, hall_id, start_time, end_time)
-	SELECT m.id, h.id, $4, $5
+	return m.CreateMany([]*Show{show})
+}
+
+// CreateMany creates shows in a single transaction, so that either all of
+// them are scheduled or none is.
+func (m *ShowModel) CreateMany(shows []*Show) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	for _, show := range shows {
+		if err := insertShow(tx, show); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func insertShow(tx *sql.Tx, show *Show) error {
+	hallID, schedule, err := lockHallSchedule(tx, show.TheaterID, show.HallCode, show.StartTime)
+	if err != nil {
+		return err
+	}
+
+	// the schedule was checked before the hall was locked, so it's checked
+	// again against the shows scheduled meanwhile.
+	schedule.From, schedule.To = show.StartTime, show.EndTime
+	if err := schedule.IsFree(*show); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO shows(movie_id, hall_id, start_time, end_time,
+	layout_version, price_tier)
+	SELECT m.imdb_id, h.id, $3, $4, h.seats_version, $5
 	FROM movies AS m
-	JOIN halls AS h ON h.theater_id = $2 AND h.code = $3
-	WHERE m.id = $1 AND h.deleted_at IS NULL
-	RETURNING id, created_at, updated_at
+	JOIN halls AS h ON h.id = $2
+	WHERE m.imdb_id = $1
+	RETURNING id, hall_id, layout_version, created_at, updated_at
 	`
 
 	args := []any{
 		show.MovieID,
-		show.TheaterID,
-		show.HallCode,
+		hallID,
 		show.StartTime,
 		show.EndTime,
+		show.PriceTier,
 	}
 
-	err := m.db.QueryRow(query, args...).Scan(
+	err = tx.QueryRow(query, args...).Scan(
 		&show.ID,
+		&show.HallID,
+		&show.LayoutVersion,
 		&show.CreatedAt,
 		&show.UpdatedAt,
 	)
@@ -102,22 +149,101 @@ func (m *ShowModel) Create(show *Show) error {
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
-			return fmt.Errorf("%w: movie or hall not found", ErrNotFound)
+			return fmt.Errorf("%w: movie not found", ErrNotFound)
 		default:
 			slog.Error("SQL Database Failure", "error", err)
 			return err
 		}
 	}
 
+	// every show gets its own copy of the hall's current seating, which is
+	// the inventory customers book against. The show keeps that layout
+	// version even after a newer one is published.
+	query = `INSERT INTO show_seats(show_id, seat_id, row, seat_number,
+	grid_column, category)
+	SELECT $1, s.id, s.row, s.seat_number, s.grid_column, s.category
+	FROM seats AS s
+	WHERE s.hall_id = $2 AND s.version = $3 AND s.deleted_at IS NULL`
+
+	if _, err := tx.Exec(query, show.ID, show.HallID, show.LayoutVersion); err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
 	return nil
 }
 
+// lockHallSchedule locks a hall for the rest of the transaction, so that no
+// other show can be scheduled in it meanwhile, and returns its shows that
+// end, turnaround included, after from.
+func lockHallSchedule(tx *sql.Tx, theaterID int, code string, from time.Time) (int, *Schedule, error) {
+	query := `SELECT h.id, COALESCE(h.turnaround_minutes, t.turnaround_minutes)
+	FROM halls AS h
+	JOIN theaters AS t ON t.id = h.theater_id
+	WHERE h.theater_id = $1 AND h.code = $2 AND h.deleted_at IS NULL
+	FOR UPDATE OF h`
+
+	var hallID, turnaround int
+	err := tx.QueryRow(query, theaterID, code).Scan(&hallID, &turnaround)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return 0, nil, fmt.Errorf("%w: hall not found", ErrNotFound)
+		default:
+			slog.Error("SQL Database Failure", "error", err)
+			return 0, nil, err
+		}
+	}
+
+	schedule := &Schedule{
+		Turnaround: time.Duration(turnaround) * time.Minute,
+		Shows:      []Show{},
+	}
+
+	query = `SELECT s.id, s.movie_id, m.title, s.start_time, s.end_time
+	FROM shows AS s
+	JOIN movies AS m ON m.imdb_id = s.movie_id
+	WHERE s.hall_id = $1 AND s.end_time > $2
+	ORDER BY s.start_time`
+
+	rows, err := tx.Query(query, hallID, from.Add(-schedule.Turnaround))
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return 0, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		show := Show{TheaterID: theaterID, HallID: hallID, HallCode: code}
+		err := rows.Scan(
+			&show.ID,
+			&show.MovieID,
+			&show.MovieTitle,
+			&show.StartTime,
+			&show.EndTime,
+		)
+		if err != nil {
+			slog.Error("Scan Failure", "error", err)
+			return 0, nil, err
+		}
+
+		schedule.Shows = append(schedule.Shows, show)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Scan Failure", "error", err)
+		return 0, nil, err
+	}
+
+	return hallID, schedule, nil
+}
+
 func (m *ShowModel) Find(id int) (*Show, error) {
 	query := `SELECT h.theater_id, s.hall_id, h.code,
-	s.movie_id, m.title, m.imdb_link, s.start_time, s.end_time,
-	s.created_at, s.updated_at
+	s.movie_id, m.title, s.start_time, s.end_time,
+	s.layout_version, s.price_tier, s.created_at, s.updated_at
 	FROM shows AS s
-	JOIN movies AS m on m.id = s.movie_id
+	JOIN movies AS m on m.imdb_id = s.movie_id
 	JOIN halls AS h on h.id = s.hall_id
 	JOIN theaters AS t on t.id = h.theater_id
 	WHERE s.id = $1 AND h.deleted_at IS NULL and t.deleted_at IS NULL`
@@ -132,9 +258,10 @@ func (m *ShowModel) Find(id int) (*Show, error) {
 		&show.HallCode,
 		&show.MovieID,
 		&show.MovieTitle,
-		&show.MovieIMDBLink,
 		&show.StartTime,
 		&show.EndTime,
+		&show.LayoutVersion,
+		&show.PriceTier,
 		&show.CreatedAt,
 		&show.UpdatedAt,
 	)
@@ -151,6 +278,53 @@ func (m *ShowModel) Find(id int) (*Show, error) {
 	return show, nil
 }
 
+// FindUpcoming lists the shows of a theater that haven't started yet.
+func (m *ShowModel) FindUpcoming(theaterID int) ([]Show, error) {
+	query := `SELECT s.id, s.hall_id, h.code, s.movie_id, s.start_time, s.end_time,
+	s.layout_version, s.price_tier, s.created_at, s.updated_at
+	FROM shows AS s
+	JOIN halls AS h ON h.id = s.hall_id
+	WHERE h.theater_id = $1 AND h.deleted_at IS NULL AND s.start_time > NOW()
+	ORDER BY s.start_time`
+
+	rows, err := m.db.Query(query, theaterID)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	shows := []Show{}
+	for rows.Next() {
+		show := Show{TheaterID: theaterID}
+		err := rows.Scan(
+			&show.ID,
+			&show.HallID,
+			&show.HallCode,
+			&show.MovieID,
+			&show.StartTime,
+			&show.EndTime,
+			&show.LayoutVersion,
+			&show.PriceTier,
+			&show.CreatedAt,
+			&show.UpdatedAt,
+		)
+		if err != nil {
+			slog.Error("Scan Failure", "error", err)
+			return nil, err
+		}
+
+		shows = append(shows, show)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Scan Failure", "error", err)
+		return nil, err
+	}
+
+	return shows, nil
+}
+
 func (m *ShowModel) Delete(id int) error {
 	query := `DELETE FROM shows WHERE id = $1`
 
@@ -217,9 +391,9 @@ func (f *ShowFilter) Validate(v *validator.Validator) {
 }
 
 func (f *ShowFilter) Build() (string, []any, error) {
-	q := sq.Select(`h.theater_id, s.hall_id, h.code,
-		s.movie_id, m.title, m.imdb_link, s.start_time, s.end_time,
-		s.created_at, s.updated_at`).From(`shows AS s`).Join(`movies AS m on m.id =
+	q := sq.Select(`s.id, h.theater_id, s.hall_id, h.code,
+		s.movie_id, m.title, s.start_time, s.end_time,
+		s.price_tier, s.created_at, s.updated_at`).From(`shows AS s`).Join(`movies AS m on m.imdb_id =
 		s.movie_id`).Join(`halls AS h on h.id = s.hall_id`).Join(`theaters AS t on
 		t.id = h.theater_id`)
 
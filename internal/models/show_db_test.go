@@ -0,0 +1,116 @@
+package models
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestModel connects to the test database migrated by `make app-test`,
+// skipping the test when it isn't configured.
+func newTestModel(t *testing.T) *Model {
+	t.Helper()
+
+	dsn := os.Getenv("DB_DSN_TEST")
+	if dsn == "" {
+		t.Skip("DB_DSN_TEST is not set")
+	}
+
+	m, err := New(dsn)
+	require.NoError(t, err)
+
+	return m
+}
+
+func TestShowModel_CreateFind(t *testing.T) {
+	m := newTestModel(t)
+	db := m.Shows.db
+	suffix := time.Now().UnixNano() % 1_000_000_000
+
+	var userID int
+	err := db.QueryRow(`INSERT INTO users(username, name, role, email, hash)
+	VALUES ($1, 'Show Test', 'manager', $2, '\x00') RETURNING id`,
+		fmt.Sprintf("show%d", suffix),
+		fmt.Sprintf("show%d@example.com", suffix),
+	).Scan(&userID)
+	require.NoError(t, err)
+
+	movieID := fmt.Sprintf("tt%d", suffix)
+	_, err = db.Exec(`INSERT INTO movies(imdb_id, title, year, rated, runtime,
+	genre, director, poster, imdb_rating)
+	VALUES ($1, 'Show Test', 2025, 'PG', '120 min', 'Drama', 'Someone', 'N/A', 7.5)`,
+		movieID,
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		db.Exec(`DELETE FROM movies WHERE imdb_id = $1`, movieID)
+		db.Exec(`DELETE FROM users WHERE id = $1`, userID)
+	})
+
+	theater := &Theater{
+		ManagerID:         userID,
+		Name:              fmt.Sprintf("Cinema %d", suffix),
+		City:              "Cairo",
+		Address:           "Somewhere",
+		Currency:          "USD",
+		TurnaroundMinutes: 15,
+	}
+	require.NoError(t, m.Theaters.Create(theater))
+
+	hall := &Hall{
+		TheaterID: theater.ID,
+		ManagerID: userID,
+		Name:      "Hall A",
+		Code:      "A",
+		Seats: &Seating{
+			Seats: []Seat{
+				{Row: "A", SeatNumber: 1, Column: 1, Category: CategoryStandard},
+				{Row: "A", SeatNumber: 2, Column: 2, Category: CategoryStandard},
+			},
+		},
+	}
+	require.NoError(t, m.Halls.Create(hall))
+
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Second).UTC()
+	show := &Show{
+		TheaterID: theater.ID,
+		HallCode:  hall.Code,
+		MovieID:   movieID,
+		StartTime: start,
+		EndTime:   start.Add(2 * time.Hour),
+		PriceTier: "standard",
+	}
+	require.NoError(t, m.Shows.Create(show))
+	assert.Equal(t, hall.ID, show.HallID)
+	assert.Equal(t, hall.Seats.Version, show.LayoutVersion)
+
+	found, err := m.Shows.Find(show.ID)
+	require.NoError(t, err)
+	assert.Equal(t, theater.ID, found.TheaterID)
+	assert.Equal(t, hall.Code, found.HallCode)
+	assert.Equal(t, movieID, found.MovieID)
+	assert.Equal(t, "Show Test", found.MovieTitle)
+	assert.True(t, start.Equal(found.StartTime))
+
+	overlapping := &Show{
+		TheaterID: theater.ID,
+		HallCode:  hall.Code,
+		MovieID:   movieID,
+		StartTime: show.EndTime.Add(5 * time.Minute),
+		EndTime:   show.EndTime.Add(2 * time.Hour),
+		PriceTier: "standard",
+	}
+	var conflict *ScheduleConflictError
+	assert.ErrorAs(t, m.Shows.Create(overlapping), &conflict)
+
+	shows, err := m.Shows.Search(ShowFilter{TheaterName: &theater.Name})
+	require.NoError(t, err)
+	if assert.Len(t, shows, 1) {
+		assert.Equal(t, show.ID, shows[0].ID)
+	}
+}
@@ -0,0 +1,154 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrSeatUnavailable = errors.New("seat unavailable")
+)
+
+const (
+	SeatAvailable = "available"
+	SeatHeld      = "held"
+	SeatSold      = "sold"
+)
+
+// ShowSeat is a seat of the hall as it is sold for a specific show.
+type ShowSeat struct {
+	ID         int       `json:"id"`
+	ShowID     int       `json:"show_id"`
+	SeatID     int       `json:"seat_id"`
+	Row        string    `json:"row"`
+	SeatNumber int       `json:"seat_number"`
+	Column     int       `json:"column"`
+	Category   string    `json:"category"`
+	Status     string    `json:"status"`
+	UserID     *int      `json:"-"`
+	HoldID     *int      `json:"-"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func (s ShowSeat) IsAvailable() bool {
+	return s.Status == SeatAvailable
+}
+
+type ShowSeatModel struct {
+	db *sql.DB
+}
+
+func (m *ShowSeatModel) FindByShow(showID int) ([]ShowSeat, error) {
+	query := `SELECT id, show_id, seat_id, row, seat_number, grid_column, category,
+	status, user_id, hold_id, created_at, updated_at
+	FROM show_seats
+	WHERE show_id = $1
+	ORDER BY LENGTH(row), row, seat_number`
+
+	rows, err := m.db.Query(query, showID)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanShowSeats(rows)
+}
+
+// CategoryOccupancy counts the seats of a category in a show, and how many
+// of them are held or sold.
+type CategoryOccupancy struct {
+	Category string
+	Seats    int
+	Taken    int
+}
+
+// Percent is the share of the category's seats that are held or sold.
+func (o CategoryOccupancy) Percent() int {
+	if o.Seats == 0 {
+		return 0
+	}
+	return o.Taken * 100 / o.Seats
+}
+
+// FindOccupancy returns the occupancy of every seat category present in each
+// of the given shows. The seats of the hold excludeHoldID, if not 0, count as
+// available.
+func (m *ShowSeatModel) FindOccupancy(showIDs []int, excludeHoldID int) (map[int][]CategoryOccupancy, error) {
+	ids := make([]int64, len(showIDs))
+	for i, id := range showIDs {
+		ids[i] = int64(id)
+	}
+
+	query := `SELECT show_id, category, COUNT(*),
+	COUNT(*) FILTER (WHERE status <> 'available' AND COALESCE(hold_id, 0) <> $2)
+	FROM show_seats
+	WHERE show_id = ANY($1)
+	GROUP BY show_id, category
+	ORDER BY show_id, category`
+
+	rows, err := m.db.Query(query, pq.Array(ids), excludeHoldID)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	occupancy := make(map[int][]CategoryOccupancy, len(showIDs))
+	for rows.Next() {
+		var showID int
+		var o CategoryOccupancy
+		if err := rows.Scan(&showID, &o.Category, &o.Seats, &o.Taken); err != nil {
+			slog.Error("Scan Failure", "error", err)
+			return nil, err
+		}
+
+		occupancy[showID] = append(occupancy[showID], o)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Scan Failure", "error", err)
+		return nil, err
+	}
+
+	return occupancy, nil
+}
+
+func scanShowSeats(rows *sql.Rows) ([]ShowSeat, error) {
+	seats := []ShowSeat{}
+	for rows.Next() {
+		var seat ShowSeat
+		err := rows.Scan(
+			&seat.ID,
+			&seat.ShowID,
+			&seat.SeatID,
+			&seat.Row,
+			&seat.SeatNumber,
+			&seat.Column,
+			&seat.Category,
+			&seat.Status,
+			&seat.UserID,
+			&seat.HoldID,
+			&seat.CreatedAt,
+			&seat.UpdatedAt,
+		)
+		if err != nil {
+			slog.Error("Scan Failure", "error", err)
+			return nil, err
+		}
+
+		seats = append(seats, seat)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Scan Failure", "error", err)
+		return nil, err
+	}
+
+	return seats, nil
+}
@@ -9,8 +9,9 @@ import (
 
 func TestSchedule_IsFree(t *testing.T) {
 	schedule := Schedule{
-		From: time.Date(2025, time.December, 5, 0, 0, 0, 0, time.UTC),
-		To:   time.Date(2025, time.December, 12, 0, 0, 0, 0, time.UTC),
+		From:       time.Date(2025, time.December, 5, 0, 0, 0, 0, time.UTC),
+		To:         time.Date(2025, time.December, 12, 0, 0, 0, 0, time.UTC),
+		Turnaround: 15 * time.Minute,
 	}
 
 	shows := []Show{
@@ -42,6 +43,7 @@ func TestSchedule_IsFree(t *testing.T) {
 		name               string
 		startTime, endTime time.Time
 		want               error
+		wantEarliestStart  time.Time
 	}{
 		{
 			name:      "valid show",
@@ -62,10 +64,38 @@ func TestSchedule_IsFree(t *testing.T) {
 			want:      ErrInvalidSchedule,
 		},
 		{
-			name:      "collision",
-			startTime: time.Date(2025, time.December, 6, 14, 0, 0, 0, time.UTC),
-			endTime:   time.Date(2025, time.December, 6, 17, 0, 0, 0, time.UTC),
-			want:      ErrInvalidSchedule,
+			name:              "collision",
+			startTime:         time.Date(2025, time.December, 6, 14, 0, 0, 0, time.UTC),
+			endTime:           time.Date(2025, time.December, 6, 17, 0, 0, 0, time.UTC),
+			want:              ErrInvalidSchedule,
+			wantEarliestStart: time.Date(2025, time.December, 6, 15, 15, 0, 0, time.UTC),
+		},
+		{
+			name:      "right after the turnaround",
+			startTime: time.Date(2025, time.December, 6, 15, 15, 0, 0, time.UTC),
+			endTime:   time.Date(2025, time.December, 6, 18, 15, 0, 0, time.UTC),
+			want:      nil,
+		},
+		{
+			name:              "back to back leaves no turnaround",
+			startTime:         time.Date(2025, time.December, 6, 15, 0, 0, 0, time.UTC),
+			endTime:           time.Date(2025, time.December, 6, 18, 0, 0, 0, time.UTC),
+			want:              ErrInvalidSchedule,
+			wantEarliestStart: time.Date(2025, time.December, 6, 15, 15, 0, 0, time.UTC),
+		},
+		{
+			name:              "ends within the turnaround before the next show",
+			startTime:         time.Date(2025, time.December, 6, 20, 0, 0, 0, time.UTC),
+			endTime:           time.Date(2025, time.December, 6, 22, 50, 0, 0, time.UTC),
+			want:              ErrInvalidSchedule,
+			wantEarliestStart: time.Date(2025, time.December, 7, 2, 15, 0, 0, time.UTC),
+		},
+		{
+			name:              "gap too short skips to the next one",
+			startTime:         time.Date(2025, time.December, 7, 12, 0, 0, 0, time.UTC),
+			endTime:           time.Date(2025, time.December, 7, 15, 0, 0, 0, time.UTC),
+			want:              ErrInvalidSchedule,
+			wantEarliestStart: time.Date(2025, time.December, 7, 18, 15, 0, 0, time.UTC),
 		},
 	}
 
@@ -77,6 +107,11 @@ func TestSchedule_IsFree(t *testing.T) {
 			})
 			if tc.want != nil {
 				assert.ErrorIs(t, err, ErrInvalidSchedule)
+
+				var conflict *ScheduleConflictError
+				if !tc.wantEarliestStart.IsZero() && assert.ErrorAs(t, err, &conflict) {
+					assert.Equal(t, tc.wantEarliestStart, conflict.EarliestStart)
+				}
 			} else {
 				assert.Nil(t, err)
 			}
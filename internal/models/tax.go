@@ -0,0 +1,167 @@
+package models
+
+import (
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	TaxKindTax = "tax"
+	TaxKindFee = "fee"
+)
+
+const (
+	TaxPerTicket  = "ticket"
+	TaxPerBooking = "booking"
+)
+
+const (
+	TaxOnTickets     = "tickets"
+	TaxOnConcessions = "concessions"
+)
+
+// TaxParts lists the parts of a booking taxes and fees may apply to.
+var TaxParts = []string{
+	TaxOnTickets,
+	TaxOnConcessions,
+}
+
+// TaxRule is a tax or a booking fee of a city. Rate is in basis points (1%
+// is 100) of the parts of the booking listed in AppliesTo. Inclusive taxes
+// are part of the prices already and are only broken out, the others are
+// added on top. Fees may also be a fixed Amount, in the currency's minor
+// unit, per ticket or per booking.
+type TaxRule struct {
+	ID        int       `json:"id"`
+	City      string    `json:"city"`
+	Name      string    `json:"name"`
+	Kind      string    `json:"kind"`
+	Rate      int       `json:"rate"`
+	Amount    int64     `json:"amount"`
+	Per       string    `json:"per"`
+	AppliesTo []string  `json:"applies_to"`
+	Inclusive bool      `json:"inclusive"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TaxLine is a tax or fee charged on a booking. Base is the amount its rate
+// applies to, or how many times a fixed fee is charged.
+type TaxLine struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	Rate      int    `json:"rate"`
+	Base      int64  `json:"base"`
+	Amount    int64  `json:"amount"`
+	Inclusive bool   `json:"inclusive"`
+}
+
+type TaxRuleModel struct {
+	db *sql.DB
+}
+
+// FindByCity returns the taxes and fees of a city, whatever its case.
+func (m *TaxRuleModel) FindByCity(city string) ([]TaxRule, error) {
+	query := `SELECT id, city, name, kind, rate, amount, per, applies_to,
+	inclusive, created_at
+	FROM tax_rules
+	WHERE LOWER(city) = LOWER($1)
+	ORDER BY kind DESC, id`
+
+	rows, err := m.db.Query(query, city)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []TaxRule{}
+	for rows.Next() {
+		var rule TaxRule
+		err := rows.Scan(
+			&rule.ID,
+			&rule.City,
+			&rule.Name,
+			&rule.Kind,
+			&rule.Rate,
+			&rule.Amount,
+			&rule.Per,
+			pq.Array(&rule.AppliesTo),
+			&rule.Inclusive,
+			&rule.CreatedAt,
+		)
+		if err != nil {
+			slog.Error("Scan Failure", "error", err)
+			return nil, err
+		}
+
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Scan Failure", "error", err)
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// Replace swaps the taxes and fees of a city for the given ones. Bookings
+// already made keep the taxes they were charged.
+func (m *TaxRuleModel) Replace(city string, rules []TaxRule) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	query := `DELETE FROM tax_rules WHERE LOWER(city) = LOWER($1)`
+	if _, err := tx.Exec(query, city); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	query = `INSERT INTO tax_rules(city, name, kind, rate, amount, per, applies_to, inclusive)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	RETURNING id, created_at`
+
+	for i := range rules {
+		rule := &rules[i]
+		rule.City = city
+
+		args := []any{rule.City, rule.Name, rule.Kind, rule.Rate, rule.Amount, rule.Per, pq.Array(rule.AppliesTo), rule.Inclusive}
+		if err := tx.QueryRow(query, args...).Scan(&rule.ID, &rule.CreatedAt); err != nil {
+			tx.Rollback()
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// insertTaxLines stores the taxes and fees of a booking as part of an
+// ongoing transaction.
+func insertTaxLines(tx *sql.Tx, booking *Booking) error {
+	query := `INSERT INTO booking_taxes(booking_id, name, kind, rate, base, amount, inclusive)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	for _, line := range booking.Taxes {
+		args := []any{booking.ID, line.Name, line.Kind, line.Rate, line.Base, line.Amount, line.Inclusive}
+		if _, err := tx.Exec(query, args...); err != nil {
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+	}
+
+	return nil
+}
@@ -13,16 +13,28 @@ import (
 	sq "github.com/Masterminds/squirrel"
 )
 
+// DefaultTurnaroundMinutes is the turnaround of theaters that don't set one.
+const DefaultTurnaroundMinutes = 15
+
+// Theater is a cinema of the chain. Its prices, and the bookings made at it,
+// are in its ISO 4217 Currency. TurnaroundMinutes are kept free between two
+// shows in any of its halls that doesn't set its own, and shows are only
+// scheduled within its operating Hours, loaded when finding a single theater.
 type Theater struct {
-	ID        int       `json:"id"`
-	ManagerID int       `json:"manager_id"`
-	Manager   *User     `json:"-"`
-	Name      string    `json:"name"`
-	City      string    `json:"city"`
-	Address   string    `json:"address"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Halls     []Hall    `json:"halls"`
+	ID                int       `json:"id"`
+	ManagerID         int       `json:"manager_id"`
+	Manager           *User     `json:"-"`
+	Name              string    `json:"name"`
+	City              string    `json:"city"`
+	Address           string    `json:"address"`
+	Currency          string    `json:"currency"`
+	TurnaroundMinutes int       `json:"turnaround_minutes"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	Halls             []Hall    `json:"halls"`
+
+	RefundPolicy []RefundRule    `json:"refund_policy"`
+	Hours        *OperatingHours `json:"hours,omitempty"`
 }
 
 func (t Theater) HasHall(code string) bool {
@@ -40,14 +52,16 @@ type TheaterModel struct {
 
 func (m *TheaterModel) Create(theater *Theater) error {
 	query := `INSERT INTO theaters(manager_id, name, city,
-	address) VALUES ($1, $2, $3, $4) RETURNING id, created_at,
-	updated_at`
+	address, currency, turnaround_minutes) VALUES ($1, $2, $3, $4, $5, $6)
+	RETURNING id, created_at, updated_at`
 
 	args := []any{
 		theater.ManagerID,
 		theater.Name,
 		theater.City,
 		theater.Address,
+		theater.Currency,
+		theater.TurnaroundMinutes,
 	}
 
 	err := m.db.QueryRow(query, args...).Scan(
@@ -86,6 +100,8 @@ func (m *TheaterModel) Search(f TheaterFilter) ([]Theater, error) {
 			&theater.Name,
 			&theater.City,
 			&theater.Address,
+			&theater.Currency,
+			&theater.TurnaroundMinutes,
 			&theater.CreatedAt,
 			&theater.UpdatedAt,
 		)
@@ -107,9 +123,10 @@ func (m *TheaterModel) Search(f TheaterFilter) ([]Theater, error) {
 }
 
 func (m *TheaterModel) Find(id int) (*Theater, error) {
-	query := `SELECT t.manager_id, t.name, t.city, t.address, t.created_at,
-	t.updated_at, u.id, u.username, u.email, u.name, u.created_at, u.updated_at,
-	h.id, h.theater_id, h.name, h.code, h.created_at, h.updated_at
+	query := `SELECT t.manager_id, t.name, t.city, t.address, t.currency,
+	t.turnaround_minutes, t.created_at, t.updated_at, u.id, u.username, u.email,
+	u.name, u.created_at, u.updated_at, h.id, h.theater_id, h.name, h.code,
+	h.turnaround_minutes, h.created_at, h.updated_at
 	FROM theaters AS t
 	JOIN users AS u ON u.id = t.manager_id
 	LEFT JOIN halls AS h ON t.id = h.theater_id
@@ -130,12 +147,13 @@ func (m *TheaterModel) Find(id int) (*Theater, error) {
 	}
 
 	type HallDB struct {
-		ID        sql.NullInt32
-		TheaterID sql.NullInt32
-		Name      sql.NullString
-		Code      sql.NullString
-		CreatedAt sql.NullTime
-		UpdatedAt sql.NullTime
+		ID                sql.NullInt32
+		TheaterID         sql.NullInt32
+		Name              sql.NullString
+		Code              sql.NullString
+		TurnaroundMinutes sql.NullInt32
+		CreatedAt         sql.NullTime
+		UpdatedAt         sql.NullTime
 	}
 
 	first := true
@@ -149,6 +167,8 @@ func (m *TheaterModel) Find(id int) (*Theater, error) {
 			&theater.Name,
 			&theater.City,
 			&theater.Address,
+			&theater.Currency,
+			&theater.TurnaroundMinutes,
 			&theater.CreatedAt,
 			&theater.UpdatedAt,
 			&theater.Manager.ID,
@@ -161,6 +181,7 @@ func (m *TheaterModel) Find(id int) (*Theater, error) {
 			&h.TheaterID,
 			&h.Name,
 			&h.Code,
+			&h.TurnaroundMinutes,
 			&h.CreatedAt,
 			&h.UpdatedAt,
 		)
@@ -175,6 +196,10 @@ func (m *TheaterModel) Find(id int) (*Theater, error) {
 			hall.TheaterID = int(h.TheaterID.Int32)
 			hall.Name = h.Name.String
 			hall.Code = h.Code.String
+			if h.TurnaroundMinutes.Valid {
+				minutes := int(h.TurnaroundMinutes.Int32)
+				hall.TurnaroundMinutes = &minutes
+			}
 			hall.CreatedAt = h.CreatedAt.Time
 			hall.UpdatedAt = h.UpdatedAt.Time
 
@@ -186,15 +211,35 @@ func (m *TheaterModel) Find(id int) (*Theater, error) {
 		return nil, ErrNotFound
 	}
 
+	theater.RefundPolicy, err = findRefundRules(m.db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	hours, err := findOperatingHours(m.db, id)
+	if err != nil {
+		return nil, err
+	}
+	theater.Hours = &hours
+
 	return theater, nil
 }
 
 func (m *TheaterModel) Update(theater *Theater) error {
 	query := `UPDATE theaters 
-	SET name = $1, city = $2, address = $3, updated_at = NOW()
-	WHERE id = $4 AND updated_at = $5 AND deleted_at IS NULL
+	SET name = $1, city = $2, address = $3, currency = $4,
+	turnaround_minutes = $5, updated_at = NOW()
+	WHERE id = $6 AND updated_at = $7 AND deleted_at IS NULL
 	RETURNING updated_at`
-	args := []any{theater.Name, theater.City, theater.Address, theater.ID, theater.UpdatedAt}
+	args := []any{
+		theater.Name,
+		theater.City,
+		theater.Address,
+		theater.Currency,
+		theater.TurnaroundMinutes,
+		theater.ID,
+		theater.UpdatedAt,
+	}
 
 	err := m.db.QueryRow(query, args...).Scan(&theater.UpdatedAt)
 	if err != nil {
@@ -210,6 +255,29 @@ func (m *TheaterModel) Update(theater *Theater) error {
 	return nil
 }
 
+// HasAmounts reports whether any amount is stored in the theater's
+// currency: prices, fixed promo codes, concessions or bookings of its shows.
+func (m *TheaterModel) HasAmounts(id int) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM theater_prices WHERE theater_id = $1)
+	OR EXISTS (SELECT 1 FROM price_bounds WHERE theater_id = $1)
+	OR EXISTS (SELECT 1 FROM promo_codes WHERE theater_id = $1 AND kind = 'fixed')
+	OR EXISTS (SELECT 1 FROM concession_items WHERE theater_id = $1)
+	OR EXISTS (
+		SELECT 1 FROM bookings AS b
+		JOIN shows AS s ON s.id = b.show_id
+		JOIN halls AS h ON h.id = s.hall_id
+		WHERE h.theater_id = $1
+	)`
+
+	var exists bool
+	if err := m.db.QueryRow(query, id).Scan(&exists); err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return false, err
+	}
+
+	return exists, nil
+}
+
 func (m *TheaterModel) Delete(id int) error {
 	tx, err := m.db.Begin()
 	if err != nil {
@@ -281,8 +349,8 @@ func (f *TheaterFilter) Validate(v *validator.Validator) {
 }
 
 func (f *TheaterFilter) Build() (string, []any, error) {
-	q := sq.Select(`id, manager_id, name, city, address, created_at,
-		updated_at`).From("theaters").Where("deleted_at IS NULL")
+	q := sq.Select(`id, manager_id, name, city, address, currency,
+		turnaround_minutes, created_at, updated_at`).From("theaters").Where("deleted_at IS NULL")
 
 	if f.Name != nil {
 		q = q.Where(sq.Expr(
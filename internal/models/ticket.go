@@ -0,0 +1,110 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+var (
+	ErrTicketUsed    = errors.New("ticket already used")
+	ErrTicketNotPaid = errors.New("ticket's booking isn't paid")
+)
+
+type Ticket struct {
+	ID         int        `json:"id"`
+	BookingID  int        `json:"booking_id"`
+	ShowID     int        `json:"show_id"`
+	ShowSeatID int        `json:"show_seat_id"`
+	Row        string     `json:"row"`
+	SeatNumber int        `json:"seat_number"`
+	Category   string     `json:"category"`
+	TicketType string     `json:"ticket_type"`
+	Price      int64      `json:"price"`
+	Token      string     `json:"token,omitempty"`
+	UsedAt     *time.Time `json:"used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type TicketModel struct {
+	db *sql.DB
+}
+
+func (m *TicketModel) Find(id int) (*Ticket, error) {
+	query := `SELECT t.booking_id, s.show_id, t.show_seat_id, s.row,
+	s.seat_number, t.category, t.ticket_type, t.price, t.used_at, t.created_at
+	FROM tickets AS t
+	JOIN show_seats AS s ON s.id = t.show_seat_id
+	WHERE t.id = $1`
+
+	ticket := &Ticket{ID: id}
+
+	err := m.db.QueryRow(query, id).Scan(
+		&ticket.BookingID,
+		&ticket.ShowID,
+		&ticket.ShowSeatID,
+		&ticket.Row,
+		&ticket.SeatNumber,
+		&ticket.Category,
+		&ticket.TicketType,
+		&ticket.Price,
+		&ticket.UsedAt,
+		&ticket.CreatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNotFound
+		default:
+			slog.Error("SQL Database Failure", "error", err)
+			return nil, err
+		}
+	}
+
+	return ticket, nil
+}
+
+// MarkUsed records the ticket's admission. A ticket can be used only once;
+// any later attempt fails with ErrTicketUsed.
+func (m *TicketModel) MarkUsed(ticket *Ticket) error {
+	// the booking is checked again here, since it may have been cancelled
+	// since the ticket was looked up.
+	query := `UPDATE tickets
+	SET used_at = NOW()
+	WHERE id = $1 AND used_at IS NULL AND EXISTS (
+		SELECT 1 FROM bookings AS b
+		WHERE b.id = tickets.booking_id AND b.status = 'paid'
+	)
+	RETURNING used_at`
+
+	err := m.db.QueryRow(query, ticket.ID).Scan(&ticket.UsedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return m.notUsable(ticket.ID)
+		default:
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// notUsable tells why a ticket couldn't be marked used.
+func (m *TicketModel) notUsable(id int) error {
+	query := `SELECT used_at IS NOT NULL FROM tickets WHERE id = $1`
+
+	var used bool
+	if err := m.db.QueryRow(query, id).Scan(&used); err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	if used {
+		return ErrTicketUsed
+	}
+
+	return ErrTicketNotPaid
+}
@@ -0,0 +1,285 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrWaitlistEntryClosed = errors.New("waitlist entry is closed")
+)
+
+const (
+	WaitlistWaiting   = "waiting"
+	WaitlistOffered   = "offered"
+	WaitlistFulfilled = "fulfilled"
+	WaitlistLapsed    = "lapsed"
+	WaitlistLeft      = "left"
+)
+
+// WaitlistEntry is a user's place in the queue for seats of a sold out show.
+// When seats are released the entry is offered a hold on them, which the user
+// may check out like any other hold until the offer expires.
+type WaitlistEntry struct {
+	ID             int        `json:"id"`
+	ShowID         int        `json:"show_id"`
+	UserID         int        `json:"user_id"`
+	SeatCount      int        `json:"seat_count"`
+	Status         string     `json:"status"`
+	Position       int        `json:"position,omitempty"`
+	HoldID         *int       `json:"hold_id,omitempty"`
+	OfferExpiresAt *time.Time `json:"offer_expires_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+type WaitlistModel struct {
+	db *sql.DB
+}
+
+// Create enrolls the user at the back of the show's waitlist. A user may only
+// be waiting once per show.
+func (m *WaitlistModel) Create(entry *WaitlistEntry) error {
+	query := `INSERT INTO waitlist_entries(show_id, user_id, seat_count)
+	VALUES ($1, $2, $3)
+	RETURNING id, status, created_at, updated_at`
+
+	err := m.db.QueryRow(query, entry.ShowID, entry.UserID, entry.SeatCount).Scan(
+		&entry.ID,
+		&entry.Status,
+		&entry.CreatedAt,
+		&entry.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "waitlist_entries_show_id_user_id_idx"):
+			return ErrDuplicate
+		default:
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *WaitlistModel) Find(id int) (*WaitlistEntry, error) {
+	query := `SELECT id, show_id, user_id, seat_count, status, hold_id,
+	offer_expires_at, created_at, updated_at
+	FROM waitlist_entries
+	WHERE id = $1`
+
+	var entry WaitlistEntry
+
+	err := m.db.QueryRow(query, id).Scan(
+		&entry.ID,
+		&entry.ShowID,
+		&entry.UserID,
+		&entry.SeatCount,
+		&entry.Status,
+		&entry.HoldID,
+		&entry.OfferExpiresAt,
+		&entry.CreatedAt,
+		&entry.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNotFound
+		default:
+			slog.Error("SQL Database Failure", "error", err)
+			return nil, err
+		}
+	}
+
+	return &entry, nil
+}
+
+// FindByUser lists the user's open waitlist entries along with their
+// position in the queue of their show.
+func (m *WaitlistModel) FindByUser(userID int) ([]WaitlistEntry, error) {
+	query := `SELECT w.id, w.show_id, w.user_id, w.seat_count, w.status,
+	w.hold_id, w.offer_expires_at, w.created_at, w.updated_at,
+	CASE WHEN w.status = 'waiting' THEN (
+		SELECT COUNT(*) FROM waitlist_entries AS q
+		WHERE q.show_id = w.show_id AND q.status = 'waiting' AND q.id <= w.id
+	) ELSE 0 END
+	FROM waitlist_entries AS w
+	WHERE w.user_id = $1 AND w.status IN ('waiting', 'offered')
+	ORDER BY w.created_at`
+
+	return m.query(query, userID)
+}
+
+// FindWaiting lists the entries still waiting for a show, first come first.
+func (m *WaitlistModel) FindWaiting(showID int) ([]WaitlistEntry, error) {
+	query := `SELECT id, show_id, user_id, seat_count, status, hold_id,
+	offer_expires_at, created_at, updated_at,
+	ROW_NUMBER() OVER (ORDER BY id)
+	FROM waitlist_entries
+	WHERE show_id = $1 AND status = 'waiting'
+	ORDER BY id`
+
+	return m.query(query, showID)
+}
+
+// Offer gives a waiting entry an exclusive hold on the given seats. The offer
+// fails with ErrSeatUnavailable if any of the seats was taken meanwhile, and
+// with ErrWaitlistEntryClosed if the entry isn't waiting anymore.
+func (m *WaitlistModel) Offer(entry *WaitlistEntry, hold *Hold, seatIDs []int) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	if err := createHold(tx, hold, seatIDs); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	query := `UPDATE waitlist_entries
+	SET status = 'offered', hold_id = $2, offer_expires_at = $3, updated_at = NOW()
+	WHERE id = $1 AND status = 'waiting'
+	RETURNING status, hold_id, offer_expires_at, updated_at`
+
+	err = tx.QueryRow(query, entry.ID, hold.ID, hold.ExpiresAt).Scan(
+		&entry.Status,
+		&entry.HoldID,
+		&entry.OfferExpiresAt,
+		&entry.UpdatedAt,
+	)
+	if err != nil {
+		tx.Rollback()
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrWaitlistEntryClosed
+		default:
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// Leave takes the entry off the waitlist, releasing the seats of a pending
+// offer.
+func (m *WaitlistModel) Leave(entry *WaitlistEntry) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	query := `UPDATE waitlist_entries
+	SET status = 'left', updated_at = NOW()
+	WHERE id = $1 AND status IN ('waiting', 'offered')
+	RETURNING status, updated_at`
+
+	err = tx.QueryRow(query, entry.ID).Scan(&entry.Status, &entry.UpdatedAt)
+	if err != nil {
+		tx.Rollback()
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrWaitlistEntryClosed
+		default:
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+	}
+
+	if entry.HoldID != nil {
+		query = `UPDATE seat_holds
+		SET status = 'released', updated_at = NOW()
+		WHERE id = $1 AND status = 'active'`
+
+		if _, err := tx.Exec(query, *entry.HoldID); err != nil {
+			tx.Rollback()
+			slog.Error("SQL Database Failure", "error", err)
+			return err
+		}
+
+		if err := releaseHeldSeats(tx, []int{*entry.HoldID}); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		tx.Rollback()
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (m *WaitlistModel) query(query string, args ...any) ([]WaitlistEntry, error) {
+	rows, err := m.db.Query(query, args...)
+	if err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []WaitlistEntry{}
+	for rows.Next() {
+		var entry WaitlistEntry
+		err := rows.Scan(
+			&entry.ID,
+			&entry.ShowID,
+			&entry.UserID,
+			&entry.SeatCount,
+			&entry.Status,
+			&entry.HoldID,
+			&entry.OfferExpiresAt,
+			&entry.CreatedAt,
+			&entry.UpdatedAt,
+			&entry.Position,
+		)
+		if err != nil {
+			slog.Error("Scan Failure", "error", err)
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Scan Failure", "error", err)
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// lapseWaitlistOffers closes the waitlist offers made through the given
+// holds once the holds end without being checked out.
+func lapseWaitlistOffers(tx *sql.Tx, holdIDs []int) error {
+	if len(holdIDs) == 0 {
+		return nil
+	}
+
+	query := `UPDATE waitlist_entries
+	SET status = 'lapsed', updated_at = NOW()
+	WHERE hold_id = ANY($1) AND status = 'offered'`
+
+	if _, err := tx.Exec(query, pq.Array(holdIDs)); err != nil {
+		slog.Error("SQL Database Failure", "error", err)
+		return err
+	}
+
+	return nil
+}
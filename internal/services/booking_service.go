@@ -0,0 +1,438 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+)
+
+var (
+	ErrBookingNotFound       = errors.New("booking not found")
+	ErrBookingNotCancellable = errors.New("booking can't be cancelled")
+	ErrPaymentPending        = errors.New("payment is being confirmed")
+)
+
+const paymentTimeout = 30 * time.Second
+
+// reconcileAttempts and reconcileDelay bound how long a booking whose
+// payment timed out waits for the gateway to confirm it.
+const (
+	reconcileAttempts = 5
+	reconcileDelay    = 10 * time.Second
+)
+
+type BookingService struct {
+	models      *models.Model
+	waitlist    *WaitlistService
+	prices      *PriceService
+	promos      *PromoCodeService
+	loyalty     *LoyaltyService
+	giftCards   *GiftCardService
+	members     *MembershipService
+	concessions *ConcessionService
+	taxes       *TaxService
+	gateway     PaymentGateway
+	signer      *TicketSigner
+}
+
+// Checkout turns the seats of an active hold, and the concessions ordered
+// with them, into a paid booking in the theater's currency. Discounts apply
+// to the tickets only; taxes and fees of the theater's city are worked out on
+// the discounted prices. A failed payment cancels the booking and leaves the
+// hold in place until it expires, so the customer can try again. A payment
+// that times out may still have been captured, so the booking is returned
+// pending along with ErrPaymentPending and settled once the gateway confirms
+// the outcome. Bookings fully covered by a membership, discounts or a gift
+// card aren't charged.
+func (s *BookingService) Checkout(user *models.User, input CheckoutInput) (*models.Booking, error) {
+	hold, err := s.models.Holds.Find(input.HoldID)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, ErrHoldNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	if hold.UserID != user.ID {
+		return nil, fmt.Errorf("%w: hold belongs to another user", ErrUnauthorized)
+	}
+
+	if !hold.IsActive(time.Now()) {
+		return nil, ErrHoldNotActive
+	}
+
+	show, err := s.models.Shows.Find(hold.ShowID)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, ErrShowNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	theater, err := s.models.Theaters.Find(show.TheaterID)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, ErrTheaterNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	// the tickets snapshot their price, so later changes to the theater's
+	// prices don't affect this booking.
+	tickets, amount, err := s.prices.quote(show, hold, input.TicketTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	booking := &models.Booking{
+		UserID:   user.ID,
+		ShowID:   hold.ShowID,
+		HoldID:   hold.ID,
+		Amount:   amount,
+		Currency: theater.Currency,
+		Tickets:  tickets,
+	}
+
+	// tickets covered by a membership take no other discounts.
+	paid := tickets
+	if input.UseMembership {
+		sub, covered, err := s.members.cover(user, tickets)
+		if err != nil {
+			return nil, err
+		}
+
+		paid = make([]models.Ticket, 0, len(tickets)-len(covered))
+		for i, ticket := range tickets {
+			if slices.Contains(covered, i) {
+				booking.MembershipDiscount += ticket.Price
+			} else {
+				paid = append(paid, ticket)
+			}
+		}
+
+		booking.SubscriptionID = &sub.ID
+		booking.MembershipTickets = len(covered)
+		booking.Amount -= booking.MembershipDiscount
+	}
+
+	if input.PromoCode != "" {
+		promo, discount, err := s.promos.apply(input.PromoCode, show, theater.Currency, paid)
+		if err != nil {
+			return nil, err
+		}
+
+		booking.PromoCodeID = &promo.ID
+		booking.Discount = discount
+		booking.Amount -= discount
+	}
+
+	loyalty, err := s.loyalty.apply(user, theater.Currency, paid, booking.Amount, input.RedeemPoints, input.FreeTickets)
+	if err != nil {
+		return nil, err
+	}
+
+	booking.LoyaltyDiscount = loyalty.Discount
+	booking.PointsRedeemed = loyalty.PointsRedeemed
+	booking.PointsEarned = loyalty.PointsEarned
+	booking.Amount -= loyalty.Discount
+
+	concessions, total, err := s.concessions.order(show, input.Concessions)
+	if err != nil {
+		return nil, err
+	}
+
+	taxes, err := s.taxes.quote(theater, taxBase{
+		Tickets:     booking.Amount,
+		TicketCount: len(tickets),
+		Concessions: total,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	booking.Concessions = concessions
+	booking.Taxes = taxes.Lines
+	booking.Amount += total + taxes.Added
+
+	if input.GiftCardCode != "" && booking.Amount > 0 {
+		card, amount, err := s.giftCards.apply(input.GiftCardCode, booking.Currency, booking.Amount)
+		if err != nil {
+			return nil, err
+		}
+
+		booking.GiftCardID = &card.ID
+		booking.GiftCardAmount = amount
+		booking.Amount -= amount
+	}
+
+	if err := s.models.Bookings.Create(booking); err != nil {
+		switch {
+		case errors.Is(err, models.ErrHoldNotActive),
+			errors.Is(err, models.ErrSeatUnavailable):
+			return nil, ErrHoldNotActive
+		case errors.Is(err, models.ErrPromoCodeExhausted):
+			return nil, ErrPromoCodeExhausted
+		case errors.Is(err, models.ErrGiftCardInsufficient):
+			return nil, ErrGiftCardInsufficient
+		case errors.Is(err, models.ErrMembershipInactive):
+			return nil, ErrMembershipInactive
+		case errors.Is(err, models.ErrMembershipExhausted):
+			return nil, fmt.Errorf("%w: entitlement was used meanwhile", ErrMembershipExhausted)
+		case errors.Is(err, models.ErrInsufficientPoints):
+			return nil, fmt.Errorf("%w: points were spent meanwhile", ErrInsufficientPoints)
+		case errors.Is(err, models.ErrPromoCodeNotFound):
+			return nil, ErrPromoCodeNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	var receipt *PaymentReceipt
+	if booking.Amount > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), paymentTimeout)
+		defer cancel()
+
+		receipt, err = s.gateway.Charge(ctx, Charge{
+			Token:          input.PaymentToken,
+			Amount:         booking.Amount,
+			Currency:       booking.Currency,
+			Description:    fmt.Sprintf("booking #%d", booking.ID),
+			IdempotencyKey: bookingChargeKey(booking.ID),
+		})
+		if err != nil {
+			// the gateway may have captured a charge that timed out, so the
+			// booking stays pending until the charge is looked up.
+			if errors.Is(err, ErrPaymentTimeout) || errors.Is(err, context.DeadlineExceeded) {
+				pending := *booking
+				go s.reconcile(&pending)
+
+				return booking, fmt.Errorf("%w: %v", ErrPaymentPending, err)
+			}
+
+			if err := s.models.Bookings.Abandon(booking, err.Error()); err != nil {
+				slog.Error("failed to cancel unpaid booking", "booking", booking.ID, "error", err)
+			}
+			return nil, err
+		}
+	}
+
+	if err := s.complete(booking, receipt); err != nil {
+		return nil, err
+	}
+
+	return booking, nil
+}
+
+// complete marks a booking paid with the charge that paid for it, if any.
+// A charge for a booking that can't be completed is refunded.
+func (s *BookingService) complete(booking *models.Booking, receipt *PaymentReceipt) error {
+	if receipt != nil {
+		booking.PaymentRef = receipt.Reference
+	}
+
+	if err := s.models.Bookings.MarkPaid(booking); err != nil {
+		slog.Error("failed to complete paid booking", "booking", booking.ID, "error", err)
+		if receipt != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), paymentTimeout)
+			defer cancel()
+
+			if err := s.gateway.Refund(ctx, receipt.Reference, receipt.Amount); err != nil {
+				slog.Error("failed to refund payment", "reference", receipt.Reference, "error", err)
+			}
+		}
+		return err
+	}
+
+	s.signTickets(booking)
+
+	return nil
+}
+
+// reconcile settles a pending booking whose charge timed out once the
+// gateway tells whether it went through: a captured charge completes the
+// booking, and a charge that never happened cancels it. Lookups that fail
+// are retried a few times before the booking is left for an operator.
+func (s *BookingService) reconcile(booking *models.Booking) {
+	for attempt := range reconcileAttempts {
+		if attempt > 0 {
+			time.Sleep(reconcileDelay)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), paymentTimeout)
+		receipt, err := s.gateway.Lookup(ctx, bookingChargeKey(booking.ID))
+		cancel()
+
+		switch {
+		case errors.Is(err, ErrPaymentNotFound):
+			if err := s.models.Bookings.Abandon(booking, "payment timed out"); err != nil {
+				slog.Error("failed to cancel unpaid booking", "booking", booking.ID, "error", err)
+			}
+			return
+		case err != nil:
+			slog.Error("failed to look up payment", "booking", booking.ID, "error", err)
+			continue
+		}
+
+		if err := s.complete(booking, receipt); err != nil {
+			slog.Error("failed to reconcile booking", "booking", booking.ID, "error", err)
+		}
+		return
+	}
+
+	slog.Error("booking left pending, payment outcome unknown", "booking", booking.ID)
+}
+
+// bookingChargeKey is the idempotency key of a booking's charge, so that a
+// booking is never charged twice.
+func bookingChargeKey(bookingID int) string {
+	return fmt.Sprintf("booking-%d", bookingID)
+}
+
+func (s *BookingService) Find(user *models.User, bookingId int) (*models.Booking, error) {
+	booking, err := s.models.Bookings.Find(bookingId)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, ErrBookingNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	if booking.UserID != user.ID && user.Role != "admin" {
+		return nil, fmt.Errorf("%w: booking belongs to another user", ErrUnauthorized)
+	}
+
+	s.signTickets(booking)
+
+	return booking, nil
+}
+
+// Cancel cancels a paid booking before its show starts and refunds the
+// customer according to the refund policy of the show's theater. Bookings
+// with checked in tickets can't be cancelled. The refund is paid back once
+// the cancellation is recorded; one the gateway fails to pay stays pending.
+func (s *BookingService) Cancel(user *models.User, bookingId int) (*models.Booking, *Refund, error) {
+	booking, err := s.Find(user, bookingId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if booking.Status != models.BookingPaid {
+		return nil, nil, fmt.Errorf("%w: booking is %v", ErrBookingNotCancellable, booking.Status)
+	}
+
+	show, err := s.models.Shows.Find(booking.ShowID)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, nil, ErrShowNotFound
+		default:
+			return nil, nil, err
+		}
+	}
+
+	notice := time.Until(show.StartTime)
+	if notice <= 0 {
+		return nil, nil, ErrShowStarted
+	}
+
+	rules, err := s.models.RefundPolicies.Find(show.TheaterID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refund := evaluateRefundPolicy(rules, booking.Amount, notice)
+	refund.GiftCardAmount = booking.GiftCardAmount * int64(refund.Percent) / 100
+	reason := fmt.Sprintf("cancelled by customer, %d%% refunded", refund.Percent)
+
+	err = s.models.Bookings.Cancel(booking, refund.Amount, refund.GiftCardAmount, reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrTicketUsed):
+			return nil, nil, fmt.Errorf("%w: tickets already checked in", ErrBookingNotCancellable)
+		case errors.Is(err, models.ErrEditConflict),
+			errors.Is(err, models.ErrInvalidTransition):
+			return nil, nil, fmt.Errorf("%w: booking was modified concurrently", ErrBookingNotCancellable)
+		default:
+			return nil, nil, err
+		}
+	}
+
+	if booking.RefundStatus == models.RefundPending {
+		s.settleRefund(booking, refund.Amount)
+	}
+
+	for i := range booking.Tickets {
+		booking.Tickets[i].Token = ""
+	}
+
+	s.waitlist.OfferReleasedSeats(booking.ShowID)
+
+	return booking, &refund, nil
+}
+
+// settleRefund pays amount back to the customer of a cancelled booking. A
+// refund the gateway fails to pay is left pending for an operator.
+func (s *BookingService) settleRefund(booking *models.Booking, amount int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), paymentTimeout)
+	defer cancel()
+
+	if err := s.gateway.Refund(ctx, booking.PaymentRef, amount); err != nil {
+		slog.Error("refund left pending", "booking", booking.ID, "error", err)
+		return
+	}
+
+	if err := s.models.Bookings.SettleRefund(booking); err != nil {
+		slog.Error("failed to settle refund", "booking", booking.ID, "error", err)
+	}
+}
+
+func (s *BookingService) List(user *models.User) ([]models.Booking, error) {
+	return s.models.Bookings.FindByUser(user.ID)
+}
+
+// signTickets attaches the admission tokens to the tickets of paid bookings.
+func (s *BookingService) signTickets(booking *models.Booking) {
+	if booking.Status != models.BookingPaid {
+		return
+	}
+
+	for i := range booking.Tickets {
+		ticket := &booking.Tickets[i]
+		ticket.Token = s.signer.Sign(ticket.ID, ticket.ShowID)
+	}
+}
+
+type CheckoutInput struct {
+	HoldID       int
+	PaymentToken string
+	// TicketTypes maps the hold's show seat IDs to their ticket type. Seats
+	// not listed get adult tickets.
+	TicketTypes map[int]string
+	// PromoCode is redeemed along with the booking, if set.
+	PromoCode string
+	// RedeemPoints and FreeTickets spend loyalty points on a discount and on
+	// free tickets.
+	RedeemPoints int
+	FreeTickets  int
+	// GiftCardCode pays for as much of the booking as its balance covers, if
+	// set.
+	GiftCardCode string
+	// UseMembership makes the user's membership pay for the tickets it
+	// covers.
+	UseMembership bool
+	// Concessions are picked up at the show.
+	Concessions []ConcessionOrderInput
+}
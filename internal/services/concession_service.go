@@ -0,0 +1,329 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+)
+
+var (
+	ErrConcessionNotFound    = errors.New("concession not found")
+	ErrConcessionUnavailable = errors.New("concession unavailable")
+	ErrInvalidCombo          = errors.New("invalid combo")
+	ErrConcessionInUse       = errors.New("concession item is part of a combo")
+	ErrNoConcessionOrder     = errors.New("no concessions to pick up")
+)
+
+// pickupWindow is how far ahead the pending concession orders are listed by
+// default.
+const pickupWindow = 24 * time.Hour
+
+type ConcessionService struct {
+	models *models.Model
+}
+
+// Menu returns the concessions catalog of a theater, with the concessions
+// currently unavailable marked as such.
+func (s *ConcessionService) Menu(theaterId int) ([]models.Concession, error) {
+	if _, err := findTheater(s.models, theaterId); err != nil {
+		return nil, err
+	}
+
+	return s.models.Concessions.FindByTheater(theaterId)
+}
+
+// Create adds a concession to the catalog of a theater. Combos are made of
+// the theater's items.
+func (s *ConcessionService) Create(user *models.User, theaterId int, input CreateConcessionInput) (*models.Concession, error) {
+	if _, err := findManagedTheater(s.models, user, theaterId, "concessions are managed by the theater manager only"); err != nil {
+		return nil, err
+	}
+
+	item := &models.Concession{
+		TheaterID:   theaterId,
+		Name:        input.Name,
+		Description: input.Description,
+		Kind:        input.Kind,
+		Price:       input.Price,
+		Available:   true,
+		Components:  input.Components,
+	}
+
+	if item.Kind == models.ConcessionCombo {
+		if err := s.checkComboComponents(item); err != nil {
+			return nil, err
+		}
+	} else {
+		item.Components = nil
+	}
+
+	if err := s.models.Concessions.Create(item); err != nil {
+		switch {
+		case errors.Is(err, models.ErrDuplicate):
+			return nil, fmt.Errorf("%w: concession %v", ErrDuplicate, item.Name)
+		case errors.Is(err, models.ErrNotFound):
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCombo, err)
+		default:
+			return nil, err
+		}
+	}
+
+	return item, nil
+}
+
+// Update changes a concession of a theater, or marks it unavailable. Bookings
+// that ordered it keep the price they paid.
+func (s *ConcessionService) Update(user *models.User, theaterId, itemId int, input UpdateConcessionInput) (*models.Concession, error) {
+	if _, err := findManagedTheater(s.models, user, theaterId, "concessions are managed by the theater manager only"); err != nil {
+		return nil, err
+	}
+
+	item, err := s.findConcession(theaterId, itemId)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Name != nil {
+		item.Name = *input.Name
+	}
+	if input.Description != nil {
+		item.Description = *input.Description
+	}
+	if input.Price != nil {
+		item.Price = *input.Price
+	}
+	if input.Available != nil {
+		item.Available = *input.Available
+	}
+	if input.Components != nil {
+		if item.Kind != models.ConcessionCombo {
+			return nil, fmt.Errorf("%w: only combos have components", ErrInvalidCombo)
+		}
+
+		item.Components = input.Components
+		if err := s.checkComboComponents(item); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.models.Concessions.Update(item); err != nil {
+		switch {
+		case errors.Is(err, models.ErrEditConflict):
+			return nil, ErrEditConflict
+		case errors.Is(err, models.ErrDuplicate):
+			return nil, fmt.Errorf("%w: concession %v", ErrDuplicate, item.Name)
+		case errors.Is(err, models.ErrNotFound):
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCombo, err)
+		default:
+			return nil, err
+		}
+	}
+
+	return item, nil
+}
+
+func (s *ConcessionService) Delete(user *models.User, theaterId, itemId int) error {
+	if _, err := findManagedTheater(s.models, user, theaterId, "concessions are managed by the theater manager only"); err != nil {
+		return err
+	}
+
+	if _, err := s.findConcession(theaterId, itemId); err != nil {
+		return err
+	}
+
+	if err := s.models.Concessions.Delete(itemId); err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return ErrConcessionNotFound
+		case errors.Is(err, models.ErrConcessionInUse):
+			return ErrConcessionInUse
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Pending lists the concession orders of a theater waiting to be picked up,
+// for the shows running between from and until, by show start time. By
+// default, the shows running from now until pickupWindow later are listed.
+func (s *ConcessionService) Pending(user *models.User, theaterId int, from, until *time.Time) ([]models.ConcessionOrder, error) {
+	if _, err := findManagedTheater(s.models, user, theaterId, "concessions are managed by the theater manager only"); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	if from != nil {
+		start = *from
+	}
+
+	end := start.Add(pickupWindow)
+	if until != nil {
+		end = *until
+	}
+
+	return s.models.Concessions.FindPending(theaterId, start, end)
+}
+
+// Collect marks the concessions of a booking as picked up.
+func (s *ConcessionService) Collect(user *models.User, theaterId, bookingId int) ([]models.ConcessionLine, error) {
+	if _, err := findManagedTheater(s.models, user, theaterId, "concessions are managed by the theater manager only"); err != nil {
+		return nil, err
+	}
+
+	lines, err := s.models.Concessions.Collect(theaterId, bookingId)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, ErrNoConcessionOrder
+		default:
+			return nil, err
+		}
+	}
+
+	return lines, nil
+}
+
+// order prices the concessions ordered along with a booking from the
+// catalog of the show's theater.
+func (s *ConcessionService) order(show *models.Show, orders []ConcessionOrderInput) ([]models.ConcessionLine, int64, error) {
+	if len(orders) == 0 {
+		return []models.ConcessionLine{}, 0, nil
+	}
+
+	catalog, err := s.models.Concessions.FindByTheater(show.TheaterID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return priceConcessions(catalog, orders)
+}
+
+// checkComboComponents makes sure a combo is made of the items of its
+// theater, each listed once.
+func (s *ConcessionService) checkComboComponents(combo *models.Concession) error {
+	if len(combo.Components) == 0 {
+		return fmt.Errorf("%w: a combo needs at least one item", ErrInvalidCombo)
+	}
+
+	catalog, err := s.models.Concessions.FindByTheater(combo.TheaterID)
+	if err != nil {
+		return err
+	}
+
+	return validateCombo(catalog, combo.Components)
+}
+
+func (s *ConcessionService) findConcession(theaterId, itemId int) (*models.Concession, error) {
+	item, err := s.models.Concessions.Find(itemId)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, ErrConcessionNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	if item.TheaterID != theaterId {
+		return nil, ErrConcessionNotFound
+	}
+
+	return item, nil
+}
+
+// validateCombo checks that the components of a combo are distinct items of
+// the catalog.
+func validateCombo(catalog []models.Concession, components []models.ComboComponent) error {
+	items := make(map[int]models.Concession, len(catalog))
+	for _, item := range catalog {
+		items[item.ID] = item
+	}
+
+	seen := make(map[int]bool, len(components))
+	for _, component := range components {
+		item, ok := items[component.ItemID]
+		if !ok {
+			return fmt.Errorf("%w: item %v not found", ErrInvalidCombo, component.ItemID)
+		}
+		if item.Kind != models.ConcessionItem {
+			return fmt.Errorf("%w: %v is a combo", ErrInvalidCombo, item.Name)
+		}
+		if seen[component.ItemID] {
+			return fmt.Errorf("%w: %v listed twice", ErrInvalidCombo, item.Name)
+		}
+		seen[component.ItemID] = true
+	}
+
+	return nil
+}
+
+// priceConcessions turns the concessions ordered into booking lines at the
+// catalog's prices, and returns their total. Combos are only available while
+// all their items are.
+func priceConcessions(catalog []models.Concession, orders []ConcessionOrderInput) ([]models.ConcessionLine, int64, error) {
+	items := make(map[int]models.Concession, len(catalog))
+	for _, item := range catalog {
+		items[item.ID] = item
+	}
+
+	available := func(item models.Concession) bool {
+		if !item.Available {
+			return false
+		}
+		for _, component := range item.Components {
+			if !items[component.ItemID].Available {
+				return false
+			}
+		}
+		return true
+	}
+
+	lines := make([]models.ConcessionLine, 0, len(orders))
+	var total int64
+	for _, order := range orders {
+		item, ok := items[order.ItemID]
+		if !ok {
+			return nil, 0, fmt.Errorf("%w: item %v", ErrConcessionNotFound, order.ItemID)
+		}
+
+		if !available(item) {
+			return nil, 0, fmt.Errorf("%w: %v", ErrConcessionUnavailable, item.Name)
+		}
+
+		lines = append(lines, models.ConcessionLine{
+			ItemID:    &item.ID,
+			Name:      item.Name,
+			Quantity:  order.Quantity,
+			UnitPrice: item.Price,
+		})
+		total += item.Price * int64(order.Quantity)
+	}
+
+	return lines, total, nil
+}
+
+type CreateConcessionInput struct {
+	Name        string
+	Description string
+	Kind        string
+	Price       int64
+	Components  []models.ComboComponent
+}
+
+type UpdateConcessionInput struct {
+	Name        *string
+	Description *string
+	Price       *int64
+	Available   *bool
+	Components  []models.ComboComponent
+}
+
+// ConcessionOrderInput is a concession ordered along with a booking.
+type ConcessionOrderInput struct {
+	ItemID   int
+	Quantity int
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var testConcessions = []models.Concession{
+	{ID: 1, Name: "Popcorn", Kind: models.ConcessionItem, Price: 4000, Available: true},
+	{ID: 2, Name: "Soda", Kind: models.ConcessionItem, Price: 2500, Available: true},
+	{ID: 3, Name: "Nachos", Kind: models.ConcessionItem, Price: 5000, Available: false},
+	{
+		ID: 4, Name: "Movie Combo", Kind: models.ConcessionCombo, Price: 5500, Available: true,
+		Components: []models.ComboComponent{{ItemID: 1, Quantity: 1}, {ItemID: 2, Quantity: 1}},
+	},
+	{
+		ID: 5, Name: "Nachos Combo", Kind: models.ConcessionCombo, Price: 6500, Available: true,
+		Components: []models.ComboComponent{{ItemID: 3, Quantity: 1}, {ItemID: 2, Quantity: 1}},
+	},
+}
+
+func TestPriceConcessions(t *testing.T) {
+	tests := []struct {
+		name      string
+		orders    []ConcessionOrderInput
+		wantTotal int64
+		wantLines int
+		err       error
+	}{
+		{
+			name:      "items and combos",
+			orders:    []ConcessionOrderInput{{ItemID: 1, Quantity: 2}, {ItemID: 4, Quantity: 1}},
+			wantTotal: 13500,
+			wantLines: 2,
+		},
+		{
+			name:   "unknown item",
+			orders: []ConcessionOrderInput{{ItemID: 9, Quantity: 1}},
+			err:    ErrConcessionNotFound,
+		},
+		{
+			name:   "unavailable item",
+			orders: []ConcessionOrderInput{{ItemID: 3, Quantity: 1}},
+			err:    ErrConcessionUnavailable,
+		},
+		{
+			name:   "combo with an unavailable item",
+			orders: []ConcessionOrderInput{{ItemID: 5, Quantity: 1}},
+			err:    ErrConcessionUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines, total, err := priceConcessions(testConcessions, tt.orders)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantTotal, total)
+			assert.Len(t, lines, tt.wantLines)
+			for i, line := range lines {
+				assert.Equal(t, tt.orders[i].ItemID, *line.ItemID)
+				assert.Equal(t, tt.orders[i].Quantity, line.Quantity)
+			}
+		})
+	}
+}
+
+func TestValidateCombo(t *testing.T) {
+	tests := []struct {
+		name       string
+		components []models.ComboComponent
+		err        error
+	}{
+		{
+			name:       "valid",
+			components: []models.ComboComponent{{ItemID: 1, Quantity: 2}, {ItemID: 3, Quantity: 1}},
+		},
+		{
+			name:       "unknown item",
+			components: []models.ComboComponent{{ItemID: 9, Quantity: 1}},
+			err:        ErrInvalidCombo,
+		},
+		{
+			name:       "combo in a combo",
+			components: []models.ComboComponent{{ItemID: 4, Quantity: 1}},
+			err:        ErrInvalidCombo,
+		},
+		{
+			name:       "item listed twice",
+			components: []models.ComboComponent{{ItemID: 1, Quantity: 1}, {ItemID: 1, Quantity: 1}},
+			err:        ErrInvalidCombo,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCombo(testConcessions, tt.components)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
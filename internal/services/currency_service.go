@@ -0,0 +1,130 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+	"github.com/AhmadAbdelrazik/showtime/pkg/money"
+)
+
+var (
+	ErrCurrencyMismatch     = errors.New("currency mismatch")
+	ErrNoExchangeRate       = errors.New("no exchange rate")
+	ErrInvalidExchangeRates = errors.New("invalid exchange rates")
+	ErrCurrencyInUse        = errors.New("currency is in use")
+)
+
+// CurrencyService keeps the exchange rates between the base currency, the
+// one chain-wide amounts such as membership prices are set in, and the
+// currencies of the theaters.
+type CurrencyService struct {
+	models *models.Model
+	base   string
+}
+
+// Base returns the base currency.
+func (s *CurrencyService) Base() string {
+	return s.base
+}
+
+// Rates returns the exchange rates from the base currency.
+func (s *CurrencyService) Rates() ([]models.ExchangeRate, error) {
+	return s.models.ExchangeRates.FindAll()
+}
+
+// UpdateRates replaces the exchange rates from the base currency.
+func (s *CurrencyService) UpdateRates(user *models.User, rates []models.ExchangeRate) ([]models.ExchangeRate, error) {
+	if user.Role != "admin" {
+		return nil, fmt.Errorf("%w: exchange rates can be updated by admins only", ErrUnauthorized)
+	}
+
+	if _, err := newConverter(s.base, rates); err != nil {
+		return nil, err
+	}
+
+	if err := s.models.ExchangeRates.Replace(rates); err != nil {
+		return nil, err
+	}
+
+	return rates, nil
+}
+
+// converter loads the current exchange rates, to convert amounts at them.
+func (s *CurrencyService) converter() (*converter, error) {
+	rates, err := s.models.ExchangeRates.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	return newConverter(s.base, rates)
+}
+
+// fromBase converts an amount in the base currency to another currency.
+func (s *CurrencyService) fromBase(amount int64, currency string) (int64, error) {
+	if currency == s.base {
+		return amount, nil
+	}
+
+	c, err := s.converter()
+	if err != nil {
+		return 0, err
+	}
+
+	converted, err := c.convert(money.New(amount, s.base), currency)
+	if err != nil {
+		return 0, err
+	}
+
+	return converted.Amount, nil
+}
+
+// converter converts amounts between currencies through the base currency.
+type converter struct {
+	base  string
+	rates map[string]*big.Rat
+}
+
+// newConverter checks that the exchange rates are from the base currency to
+// distinct known currencies, each at a positive rate.
+func newConverter(base string, rates []models.ExchangeRate) (*converter, error) {
+	c := &converter{
+		base:  base,
+		rates: map[string]*big.Rat{base: big.NewRat(1, 1)},
+	}
+
+	for _, rate := range rates {
+		if !money.IsKnown(rate.Currency) {
+			return nil, fmt.Errorf("%w: %v: %v", ErrInvalidExchangeRates, rate.Currency, money.ErrUnknownCurrency)
+		}
+		if _, ok := c.rates[rate.Currency]; ok {
+			return nil, fmt.Errorf("%w: %v listed twice or is the base currency", ErrInvalidExchangeRates, rate.Currency)
+		}
+
+		r, err := money.ParseRate(rate.Rate)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v: %v", ErrInvalidExchangeRates, rate.Currency, err)
+		}
+		c.rates[rate.Currency] = r
+	}
+
+	return c, nil
+}
+
+func (c *converter) convert(m money.Money, currency string) (money.Money, error) {
+	if m.Currency == currency {
+		return m, nil
+	}
+
+	from, ok := c.rates[m.Currency]
+	if !ok {
+		return money.Money{}, fmt.Errorf("%w: %v to %v", ErrNoExchangeRate, c.base, m.Currency)
+	}
+	to, ok := c.rates[currency]
+	if !ok {
+		return money.Money{}, fmt.Errorf("%w: %v to %v", ErrNoExchangeRate, c.base, currency)
+	}
+
+	return money.Convert(m, currency, new(big.Rat).Quo(to, from)), nil
+}
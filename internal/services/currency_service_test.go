@@ -0,0 +1,162 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+	"github.com/AhmadAbdelrazik/showtime/pkg/money"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewConverter(t *testing.T) {
+	tests := []struct {
+		name    string
+		rates   []models.ExchangeRate
+		wantErr error
+	}{
+		{
+			name:  "no rates",
+			rates: nil,
+		},
+		{
+			name:  "valid rates",
+			rates: []models.ExchangeRate{{Currency: "EGP", Rate: "48.35"}, {Currency: "JPY", Rate: "150"}},
+		},
+		{
+			name:    "unknown currency",
+			rates:   []models.ExchangeRate{{Currency: "XYZ", Rate: "2"}},
+			wantErr: ErrInvalidExchangeRates,
+		},
+		{
+			name:    "currency listed twice",
+			rates:   []models.ExchangeRate{{Currency: "EGP", Rate: "48"}, {Currency: "EGP", Rate: "49"}},
+			wantErr: ErrInvalidExchangeRates,
+		},
+		{
+			name:    "rate for the base currency",
+			rates:   []models.ExchangeRate{{Currency: "USD", Rate: "1"}},
+			wantErr: ErrInvalidExchangeRates,
+		},
+		{
+			name:    "zero rate",
+			rates:   []models.ExchangeRate{{Currency: "EGP", Rate: "0"}},
+			wantErr: ErrInvalidExchangeRates,
+		},
+		{
+			name:    "rate is not a number",
+			rates:   []models.ExchangeRate{{Currency: "EGP", Rate: "abc"}},
+			wantErr: ErrInvalidExchangeRates,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newConverter("USD", tt.rates)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestConverterConvert(t *testing.T) {
+	c, err := newConverter("USD", []models.ExchangeRate{
+		{Currency: "EGP", Rate: "50"},
+		{Currency: "JPY", Rate: "150"},
+		{Currency: "KWD", Rate: "0.3"},
+	})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		from     money.Money
+		currency string
+		want     money.Money
+		wantErr  error
+	}{
+		{
+			name:     "same currency",
+			from:     money.New(1234, "EGP"),
+			currency: "EGP",
+			want:     money.New(1234, "EGP"),
+		},
+		{
+			name:     "from the base currency",
+			from:     money.New(1000, "USD"),
+			currency: "EGP",
+			want:     money.New(50000, "EGP"),
+		},
+		{
+			name:     "to the base currency",
+			from:     money.New(50000, "EGP"),
+			currency: "USD",
+			want:     money.New(1000, "USD"),
+		},
+		{
+			name:     "between two currencies through the base currency",
+			from:     money.New(15000, "EGP"),
+			currency: "JPY",
+			want:     money.New(450, "JPY"),
+		},
+		{
+			name:     "to a currency with three decimals",
+			from:     money.New(1000, "USD"),
+			currency: "KWD",
+			want:     money.New(3000, "KWD"),
+		},
+		{
+			name:     "rounds half away from zero",
+			from:     money.New(25, "EGP"),
+			currency: "USD",
+			want:     money.New(1, "USD"),
+		},
+		{
+			name:     "negative amounts round away from zero",
+			from:     money.New(-25, "EGP"),
+			currency: "USD",
+			want:     money.New(-1, "USD"),
+		},
+		{
+			name:     "missing rate",
+			from:     money.New(1000, "USD"),
+			currency: "GBP",
+			wantErr:  ErrNoExchangeRate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.convert(tt.from, tt.currency)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewSalesReport(t *testing.T) {
+	c, err := newConverter("USD", []models.ExchangeRate{{Currency: "EGP", Rate: "50"}})
+	assert.NoError(t, err)
+
+	sales := []models.TheaterSales{
+		{TheaterID: 1, TheaterName: "Downtown", Currency: "USD", Bookings: 3, Tickets: 7, Gross: 10000, Refunded: 2000},
+		{TheaterID: 2, TheaterName: "Nile", Currency: "EGP", Bookings: 5, Tickets: 9, Gross: 250025, Refunded: 0},
+	}
+
+	report, err := newSalesReport(sales, c, "USD")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "USD", report.Currency)
+	assert.Len(t, report.Theaters, 2)
+	assert.Equal(t, newSalesTotals(250025, 0, "EGP"), report.Theaters[1].Sales)
+	assert.Equal(t, newSalesTotals(5001, 0, "USD"), report.Theaters[1].Converted)
+	assert.Equal(t, newSalesTotals(15001, 2000, "USD"), report.Total)
+
+	_, err = newSalesReport(sales, c, "GBP")
+	assert.ErrorIs(t, err, ErrNoExchangeRate)
+}
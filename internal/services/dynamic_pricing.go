@@ -0,0 +1,91 @@
+package services
+
+import (
+	"time"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+)
+
+// simulatedOccupancies and simulatedLeadHours are the grid a price curve is
+// previewed on unless the manager asks for other points.
+var (
+	simulatedOccupancies = []int{0, 10, 20, 30, 40, 50, 60, 70, 80, 90, 100}
+	simulatedLeadHours   = []int{168, 72, 48, 24, 12, 6, 3, 1}
+)
+
+// PriceSimulation previews the adult price of a seat category under a price
+// curve for a grid of occupancies and times before the show.
+type PriceSimulation struct {
+	Category    string           `json:"category"`
+	Tier        string           `json:"tier"`
+	StaticPrice int64            `json:"static_price"`
+	Prices      []SimulatedPrice `json:"prices"`
+}
+
+type SimulatedPrice struct {
+	Occupancy   int   `json:"occupancy"`
+	HoursBefore int   `json:"hours_before"`
+	Price       int64 `json:"price"`
+}
+
+// dynamicPrice adjusts the static price of a seat category by the curve's
+// occupancy and lead time rates, then bounds it by the category's floor and
+// ceiling. Occupancy is the percentage of the category's seats already held
+// or sold and lead is the time left until the show starts.
+func dynamicPrice(curve models.PriceCurve, category string, static int64, occupancy int, lead time.Duration) int64 {
+	if !curve.IsDynamic() {
+		return static
+	}
+
+	price := static * int64(occupancyRate(curve.Occupancy, occupancy)) * int64(leadTimeRate(curve.LeadTime, lead)) / 10000
+
+	if floor, ok := curve.Floors[category]; ok && price < floor {
+		price = floor
+	}
+	if ceiling, ok := curve.Ceilings[category]; ok && price > ceiling {
+		price = ceiling
+	}
+
+	return price
+}
+
+// occupancyRate is the percent of the point with the highest threshold the
+// occupancy has reached, or 100 when it reached none.
+func occupancyRate(points []models.CurvePoint, occupancy int) int {
+	rate, best := 100, -1
+	for _, p := range points {
+		if p.Threshold <= occupancy && p.Threshold > best {
+			rate, best = p.Percent, p.Threshold
+		}
+	}
+	return rate
+}
+
+// leadTimeRate is the percent of the point with the lowest threshold the show
+// starts within, or 100 when the show is further away than every point.
+func leadTimeRate(points []models.CurvePoint, lead time.Duration) int {
+	rate, best := 100, -1
+	for _, p := range points {
+		within := lead <= time.Duration(p.Threshold)*time.Hour
+		if within && (best == -1 || p.Threshold < best) {
+			rate, best = p.Percent, p.Threshold
+		}
+	}
+	return rate
+}
+
+// simulatePriceCurve prices a seat category under the curve for every
+// combination of the given occupancies and hours before the show.
+func simulatePriceCurve(curve models.PriceCurve, category string, static int64, occupancies, leadHours []int) []SimulatedPrice {
+	prices := make([]SimulatedPrice, 0, len(occupancies)*len(leadHours))
+	for _, hours := range leadHours {
+		for _, occupancy := range occupancies {
+			prices = append(prices, SimulatedPrice{
+				Occupancy:   occupancy,
+				HoursBefore: hours,
+				Price:       dynamicPrice(curve, category, static, occupancy, time.Duration(hours)*time.Hour),
+			})
+		}
+	}
+	return prices
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDynamicPrice(t *testing.T) {
+	curve := models.PriceCurve{
+		Occupancy: []models.CurvePoint{
+			{Threshold: 80, Percent: 150},
+			{Threshold: 50, Percent: 120},
+		},
+		LeadTime: []models.CurvePoint{
+			{Threshold: 24, Percent: 110},
+			{Threshold: 2, Percent: 90},
+		},
+		Floors:   map[string]int64{models.CategoryStandard: 9500},
+		Ceilings: map[string]int64{models.CategoryStandard: 16000},
+	}
+
+	tests := []struct {
+		name      string
+		curve     models.PriceCurve
+		category  string
+		occupancy int
+		lead      time.Duration
+		want      int64
+	}{
+		{
+			name:      "static without a curve",
+			curve:     models.PriceCurve{},
+			category:  models.CategoryStandard,
+			occupancy: 95,
+			lead:      time.Hour,
+			want:      10000,
+		},
+		{
+			name:      "empty show far ahead",
+			curve:     curve,
+			category:  models.CategoryVIP,
+			occupancy: 0,
+			lead:      7 * 24 * time.Hour,
+			want:      10000,
+		},
+		{
+			name:      "occupancy step reached exactly",
+			curve:     curve,
+			category:  models.CategoryVIP,
+			occupancy: 50,
+			lead:      7 * 24 * time.Hour,
+			want:      12000,
+		},
+		{
+			name:      "highest occupancy step wins",
+			curve:     curve,
+			category:  models.CategoryVIP,
+			occupancy: 90,
+			lead:      7 * 24 * time.Hour,
+			want:      15000,
+		},
+		{
+			name:      "occupancy and lead time combine",
+			curve:     curve,
+			category:  models.CategoryVIP,
+			occupancy: 60,
+			lead:      12 * time.Hour,
+			want:      13200,
+		},
+		{
+			name:      "closest lead time step wins",
+			curve:     curve,
+			category:  models.CategoryVIP,
+			occupancy: 0,
+			lead:      time.Hour,
+			want:      9000,
+		},
+		{
+			name:      "floor",
+			curve:     curve,
+			category:  models.CategoryStandard,
+			occupancy: 0,
+			lead:      time.Hour,
+			want:      9500,
+		},
+		{
+			name:      "ceiling",
+			curve:     curve,
+			category:  models.CategoryStandard,
+			occupancy: 100,
+			lead:      12 * time.Hour,
+			want:      16000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, dynamicPrice(tt.curve, tt.category, 10000, tt.occupancy, tt.lead))
+		})
+	}
+}
+
+func TestSimulatePriceCurve(t *testing.T) {
+	curve := models.PriceCurve{
+		Occupancy: []models.CurvePoint{{Threshold: 50, Percent: 200}},
+	}
+
+	prices := simulatePriceCurve(curve, models.CategoryStandard, 1000, []int{0, 50}, []int{24, 1})
+
+	assert.Equal(t, []SimulatedPrice{
+		{Occupancy: 0, HoursBefore: 24, Price: 1000},
+		{Occupancy: 50, HoursBefore: 24, Price: 2000},
+		{Occupancy: 0, HoursBefore: 1, Price: 1000},
+		{Occupancy: 50, HoursBefore: 1, Price: 2000},
+	}, prices)
+}
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+)
+
+var (
+	ErrGiftCardNotFound     = errors.New("gift card not found")
+	ErrGiftCardExpired      = errors.New("gift card expired")
+	ErrGiftCardEmpty        = errors.New("gift card has no balance left")
+	ErrGiftCardInactive     = errors.New("gift card can't be spent")
+	ErrGiftCardInsufficient = errors.New("gift card balance changed, try again")
+)
+
+// giftCardAlphabet leaves out the letters and digits that are easily
+// confused with each other. 16 characters of it make 80 random bits.
+const (
+	giftCardAlphabet   = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	giftCardCodeLength = 16
+	giftCardGroupSize  = 4
+)
+
+type GiftCardService struct {
+	models   *models.Model
+	gateway  PaymentGateway
+	validity time.Duration
+	currency string
+}
+
+// Purchase charges the customer for a new gift card of the given amount, in
+// the base currency unless another one is given. The card is stored pending
+// before it's charged for, and can't be spent until the charge is captured.
+func (s *GiftCardService) Purchase(user *models.User, input PurchaseGiftCardInput) (*models.GiftCard, error) {
+	code, err := newGiftCardCode()
+	if err != nil {
+		return nil, err
+	}
+
+	currency := input.Currency
+	if currency == "" {
+		currency = s.currency
+	}
+
+	card := &models.GiftCard{
+		Code:           code,
+		PurchasedBy:    &user.ID,
+		InitialBalance: input.Amount,
+		Currency:       currency,
+		Status:         models.GiftCardPending,
+		ExpiresAt:      time.Now().Add(s.validity),
+	}
+
+	if err := s.models.GiftCards.Create(card); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), paymentTimeout)
+	defer cancel()
+
+	receipt, err := s.gateway.Charge(ctx, Charge{
+		Token:          input.PaymentToken,
+		Amount:         card.InitialBalance,
+		Currency:       card.Currency,
+		Description:    "gift card",
+		IdempotencyKey: giftCardChargeKey(card.ID),
+	})
+	if err != nil {
+		// the gateway may have captured a charge that timed out, so the
+		// card stays pending until the charge is looked up.
+		if errors.Is(err, ErrPaymentTimeout) || errors.Is(err, context.DeadlineExceeded) {
+			pending := *card
+			go s.reconcile(&pending)
+
+			return card, fmt.Errorf("%w: %v", ErrPaymentPending, err)
+		}
+
+		if err := s.models.GiftCards.Void(card); err != nil {
+			slog.Error("failed to void unpaid gift card", "gift_card", card.ID, "error", err)
+		}
+		return nil, err
+	}
+
+	if err := s.activate(card, receipt); err != nil {
+		return nil, err
+	}
+
+	return card, nil
+}
+
+// activate makes a gift card spendable with the charge that paid for it. A
+// charge for a card that can't be activated is refunded.
+func (s *GiftCardService) activate(card *models.GiftCard, receipt *PaymentReceipt) error {
+	card.PaymentRef = receipt.Reference
+
+	if err := s.models.GiftCards.Activate(card); err != nil {
+		slog.Error("failed to issue paid gift card", "reference", receipt.Reference, "error", err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), paymentTimeout)
+		defer cancel()
+
+		if err := s.gateway.Refund(ctx, receipt.Reference, receipt.Amount); err != nil {
+			slog.Error("failed to refund payment", "reference", receipt.Reference, "error", err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// reconcile settles a pending gift card whose charge timed out once the
+// gateway tells whether it went through, the same way bookings are.
+func (s *GiftCardService) reconcile(card *models.GiftCard) {
+	for attempt := range reconcileAttempts {
+		if attempt > 0 {
+			time.Sleep(reconcileDelay)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), paymentTimeout)
+		receipt, err := s.gateway.Lookup(ctx, giftCardChargeKey(card.ID))
+		cancel()
+
+		switch {
+		case errors.Is(err, ErrPaymentNotFound):
+			if err := s.models.GiftCards.Void(card); err != nil {
+				slog.Error("failed to void unpaid gift card", "gift_card", card.ID, "error", err)
+			}
+			return
+		case err != nil:
+			slog.Error("failed to look up payment", "gift_card", card.ID, "error", err)
+			continue
+		}
+
+		if err := s.activate(card, receipt); err != nil {
+			slog.Error("failed to reconcile gift card", "gift_card", card.ID, "error", err)
+		}
+		return
+	}
+
+	slog.Error("gift card left pending, payment outcome unknown", "gift_card", card.ID)
+}
+
+// giftCardChargeKey is the idempotency key of a gift card purchase's charge,
+// so that a card is never paid for twice.
+func giftCardChargeKey(giftCardID int) string {
+	return fmt.Sprintf("gift-card-%d", giftCardID)
+}
+
+// Balance returns the balance and ledger of a gift card. Knowing the code is
+// enough to check it, as it is to spend it.
+func (s *GiftCardService) Balance(code string) (*models.GiftCard, error) {
+	card, err := s.find(code)
+	if err != nil {
+		return nil, err
+	}
+
+	card.PurchasedBy = nil
+	card.PaymentRef = ""
+
+	return card, nil
+}
+
+// List returns the gift cards the user purchased.
+func (s *GiftCardService) List(user *models.User) ([]models.GiftCard, error) {
+	return s.models.GiftCards.FindByUser(user.ID)
+}
+
+// apply looks up a gift card and works out how much of the amount due it
+// covers. The balance is checked again when the booking is stored, since
+// other checkouts may spend it meanwhile.
+func (s *GiftCardService) apply(code, currency string, due int64) (*models.GiftCard, int64, error) {
+	card, err := s.find(code)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	amount, err := giftCardRedemption(card, currency, due, time.Now())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return card, amount, nil
+}
+
+func (s *GiftCardService) find(code string) (*models.GiftCard, error) {
+	card, err := s.models.GiftCards.FindByCode(normalizeGiftCardCode(code))
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, ErrGiftCardNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return card, nil
+}
+
+// giftCardRedemption is the part of the amount due a gift card covers: all of
+// it when the balance allows, otherwise the whole balance.
+func giftCardRedemption(card *models.GiftCard, currency string, due int64, now time.Time) (int64, error) {
+	switch {
+	case card.Status != models.GiftCardActive:
+		return 0, fmt.Errorf("%w: gift card is %v", ErrGiftCardInactive, card.Status)
+	case card.Currency != currency:
+		return 0, fmt.Errorf("%w: gift card is in %v", ErrCurrencyMismatch, card.Currency)
+	case card.IsExpired(now):
+		return 0, fmt.Errorf("%w: expired on %v", ErrGiftCardExpired, card.ExpiresAt.Format(time.DateOnly))
+	case card.Balance <= 0:
+		return 0, ErrGiftCardEmpty
+	}
+
+	return min(card.Balance, due), nil
+}
+
+// newGiftCardCode generates a random code formatted in groups, e.g.
+// ABCD-EFGH-JKLM-NPQR.
+func newGiftCardCode() (string, error) {
+	b := make([]byte, giftCardCodeLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	// the alphabet has 32 characters, so every random byte maps to one of
+	// them without bias.
+	for i := range b {
+		b[i] = giftCardAlphabet[int(b[i])%len(giftCardAlphabet)]
+	}
+
+	return formatGiftCardCode(string(b)), nil
+}
+
+// normalizeGiftCardCode accepts codes typed in lower case, with spaces or
+// without dashes.
+func normalizeGiftCardCode(code string) string {
+	code = strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(code))
+
+	if len(code) != giftCardCodeLength {
+		return code
+	}
+
+	return formatGiftCardCode(code)
+}
+
+func formatGiftCardCode(code string) string {
+	groups := make([]string, 0, len(code)/giftCardGroupSize)
+	for i := 0; i < len(code); i += giftCardGroupSize {
+		groups = append(groups, code[i:i+giftCardGroupSize])
+	}
+	return strings.Join(groups, "-")
+}
+
+type PurchaseGiftCardInput struct {
+	Amount       int64
+	Currency     string
+	PaymentToken string
+}
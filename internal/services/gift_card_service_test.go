@@ -0,0 +1,104 @@
+package services
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGiftCardRedemption(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		card models.GiftCard
+		due  int64
+		want int64
+		err  error
+	}{
+		{
+			name: "balance covers the amount due",
+			card: models.GiftCard{Status: models.GiftCardActive, Currency: "USD", Balance: 50000, ExpiresAt: now.Add(time.Hour)},
+			due:  30000,
+			want: 30000,
+		},
+		{
+			name: "partial redemption",
+			card: models.GiftCard{Status: models.GiftCardActive, Currency: "USD", Balance: 10000, ExpiresAt: now.Add(time.Hour)},
+			due:  30000,
+			want: 10000,
+		},
+		{
+			name: "expired",
+			card: models.GiftCard{Status: models.GiftCardActive, Currency: "USD", Balance: 10000, ExpiresAt: now},
+			due:  30000,
+			err:  ErrGiftCardExpired,
+		},
+		{
+			name: "empty",
+			card: models.GiftCard{Status: models.GiftCardActive, Currency: "USD", Balance: 0, ExpiresAt: now.Add(time.Hour)},
+			due:  30000,
+			err:  ErrGiftCardEmpty,
+		},
+		{
+			name: "other currency",
+			card: models.GiftCard{Status: models.GiftCardActive, Currency: "EUR", Balance: 10000, ExpiresAt: now.Add(time.Hour)},
+			due:  30000,
+			err:  ErrCurrencyMismatch,
+		},
+		{
+			name: "purchase not paid for yet",
+			card: models.GiftCard{Status: models.GiftCardPending, Currency: "USD", Balance: 10000, ExpiresAt: now.Add(time.Hour)},
+			due:  30000,
+			err:  ErrGiftCardInactive,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, err := giftCardRedemption(&tt.card, "USD", tt.due, now)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, amount)
+		})
+	}
+}
+
+func TestNewGiftCardCode(t *testing.T) {
+	format := regexp.MustCompile(`^[A-HJ-NP-Z2-9]{4}(-[A-HJ-NP-Z2-9]{4}){3}$`)
+
+	seen := make(map[string]bool)
+	for range 100 {
+		code, err := newGiftCardCode()
+		assert.NoError(t, err)
+		assert.Regexp(t, format, code)
+		assert.False(t, seen[code], "duplicate code %v", code)
+		seen[code] = true
+	}
+}
+
+func TestNormalizeGiftCardCode(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{"ABCD-EFGH-JKLM-NPQR", "ABCD-EFGH-JKLM-NPQR"},
+		{"abcd-efgh-jklm-npqr", "ABCD-EFGH-JKLM-NPQR"},
+		{"ABCDEFGHJKLMNPQR", "ABCD-EFGH-JKLM-NPQR"},
+		{" abcd efgh jklm npqr ", "ABCD-EFGH-JKLM-NPQR"},
+		{"abc", "ABC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			assert.Equal(t, tt.want, normalizeGiftCardCode(tt.code))
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+)
+
+var (
+	ErrInvalidLayout = errors.New("invalid hall layout")
+)
+
+const (
+	maxLayoutRows    = 100
+	maxLayoutColumns = 100
+)
+
+var rowLabelRX = regexp.MustCompile(`^[A-Z0-9]{1,4}$`)
+
+// layoutCells maps the characters of a layout grid to seat categories. Any
+// cell marked '_' or '.' is left empty.
+var layoutCells = map[rune]string{
+	's': models.CategoryStandard,
+	'v': models.CategoryVIP,
+	'w': models.CategoryWheelchair,
+	'c': models.CategoryCompanion,
+	'l': models.CategoryCouple,
+}
+
+// HallLayout describes the seats of a hall either as a textual grid or as a
+// list of rows.
+//
+// The grid has a line per row, front row first, optionally prefixed with the
+// row's label ("AA: ss_vvvv_ss"). Every character is a cell: 's' standard,
+// 'v' VIP, 'w' wheelchair space, 'c' companion and 'l' couple seat, while
+// '_' or '.' leaves the cell empty. Blank lines and lines starting with '#'
+// are ignored.
+type HallLayout struct {
+	Grid string
+	Rows []LayoutRow
+}
+
+// LayoutRow is a row of standard seats. Offset shifts the row to the right by
+// as many empty cells, for curved rows, and an aisle follows every seat
+// number listed in AislesAfter.
+type LayoutRow struct {
+	Label       string
+	Seats       int
+	Offset      int
+	AislesAfter []int
+}
+
+type layoutRow struct {
+	label string
+	cells string
+}
+
+// newLayoutSeating validates a layout and turns it into the hall's seats.
+// Rows without a label are labeled after their position: A to Z, then AA,
+// AB and so on.
+func newLayoutSeating(layout HallLayout) (*models.Seating, error) {
+	var (
+		rows []layoutRow
+		err  error
+	)
+
+	switch {
+	case layout.Grid != "" && len(layout.Rows) > 0:
+		return nil, fmt.Errorf("%w: provide either a grid or rows, not both", ErrInvalidLayout)
+	case layout.Grid != "":
+		rows, err = parseLayoutGrid(layout.Grid)
+	default:
+		rows, err = expandLayoutRows(layout.Rows)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: layout has no rows", ErrInvalidLayout)
+	}
+	if len(rows) > maxLayoutRows {
+		return nil, fmt.Errorf("%w: layout has more than %d rows", ErrInvalidLayout, maxLayoutRows)
+	}
+
+	seats := []models.Seat{}
+	labels := make(map[string]bool, len(rows))
+
+	for i, row := range rows {
+		if row.label == "" {
+			row.label = rowLabel(i)
+		}
+
+		if !rowLabelRX.MatchString(row.label) {
+			return nil, fmt.Errorf("%w: row label %q must be 1 to 4 capital letters or digits", ErrInvalidLayout, row.label)
+		}
+		if labels[row.label] {
+			return nil, fmt.Errorf("%w: row %v appears more than once", ErrInvalidLayout, row.label)
+		}
+		labels[row.label] = true
+
+		if len(row.cells) > maxLayoutColumns {
+			return nil, fmt.Errorf("%w: row %v is wider than %d cells", ErrInvalidLayout, row.label, maxLayoutColumns)
+		}
+
+		number := 0
+		for column, cell := range row.cells {
+			if cell == '_' || cell == '.' {
+				continue
+			}
+
+			category, ok := layoutCells[cell]
+			if !ok {
+				return nil, fmt.Errorf("%w: row %v has an unknown cell %q", ErrInvalidLayout, row.label, cell)
+			}
+
+			number++
+			seats = append(seats, models.Seat{
+				Row:        row.label,
+				SeatNumber: number,
+				Column:     column,
+				Category:   category,
+			})
+		}
+
+		if number == 0 {
+			return nil, fmt.Errorf("%w: row %v has no seats", ErrInvalidLayout, row.label)
+		}
+	}
+
+	return &models.Seating{Seats: seats}, nil
+}
+
+func parseLayoutGrid(grid string) ([]layoutRow, error) {
+	rows := []layoutRow{}
+
+	for line := range strings.Lines(grid) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var row layoutRow
+		if label, cells, ok := strings.Cut(line, ":"); ok {
+			row.label = strings.TrimSpace(label)
+			row.cells = strings.TrimSpace(cells)
+			if row.label == "" {
+				return nil, fmt.Errorf("%w: empty row label in line %q", ErrInvalidLayout, line)
+			}
+		} else {
+			row.cells = line
+		}
+
+		row.cells = strings.ToLower(row.cells)
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func expandLayoutRows(layoutRows []LayoutRow) ([]layoutRow, error) {
+	rows := make([]layoutRow, len(layoutRows))
+
+	for i, lr := range layoutRows {
+		if lr.Seats <= 0 || lr.Offset < 0 {
+			return nil, fmt.Errorf("%w: row %d needs a positive seat count and a non-negative offset", ErrInvalidLayout, i+1)
+		}
+		if lr.Offset+lr.Seats+len(lr.AislesAfter) > maxLayoutColumns {
+			return nil, fmt.Errorf("%w: row %d is wider than %d cells", ErrInvalidLayout, i+1, maxLayoutColumns)
+		}
+
+		var cells strings.Builder
+		cells.WriteString(strings.Repeat("_", lr.Offset))
+		for number := 1; number <= lr.Seats; number++ {
+			cells.WriteByte('s')
+			if slices.Contains(lr.AislesAfter, number) && number < lr.Seats {
+				cells.WriteByte('_')
+			}
+		}
+
+		rows[i] = layoutRow{label: lr.Label, cells: cells.String()}
+	}
+
+	return rows, nil
+}
+
+// rowLabel names the i-th row, counting from 0, like spreadsheet columns:
+// A to Z, then AA to AZ, BA and so on.
+func rowLabel(i int) string {
+	label := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		label = string(rune('A'+(i-1)%26)) + label
+	}
+	return label
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRowLabel(t *testing.T) {
+	tests := []struct {
+		index int
+		want  string
+	}{
+		{0, "A"},
+		{25, "Z"},
+		{26, "AA"},
+		{27, "AB"},
+		{51, "AZ"},
+		{52, "BA"},
+		{701, "ZZ"},
+		{702, "AAA"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.want, func(t *testing.T) {
+			assert.Equal(t, tc.want, rowLabel(tc.index))
+		})
+	}
+}
+
+func TestNewSeating(t *testing.T) {
+	seating := newSeating(30, 2)
+
+	assert.Equal(t, 60, seating.Capacity())
+	assert.Equal(t, models.Seat{Row: "A", SeatNumber: 1, Column: 0, Category: models.CategoryStandard}, seating.Seats[0])
+	assert.Equal(t, models.Seat{Row: "Z", SeatNumber: 2, Column: 1, Category: models.CategoryStandard}, seating.Seats[51])
+	assert.Equal(t, models.Seat{Row: "AD", SeatNumber: 2, Column: 1, Category: models.CategoryStandard}, seating.Seats[59])
+}
+
+// describe renders seats as "row number@column category" for comparison.
+func describe(seats []models.Seat) []string {
+	out := make([]string, len(seats))
+	for i, s := range seats {
+		out[i] = fmt.Sprintf("%v%d@%d %v", s.Row, s.SeatNumber, s.Column, s.Category)
+	}
+	return out
+}
+
+func TestNewLayoutSeating(t *testing.T) {
+	tests := []struct {
+		name    string
+		layout  HallLayout
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "grid with aisle and categories",
+			layout: HallLayout{Grid: `
+				# front
+				ss_wc
+				AA: _ll.v
+			`},
+			want: []string{
+				"A1@0 standard", "A2@1 standard", "A3@3 wheelchair", "A4@4 companion",
+				"AA1@1 couple", "AA2@2 couple", "AA3@4 vip",
+			},
+		},
+		{
+			name: "rows with offsets and aisles",
+			layout: HallLayout{Rows: []LayoutRow{
+				{Seats: 4, AislesAfter: []int{2}},
+				{Label: "BB", Seats: 3, Offset: 1},
+			}},
+			want: []string{
+				"A1@0 standard", "A2@1 standard", "A3@3 standard", "A4@4 standard",
+				"BB1@1 standard", "BB2@2 standard", "BB3@3 standard",
+			},
+		},
+		{
+			name:    "grid and rows together",
+			layout:  HallLayout{Grid: "sss", Rows: []LayoutRow{{Seats: 3}}},
+			wantErr: "not both",
+		},
+		{
+			name:    "empty layout",
+			layout:  HallLayout{Grid: "\n# nothing\n"},
+			wantErr: "no rows",
+		},
+		{
+			name:    "unknown cell",
+			layout:  HallLayout{Grid: "ssxs"},
+			wantErr: "unknown cell",
+		},
+		{
+			name:    "row without seats",
+			layout:  HallLayout{Grid: "sss\n___"},
+			wantErr: "no seats",
+		},
+		{
+			name:    "duplicate labels",
+			layout:  HallLayout{Grid: "B: sss\nsss"},
+			wantErr: "more than once",
+		},
+		{
+			name:    "invalid label",
+			layout:  HallLayout{Grid: "row1: sss"},
+			wantErr: "row label",
+		},
+		{
+			name:    "too wide",
+			layout:  HallLayout{Grid: strings.Repeat("s", maxLayoutColumns+1)},
+			wantErr: "wider than",
+		},
+		{
+			name:    "non-positive seat count",
+			layout:  HallLayout{Rows: []LayoutRow{{Seats: 0}}},
+			wantErr: "positive seat count",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			seating, err := newLayoutSeating(tc.layout)
+			if tc.wantErr != "" {
+				assert.ErrorIs(t, err, ErrInvalidLayout)
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tc.want, describe(seating.Seats))
+		})
+	}
+}
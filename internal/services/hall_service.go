@@ -3,10 +3,15 @@ package services
 import (
 	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/AhmadAbdelrazik/showtime/internal/models"
 )
 
+var (
+	ErrLayoutNotFound = errors.New("hall layout version not found")
+)
+
 type HallService struct {
 	models *models.Model
 }
@@ -24,6 +29,21 @@ func (s *HallService) Find(theaterId int, hallCode string) (*models.Hall, error)
 	return hall, nil
 }
 
+// SeatMap lays out the current seating of a hall as a grid.
+func (s *HallService) SeatMap(theaterId int, hallCode string) (*SeatMap, error) {
+	seating, err := s.models.Seats.FindByHallCode(theaterId, hallCode)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, ErrHallNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return newHallSeatMap(seating.Seats), nil
+}
+
 func (s *HallService) Create(input CreateHallInput) (*models.Hall, error) {
 	theater, err := s.models.Theaters.Find(input.TheaterID)
 	if err != nil {
@@ -39,12 +59,19 @@ func (s *HallService) Create(input CreateHallInput) (*models.Hall, error) {
 		return nil, fmt.Errorf("%w: creating halls is available for theater's manager only.", ErrUnauthorized)
 	}
 
+	seating, err := buildSeating(input.Hall.Rows, input.Hall.SeatsPerRow, input.Layout, input.Categories)
+	if err != nil {
+		return nil, err
+	}
+
 	hall := &models.Hall{
 		TheaterID: input.TheaterID,
 		ManagerID: input.User.ID,
 		Name:      input.Hall.Name,
 		Code:      input.Hall.Code,
-		Seats:     newSeating(input.Hall.Rows, input.Hall.SeatsPerRow),
+		Seats:     seating,
+
+		TurnaroundMinutes: input.TurnaroundMinutes,
 	}
 
 	if err := s.models.Halls.Create(hall); err != nil {
@@ -78,6 +105,12 @@ func (s *HallService) Update(input UpdateHallInput) (*models.Hall, error) {
 	if input.Name != nil {
 		hall.Name = *input.Name
 	}
+	if input.TurnaroundMinutes != nil {
+		hall.TurnaroundMinutes = input.TurnaroundMinutes
+	}
+	if input.InheritTurnaround {
+		hall.TurnaroundMinutes = nil
+	}
 
 	if err := s.models.Halls.Update(hall); err != nil {
 		switch {
@@ -119,12 +152,147 @@ func (s *HallService) Delete(user *models.User, theaterId int, hallCode string)
 	return nil
 }
 
+// UpdateSeatCategories changes the categories of seats of a hall. The seats
+// still available in upcoming shows of the hall pick up the new categories
+// as well.
+func (s *HallService) UpdateSeatCategories(user *models.User, theaterId int, hallCode string, assignments []SeatCategoryAssignment) (*models.Seating, error) {
+	if _, err := findManagedTheater(s.models, user, theaterId, "seats can be edited only by the theater manager"); err != nil {
+		return nil, err
+	}
+
+	seating, err := s.models.Seats.FindByHallCode(theaterId, hallCode)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, ErrHallNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	changed, err := applySeatCategories(seating.Seats, assignments)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.models.Seats.UpdateCategories(changed); err != nil {
+		return nil, err
+	}
+
+	return seating, nil
+}
+
+// Layouts lists the published layout revisions of a hall, newest first.
+func (s *HallService) Layouts(user *models.User, theaterId int, hallCode string) ([]models.HallLayoutRevision, error) {
+	if _, err := findManagedTheater(s.models, user, theaterId, "hall layouts are available for the theater manager only"); err != nil {
+		return nil, err
+	}
+
+	revisions, err := s.models.HallLayouts.FindByHallCode(theaterId, hallCode)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, ErrHallNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return revisions, nil
+}
+
+// PublishLayout publishes a new layout revision of a hall. Shows scheduled
+// from now on use it, while existing shows keep the layout their seats were
+// sold against.
+func (s *HallService) PublishLayout(input PublishLayoutInput) (*models.HallLayoutRevision, error) {
+	revisions, err := s.Layouts(input.User, input.TheaterID, input.HallCode)
+	if err != nil {
+		return nil, err
+	}
+
+	seating, err := buildSeating(input.Rows, input.SeatsPerRow, input.Layout, input.Categories)
+	if err != nil {
+		return nil, err
+	}
+
+	revision := &models.HallLayoutRevision{
+		HallID:      revisions[0].HallID,
+		Note:        input.Note,
+		PublishedBy: &input.User.ID,
+	}
+
+	if err := s.models.HallLayouts.Publish(revision, seating.Seats); err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, ErrHallNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return revision, nil
+}
+
+// LayoutImpact reports the upcoming shows and bookings that would be
+// affected by moving every upcoming show of a hall onto the given layout
+// version. A version of zero stands for the current layout.
+func (s *HallService) LayoutImpact(user *models.User, theaterId int, hallCode string, version int) (*LayoutImpact, error) {
+	revisions, err := s.Layouts(user, theaterId, hallCode)
+	if err != nil {
+		return nil, err
+	}
+
+	target := revisions[0]
+	if version != 0 {
+		i := slices.IndexFunc(revisions, func(r models.HallLayoutRevision) bool {
+			return r.Version == version
+		})
+		if i == -1 {
+			return nil, ErrLayoutNotFound
+		}
+		target = revisions[i]
+	}
+
+	seats, err := s.models.Seats.FindByHallVersion(target.HallID, target.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	usages, err := s.models.HallLayouts.FindUpgradeImpact(target.HallID, target.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	return evaluateLayoutImpact(target.Version, seats, usages), nil
+}
+
+// buildSeating creates the seats of a hall from an explicit layout, or from
+// a plain grid of rows when no layout is given, and applies the categories.
+func buildSeating(rows, seatsPerRow int, layout *HallLayout, categories []SeatCategoryAssignment) (*models.Seating, error) {
+	seating := newSeating(rows, seatsPerRow)
+	if layout != nil {
+		var err error
+		seating, err = newLayoutSeating(*layout)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := applySeatCategories(seating.Seats, categories); err != nil {
+		return nil, err
+	}
+
+	return seating, nil
+}
+
 func newSeating(rows, seatsPerRow int) *models.Seating {
 	seats := make([]models.Seat, rows*seatsPerRow)
 
 	for i := range seats {
-		seats[i].Row = string(rune('A' + i/seatsPerRow))
-		seats[i].SeatNumber = i % seatsPerRow
+		seats[i].Row = rowLabel(i / seatsPerRow)
+		seats[i].SeatNumber = i%seatsPerRow + 1
+		seats[i].Column = i % seatsPerRow
+		seats[i].Category = models.CategoryStandard
 	}
 
 	return &models.Seating{
@@ -140,7 +308,22 @@ type CreateHallInput struct {
 		Rows        int
 		SeatsPerRow int
 	}
-	TheaterID int
+	// TurnaroundMinutes overrides the theater's turnaround if set.
+	TurnaroundMinutes *int
+	Layout            *HallLayout
+	Categories        []SeatCategoryAssignment
+	TheaterID         int
+}
+
+type PublishLayoutInput struct {
+	User        *models.User
+	TheaterID   int
+	HallCode    string
+	Rows        int
+	SeatsPerRow int
+	Layout      *HallLayout
+	Categories  []SeatCategoryAssignment
+	Note        string
 }
 
 type UpdateHallInput struct {
@@ -148,4 +331,7 @@ type UpdateHallInput struct {
 	TheaterId int
 	HallCode  string
 	Name      *string
+
+	TurnaroundMinutes *int
+	InheritTurnaround bool
 }
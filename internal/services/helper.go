@@ -1,6 +1,12 @@
 package services
 
-import "github.com/AhmadAbdelrazik/showtime/internal/models"
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+)
 
 func isManagerOrAdmin(user *models.User) bool {
 	return user.Role == "manager" || user.Role == "admin"
@@ -13,3 +19,65 @@ func isTheaterManagerOrAdmin(user *models.User, theater *models.Theater) bool {
 func isHallManagerOrAdmin(user *models.User, hall *models.Hall) bool {
 	return hall.ManagerID == user.ID || user.Role == "admin"
 }
+
+func findTheater(m *models.Model, theaterId int) (*models.Theater, error) {
+	theater, err := m.Theaters.Find(theaterId)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, ErrTheaterNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return theater, nil
+}
+
+// findManagedTheater fetches a theater making sure the user manages it,
+// telling them what they were denied otherwise.
+func findManagedTheater(m *models.Model, user *models.User, theaterId int, denied string) (*models.Theater, error) {
+	theater, err := findTheater(m, theaterId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isTheaterManagerOrAdmin(user, theater) {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthorized, denied)
+	}
+
+	return theater, nil
+}
+
+// scheduleLocation is the time zone named by timezone, or the theater's when
+// timezone is empty.
+func scheduleLocation(timezone string, hours models.OperatingHours) (*time.Location, error) {
+	if timezone == "" {
+		return hours.Location(), nil
+	}
+	return time.LoadLocation(timezone)
+}
+
+// onDate returns midnight of the date of t in loc.
+func onDate(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// findTheaterShow fetches a show making sure it belongs to the given theater.
+func findTheaterShow(m *models.Model, theaterId, showId int) (*models.Show, error) {
+	show, err := m.Shows.Find(showId)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, ErrShowNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	if show.TheaterID != theaterId {
+		return nil, ErrShowNotFound
+	}
+
+	return show, nil
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+)
+
+var (
+	ErrSeatUnavailable = errors.New("seat unavailable")
+	ErrShowStarted     = errors.New("show has already started")
+	ErrHoldNotFound    = errors.New("hold not found")
+	ErrHoldNotActive   = errors.New("hold is no longer active")
+)
+
+type HoldService struct {
+	models      *models.Model
+	waitlist    *WaitlistService
+	duration    time.Duration
+	maxDuration time.Duration
+}
+
+// Create holds the selected seats of a show for the user for the configured
+// hold duration.
+func (s *HoldService) Create(user *models.User, theaterId, showId int, seatIds []int) (*models.Hold, error) {
+	show, err := findTheaterShow(s.models, theaterId, showId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !show.StartTime.After(time.Now()) {
+		return nil, ErrShowStarted
+	}
+
+	seats, err := s.models.ShowSeats.FindByShow(show.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkSeatCategoryRules(seats, seatIds); err != nil {
+		return nil, err
+	}
+
+	hold := &models.Hold{
+		ShowID:    show.ID,
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(s.duration),
+	}
+
+	if err := s.models.Holds.Create(hold, seatIds); err != nil {
+		switch {
+		case errors.Is(err, models.ErrSeatUnavailable):
+			return nil, fmt.Errorf("%w: one or more of the selected seats are already taken", ErrSeatUnavailable)
+		default:
+			return nil, err
+		}
+	}
+
+	return hold, nil
+}
+
+func (s *HoldService) Find(user *models.User, holdId int) (*models.Hold, error) {
+	hold, err := s.models.Holds.Find(holdId)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, ErrHoldNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	if hold.UserID != user.ID && user.Role != "admin" {
+		return nil, fmt.Errorf("%w: hold belongs to another user", ErrUnauthorized)
+	}
+
+	return hold, nil
+}
+
+// Extend restarts the hold's countdown, without letting the hold outlive
+// the configured maximum hold duration.
+func (s *HoldService) Extend(user *models.User, holdId int) (*models.Hold, error) {
+	hold, err := s.Find(user, holdId)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt, err := extendedExpiry(hold, s.duration, s.maxDuration, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	hold.ExpiresAt = expiresAt
+
+	if err := s.models.Holds.Extend(hold); err != nil {
+		switch {
+		case errors.Is(err, models.ErrHoldNotActive):
+			return nil, ErrHoldNotActive
+		default:
+			return nil, err
+		}
+	}
+
+	return hold, nil
+}
+
+// extendedExpiry is when an active hold expires once its countdown is
+// restarted at now, capped at maxDuration after the hold was created.
+func extendedExpiry(hold *models.Hold, duration, maxDuration time.Duration, now time.Time) (time.Time, error) {
+	if !hold.IsActive(now) {
+		return time.Time{}, ErrHoldNotActive
+	}
+
+	expiresAt := now.Add(duration)
+	if limit := hold.CreatedAt.Add(maxDuration); expiresAt.After(limit) {
+		expiresAt = limit
+	}
+
+	if !expiresAt.After(hold.ExpiresAt) {
+		return time.Time{}, fmt.Errorf("%w: hold has reached its maximum duration", ErrHoldNotActive)
+	}
+
+	return expiresAt, nil
+}
+
+func (s *HoldService) Release(user *models.User, holdId int) error {
+	hold, err := s.Find(user, holdId)
+	if err != nil {
+		return err
+	}
+
+	if err := s.models.Holds.Release(hold.ID); err != nil {
+		switch {
+		case errors.Is(err, models.ErrHoldNotActive):
+			return ErrHoldNotActive
+		default:
+			return err
+		}
+	}
+
+	s.waitlist.OfferReleasedSeats(hold.ShowID)
+
+	return nil
+}
+
+// ReleaseExpired returns the seats of every expired hold to the inventory
+// and offers them to the shows' waitlists.
+func (s *HoldService) ReleaseExpired() ([]models.Hold, error) {
+	holds, err := s.models.Holds.ReleaseExpired()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(holds) > 0 {
+		slog.Info("released expired seat holds", "count", len(holds))
+	}
+
+	showIds := make([]int, len(holds))
+	for i, hold := range holds {
+		showIds[i] = hold.ShowID
+	}
+	s.waitlist.OfferReleasedSeats(showIds...)
+
+	return holds, nil
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtendedExpiry(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	duration := 10 * time.Minute
+	maxDuration := 30 * time.Minute
+
+	tests := []struct {
+		name string
+		hold models.Hold
+		want time.Time
+		err  error
+	}{
+		{
+			name: "restarts the countdown",
+			hold: models.Hold{Status: models.HoldActive, CreatedAt: now.Add(-5 * time.Minute), ExpiresAt: now.Add(5 * time.Minute)},
+			want: now.Add(duration),
+		},
+		{
+			name: "capped at the maximum duration",
+			hold: models.Hold{Status: models.HoldActive, CreatedAt: now.Add(-25 * time.Minute), ExpiresAt: now.Add(2 * time.Minute)},
+			want: now.Add(5 * time.Minute),
+		},
+		{
+			name: "maximum duration reached",
+			hold: models.Hold{Status: models.HoldActive, CreatedAt: now.Add(-25 * time.Minute), ExpiresAt: now.Add(5 * time.Minute)},
+			err:  ErrHoldNotActive,
+		},
+		{
+			name: "expired",
+			hold: models.Hold{Status: models.HoldActive, CreatedAt: now.Add(-15 * time.Minute), ExpiresAt: now.Add(-time.Minute)},
+			err:  ErrHoldNotActive,
+		},
+		{
+			name: "expires right now",
+			hold: models.Hold{Status: models.HoldActive, CreatedAt: now.Add(-10 * time.Minute), ExpiresAt: now},
+			err:  ErrHoldNotActive,
+		},
+		{
+			name: "released",
+			hold: models.Hold{Status: models.HoldReleased, CreatedAt: now.Add(-5 * time.Minute), ExpiresAt: now.Add(5 * time.Minute)},
+			err:  ErrHoldNotActive,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expiresAt, err := extendedExpiry(&tt.hold, duration, maxDuration, now)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, expiresAt)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// HoldSweeper periodically releases the seats of expired holds.
+type HoldSweeper struct {
+	holds       *HoldService
+	interval    time.Duration
+	cancelSweep context.CancelFunc
+}
+
+func NewHoldSweeper(holds *HoldService, interval time.Duration) *HoldSweeper {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	sweeper := &HoldSweeper{
+		holds:       holds,
+		interval:    interval,
+		cancelSweep: cancel,
+	}
+
+	go sweeper.sweep(ctx)
+
+	return sweeper
+}
+
+func (s *HoldSweeper) sweep(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.holds.ReleaseExpired(); err != nil {
+				slog.Error("failed to release expired holds", "error", err)
+			}
+		}
+	}
+}
+
+// Stop the hold sweeper
+func (s *HoldSweeper) Stop() {
+	s.cancelSweep()
+}
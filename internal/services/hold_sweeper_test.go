@@ -0,0 +1,114 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHoldSweeper(t *testing.T) {
+	dsn := os.Getenv("DB_DSN_TEST")
+	if dsn == "" {
+		t.Skip("DB_DSN_TEST is not set")
+	}
+
+	m, err := models.New(dsn)
+	require.NoError(t, err)
+
+	db, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	suffix := time.Now().UnixNano() % 1_000_000_000
+
+	var userID int
+	err = db.QueryRow(`INSERT INTO users(username, name, role, email, hash)
+	VALUES ($1, 'Sweep Test', 'manager', $2, '\x00') RETURNING id`,
+		fmt.Sprintf("sweep%d", suffix),
+		fmt.Sprintf("sweep%d@example.com", suffix),
+	).Scan(&userID)
+	require.NoError(t, err)
+
+	movieID := fmt.Sprintf("tt%d", suffix)
+	_, err = db.Exec(`INSERT INTO movies(imdb_id, title, year, rated, runtime,
+	genre, director, poster, imdb_rating)
+	VALUES ($1, 'Sweep Test', 2025, 'PG', '120 min', 'Drama', 'Someone', 'N/A', 7.5)`,
+		movieID,
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		db.Exec(`DELETE FROM movies WHERE imdb_id = $1`, movieID)
+		db.Exec(`DELETE FROM users WHERE id = $1`, userID)
+	})
+
+	theater := &models.Theater{
+		ManagerID:         userID,
+		Name:              fmt.Sprintf("Cinema %d", suffix),
+		City:              "Cairo",
+		Address:           "Somewhere",
+		Currency:          "USD",
+		TurnaroundMinutes: 15,
+	}
+	require.NoError(t, m.Theaters.Create(theater))
+
+	hall := &models.Hall{
+		TheaterID: theater.ID,
+		ManagerID: userID,
+		Name:      "Hall A",
+		Code:      "A",
+		Seats: &models.Seating{
+			Seats: []models.Seat{
+				{Row: "A", SeatNumber: 1, Column: 1, Category: models.CategoryStandard},
+				{Row: "A", SeatNumber: 2, Column: 2, Category: models.CategoryStandard},
+			},
+		},
+	}
+	require.NoError(t, m.Halls.Create(hall))
+
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Second)
+	show := &models.Show{
+		TheaterID: theater.ID,
+		HallCode:  hall.Code,
+		MovieID:   movieID,
+		StartTime: start,
+		EndTime:   start.Add(2 * time.Hour),
+		PriceTier: "standard",
+	}
+	require.NoError(t, m.Shows.Create(show))
+
+	seats, err := m.ShowSeats.FindByShow(show.ID)
+	require.NoError(t, err)
+	require.Len(t, seats, 2)
+
+	hold := &models.Hold{
+		ShowID:    show.ID,
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}
+	require.NoError(t, m.Holds.Create(hold, []int{seats[0].ID}))
+
+	holds := &HoldService{
+		models:      m,
+		waitlist:    &WaitlistService{m, time.Minute},
+		duration:    time.Minute,
+		maxDuration: time.Minute,
+	}
+	sweeper := NewHoldSweeper(holds, 10*time.Millisecond)
+	defer sweeper.Stop()
+
+	assert.Eventually(t, func() bool {
+		seats, err := m.ShowSeats.FindByShow(show.ID)
+		return err == nil && seats[0].Status == models.SeatAvailable && seats[0].HoldID == nil
+	}, time.Second, 10*time.Millisecond)
+
+	expired, err := m.Holds.Find(hold.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.HoldExpired, expired.Status)
+}
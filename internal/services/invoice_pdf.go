@@ -0,0 +1,111 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/AhmadAbdelrazik/showtime/pkg/money"
+	"github.com/go-pdf/fpdf"
+)
+
+const invoiceTimeFormat = "Mon 02 Jan 2006 15:04 MST"
+
+// renderInvoicePDF draws an invoice on an A4 page.
+func renderInvoicePDF(doc invoiceDocument) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle("Invoice "+doc.Number, true)
+	pdf.SetMargins(20, 20, 20)
+	pdf.AddPage()
+
+	// the core fonts are latin-1 only.
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(0, 10, tr(doc.Theater.Name), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(0, 5, tr(doc.Theater.Address), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 5, tr(doc.Theater.City), "", 1, "L", false, 0, "")
+	pdf.Ln(8)
+
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(0, 8, "Invoice "+doc.Number, "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	details := [][2]string{
+		{"Issued", doc.IssuedAt.Format(invoiceTimeFormat)},
+		{"Billed to", fmt.Sprintf("%v <%v>", doc.Customer.Name, doc.Customer.Email)},
+		{"Movie", doc.Movie},
+		{"Show", fmt.Sprintf("%v, hall %v", doc.ShowTime.Format(invoiceTimeFormat), doc.Hall)},
+	}
+	for _, detail := range details {
+		pdf.CellFormat(30, 6, detail[0], "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 6, tr(detail[1]), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(6)
+
+	widths := []float64{100, 15, 27, 28}
+	pdf.SetFont("Helvetica", "B", 10)
+	headers := []string{"Description", "Qty", "Unit price", fmt.Sprintf("Amount (%v)", doc.Currency)}
+	for i, header := range headers {
+		align := "R"
+		if i == 0 {
+			align = "L"
+		}
+		pdf.CellFormat(widths[i], 7, header, "B", 0, align, false, 0, "")
+	}
+	pdf.Ln(-1)
+
+	formatAmount := func(amount int64) string {
+		return money.New(amount, doc.Currency).Decimal()
+	}
+
+	pdf.SetFont("Helvetica", "", 10)
+	for _, line := range doc.Lines {
+		pdf.CellFormat(widths[0], 6, tr(line.Description), "", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[1], 6, fmt.Sprint(line.Quantity), "", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[2], 6, formatAmount(line.UnitPrice), "", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[3], 6, formatAmount(line.Amount), "", 1, "R", false, 0, "")
+	}
+
+	label := widths[0] + widths[1] + widths[2]
+	total := func(description string, amount int64, style string) {
+		pdf.SetFont("Helvetica", style, 10)
+		pdf.CellFormat(label, 6, tr(description), "", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[3], 6, formatAmount(amount), "", 1, "R", false, 0, "")
+	}
+
+	pdf.Ln(2)
+	total("Subtotal", doc.Subtotal, "")
+	for _, discount := range doc.Discounts {
+		total(discount.Description, discount.Amount, "")
+	}
+	for _, tax := range doc.Taxes {
+		total(tax.Description, tax.Amount, "")
+	}
+	total("Total", doc.Total, "B")
+	for _, tax := range doc.Included {
+		total("Includes "+tax.Description, tax.Amount, "I")
+	}
+	if doc.GiftCard > 0 {
+		total("Paid by gift card", doc.GiftCard, "")
+	}
+	total("Charged", doc.Charged, "")
+	if doc.Refunded > 0 {
+		total("Refunded", -doc.Refunded, "")
+	}
+
+	var b bytes.Buffer
+	if err := pdf.Output(&b); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// formatRate writes a rate in basis points as a percentage, e.g. 1450 as
+// 14.5.
+func formatRate(rate int) string {
+	percent := fmt.Sprintf("%d.%02d", rate/100, rate%100)
+	percent = strings.TrimRight(percent, "0")
+	return strings.TrimSuffix(percent, ".")
+}
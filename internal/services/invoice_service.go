@@ -0,0 +1,221 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+)
+
+var (
+	ErrNoInvoice = errors.New("booking has no invoice")
+)
+
+type InvoiceService struct {
+	models *models.Model
+}
+
+// Invoice returns the invoice of a booking of the user as a PDF document.
+// Paid bookings without an invoice, such as the ones paid before invoices
+// were introduced, get the next number of their theater.
+func (s *InvoiceService) Invoice(user *models.User, bookingId int) (*models.Invoice, []byte, error) {
+	booking, err := s.models.Bookings.Find(bookingId)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, nil, ErrBookingNotFound
+		default:
+			return nil, nil, err
+		}
+	}
+
+	if booking.UserID != user.ID && user.Role != "admin" {
+		return nil, nil, fmt.Errorf("%w: booking belongs to another user", ErrUnauthorized)
+	}
+
+	invoice, err := s.findInvoice(booking)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	show, err := s.models.Shows.Find(booking.ShowID)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, nil, ErrShowNotFound
+		default:
+			return nil, nil, err
+		}
+	}
+
+	theater, err := s.models.Theaters.Find(show.TheaterID)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, nil, ErrTheaterNotFound
+		default:
+			return nil, nil, err
+		}
+	}
+
+	customer, err := s.models.Users.Find(booking.UserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, nil, ErrUserNotFound
+		default:
+			return nil, nil, err
+		}
+	}
+
+	doc := newInvoiceDocument(invoice, booking, show, theater, customer)
+
+	pdf, err := renderInvoicePDF(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return invoice, pdf, nil
+}
+
+func (s *InvoiceService) findInvoice(booking *models.Booking) (*models.Invoice, error) {
+	invoice, err := s.models.Invoices.FindByBooking(booking.ID)
+	if err == nil {
+		return invoice, nil
+	} else if !errors.Is(err, models.ErrNotFound) {
+		return nil, err
+	}
+
+	if booking.Status != models.BookingPaid {
+		return nil, fmt.Errorf("%w: booking is %v", ErrNoInvoice, booking.Status)
+	}
+
+	invoice, err = s.models.Invoices.Issue(booking.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, fmt.Errorf("%w: booking is no longer paid", ErrNoInvoice)
+		default:
+			return nil, err
+		}
+	}
+
+	return invoice, nil
+}
+
+// invoiceDocument is what an invoice shows. Amounts are in the minor unit of
+// the booking's currency.
+type invoiceDocument struct {
+	Number    string
+	Currency  string
+	IssuedAt  time.Time
+	Theater   models.Theater
+	Customer  models.User
+	Movie     string
+	Hall      string
+	ShowTime  time.Time
+	Lines     []invoiceLine
+	Discounts []invoiceLine
+	Taxes     []invoiceLine
+	Included  []invoiceLine
+	Subtotal  int64
+	Total     int64
+	GiftCard  int64
+	Charged   int64
+	Refunded  int64
+}
+
+type invoiceLine struct {
+	Description string
+	Quantity    int
+	UnitPrice   int64
+	Amount      int64
+}
+
+// newInvoiceDocument lays out the price breakdown of a booking: its tickets
+// and concessions, the discounts taken off them, the taxes and fees added on
+// top or included in the prices, and how the total was paid.
+func newInvoiceDocument(invoice *models.Invoice, booking *models.Booking, show *models.Show, theater *models.Theater, customer *models.User) invoiceDocument {
+	doc := invoiceDocument{
+		Number:   invoice.Code(),
+		Currency: booking.Currency,
+		IssuedAt: invoice.IssuedAt,
+		Theater:  *theater,
+		Customer: *customer,
+		Movie:    show.MovieTitle,
+		Hall:     show.HallCode,
+		ShowTime: show.StartTime,
+		GiftCard: booking.GiftCardAmount,
+		Charged:  booking.Amount,
+		Refunded: booking.Refunded,
+	}
+
+	for _, ticket := range booking.Tickets {
+		doc.Lines = append(doc.Lines, invoiceLine{
+			Description: fmt.Sprintf("Seat %v%v, %v, %v ticket", ticket.Row, ticket.SeatNumber, ticket.Category, ticket.TicketType),
+			Quantity:    1,
+			UnitPrice:   ticket.Price,
+			Amount:      ticket.Price,
+		})
+	}
+
+	for _, line := range booking.Concessions {
+		doc.Lines = append(doc.Lines, invoiceLine{
+			Description: line.Name,
+			Quantity:    line.Quantity,
+			UnitPrice:   line.UnitPrice,
+			Amount:      line.UnitPrice * int64(line.Quantity),
+		})
+	}
+
+	for _, line := range doc.Lines {
+		doc.Subtotal += line.Amount
+	}
+
+	discounts := []struct {
+		description string
+		amount      int64
+	}{
+		{fmt.Sprintf("Membership (%v tickets)", booking.MembershipTickets), booking.MembershipDiscount},
+		{"Promo code", booking.Discount},
+		{"Loyalty", booking.LoyaltyDiscount},
+	}
+
+	doc.Total = doc.Subtotal
+	for _, discount := range discounts {
+		if discount.amount == 0 {
+			continue
+		}
+
+		doc.Discounts = append(doc.Discounts, invoiceLine{
+			Description: discount.description,
+			Amount:      -discount.amount,
+		})
+		doc.Total -= discount.amount
+	}
+
+	for _, tax := range booking.Taxes {
+		line := invoiceLine{Description: taxDescription(tax), Amount: tax.Amount}
+		if tax.Inclusive {
+			doc.Included = append(doc.Included, line)
+			continue
+		}
+
+		doc.Taxes = append(doc.Taxes, line)
+		doc.Total += tax.Amount
+	}
+
+	return doc
+}
+
+func taxDescription(tax models.TaxLine) string {
+	switch {
+	case tax.Rate != 0:
+		return fmt.Sprintf("%v (%v%%)", tax.Name, formatRate(tax.Rate))
+	case tax.Base > 1:
+		return fmt.Sprintf("%v (x%v)", tax.Name, tax.Base)
+	default:
+		return tax.Name
+	}
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewInvoiceDocument(t *testing.T) {
+	popcorn := 1
+	invoice := &models.Invoice{TheaterID: 3, Number: 42, IssuedAt: time.Now()}
+	show := &models.Show{MovieTitle: "Dune", HallCode: "A", StartTime: time.Now()}
+	theater := &models.Theater{Name: "Cinema Paradiso", Address: "1 Main St", City: "Cairo"}
+	customer := &models.User{Name: "Nour", Email: "nour@example.com"}
+
+	booking := &models.Booking{
+		Tickets: []models.Ticket{
+			{Row: "C", SeatNumber: 4, Category: "standard", TicketType: "adult", Price: 10000},
+			{Row: "C", SeatNumber: 5, Category: "standard", TicketType: "child", Price: 6000},
+		},
+		Concessions: []models.ConcessionLine{
+			{ItemID: &popcorn, Name: "Popcorn", Quantity: 2, UnitPrice: 4000},
+		},
+		Discount:        1600,
+		LoyaltyDiscount: 1000,
+		Taxes: []models.TaxLine{
+			{Name: "VAT", Kind: models.TaxKindTax, Rate: 1400, Base: 14000, Amount: 1719, Inclusive: true},
+			{Name: "Booking fee", Kind: models.TaxKindFee, Base: 1, Amount: 300},
+		},
+		GiftCardAmount: 5000,
+		Amount:         16700,
+		Currency:       "EGP",
+	}
+
+	doc := newInvoiceDocument(invoice, booking, show, theater, customer)
+
+	assert.Equal(t, "INV-3-000042", doc.Number)
+	assert.Len(t, doc.Lines, 3)
+	assert.Equal(t, "Seat C4, standard, adult ticket", doc.Lines[0].Description)
+	assert.Equal(t, int64(8000), doc.Lines[2].Amount)
+	assert.Equal(t, int64(24000), doc.Subtotal)
+	assert.Equal(t, []invoiceLine{
+		{Description: "Promo code", Amount: -1600},
+		{Description: "Loyalty", Amount: -1000},
+	}, doc.Discounts)
+	assert.Equal(t, []invoiceLine{{Description: "Booking fee", Amount: 300}}, doc.Taxes)
+	assert.Equal(t, []invoiceLine{{Description: "VAT (14%)", Amount: 1719}}, doc.Included)
+	assert.Equal(t, int64(21700), doc.Total)
+	assert.Equal(t, doc.Total, doc.GiftCard+doc.Charged)
+
+	pdf, err := renderInvoicePDF(doc)
+	assert.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-")))
+}
+
+func TestFormatRate(t *testing.T) {
+	tests := []struct {
+		rate int
+		want string
+	}{
+		{1400, "14"},
+		{1450, "14.5"},
+		{725, "7.25"},
+		{5, "0.05"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, formatRate(tt.rate))
+		})
+	}
+}
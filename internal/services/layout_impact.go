@@ -0,0 +1,112 @@
+package services
+
+import (
+	"slices"
+	"time"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+)
+
+const (
+	SeatRemoved       = "removed"
+	SeatRecategorized = "category_changed"
+)
+
+// LayoutImpact reports what moving the upcoming shows of a hall onto a layout
+// version would do to the seats already held or sold for them.
+type LayoutImpact struct {
+	Version          int          `json:"version"`
+	Shows            []ShowImpact `json:"shows"`
+	AffectedShows    int          `json:"affected_shows"`
+	AffectedBookings int          `json:"affected_bookings"`
+}
+
+// ShowImpact is an upcoming show running on another layout version. Seats
+// that don't exist in the target layout, or exist with another category, are
+// listed in AffectedSeats.
+type ShowImpact struct {
+	ShowID           int            `json:"show_id"`
+	MovieTitle       string         `json:"movie_title"`
+	StartTime        time.Time      `json:"start_time"`
+	LayoutVersion    int            `json:"layout_version"`
+	TakenSeats       int            `json:"taken_seats"`
+	AffectedSeats    []AffectedSeat `json:"affected_seats"`
+	AffectedBookings []int          `json:"affected_bookings"`
+}
+
+type AffectedSeat struct {
+	Row         string `json:"row"`
+	SeatNumber  int    `json:"seat_number"`
+	Status      string `json:"status"`
+	BookingID   int    `json:"booking_id,omitempty"`
+	UserID      int    `json:"user_id"`
+	Reason      string `json:"reason"`
+	Category    string `json:"category"`
+	NewCategory string `json:"new_category,omitempty"`
+}
+
+// evaluateLayoutImpact matches the taken seats of each show against the
+// target layout by row and seat number.
+func evaluateLayoutImpact(version int, target []models.Seat, usages []models.ShowLayoutUsage) *LayoutImpact {
+	type seatKey struct {
+		row    string
+		number int
+	}
+
+	categories := make(map[seatKey]string, len(target))
+	for _, seat := range target {
+		categories[seatKey{seat.Row, seat.SeatNumber}] = seat.Category
+	}
+
+	impact := &LayoutImpact{
+		Version: version,
+		Shows:   make([]ShowImpact, 0, len(usages)),
+	}
+
+	for _, usage := range usages {
+		show := ShowImpact{
+			ShowID:           usage.ShowID,
+			MovieTitle:       usage.MovieTitle,
+			StartTime:        usage.StartTime,
+			LayoutVersion:    usage.LayoutVersion,
+			TakenSeats:       len(usage.TakenSeats),
+			AffectedSeats:    []AffectedSeat{},
+			AffectedBookings: []int{},
+		}
+
+		for _, seat := range usage.TakenSeats {
+			affected := AffectedSeat{
+				Row:        seat.Row,
+				SeatNumber: seat.SeatNumber,
+				Status:     seat.Status,
+				BookingID:  seat.BookingID,
+				UserID:     seat.UserID,
+				Category:   seat.Category,
+			}
+
+			category, ok := categories[seatKey{seat.Row, seat.SeatNumber}]
+			switch {
+			case !ok:
+				affected.Reason = SeatRemoved
+			case category != seat.Category:
+				affected.Reason = SeatRecategorized
+				affected.NewCategory = category
+			default:
+				continue
+			}
+
+			show.AffectedSeats = append(show.AffectedSeats, affected)
+			if seat.BookingID != 0 && !slices.Contains(show.AffectedBookings, seat.BookingID) {
+				show.AffectedBookings = append(show.AffectedBookings, seat.BookingID)
+			}
+		}
+
+		if len(show.AffectedSeats) > 0 {
+			impact.AffectedShows++
+		}
+		impact.AffectedBookings += len(show.AffectedBookings)
+		impact.Shows = append(impact.Shows, show)
+	}
+
+	return impact
+}
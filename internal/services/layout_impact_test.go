@@ -0,0 +1,101 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluateLayoutImpact(t *testing.T) {
+	// the new layout drops seat A3 and turns B1 into a wheelchair space.
+	target := newSeating(2, 3).Seats
+	target = append(target[:2], target[3:]...)
+	target[2].Category = models.CategoryWheelchair
+
+	start := time.Date(2026, 10, 20, 18, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		usages           []models.ShowLayoutUsage
+		affectedShows    int
+		affectedBookings int
+		want             []AffectedSeat
+	}{
+		{
+			name:   "no upcoming shows",
+			usages: []models.ShowLayoutUsage{},
+			want:   nil,
+		},
+		{
+			name: "show without taken seats",
+			usages: []models.ShowLayoutUsage{
+				{ShowID: 1, StartTime: start, LayoutVersion: 1, TakenSeats: []models.TakenSeat{}},
+			},
+			want: []AffectedSeat{},
+		},
+		{
+			name: "seats kept by the new layout",
+			usages: []models.ShowLayoutUsage{
+				{ShowID: 1, StartTime: start, LayoutVersion: 1, TakenSeats: []models.TakenSeat{
+					{Row: "A", SeatNumber: 1, Category: models.CategoryStandard, Status: "sold", UserID: 7, BookingID: 3},
+					{Row: "B", SeatNumber: 2, Category: models.CategoryStandard, Status: "held", UserID: 8},
+				}},
+			},
+			want: []AffectedSeat{},
+		},
+		{
+			name: "removed and recategorized seats",
+			usages: []models.ShowLayoutUsage{
+				{ShowID: 1, StartTime: start, LayoutVersion: 1, TakenSeats: []models.TakenSeat{
+					{Row: "A", SeatNumber: 2, Category: models.CategoryStandard, Status: "sold", UserID: 7, BookingID: 3},
+					{Row: "A", SeatNumber: 3, Category: models.CategoryStandard, Status: "sold", UserID: 7, BookingID: 3},
+					{Row: "B", SeatNumber: 1, Category: models.CategoryStandard, Status: "held", UserID: 8},
+				}},
+			},
+			affectedShows:    1,
+			affectedBookings: 1,
+			want: []AffectedSeat{
+				{Row: "A", SeatNumber: 3, Status: "sold", BookingID: 3, UserID: 7, Reason: SeatRemoved, Category: models.CategoryStandard},
+				{Row: "B", SeatNumber: 1, Status: "held", UserID: 8, Reason: SeatRecategorized, Category: models.CategoryStandard, NewCategory: models.CategoryWheelchair},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			impact := evaluateLayoutImpact(2, target, tt.usages)
+
+			assert.Equal(t, 2, impact.Version)
+			assert.Len(t, impact.Shows, len(tt.usages))
+			assert.Equal(t, tt.affectedShows, impact.AffectedShows)
+			assert.Equal(t, tt.affectedBookings, impact.AffectedBookings)
+
+			if tt.want != nil {
+				assert.Equal(t, tt.want, impact.Shows[0].AffectedSeats)
+				assert.Equal(t, len(tt.usages[0].TakenSeats), impact.Shows[0].TakenSeats)
+			}
+		})
+	}
+}
+
+func TestEvaluateLayoutImpactCountsBookingsPerShow(t *testing.T) {
+	target := newSeating(1, 2).Seats
+
+	removed := func(number, booking int) models.TakenSeat {
+		return models.TakenSeat{Row: "B", SeatNumber: number, Category: models.CategoryStandard, Status: "sold", UserID: booking, BookingID: booking}
+	}
+
+	usages := []models.ShowLayoutUsage{
+		{ShowID: 1, LayoutVersion: 1, TakenSeats: []models.TakenSeat{removed(1, 4), removed(2, 4), removed(3, 5)}},
+		{ShowID: 2, LayoutVersion: 1, TakenSeats: []models.TakenSeat{removed(1, 6)}},
+	}
+
+	impact := evaluateLayoutImpact(2, target, usages)
+
+	assert.Equal(t, 2, impact.AffectedShows)
+	assert.Equal(t, 3, impact.AffectedBookings)
+	assert.Equal(t, []int{4, 5}, impact.Shows[0].AffectedBookings)
+	assert.Equal(t, []int{6}, impact.Shows[1].AffectedBookings)
+}
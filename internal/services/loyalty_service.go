@@ -0,0 +1,230 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+)
+
+var (
+	ErrNotLoyaltyMember         = errors.New("loyalty program is for customers only")
+	ErrInsufficientPoints       = errors.New("insufficient loyalty points")
+	ErrInvalidLoyaltyRedemption = errors.New("invalid loyalty redemption")
+	ErrInvalidLoyaltyRules      = errors.New("invalid loyalty rules")
+)
+
+// tierWindow is how far back earned points count towards a tier.
+const tierWindow = 365 * 24 * time.Hour
+
+type LoyaltyService struct {
+	models     *models.Model
+	currencies *CurrencyService
+}
+
+// LoyaltyAccount sums up a customer's standing in the loyalty program.
+type LoyaltyAccount struct {
+	Balance          int    `json:"balance"`
+	Tier             string `json:"tier"`
+	TierPoints       int    `json:"tier_points"`
+	NextTier         string `json:"next_tier,omitempty"`
+	PointsToNextTier int    `json:"points_to_next_tier,omitempty"`
+	EarnBonus        int    `json:"earn_bonus"`
+	Discount         int    `json:"discount"`
+}
+
+// Account returns the points balance and tier of a customer.
+func (s *LoyaltyService) Account(user *models.User) (*LoyaltyAccount, error) {
+	if user.Role != "customer" {
+		return nil, ErrNotLoyaltyMember
+	}
+
+	rules, balance, tierPoints, err := s.findStanding(user)
+	if err != nil {
+		return nil, err
+	}
+
+	account := loyaltyAccount(rules, balance, tierPoints)
+	return &account, nil
+}
+
+// Transactions returns the points ledger of a customer.
+func (s *LoyaltyService) Transactions(user *models.User) ([]models.LoyaltyTransaction, error) {
+	if user.Role != "customer" {
+		return nil, ErrNotLoyaltyMember
+	}
+
+	return s.models.Loyalty.FindTransactions(user.ID)
+}
+
+func (s *LoyaltyService) Rules() (*models.LoyaltyRules, error) {
+	return s.models.Loyalty.FindRules()
+}
+
+// UpdateRules replaces the earn and burn rules and the tiers of the loyalty
+// program. Points already earned keep their value in points, not in money.
+func (s *LoyaltyService) UpdateRules(user *models.User, rules models.LoyaltyRules) (*models.LoyaltyRules, error) {
+	if user.Role != "admin" {
+		return nil, fmt.Errorf("%w: loyalty rules can be updated by admins only", ErrUnauthorized)
+	}
+
+	slices.SortFunc(rules.Tiers, func(a, b models.LoyaltyTier) int {
+		return a.MinPoints - b.MinPoints
+	})
+
+	for i := 1; i < len(rules.Tiers); i++ {
+		if rules.Tiers[i].MinPoints == rules.Tiers[i-1].MinPoints {
+			return nil, fmt.Errorf("%w: tiers must have different min_points", ErrInvalidLoyaltyRules)
+		}
+		if slices.Index(models.LoyaltyTiers, rules.Tiers[i].Name) < slices.Index(models.LoyaltyTiers, rules.Tiers[i-1].Name) {
+			return nil, fmt.Errorf("%w: %v must take more points than %v", ErrInvalidLoyaltyRules, rules.Tiers[i-1].Name, rules.Tiers[i].Name)
+		}
+	}
+
+	if err := s.models.Loyalty.ReplaceRules(&rules); err != nil {
+		return nil, err
+	}
+
+	return &rules, nil
+}
+
+// apply works out the loyalty discount and points of a booking: the tier
+// discount, the free tickets and points the customer redeems, and the points
+// the booking earns once paid. Users other than customers aren't part of the
+// program. The balance is checked again when the booking is stored, since
+// other checkouts may spend the points meanwhile.
+func (s *LoyaltyService) apply(user *models.User, currency string, tickets []models.Ticket, due int64, points, freeTickets int) (loyaltyQuote, error) {
+	if user.Role != "customer" {
+		if points > 0 || freeTickets > 0 {
+			return loyaltyQuote{}, ErrNotLoyaltyMember
+		}
+		return loyaltyQuote{}, nil
+	}
+
+	rules, balance, tierPoints, err := s.findStanding(user)
+	if err != nil {
+		return loyaltyQuote{}, err
+	}
+
+	// points are worth their value in the base currency.
+	if points > 0 {
+		rules.PointValue, err = s.currencies.fromBase(rules.PointValue, currency)
+		if err != nil {
+			return loyaltyQuote{}, err
+		}
+	}
+
+	return quoteLoyalty(*rules, loyaltyTier(*rules, tierPoints), tickets, due, balance, points, freeTickets)
+}
+
+func (s *LoyaltyService) findStanding(user *models.User) (*models.LoyaltyRules, int, int, error) {
+	rules, err := s.models.Loyalty.FindRules()
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	balance, err := s.models.Loyalty.Balance(user.ID)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	tierPoints, err := s.models.Loyalty.TierPoints(user.ID, time.Now().Add(-tierWindow))
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	return rules, balance, tierPoints, nil
+}
+
+// loyaltyQuote is the loyalty part of a booking.
+type loyaltyQuote struct {
+	Discount       int64
+	PointsRedeemed int
+	PointsEarned   int
+}
+
+// loyaltyTier returns the highest tier reached with the given points, or nil
+// for basic members.
+func loyaltyTier(rules models.LoyaltyRules, tierPoints int) *models.LoyaltyTier {
+	var reached *models.LoyaltyTier
+	for i, tier := range rules.Tiers {
+		if tierPoints >= tier.MinPoints && (reached == nil || tier.MinPoints > reached.MinPoints) {
+			reached = &rules.Tiers[i]
+		}
+	}
+	return reached
+}
+
+func loyaltyAccount(rules *models.LoyaltyRules, balance, tierPoints int) LoyaltyAccount {
+	account := LoyaltyAccount{
+		Balance:    balance,
+		Tier:       models.LoyaltyBasic,
+		TierPoints: tierPoints,
+	}
+
+	if tier := loyaltyTier(*rules, tierPoints); tier != nil {
+		account.Tier = tier.Name
+		account.EarnBonus = tier.EarnBonus
+		account.Discount = tier.Discount
+	}
+
+	for _, tier := range rules.Tiers {
+		if tier.MinPoints > tierPoints && (account.NextTier == "" || tier.MinPoints-tierPoints < account.PointsToNextTier) {
+			account.NextTier = tier.Name
+			account.PointsToNextTier = tier.MinPoints - tierPoints
+		}
+	}
+
+	return account
+}
+
+// quoteLoyalty prices the loyalty part of a booking. Free tickets go to the
+// cheapest tickets first, then the tier discount applies to what's left and
+// the redeemed points pay for the rest, never spending more points than
+// needed. Free tickets earn no points.
+func quoteLoyalty(rules models.LoyaltyRules, tier *models.LoyaltyTier, tickets []models.Ticket, due int64, balance, points, freeTickets int) (loyaltyQuote, error) {
+	if freeTickets > len(tickets) {
+		return loyaltyQuote{}, fmt.Errorf("%w: %v free tickets for %v seats", ErrInvalidLoyaltyRedemption, freeTickets, len(tickets))
+	}
+
+	if cost := freeTickets*rules.FreeTicketPoints + points; cost > balance {
+		return loyaltyQuote{}, fmt.Errorf("%w: %v points needed, %v left", ErrInsufficientPoints, cost, balance)
+	}
+
+	prices := make([]int64, len(tickets))
+	for i, ticket := range tickets {
+		prices[i] = ticket.Price
+	}
+	slices.Sort(prices)
+
+	var free int64
+	for _, price := range prices[:freeTickets] {
+		free += price
+	}
+
+	quote := loyaltyQuote{Discount: min(free, due)}
+	remaining := due - quote.Discount
+
+	bonus := 0
+	if tier != nil {
+		tierDiscount := remaining * int64(tier.Discount) / 100
+		quote.Discount += tierDiscount
+		remaining -= tierDiscount
+		bonus = tier.EarnBonus
+	}
+
+	if points > 0 && rules.PointValue > 0 {
+		needed := int((remaining + rules.PointValue - 1) / rules.PointValue)
+		points = min(points, needed)
+		quote.Discount += min(int64(points)*rules.PointValue, remaining)
+	} else {
+		points = 0
+	}
+
+	quote.PointsRedeemed = freeTickets*rules.FreeTicketPoints + points
+	quote.PointsEarned = (len(tickets) - freeTickets) * rules.PointsPerTicket * (100 + bonus) / 100
+
+	return quote, nil
+}
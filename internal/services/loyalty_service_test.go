@@ -0,0 +1,150 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var testLoyaltyRules = models.LoyaltyRules{
+	PointsPerTicket:  10,
+	PointValue:       10,
+	FreeTicketPoints: 500,
+	Tiers: []models.LoyaltyTier{
+		{Name: models.LoyaltySilver, MinPoints: 1000, EarnBonus: 25, Discount: 5},
+		{Name: models.LoyaltyGold, MinPoints: 5000, EarnBonus: 50, Discount: 10},
+	},
+}
+
+func TestLoyaltyAccount(t *testing.T) {
+	tests := []struct {
+		name       string
+		tierPoints int
+		want       LoyaltyAccount
+	}{
+		{
+			name:       "basic",
+			tierPoints: 400,
+			want: LoyaltyAccount{
+				Balance: 100, Tier: models.LoyaltyBasic, TierPoints: 400,
+				NextTier: models.LoyaltySilver, PointsToNextTier: 600,
+			},
+		},
+		{
+			name:       "silver reached exactly",
+			tierPoints: 1000,
+			want: LoyaltyAccount{
+				Balance: 100, Tier: models.LoyaltySilver, TierPoints: 1000,
+				NextTier: models.LoyaltyGold, PointsToNextTier: 4000,
+				EarnBonus: 25, Discount: 5,
+			},
+		},
+		{
+			name:       "gold",
+			tierPoints: 7000,
+			want: LoyaltyAccount{
+				Balance: 100, Tier: models.LoyaltyGold, TierPoints: 7000,
+				EarnBonus: 50, Discount: 10,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, loyaltyAccount(&testLoyaltyRules, 100, tt.tierPoints))
+		})
+	}
+}
+
+func TestQuoteLoyalty(t *testing.T) {
+	gold := &testLoyaltyRules.Tiers[1]
+	tickets := []models.Ticket{{Price: 10000}, {Price: 6000}, {Price: 8000}}
+
+	tests := []struct {
+		name        string
+		tier        *models.LoyaltyTier
+		due         int64
+		balance     int
+		points      int
+		freeTickets int
+		want        loyaltyQuote
+		err         error
+	}{
+		{
+			name: "earn only",
+			due:  24000,
+			want: loyaltyQuote{PointsEarned: 30},
+		},
+		{
+			name: "tier discount and earn bonus",
+			tier: gold,
+			due:  24000,
+			want: loyaltyQuote{Discount: 2400, PointsEarned: 45},
+		},
+		{
+			name:    "points discount",
+			due:     24000,
+			balance: 1000,
+			points:  300,
+			want:    loyaltyQuote{Discount: 3000, PointsRedeemed: 300, PointsEarned: 30},
+		},
+		{
+			name:    "no more points spent than needed",
+			due:     2005,
+			balance: 1000,
+			points:  1000,
+			want:    loyaltyQuote{Discount: 2005, PointsRedeemed: 201, PointsEarned: 30},
+		},
+		{
+			name:        "free ticket goes to the cheapest ticket",
+			due:         24000,
+			balance:     500,
+			freeTickets: 1,
+			want:        loyaltyQuote{Discount: 6000, PointsRedeemed: 500, PointsEarned: 20},
+		},
+		{
+			name:        "free ticket then tier discount",
+			tier:        gold,
+			due:         24000,
+			balance:     500,
+			freeTickets: 1,
+			want:        loyaltyQuote{Discount: 7800, PointsRedeemed: 500, PointsEarned: 30},
+		},
+		{
+			name:        "free ticket capped at the amount due",
+			due:         4000,
+			balance:     500,
+			freeTickets: 1,
+			want:        loyaltyQuote{Discount: 4000, PointsRedeemed: 500, PointsEarned: 20},
+		},
+		{
+			name:        "not enough points",
+			due:         24000,
+			balance:     600,
+			points:      200,
+			freeTickets: 1,
+			err:         ErrInsufficientPoints,
+		},
+		{
+			name:        "more free tickets than seats",
+			due:         24000,
+			balance:     5000,
+			freeTickets: 4,
+			err:         ErrInvalidLoyaltyRedemption,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote, err := quoteLoyalty(testLoyaltyRules, tt.tier, tickets, tt.due, tt.balance, tt.points, tt.freeTickets)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, quote)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// MembershipRenewer periodically rolls over the memberships whose billing
+// period ended.
+type MembershipRenewer struct {
+	memberships *MembershipService
+	interval    time.Duration
+	cancelRenew context.CancelFunc
+}
+
+func NewMembershipRenewer(memberships *MembershipService, interval time.Duration) *MembershipRenewer {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	renewer := &MembershipRenewer{
+		memberships: memberships,
+		interval:    interval,
+		cancelRenew: cancel,
+	}
+
+	go renewer.renew(ctx)
+
+	return renewer
+}
+
+func (r *MembershipRenewer) renew(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.memberships.RenewDue(); err != nil {
+				slog.Error("failed to renew memberships", "error", err)
+			}
+		}
+	}
+}
+
+// Stop the membership renewer
+func (r *MembershipRenewer) Stop() {
+	r.cancelRenew()
+}
@@ -0,0 +1,553 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+)
+
+var (
+	ErrPlanNotFound         = errors.New("membership plan not found")
+	ErrNoMembership         = errors.New("no membership")
+	ErrAlreadySubscribed    = errors.New("already subscribed to a membership")
+	ErrMembershipInactive   = errors.New("membership inactive")
+	ErrMembershipExhausted  = errors.New("membership entitlement used up")
+	ErrMembershipsCustomers = errors.New("memberships are for customers only")
+)
+
+const (
+	// renewalRetryInterval is how long to wait before charging a past due
+	// subscription again.
+	renewalRetryInterval = 24 * time.Hour
+	// maxFailedPayments is how many failed renewals cancel a subscription.
+	maxFailedPayments = 3
+)
+
+type MembershipService struct {
+	models   *models.Model
+	gateway  PaymentGateway
+	currency string
+}
+
+// Plans lists the membership plans on sale.
+func (s *MembershipService) Plans() ([]models.MembershipPlan, error) {
+	return s.models.Plans.FindAll(false)
+}
+
+func (s *MembershipService) CreatePlan(user *models.User, input CreatePlanInput) (*models.MembershipPlan, error) {
+	if user.Role != "admin" {
+		return nil, fmt.Errorf("%w: membership plans are managed by admins only", ErrUnauthorized)
+	}
+
+	plan := &models.MembershipPlan{
+		Name:             input.Name,
+		Kind:             input.Kind,
+		TicketsPerPeriod: input.TicketsPerPeriod,
+		Price:            input.Price,
+		Currency:         input.Currency,
+		PeriodDays:       input.PeriodDays,
+		Active:           true,
+	}
+
+	if plan.Currency == "" {
+		plan.Currency = s.currency
+	}
+
+	if plan.Kind == models.PlanUnlimited {
+		plan.TicketsPerPeriod = nil
+	}
+
+	if err := s.models.Plans.Create(plan); err != nil {
+		switch {
+		case errors.Is(err, models.ErrDuplicate):
+			return nil, fmt.Errorf("%w: %v", ErrDuplicate, err)
+		default:
+			return nil, err
+		}
+	}
+
+	return plan, nil
+}
+
+// UpdatePlan changes the name, price or availability of a plan. Members pay
+// the new price from their next renewal.
+func (s *MembershipService) UpdatePlan(user *models.User, planId int, input UpdatePlanInput) (*models.MembershipPlan, error) {
+	if user.Role != "admin" {
+		return nil, fmt.Errorf("%w: membership plans are managed by admins only", ErrUnauthorized)
+	}
+
+	plan, err := s.findPlan(planId)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Name != nil {
+		plan.Name = *input.Name
+	}
+	if input.Price != nil {
+		plan.Price = *input.Price
+	}
+	if input.Active != nil {
+		plan.Active = *input.Active
+	}
+
+	if err := s.models.Plans.Update(plan); err != nil {
+		switch {
+		case errors.Is(err, models.ErrEditConflict):
+			return nil, ErrEditConflict
+		case errors.Is(err, models.ErrDuplicate):
+			return nil, fmt.Errorf("%w: %v", ErrDuplicate, err)
+		default:
+			return nil, err
+		}
+	}
+
+	return plan, nil
+}
+
+// Subscribe charges the customer for the first period of a plan and starts
+// the membership. The membership is stored pending before it's charged for,
+// and only starts once the charge is captured. The payment token is kept to
+// charge the renewals.
+func (s *MembershipService) Subscribe(user *models.User, input SubscribeInput) (*models.Subscription, error) {
+	if user.Role != "customer" {
+		return nil, ErrMembershipsCustomers
+	}
+
+	plan, err := s.findPlan(input.PlanID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !plan.Active {
+		return nil, fmt.Errorf("%w: plan is no longer sold", ErrPlanNotFound)
+	}
+
+	if _, err := s.models.Subscriptions.FindCurrent(user.ID); err == nil {
+		return nil, ErrAlreadySubscribed
+	} else if !errors.Is(err, models.ErrNotFound) {
+		return nil, err
+	}
+
+	now := time.Now()
+	sub := &models.Subscription{
+		UserID:             user.ID,
+		PlanID:             plan.ID,
+		Plan:               *plan,
+		Status:             models.SubscriptionPending,
+		PaymentToken:       input.PaymentToken,
+		CurrentPeriodStart: now,
+		CurrentPeriodEnd:   now.Add(plan.Period()),
+	}
+
+	if err := s.models.Subscriptions.Create(sub); err != nil {
+		switch {
+		case errors.Is(err, models.ErrDuplicate):
+			return nil, ErrAlreadySubscribed
+		default:
+			return nil, err
+		}
+	}
+
+	payment := &models.SubscriptionPayment{
+		PeriodStart: sub.CurrentPeriodStart,
+		PeriodEnd:   sub.CurrentPeriodEnd,
+		Amount:      plan.Price,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), paymentTimeout)
+	defer cancel()
+
+	receipt, err := s.gateway.Charge(ctx, Charge{
+		Token:          input.PaymentToken,
+		Amount:         plan.Price,
+		Currency:       plan.Currency,
+		Description:    fmt.Sprintf("%v membership", plan.Name),
+		IdempotencyKey: membershipChargeKey(sub.ID, sub.CurrentPeriodStart),
+	})
+	if err != nil {
+		// the gateway may have captured a charge that timed out, so the
+		// membership stays pending until the charge is looked up.
+		if errors.Is(err, ErrPaymentTimeout) || errors.Is(err, context.DeadlineExceeded) {
+			pending := *sub
+			go s.reconcile(&pending, payment)
+
+			return sub, fmt.Errorf("%w: %v", ErrPaymentPending, err)
+		}
+
+		s.abandon(sub, payment, err.Error())
+		return nil, err
+	}
+
+	if err := s.start(sub, payment, receipt); err != nil {
+		return nil, err
+	}
+
+	return sub, nil
+}
+
+// start activates a pending membership with the charge that paid for its
+// first period. A charge for a membership that can't be started is refunded.
+func (s *MembershipService) start(sub *models.Subscription, payment *models.SubscriptionPayment, receipt *PaymentReceipt) error {
+	payment.Status = "paid"
+	payment.PaymentRef = receipt.Reference
+	sub.Status = models.SubscriptionActive
+
+	if err := s.models.Subscriptions.Update(sub, payment); err != nil {
+		slog.Error("failed to start paid membership", "reference", receipt.Reference, "error", err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), paymentTimeout)
+		defer cancel()
+
+		if err := s.gateway.Refund(ctx, receipt.Reference, receipt.Amount); err != nil {
+			slog.Error("failed to refund payment", "reference", receipt.Reference, "error", err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// abandon cancels a pending membership whose first period wasn't paid for.
+func (s *MembershipService) abandon(sub *models.Subscription, payment *models.SubscriptionPayment, reason string) {
+	payment.Status = "failed"
+	payment.FailureReason = reason
+	sub.Status = models.SubscriptionCancelled
+
+	if err := s.models.Subscriptions.Update(sub, payment); err != nil {
+		slog.Error("failed to cancel unpaid membership", "subscription", sub.ID, "error", err)
+	}
+}
+
+// reconcile settles a pending membership whose first charge timed out once
+// the gateway tells whether it went through, the same way bookings are.
+func (s *MembershipService) reconcile(sub *models.Subscription, payment *models.SubscriptionPayment) {
+	for attempt := range reconcileAttempts {
+		if attempt > 0 {
+			time.Sleep(reconcileDelay)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), paymentTimeout)
+		receipt, err := s.gateway.Lookup(ctx, membershipChargeKey(sub.ID, sub.CurrentPeriodStart))
+		cancel()
+
+		switch {
+		case errors.Is(err, ErrPaymentNotFound):
+			s.abandon(sub, payment, "payment timed out")
+			return
+		case err != nil:
+			slog.Error("failed to look up payment", "subscription", sub.ID, "error", err)
+			continue
+		}
+
+		if err := s.start(sub, payment, receipt); err != nil {
+			slog.Error("failed to reconcile membership", "subscription", sub.ID, "error", err)
+		}
+		return
+	}
+
+	slog.Error("membership left pending, payment outcome unknown", "subscription", sub.ID)
+}
+
+// Current returns the membership of a user with its billing history.
+func (s *MembershipService) Current(user *models.User) (*models.Subscription, []models.SubscriptionPayment, error) {
+	sub, err := s.findCurrent(user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	payments, err := s.models.Subscriptions.FindPayments(sub.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return sub, payments, nil
+}
+
+// Cancel stops the renewals of a membership. Its entitlements stay until the
+// end of the paid period; past due memberships end right away.
+func (s *MembershipService) Cancel(user *models.User) (*models.Subscription, error) {
+	sub, err := s.findCurrent(user)
+	if err != nil {
+		return nil, err
+	}
+
+	if sub.Status == models.SubscriptionPastDue {
+		sub.Status = models.SubscriptionCancelled
+	} else {
+		sub.CancelAtPeriodEnd = true
+	}
+
+	if err := s.models.Subscriptions.Update(sub, nil); err != nil {
+		switch {
+		case errors.Is(err, models.ErrEditConflict):
+			return nil, ErrEditConflict
+		default:
+			return nil, err
+		}
+	}
+
+	return sub, nil
+}
+
+// RenewDue rolls over the memberships whose period ended: cancelled ones
+// end, the others are charged for a new period. Failed charges make the
+// membership past due until a later retry succeeds, and cancel it after
+// maxFailedPayments attempts.
+func (s *MembershipService) RenewDue() (int, error) {
+	now := time.Now()
+
+	subs, err := s.models.Subscriptions.FindDue(now)
+	if err != nil {
+		return 0, err
+	}
+
+	renewed := 0
+	for i := range subs {
+		sub := &subs[i]
+
+		action := renewalAction(sub, now)
+		if action == renewalWait {
+			continue
+		}
+
+		var payment *models.SubscriptionPayment
+		switch action {
+		case renewalCharge:
+			payment = s.chargeRenewal(sub, now)
+		case renewalEnd:
+			s.refundRenewal(sub)
+		}
+
+		if err := s.models.Subscriptions.Update(sub, payment); err != nil {
+			// a captured renewal isn't refunded, since another run may have
+			// recorded the very same charge. Otherwise the next run finds it
+			// by its key instead of charging again.
+			slog.Error("failed to renew membership", "subscription", sub.ID, "error", err)
+			continue
+		}
+
+		if sub.Status == models.SubscriptionActive {
+			renewed++
+		}
+	}
+
+	if renewed > 0 {
+		slog.Info("renewed memberships", "count", renewed)
+	}
+
+	return renewed, nil
+}
+
+// chargeRenewal charges a membership for its next period and rolls it over
+// on success. The charge is keyed by the membership and the end of its
+// current period, which retries of a past due membership share, and looked
+// up first so that a charge captured by an earlier attempt isn't made again.
+func (s *MembershipService) chargeRenewal(sub *models.Subscription, now time.Time) *models.SubscriptionPayment {
+	start, end := nextBillingPeriod(sub, now)
+
+	payment := &models.SubscriptionPayment{
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Amount:      sub.Plan.Price,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), paymentTimeout)
+	defer cancel()
+
+	key := membershipChargeKey(sub.ID, sub.CurrentPeriodEnd)
+
+	receipt, err := s.gateway.Lookup(ctx, key)
+	if errors.Is(err, ErrPaymentNotFound) {
+		receipt, err = s.gateway.Charge(ctx, Charge{
+			Token:          sub.PaymentToken,
+			Amount:         sub.Plan.Price,
+			Currency:       sub.Plan.Currency,
+			Description:    fmt.Sprintf("%v membership renewal", sub.Plan.Name),
+			IdempotencyKey: key,
+		})
+	}
+	if err != nil {
+		payment.Status = "failed"
+		payment.FailureReason = err.Error()
+		sub.Status = models.SubscriptionPastDue
+
+		// a charge that timed out or couldn't be looked up may have gone
+		// through, so it's looked up on the next attempt rather than
+		// counted as failed.
+		if errors.Is(err, ErrPaymentDeclined) {
+			sub.FailedPayments++
+			if sub.FailedPayments >= maxFailedPayments {
+				sub.Status = models.SubscriptionCancelled
+			}
+		}
+
+		return payment
+	}
+
+	payment.Status = "paid"
+	payment.PaymentRef = receipt.Reference
+
+	sub.Status = models.SubscriptionActive
+	sub.CurrentPeriodStart = start
+	sub.CurrentPeriodEnd = end
+	sub.TicketsUsed = 0
+	sub.FailedPayments = 0
+
+	return payment
+}
+
+// refundRenewal refunds a renewal of an ending membership that was captured
+// but never recorded, e.g. because the membership was cancelled meanwhile.
+func (s *MembershipService) refundRenewal(sub *models.Subscription) {
+	ctx, cancel := context.WithTimeout(context.Background(), paymentTimeout)
+	defer cancel()
+
+	receipt, err := s.gateway.Lookup(ctx, membershipChargeKey(sub.ID, sub.CurrentPeriodEnd))
+	switch {
+	case errors.Is(err, ErrPaymentNotFound):
+		return
+	case err != nil:
+		slog.Error("failed to look up payment", "subscription", sub.ID, "error", err)
+		return
+	}
+
+	if err := s.gateway.Refund(ctx, receipt.Reference, receipt.Amount); err != nil {
+		slog.Error("failed to refund payment", "reference", receipt.Reference, "error", err)
+	}
+}
+
+// membershipChargeKey is the idempotency key of the charge for the period of
+// a membership due at the given time, so that no period is charged twice.
+func membershipChargeKey(subscriptionID int, due time.Time) string {
+	return fmt.Sprintf("membership-%d-%d", subscriptionID, due.Unix())
+}
+
+// cover picks the tickets of a booking the user's membership pays for. The
+// entitlements are checked again when the booking is stored, since other
+// checkouts may use them meanwhile.
+func (s *MembershipService) cover(user *models.User, tickets []models.Ticket) (*models.Subscription, []int, error) {
+	sub, err := s.findCurrent(user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	covered, err := membershipCoverage(sub, tickets, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return sub, covered, nil
+}
+
+func (s *MembershipService) findCurrent(user *models.User) (*models.Subscription, error) {
+	sub, err := s.models.Subscriptions.FindCurrent(user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, ErrNoMembership
+		default:
+			return nil, err
+		}
+	}
+
+	return sub, nil
+}
+
+func (s *MembershipService) findPlan(planId int) (*models.MembershipPlan, error) {
+	plan, err := s.models.Plans.Find(planId)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, ErrPlanNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return plan, nil
+}
+
+type renewal int
+
+const (
+	renewalWait renewal = iota
+	renewalEnd
+	renewalCharge
+)
+
+// renewalAction decides what to do with a membership whose period ended.
+func renewalAction(sub *models.Subscription, now time.Time) renewal {
+	switch {
+	case sub.CancelAtPeriodEnd:
+		sub.Status = models.SubscriptionCancelled
+		return renewalEnd
+	case sub.Status == models.SubscriptionPastDue && now.Sub(sub.UpdatedAt) < renewalRetryInterval:
+		return renewalWait
+	default:
+		return renewalCharge
+	}
+}
+
+// nextBillingPeriod follows on from the current period, unless the
+// membership lapsed for a while, in which case the new period starts now.
+func nextBillingPeriod(sub *models.Subscription, now time.Time) (time.Time, time.Time) {
+	start := sub.CurrentPeriodEnd
+	if sub.Status != models.SubscriptionActive || !start.Add(sub.Plan.Period()).After(now) {
+		start = now
+	}
+
+	return start, start.Add(sub.Plan.Period())
+}
+
+// membershipCoverage returns the indices of the tickets a membership pays
+// for, cheapest first: as many as the tickets left this period for tickets
+// plans, and a single ticket for unlimited plans.
+func membershipCoverage(sub *models.Subscription, tickets []models.Ticket, now time.Time) ([]int, error) {
+	if !sub.IsActive(now) {
+		return nil, ErrMembershipInactive
+	}
+
+	n := 1
+	if sub.Plan.Kind == models.PlanTickets {
+		n = *sub.Plan.TicketsPerPeriod - sub.TicketsUsed
+		if n <= 0 {
+			return nil, fmt.Errorf("%w: all %v tickets of this period used", ErrMembershipExhausted, *sub.Plan.TicketsPerPeriod)
+		}
+	}
+
+	order := make([]int, len(tickets))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return int(tickets[a].Price - tickets[b].Price)
+	})
+
+	return order[:min(n, len(order))], nil
+}
+
+type CreatePlanInput struct {
+	Name             string
+	Kind             string
+	TicketsPerPeriod *int
+	Price            int64
+	Currency         string
+	PeriodDays       int
+}
+
+type UpdatePlanInput struct {
+	Name   *string
+	Price  *int64
+	Active *bool
+}
+
+type SubscribeInput struct {
+	PlanID       int
+	PaymentToken string
+}
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMembershipCoverage(t *testing.T) {
+	now := time.Now()
+	four := 4
+	tickets := []models.Ticket{{Price: 10000}, {Price: 6000}, {Price: 8000}}
+
+	ticketsPlan := models.MembershipPlan{Kind: models.PlanTickets, TicketsPerPeriod: &four}
+	unlimitedPlan := models.MembershipPlan{Kind: models.PlanUnlimited}
+
+	tests := []struct {
+		name string
+		sub  models.Subscription
+		want []int
+		err  error
+	}{
+		{
+			name: "tickets plan covers all seats",
+			sub:  models.Subscription{Plan: ticketsPlan, Status: models.SubscriptionActive, CurrentPeriodEnd: now.Add(time.Hour)},
+			want: []int{1, 2, 0},
+		},
+		{
+			name: "tickets plan covers the cheapest seats left",
+			sub:  models.Subscription{Plan: ticketsPlan, Status: models.SubscriptionActive, CurrentPeriodEnd: now.Add(time.Hour), TicketsUsed: 2},
+			want: []int{1, 2},
+		},
+		{
+			name: "tickets plan used up",
+			sub:  models.Subscription{Plan: ticketsPlan, Status: models.SubscriptionActive, CurrentPeriodEnd: now.Add(time.Hour), TicketsUsed: 4},
+			err:  ErrMembershipExhausted,
+		},
+		{
+			name: "unlimited plan covers one seat",
+			sub:  models.Subscription{Plan: unlimitedPlan, Status: models.SubscriptionActive, CurrentPeriodEnd: now.Add(time.Hour)},
+			want: []int{1},
+		},
+		{
+			name: "past due",
+			sub:  models.Subscription{Plan: unlimitedPlan, Status: models.SubscriptionPastDue, CurrentPeriodEnd: now.Add(time.Hour)},
+			err:  ErrMembershipInactive,
+		},
+		{
+			name: "period ended",
+			sub:  models.Subscription{Plan: unlimitedPlan, Status: models.SubscriptionActive, CurrentPeriodEnd: now.Add(-time.Hour)},
+			err:  ErrMembershipInactive,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			covered, err := membershipCoverage(&tt.sub, tickets, now)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, covered)
+		})
+	}
+}
+
+func TestRenewalAction(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name       string
+		sub        models.Subscription
+		want       renewal
+		wantStatus string
+	}{
+		{
+			name:       "active",
+			sub:        models.Subscription{Status: models.SubscriptionActive},
+			want:       renewalCharge,
+			wantStatus: models.SubscriptionActive,
+		},
+		{
+			name:       "cancelled at period end",
+			sub:        models.Subscription{Status: models.SubscriptionActive, CancelAtPeriodEnd: true},
+			want:       renewalEnd,
+			wantStatus: models.SubscriptionCancelled,
+		},
+		{
+			name:       "past due retried too soon",
+			sub:        models.Subscription{Status: models.SubscriptionPastDue, UpdatedAt: now.Add(-time.Hour)},
+			want:       renewalWait,
+			wantStatus: models.SubscriptionPastDue,
+		},
+		{
+			name:       "past due retried",
+			sub:        models.Subscription{Status: models.SubscriptionPastDue, UpdatedAt: now.Add(-renewalRetryInterval)},
+			want:       renewalCharge,
+			wantStatus: models.SubscriptionPastDue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, renewalAction(&tt.sub, now))
+			assert.Equal(t, tt.wantStatus, tt.sub.Status)
+		})
+	}
+}
+
+func TestNextBillingPeriod(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	plan := models.MembershipPlan{PeriodDays: 30}
+	period := plan.Period()
+
+	tests := []struct {
+		name      string
+		sub       models.Subscription
+		wantStart time.Time
+	}{
+		{
+			name:      "follows on from the ended period",
+			sub:       models.Subscription{Plan: plan, Status: models.SubscriptionActive, CurrentPeriodEnd: now.Add(-time.Hour)},
+			wantStart: now.Add(-time.Hour),
+		},
+		{
+			name:      "past due starts now",
+			sub:       models.Subscription{Plan: plan, Status: models.SubscriptionPastDue, CurrentPeriodEnd: now.Add(-48 * time.Hour)},
+			wantStart: now,
+		},
+		{
+			name:      "lapsed for a whole period starts now",
+			sub:       models.Subscription{Plan: plan, Status: models.SubscriptionActive, CurrentPeriodEnd: now.Add(-period)},
+			wantStart: now,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := nextBillingPeriod(&tt.sub, now)
+			assert.Equal(t, tt.wantStart, start)
+			assert.Equal(t, tt.wantStart.Add(period), end)
+		})
+	}
+}
+
+// stubGateway captures every charge once per idempotency key, timing out the
+// first timeouts charges after capturing them.
+type stubGateway struct {
+	timeouts int
+	declines int
+	captured map[string]int64
+	charges  int
+}
+
+func (g *stubGateway) Charge(_ context.Context, c Charge) (*PaymentReceipt, error) {
+	if g.declines > 0 {
+		g.declines--
+		return nil, ErrPaymentDeclined
+	}
+
+	if _, ok := g.captured[c.IdempotencyKey]; !ok {
+		g.captured[c.IdempotencyKey] = c.Amount
+		g.charges++
+	}
+
+	if g.timeouts > 0 {
+		g.timeouts--
+		return nil, ErrPaymentTimeout
+	}
+
+	return &PaymentReceipt{Reference: c.IdempotencyKey, Amount: c.Amount}, nil
+}
+
+func (g *stubGateway) Refund(context.Context, string, int64) error {
+	return nil
+}
+
+func (g *stubGateway) Lookup(_ context.Context, key string) (*PaymentReceipt, error) {
+	amount, ok := g.captured[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %v", ErrPaymentNotFound, key)
+	}
+
+	return &PaymentReceipt{Reference: key, Amount: amount}, nil
+}
+
+func TestChargeRenewal(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	plan := models.MembershipPlan{Name: "Monthly", Price: 1500, Currency: "USD", PeriodDays: 30}
+
+	tests := []struct {
+		name        string
+		gateway     *stubGateway
+		attempts    int
+		wantStatus  string
+		wantFailed  int
+		wantCharges int
+	}{
+		{
+			name:        "renewed",
+			gateway:     &stubGateway{},
+			attempts:    1,
+			wantStatus:  models.SubscriptionActive,
+			wantCharges: 1,
+		},
+		{
+			name:        "timed out charge is looked up on retry",
+			gateway:     &stubGateway{timeouts: 1},
+			attempts:    2,
+			wantStatus:  models.SubscriptionActive,
+			wantCharges: 1,
+		},
+		{
+			name:        "timed out charge isn't counted as failed",
+			gateway:     &stubGateway{timeouts: 1},
+			attempts:    1,
+			wantStatus:  models.SubscriptionPastDue,
+			wantCharges: 1,
+		},
+		{
+			name:        "declined",
+			gateway:     &stubGateway{declines: 1},
+			attempts:    1,
+			wantStatus:  models.SubscriptionPastDue,
+			wantFailed:  1,
+			wantCharges: 0,
+		},
+		{
+			name:        "declined too often",
+			gateway:     &stubGateway{declines: maxFailedPayments},
+			attempts:    maxFailedPayments,
+			wantStatus:  models.SubscriptionCancelled,
+			wantFailed:  maxFailedPayments,
+			wantCharges: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.gateway.captured = make(map[string]int64)
+			s := &MembershipService{gateway: tt.gateway}
+			sub := &models.Subscription{
+				ID:               1,
+				Plan:             plan,
+				Status:           models.SubscriptionActive,
+				CurrentPeriodEnd: now.Add(-time.Hour),
+			}
+
+			var payment *models.SubscriptionPayment
+			for range tt.attempts {
+				payment = s.chargeRenewal(sub, now)
+			}
+
+			assert.Equal(t, tt.wantStatus, sub.Status)
+			assert.Equal(t, tt.wantFailed, sub.FailedPayments)
+			assert.Equal(t, tt.wantCharges, tt.gateway.charges)
+			if tt.wantStatus == models.SubscriptionActive {
+				assert.Equal(t, "paid", payment.Status)
+				assert.True(t, sub.CurrentPeriodEnd.After(now))
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrPaymentDeclined = errors.New("payment declined")
+	ErrPaymentTimeout  = errors.New("payment gateway timed out")
+	ErrPaymentNotFound = errors.New("payment not found")
+)
+
+// PaymentGateway charges and refunds customers. Amounts are in the currency's
+// minor unit. Charges with the same IdempotencyKey are only captured once,
+// and Lookup tells whether a charge went through after a timeout, returning
+// ErrPaymentNotFound if it didn't.
+type PaymentGateway interface {
+	Charge(ctx context.Context, charge Charge) (*PaymentReceipt, error)
+	Refund(ctx context.Context, reference string, amount int64) error
+	Lookup(ctx context.Context, idempotencyKey string) (*PaymentReceipt, error)
+}
+
+type Charge struct {
+	Token          string
+	Amount         int64
+	Currency       string
+	Description    string
+	IdempotencyKey string
+}
+
+type PaymentReceipt struct {
+	Reference string
+	Amount    int64
+}
@@ -0,0 +1,430 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+)
+
+var (
+	ErrInvalidTicketType = errors.New("invalid ticket type")
+	ErrSeatNotHeld       = errors.New("seat not held")
+)
+
+// defaultTierRates and defaultTicketTypeRates apply to the tiers and ticket
+// types a theater hasn't priced itself, as a percentage of the base price.
+var (
+	defaultTierRates = map[string]int{
+		models.TierMatinee: 80,
+		models.TierEvening: 100,
+		models.TierWeekend: 120,
+		models.TierHoliday: 150,
+	}
+
+	defaultTicketTypeRates = map[string]int{
+		models.TicketAdult:   100,
+		models.TicketChild:   60,
+		models.TicketSenior:  70,
+		models.TicketStudent: 80,
+	}
+)
+
+// matineeEnd is the hour from which weekday shows are priced as evening
+// shows.
+const matineeEnd = 17
+
+type PriceService struct {
+	models     *models.Model
+	currencies *CurrencyService
+	basePrice  int64
+}
+
+// PriceList returns the prices of a theater, with the defaults filled in for
+// anything the theater hasn't priced.
+func (s *PriceService) PriceList(theaterId int) (*models.PriceList, error) {
+	theater, err := findTheater(s.models, theaterId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.findPriceList(theater)
+}
+
+// UpdatePriceList replaces the prices of a theater. Tickets already sold keep
+// the price they were bought at; the new prices of upcoming shows are added
+// to their audit trails.
+func (s *PriceService) UpdatePriceList(user *models.User, theaterId int, list models.PriceList) (*models.PriceList, error) {
+	theater, err := findManagedTheater(s.models, user, theaterId, "prices can be updated by theater manager only")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.models.PriceLists.Replace(theaterId, list); err != nil {
+		return nil, err
+	}
+
+	s.recordPriceListChanges(theaterId)
+
+	return s.findPriceList(theater)
+}
+
+// PriceCurve returns the dynamic pricing curve of a theater.
+func (s *PriceService) PriceCurve(user *models.User, theaterId int) (*models.PriceCurve, error) {
+	if _, err := findManagedTheater(s.models, user, theaterId, "pricing is available for the theater manager only"); err != nil {
+		return nil, err
+	}
+
+	return s.models.PriceCurves.Find(theaterId)
+}
+
+// UpdatePriceCurve replaces the dynamic pricing curve of a theater. An empty
+// curve turns dynamic pricing off.
+func (s *PriceService) UpdatePriceCurve(user *models.User, theaterId int, curve models.PriceCurve) (*models.PriceCurve, error) {
+	if _, err := findManagedTheater(s.models, user, theaterId, "pricing is available for the theater manager only"); err != nil {
+		return nil, err
+	}
+
+	if err := s.models.PriceCurves.Replace(theaterId, curve); err != nil {
+		return nil, err
+	}
+
+	return s.models.PriceCurves.Find(theaterId)
+}
+
+// Simulate previews the prices of a seat category under a price curve, the
+// theater's own curve unless input.Curve is set.
+func (s *PriceService) Simulate(user *models.User, theaterId int, input SimulatePricingInput) (*PriceSimulation, error) {
+	theater, err := findManagedTheater(s.models, user, theaterId, "pricing is available for the theater manager only")
+	if err != nil {
+		return nil, err
+	}
+
+	curve := input.Curve
+	if curve == nil {
+		curve, err = s.models.PriceCurves.Find(theaterId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	list, err := s.findPriceList(theater)
+	if err != nil {
+		return nil, err
+	}
+
+	occupancies := input.Occupancies
+	if len(occupancies) == 0 {
+		occupancies = simulatedOccupancies
+	}
+	leadHours := input.HoursBefore
+	if len(leadHours) == 0 {
+		leadHours = simulatedLeadHours
+	}
+
+	static := categoryPrice(*list, input.Category, input.Tier)
+
+	return &PriceSimulation{
+		Category:    input.Category,
+		Tier:        input.Tier,
+		StaticPrice: static,
+		Prices:      simulatePriceCurve(*curve, input.Category, static, occupancies, leadHours),
+	}, nil
+}
+
+// PriceChanges returns the audit trail of the dynamic prices of a show.
+func (s *PriceService) PriceChanges(user *models.User, theaterId, showId int) ([]models.PriceChange, error) {
+	if _, err := findManagedTheater(s.models, user, theaterId, "pricing is available for the theater manager only"); err != nil {
+		return nil, err
+	}
+
+	if _, err := findTheaterShow(s.models, theaterId, showId); err != nil {
+		return nil, err
+	}
+
+	return s.models.PriceChanges.FindByShow(showId)
+}
+
+// attachPriceRanges sets the range of current ticket prices of each show,
+// from the cheapest ticket type of its cheapest seat category to the most
+// expensive ones.
+func (s *PriceService) attachPriceRanges(shows []models.Show) error {
+	if len(shows) == 0 {
+		return nil
+	}
+
+	ids := make([]int, len(shows))
+	for i, show := range shows {
+		ids[i] = show.ID
+	}
+
+	occupancy, err := s.models.ShowSeats.FindOccupancy(ids, 0)
+	if err != nil {
+		return err
+	}
+
+	pricing := make(map[int]*theaterPricing)
+	for i := range shows {
+		show := &shows[i]
+
+		p, ok := pricing[show.TheaterID]
+		if !ok {
+			p, err = s.findTheaterPricing(show.TheaterID)
+			if err != nil {
+				return err
+			}
+			pricing[show.TheaterID] = p
+		}
+
+		if o := occupancy[show.ID]; len(o) > 0 {
+			r := priceRange(p.list, p.showPrices(show, o, time.Now()))
+			show.PriceRange = &r
+		}
+	}
+
+	return nil
+}
+
+// quote prices the seats of a hold at the current prices, as they were
+// before the hold took them. ticketTypes maps show seat IDs to their ticket
+// type; seats missing from it get adult tickets.
+func (s *PriceService) quote(show *models.Show, hold *models.Hold, ticketTypes map[int]string) ([]models.Ticket, int64, error) {
+	for seatID, ticketType := range ticketTypes {
+		if !slices.Contains(models.TicketTypes, ticketType) {
+			return nil, 0, fmt.Errorf("%w: %v", ErrInvalidTicketType, ticketType)
+		}
+		if !slices.ContainsFunc(hold.Seats, func(seat models.ShowSeat) bool { return seat.ID == seatID }) {
+			return nil, 0, fmt.Errorf("%w: seat %v is not part of the hold", ErrSeatNotHeld, seatID)
+		}
+	}
+
+	p, err := s.findTheaterPricing(show.TheaterID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	occupancy, err := s.models.ShowSeats.FindOccupancy([]int{show.ID}, hold.ID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	now := time.Now()
+	prices := p.showPrices(show, occupancy[show.ID], now)
+	s.recordPriceChanges(show, p, prices, occupancy[show.ID], now)
+
+	var amount int64
+	tickets := make([]models.Ticket, len(hold.Seats))
+	for i, seat := range hold.Seats {
+		ticketType, ok := ticketTypes[seat.ID]
+		if !ok {
+			ticketType = models.TicketAdult
+		}
+
+		tickets[i] = models.Ticket{
+			ShowSeatID: seat.ID,
+			Row:        seat.Row,
+			SeatNumber: seat.SeatNumber,
+			Category:   seat.Category,
+			TicketType: ticketType,
+			Price:      ticketTypePrice(p.list, prices[seat.Category], ticketType),
+		}
+		amount += tickets[i].Price
+	}
+
+	return tickets, amount, nil
+}
+
+// recordPriceChanges adds the dynamic prices of a show that changed since
+// they were last recorded to the show's audit trail. Failing to record them
+// doesn't stop the sale.
+func (s *PriceService) recordPriceChanges(show *models.Show, p *theaterPricing, prices map[string]int64, occupancy []models.CategoryOccupancy, now time.Time) {
+	if !p.curve.IsDynamic() {
+		return
+	}
+
+	for _, o := range occupancy {
+		change := &models.PriceChange{
+			ShowID:      show.ID,
+			Category:    o.Category,
+			NewPrice:    prices[o.Category],
+			Occupancy:   o.Percent(),
+			HoursBefore: int(show.StartTime.Sub(now).Hours()),
+		}
+		if _, err := s.models.PriceChanges.Record(change); err != nil {
+			slog.Error("failed to record price change", "show", show.ID, "category", o.Category, "error", err)
+		}
+	}
+}
+
+// recordPriceListChanges adds the prices of the theater's upcoming shows
+// under its new price list to their audit trails. Failing to record them
+// doesn't stop the update.
+func (s *PriceService) recordPriceListChanges(theaterId int) {
+	p, err := s.findTheaterPricing(theaterId)
+	if err != nil {
+		slog.Error("failed to record price changes", "theater", theaterId, "error", err)
+		return
+	}
+
+	if !p.curve.IsDynamic() {
+		return
+	}
+
+	shows, err := s.models.Shows.FindUpcoming(theaterId)
+	if err != nil {
+		slog.Error("failed to record price changes", "theater", theaterId, "error", err)
+		return
+	}
+
+	showIds := make([]int, len(shows))
+	for i, show := range shows {
+		showIds[i] = show.ID
+	}
+
+	occupancy, err := s.models.ShowSeats.FindOccupancy(showIds, 0)
+	if err != nil {
+		slog.Error("failed to record price changes", "theater", theaterId, "error", err)
+		return
+	}
+
+	now := time.Now()
+	for i := range shows {
+		show := &shows[i]
+		prices := p.showPrices(show, occupancy[show.ID], now)
+		s.recordPriceChanges(show, p, prices, occupancy[show.ID], now)
+	}
+}
+
+// theaterPricing is everything needed to price the shows of a theater.
+type theaterPricing struct {
+	list  models.PriceList
+	curve models.PriceCurve
+}
+
+// showPrices returns the current adult price of every seat category of a
+// show.
+func (p *theaterPricing) showPrices(show *models.Show, occupancy []models.CategoryOccupancy, now time.Time) map[string]int64 {
+	prices := make(map[string]int64, len(occupancy))
+	for _, o := range occupancy {
+		static := categoryPrice(p.list, o.Category, show.PriceTier)
+		prices[o.Category] = dynamicPrice(p.curve, o.Category, static, o.Percent(), show.StartTime.Sub(now))
+	}
+	return prices
+}
+
+func (s *PriceService) findTheaterPricing(theaterId int) (*theaterPricing, error) {
+	theater, err := findTheater(s.models, theaterId)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := s.findPriceList(theater)
+	if err != nil {
+		return nil, err
+	}
+
+	curve, err := s.models.PriceCurves.Find(theaterId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &theaterPricing{*list, *curve}, nil
+}
+
+// findPriceList returns the prices of a theater in its currency. The default
+// ticket price is in the base currency.
+func (s *PriceService) findPriceList(theater *models.Theater) (*models.PriceList, error) {
+	list, err := s.models.PriceLists.Find(theater.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	basePrice, err := s.currencies.fromBase(s.basePrice, theater.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	completePriceList(list, basePrice)
+	list.Currency = theater.Currency
+
+	return list, nil
+}
+
+// completePriceList fills in the defaults for every seat category, tier and
+// ticket type missing from the list.
+func completePriceList(list *models.PriceList, basePrice int64) {
+	for _, category := range models.SeatCategories {
+		if _, ok := list.Categories[category]; !ok {
+			list.Categories[category] = basePrice
+		}
+	}
+
+	for tier, percent := range defaultTierRates {
+		if _, ok := list.Tiers[tier]; !ok {
+			list.Tiers[tier] = percent
+		}
+	}
+
+	for ticketType, percent := range defaultTicketTypeRates {
+		if _, ok := list.TicketTypes[ticketType]; !ok {
+			list.TicketTypes[ticketType] = percent
+		}
+	}
+}
+
+// categoryPrice is the static adult price of a seat category for shows of the
+// given tier. Fractions of the minor unit are dropped.
+func categoryPrice(list models.PriceList, category, tier string) int64 {
+	return list.Categories[category] * int64(list.Tiers[tier]) / 100
+}
+
+// ticketTypePrice scales an adult price to the given ticket type.
+func ticketTypePrice(list models.PriceList, adult int64, ticketType string) int64 {
+	return adult * int64(list.TicketTypes[ticketType]) / 100
+}
+
+// priceRange spans the prices of every ticket type of the given adult
+// category prices.
+func priceRange(list models.PriceList, prices map[string]int64) models.PriceRange {
+	var r models.PriceRange
+	first := true
+	for _, adult := range prices {
+		for ticketType := range list.TicketTypes {
+			price := ticketTypePrice(list, adult, ticketType)
+			if first || price < r.Min {
+				r.Min = price
+			}
+			if first || price > r.Max {
+				r.Max = price
+			}
+			first = false
+		}
+	}
+
+	return r
+}
+
+// defaultPriceTier picks the tier of a show from its start time: weekend
+// shows, then weekday matinees before matineeEnd, then evening shows.
+// Holidays have to be set explicitly.
+func defaultPriceTier(start time.Time) string {
+	switch {
+	case start.Weekday() == time.Saturday || start.Weekday() == time.Sunday:
+		return models.TierWeekend
+	case start.Hour() < matineeEnd:
+		return models.TierMatinee
+	default:
+		return models.TierEvening
+	}
+}
+
+type SimulatePricingInput struct {
+	Curve       *models.PriceCurve
+	Category    string
+	Tier        string
+	Occupancies []int
+	HoursBefore []int
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func testPriceList() models.PriceList {
+	list := models.PriceList{
+		Categories:  map[string]int64{models.CategoryVIP: 25000},
+		Tiers:       map[string]int{models.TierHoliday: 200},
+		TicketTypes: map[string]int{models.TicketChild: 50},
+	}
+	completePriceList(&list, 10000)
+	return list
+}
+
+func TestCompletePriceList(t *testing.T) {
+	list := testPriceList()
+
+	assert.Len(t, list.Categories, len(models.SeatCategories))
+	assert.Equal(t, int64(25000), list.Categories[models.CategoryVIP])
+	assert.Equal(t, int64(10000), list.Categories[models.CategoryStandard])
+
+	assert.Len(t, list.Tiers, len(models.PriceTiers))
+	assert.Equal(t, 200, list.Tiers[models.TierHoliday])
+	assert.Equal(t, 80, list.Tiers[models.TierMatinee])
+
+	assert.Len(t, list.TicketTypes, len(models.TicketTypes))
+	assert.Equal(t, 50, list.TicketTypes[models.TicketChild])
+	assert.Equal(t, 100, list.TicketTypes[models.TicketAdult])
+}
+
+func TestTicketPrice(t *testing.T) {
+	list := testPriceList()
+
+	tests := []struct {
+		name       string
+		category   string
+		tier       string
+		ticketType string
+		want       int64
+	}{
+		{"standard adult evening", models.CategoryStandard, models.TierEvening, models.TicketAdult, 10000},
+		{"vip adult evening", models.CategoryVIP, models.TierEvening, models.TicketAdult, 25000},
+		{"standard adult matinee", models.CategoryStandard, models.TierMatinee, models.TicketAdult, 8000},
+		{"vip child holiday", models.CategoryVIP, models.TierHoliday, models.TicketChild, 25000},
+		{"standard senior weekend", models.CategoryStandard, models.TierWeekend, models.TicketSenior, 8400},
+		{"standard student matinee", models.CategoryStandard, models.TierMatinee, models.TicketStudent, 6400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adult := categoryPrice(list, tt.category, tt.tier)
+			assert.Equal(t, tt.want, ticketTypePrice(list, adult, tt.ticketType))
+		})
+	}
+}
+
+func TestPriceRange(t *testing.T) {
+	list := testPriceList()
+
+	r := priceRange(list, map[string]int64{
+		models.CategoryStandard: 10000,
+		models.CategoryVIP:      25000,
+	})
+	assert.Equal(t, models.PriceRange{Min: 5000, Max: 25000}, r)
+
+	r = priceRange(list, map[string]int64{models.CategoryStandard: 8000})
+	assert.Equal(t, models.PriceRange{Min: 4000, Max: 8000}, r)
+}
+
+func TestDefaultPriceTier(t *testing.T) {
+	tests := []struct {
+		name  string
+		start time.Time
+		want  string
+	}{
+		{"weekday afternoon", time.Date(2026, 10, 20, 14, 0, 0, 0, time.UTC), models.TierMatinee},
+		{"weekday evening", time.Date(2026, 10, 20, 17, 0, 0, 0, time.UTC), models.TierEvening},
+		{"saturday afternoon", time.Date(2026, 10, 24, 14, 0, 0, 0, time.UTC), models.TierWeekend},
+		{"sunday evening", time.Date(2026, 10, 25, 20, 0, 0, 0, time.UTC), models.TierWeekend},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, defaultPriceTier(tt.start))
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+)
+
+var (
+	ErrPromoCodeNotFound      = errors.New("promo code not found")
+	ErrInvalidPromoCode       = errors.New("invalid promo code")
+	ErrPromoCodeNotApplicable = errors.New("promo code doesn't apply to this booking")
+	ErrPromoCodeExhausted     = errors.New("promo code redemption limit reached")
+)
+
+type PromoCodeService struct {
+	models     *models.Model
+	currencies *CurrencyService
+}
+
+// Create adds a promo code to a theater. Promo codes valid at every theater
+// can be created by admins only.
+func (s *PromoCodeService) Create(user *models.User, input CreatePromoCodeInput) (*models.PromoCode, error) {
+	if err := s.checkPromoCodeManager(user, input.TheaterID); err != nil {
+		return nil, err
+	}
+
+	promo := &models.PromoCode{
+		Code:           strings.ToUpper(input.Code),
+		TheaterID:      input.TheaterID,
+		CreatedBy:      &user.ID,
+		Kind:           input.Kind,
+		Value:          input.Value,
+		MovieID:        input.MovieID,
+		ShowsFrom:      input.ShowsFrom,
+		ShowsUntil:     input.ShowsUntil,
+		TicketTypes:    input.TicketTypes,
+		MinSeats:       input.MinSeats,
+		MaxRedemptions: input.MaxRedemptions,
+		MaxPerUser:     input.MaxPerUser,
+		Active:         true,
+	}
+
+	if promo.TicketTypes == nil {
+		promo.TicketTypes = []string{}
+	}
+	if promo.MinSeats == 0 {
+		promo.MinSeats = 1
+	}
+
+	if err := validatePromoCode(promo); err != nil {
+		return nil, err
+	}
+
+	if err := s.models.PromoCodes.Create(promo); err != nil {
+		switch {
+		case errors.Is(err, models.ErrDuplicate):
+			return nil, fmt.Errorf("%w: promo code %v", ErrDuplicate, promo.Code)
+		case errors.Is(err, models.ErrNotFound):
+			return nil, ErrMovieNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return promo, nil
+}
+
+// List returns the promo codes of a theater, or the promo codes valid at
+// every theater when theaterId is nil.
+func (s *PromoCodeService) List(user *models.User, theaterId *int) ([]models.PromoCode, error) {
+	if err := s.checkPromoCodeManager(user, theaterId); err != nil {
+		return nil, err
+	}
+
+	return s.models.PromoCodes.FindByTheater(theaterId)
+}
+
+func (s *PromoCodeService) Find(user *models.User, promoId int) (*models.PromoCode, error) {
+	promo, err := s.models.PromoCodes.Find(promoId)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, ErrPromoCodeNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	if err := s.checkPromoCodeManager(user, promo.TheaterID); err != nil {
+		return nil, err
+	}
+
+	return promo, nil
+}
+
+// Update changes the discount, restrictions and limits of a promo code.
+// Bookings that already redeemed it keep their discount.
+func (s *PromoCodeService) Update(user *models.User, promoId int, input UpdatePromoCodeInput) (*models.PromoCode, error) {
+	promo, err := s.Find(user, promoId)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Kind != nil {
+		promo.Kind = *input.Kind
+	}
+	if input.Value != nil {
+		promo.Value = *input.Value
+	}
+	if input.MovieID != nil {
+		promo.MovieID = input.MovieID
+	}
+	if input.ShowsFrom != nil {
+		promo.ShowsFrom = input.ShowsFrom
+	}
+	if input.ShowsUntil != nil {
+		promo.ShowsUntil = input.ShowsUntil
+	}
+	if input.TicketTypes != nil {
+		promo.TicketTypes = input.TicketTypes
+	}
+	if input.MinSeats != nil {
+		promo.MinSeats = *input.MinSeats
+	}
+	if input.MaxRedemptions != nil {
+		promo.MaxRedemptions = input.MaxRedemptions
+	}
+	if input.MaxPerUser != nil {
+		promo.MaxPerUser = input.MaxPerUser
+	}
+	if input.Active != nil {
+		promo.Active = *input.Active
+	}
+
+	if err := validatePromoCode(promo); err != nil {
+		return nil, err
+	}
+
+	if err := s.models.PromoCodes.Update(promo); err != nil {
+		switch {
+		case errors.Is(err, models.ErrEditConflict):
+			return nil, fmt.Errorf("%w: %v", ErrEditConflict, err)
+		case errors.Is(err, models.ErrNotFound):
+			return nil, ErrMovieNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return promo, nil
+}
+
+// Delete removes a promo code along with its redemption history. Bookings
+// that redeemed it keep their discount.
+func (s *PromoCodeService) Delete(user *models.User, promoId int) error {
+	if _, err := s.Find(user, promoId); err != nil {
+		return err
+	}
+
+	if err := s.models.PromoCodes.Delete(promoId); err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return ErrPromoCodeNotFound
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// apply looks up a promo code and works out its discount on the tickets of
+// a show. The redemption limits are checked again when the booking is
+// stored, since other checkouts may redeem the code meanwhile.
+func (s *PromoCodeService) apply(code string, show *models.Show, currency string, tickets []models.Ticket) (*models.PromoCode, int64, error) {
+	promo, err := s.models.PromoCodes.FindByCode(code)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, 0, ErrPromoCodeNotFound
+		default:
+			return nil, 0, err
+		}
+	}
+
+	// fixed discounts of promo codes valid at every theater are in the base
+	// currency.
+	if promo.Kind == models.PromoFixed && promo.TheaterID == nil {
+		promo.Value, err = s.currencies.fromBase(promo.Value, currency)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	discount, err := evaluatePromoCode(promo, show, tickets)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return promo, discount, nil
+}
+
+func (s *PromoCodeService) checkPromoCodeManager(user *models.User, theaterId *int) error {
+	if theaterId == nil {
+		if user.Role != "admin" {
+			return fmt.Errorf("%w: promo codes for every theater are managed by admins only", ErrUnauthorized)
+		}
+		return nil
+	}
+
+	_, err := findManagedTheater(s.models, user, *theaterId, "promo codes are managed by the theater manager only")
+	return err
+}
+
+// validatePromoCode checks the rules spanning several fields of a promo
+// code, which may be set separately by updates.
+func validatePromoCode(promo *models.PromoCode) error {
+	switch {
+	case promo.Kind == models.PromoPercent && promo.Value > 100:
+		return fmt.Errorf("%w: percent discount must be at most 100", ErrInvalidPromoCode)
+	case promo.ShowsFrom != nil && promo.ShowsUntil != nil && promo.ShowsUntil.Before(*promo.ShowsFrom):
+		return fmt.Errorf("%w: shows_until must be after shows_from", ErrInvalidPromoCode)
+	case promo.MaxRedemptions != nil && *promo.MaxRedemptions < promo.Redemptions:
+		return fmt.Errorf("%w: promo code was redeemed %v times already", ErrInvalidPromoCode, promo.Redemptions)
+	}
+
+	return nil
+}
+
+// evaluatePromoCode returns the discount a promo code gives on the tickets of
+// a show. Percent discounts apply to the tickets of the promo code's ticket
+// types only; fixed discounts never exceed the price of those tickets.
+func evaluatePromoCode(promo *models.PromoCode, show *models.Show, tickets []models.Ticket) (int64, error) {
+	switch {
+	case !promo.Active:
+		return 0, fmt.Errorf("%w: promo code is inactive", ErrPromoCodeNotApplicable)
+	case promo.MaxRedemptions != nil && promo.Redemptions >= *promo.MaxRedemptions:
+		return 0, ErrPromoCodeExhausted
+	case promo.TheaterID != nil && *promo.TheaterID != show.TheaterID:
+		return 0, fmt.Errorf("%w: not valid at this theater", ErrPromoCodeNotApplicable)
+	case promo.MovieID != nil && *promo.MovieID != show.MovieID:
+		return 0, fmt.Errorf("%w: not valid for this movie", ErrPromoCodeNotApplicable)
+	case promo.ShowsFrom != nil && show.StartTime.Before(*promo.ShowsFrom):
+		return 0, fmt.Errorf("%w: valid for shows from %v", ErrPromoCodeNotApplicable, promo.ShowsFrom.Format(time.DateOnly))
+	case promo.ShowsUntil != nil && show.StartTime.After(*promo.ShowsUntil):
+		return 0, fmt.Errorf("%w: valid for shows until %v", ErrPromoCodeNotApplicable, promo.ShowsUntil.Format(time.DateOnly))
+	}
+
+	var eligible int
+	var total int64
+	for _, ticket := range tickets {
+		if len(promo.TicketTypes) > 0 && !slices.Contains(promo.TicketTypes, ticket.TicketType) {
+			continue
+		}
+		eligible++
+		total += ticket.Price
+	}
+
+	if eligible == 0 || eligible < promo.MinSeats {
+		return 0, fmt.Errorf("%w: requires at least %v eligible seats", ErrPromoCodeNotApplicable, max(promo.MinSeats, 1))
+	}
+
+	if promo.Kind == models.PromoPercent {
+		return total * promo.Value / 100, nil
+	}
+
+	return min(promo.Value, total), nil
+}
+
+type CreatePromoCodeInput struct {
+	TheaterID      *int
+	Code           string
+	Kind           string
+	Value          int64
+	MovieID        *string
+	ShowsFrom      *time.Time
+	ShowsUntil     *time.Time
+	TicketTypes    []string
+	MinSeats       int
+	MaxRedemptions *int
+	MaxPerUser     *int
+}
+
+type UpdatePromoCodeInput struct {
+	Kind           *string
+	Value          *int64
+	MovieID        *string
+	ShowsFrom      *time.Time
+	ShowsUntil     *time.Time
+	TicketTypes    []string
+	MinSeats       *int
+	MaxRedemptions *int
+	MaxPerUser     *int
+	Active         *bool
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluatePromoCode(t *testing.T) {
+	theaterID, otherTheaterID := 1, 2
+	movieID, otherMovieID := "tt0111161", "tt0068646"
+	two := 2
+
+	start := time.Date(2026, 10, 20, 19, 0, 0, 0, time.UTC)
+	before := start.Add(-24 * time.Hour)
+	after := start.Add(24 * time.Hour)
+
+	show := &models.Show{TheaterID: theaterID, MovieID: movieID, StartTime: start}
+	tickets := []models.Ticket{
+		{TicketType: models.TicketAdult, Price: 10000},
+		{TicketType: models.TicketChild, Price: 6000},
+		{TicketType: models.TicketChild, Price: 6000},
+	}
+
+	tests := []struct {
+		name  string
+		promo models.PromoCode
+		want  int64
+		err   error
+	}{
+		{
+			name:  "percent of every ticket",
+			promo: models.PromoCode{Kind: models.PromoPercent, Value: 10, Active: true},
+			want:  2200,
+		},
+		{
+			name:  "fixed",
+			promo: models.PromoCode{Kind: models.PromoFixed, Value: 5000, Active: true},
+			want:  5000,
+		},
+		{
+			name:  "fixed capped at the eligible tickets",
+			promo: models.PromoCode{Kind: models.PromoFixed, Value: 50000, TicketTypes: []string{models.TicketChild}, Active: true},
+			want:  12000,
+		},
+		{
+			name:  "percent of eligible ticket types only",
+			promo: models.PromoCode{Kind: models.PromoPercent, Value: 50, TicketTypes: []string{models.TicketChild}, Active: true},
+			want:  6000,
+		},
+		{
+			name:  "matching restrictions",
+			promo: models.PromoCode{Kind: models.PromoPercent, Value: 100, TheaterID: &theaterID, MovieID: &movieID, ShowsFrom: &before, ShowsUntil: &after, Active: true},
+			want:  22000,
+		},
+		{
+			name:  "inactive",
+			promo: models.PromoCode{Kind: models.PromoPercent, Value: 10},
+			err:   ErrPromoCodeNotApplicable,
+		},
+		{
+			name:  "redemption limit reached",
+			promo: models.PromoCode{Kind: models.PromoPercent, Value: 10, MaxRedemptions: &two, Redemptions: 2, Active: true},
+			err:   ErrPromoCodeExhausted,
+		},
+		{
+			name:  "other theater",
+			promo: models.PromoCode{Kind: models.PromoPercent, Value: 10, TheaterID: &otherTheaterID, Active: true},
+			err:   ErrPromoCodeNotApplicable,
+		},
+		{
+			name:  "other movie",
+			promo: models.PromoCode{Kind: models.PromoPercent, Value: 10, MovieID: &otherMovieID, Active: true},
+			err:   ErrPromoCodeNotApplicable,
+		},
+		{
+			name:  "show before the date range",
+			promo: models.PromoCode{Kind: models.PromoPercent, Value: 10, ShowsFrom: &after, Active: true},
+			err:   ErrPromoCodeNotApplicable,
+		},
+		{
+			name:  "show after the date range",
+			promo: models.PromoCode{Kind: models.PromoPercent, Value: 10, ShowsUntil: &before, Active: true},
+			err:   ErrPromoCodeNotApplicable,
+		},
+		{
+			name:  "not enough eligible seats",
+			promo: models.PromoCode{Kind: models.PromoPercent, Value: 10, TicketTypes: []string{models.TicketChild}, MinSeats: 3, Active: true},
+			err:   ErrPromoCodeNotApplicable,
+		},
+		{
+			name:  "no eligible ticket types",
+			promo: models.PromoCode{Kind: models.PromoPercent, Value: 10, TicketTypes: []string{models.TicketSenior}, Active: true},
+			err:   ErrPromoCodeNotApplicable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			discount, err := evaluatePromoCode(&tt.promo, show, tickets)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, discount)
+		})
+	}
+}
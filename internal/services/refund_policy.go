@@ -0,0 +1,48 @@
+package services
+
+import (
+	"time"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+)
+
+// Refund is the outcome of evaluating a theater's refund policy for a
+// cancellation. Amount is paid back to the customer and GiftCardAmount to
+// the gift card the booking was partly paid with.
+type Refund struct {
+	Percent        int   `json:"percent"`
+	Amount         int64 `json:"amount"`
+	GiftCardAmount int64 `json:"gift_card_amount"`
+}
+
+// evaluateRefundPolicy picks the rule with the longest notice the
+// cancellation still satisfies and applies it to the paid amount. Without a
+// matching rule nothing is refunded. Partial amounts are rounded down.
+func evaluateRefundPolicy(rules []models.RefundRule, amount int64, notice time.Duration) Refund {
+	var (
+		best  *models.RefundRule
+		hours = int(notice / time.Hour)
+	)
+
+	if notice < 0 {
+		return Refund{}
+	}
+
+	for i, rule := range rules {
+		if hours < rule.MinHoursBefore {
+			continue
+		}
+		if best == nil || rule.MinHoursBefore > best.MinHoursBefore {
+			best = &rules[i]
+		}
+	}
+
+	if best == nil {
+		return Refund{}
+	}
+
+	return Refund{
+		Percent: best.Percent,
+		Amount:  amount * int64(best.Percent) / 100,
+	}
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluateRefundPolicy(t *testing.T) {
+	rules := []models.RefundRule{
+		{MinHoursBefore: 2, Percent: 50},
+		{MinHoursBefore: 24, Percent: 100},
+		{MinHoursBefore: 0, Percent: 0},
+	}
+
+	tests := []struct {
+		name   string
+		rules  []models.RefundRule
+		amount int64
+		notice time.Duration
+		want   Refund
+	}{
+		{
+			name:   "full refund well ahead",
+			rules:  rules,
+			amount: 30000,
+			notice: 72 * time.Hour,
+			want:   Refund{Percent: 100, Amount: 30000},
+		},
+		{
+			name:   "full refund exactly at the cutoff",
+			rules:  rules,
+			amount: 30000,
+			notice: 24 * time.Hour,
+			want:   Refund{Percent: 100, Amount: 30000},
+		},
+		{
+			name:   "half refund just under a day",
+			rules:  rules,
+			amount: 30000,
+			notice: 23*time.Hour + 59*time.Minute,
+			want:   Refund{Percent: 50, Amount: 15000},
+		},
+		{
+			name:   "no refund close to the show",
+			rules:  rules,
+			amount: 30000,
+			notice: 90 * time.Minute,
+			want:   Refund{Percent: 0, Amount: 0},
+		},
+		{
+			name:   "partial amounts round down",
+			rules:  rules,
+			amount: 15001,
+			notice: 3 * time.Hour,
+			want:   Refund{Percent: 50, Amount: 7500},
+		},
+		{
+			name:   "no rules no refund",
+			rules:  nil,
+			amount: 30000,
+			notice: 72 * time.Hour,
+			want:   Refund{},
+		},
+		{
+			name:   "show already started",
+			rules:  []models.RefundRule{{MinHoursBefore: 0, Percent: 100}},
+			amount: 30000,
+			notice: -time.Minute,
+			want:   Refund{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := evaluateRefundPolicy(tc.rules, tc.amount, tc.notice)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+	"github.com/AhmadAbdelrazik/showtime/pkg/money"
+)
+
+// reportPeriod is the period reported on by default, up to now.
+const reportPeriod = 30 * 24 * time.Hour
+
+type ReportService struct {
+	models     *models.Model
+	currencies *CurrencyService
+}
+
+// Sales reports the sales of every theater between from and until, the last
+// reportPeriod by default, in the theaters' own currencies and converted to
+// the given one, the base currency by default, at the current exchange
+// rates.
+func (s *ReportService) Sales(user *models.User, from, until *time.Time, currency string) (*SalesReport, error) {
+	if user.Role != "admin" {
+		return nil, fmt.Errorf("%w: sales reports are available to admins only", ErrUnauthorized)
+	}
+
+	end := time.Now()
+	if until != nil {
+		end = *until
+	}
+
+	start := end.Add(-reportPeriod)
+	if from != nil {
+		start = *from
+	}
+
+	if currency == "" {
+		currency = s.currencies.base
+	}
+
+	sales, err := s.models.Bookings.SalesByTheater(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := s.currencies.converter()
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := newSalesReport(sales, c, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	report.From, report.Until = start, end
+
+	return report, nil
+}
+
+// SalesReport is what the bookings paid within a period brought in, by
+// theater and in total. Net is Gross less the refunds of the bookings
+// cancelled since.
+type SalesReport struct {
+	From     time.Time          `json:"from"`
+	Until    time.Time          `json:"until"`
+	Currency string             `json:"currency"`
+	Theaters []TheaterSalesLine `json:"theaters"`
+	Total    SalesTotals        `json:"total"`
+}
+
+// TheaterSalesLine is the sales of a theater in a currency, and their value
+// in the report's currency.
+type TheaterSalesLine struct {
+	TheaterID int         `json:"theater_id"`
+	Name      string      `json:"name"`
+	Bookings  int         `json:"bookings"`
+	Tickets   int         `json:"tickets"`
+	Sales     SalesTotals `json:"sales"`
+	Converted SalesTotals `json:"converted"`
+}
+
+type SalesTotals struct {
+	Gross    money.Money `json:"gross"`
+	Refunded money.Money `json:"refunded"`
+	Net      money.Money `json:"net"`
+}
+
+func newSalesTotals(gross, refunded int64, currency string) SalesTotals {
+	return SalesTotals{
+		Gross:    money.New(gross, currency),
+		Refunded: money.New(refunded, currency),
+		Net:      money.New(gross-refunded, currency),
+	}
+}
+
+// newSalesReport converts the sales of every theater to the report's
+// currency. Each theater's gross and refunds are converted on their own, so
+// the totals add up to the sum of the lines.
+func newSalesReport(sales []models.TheaterSales, c *converter, currency string) (*SalesReport, error) {
+	report := &SalesReport{
+		Currency: currency,
+		Theaters: make([]TheaterSalesLine, 0, len(sales)),
+	}
+
+	var gross, refunded int64
+	for _, s := range sales {
+		g, err := c.convert(money.New(s.Gross, s.Currency), currency)
+		if err != nil {
+			return nil, err
+		}
+		r, err := c.convert(money.New(s.Refunded, s.Currency), currency)
+		if err != nil {
+			return nil, err
+		}
+
+		report.Theaters = append(report.Theaters, TheaterSalesLine{
+			TheaterID: s.TheaterID,
+			Name:      s.TheaterName,
+			Bookings:  s.Bookings,
+			Tickets:   s.Tickets,
+			Sales:     newSalesTotals(s.Gross, s.Refunded, s.Currency),
+			Converted: newSalesTotals(g.Amount, r.Amount, currency),
+		})
+
+		gross += g.Amount
+		refunded += r.Amount
+	}
+
+	report.Total = newSalesTotals(gross, refunded, currency)
+
+	return report, nil
+}
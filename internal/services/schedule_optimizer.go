@@ -0,0 +1,540 @@
+package services
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+)
+
+var ErrInvalidScheduleRequest = errors.New("invalid schedule request")
+
+const (
+	defaultOpeningTime    = "10:00"
+	defaultClosingTime    = "00:00"
+	defaultPrimeTimeFrom  = "18:00"
+	defaultPrimeTimeUntil = "22:00"
+
+	// scheduleSlot is the granularity of the proposed start times.
+	scheduleSlot = 5 * time.Minute
+)
+
+// ProposeSchedule works out a week of shows for a theater, starting at
+// WeekStart, without scheduling them. Every movie is given its target number
+// of screenings where the halls have room, the most popular ones first, and
+// prime time goes to the popular movies before any other screening is
+// placed. Shows already scheduled are kept clear of, every hall's turnaround
+// kept free between shows, and the days the theater is closed left out.
+func (s *ShowService) ProposeSchedule(user *models.User, theaterId int, input ScheduleRequest) (*ProposedSchedule, error) {
+	theater, err := findManagedTheater(s.models, user, theaterId, "scheduling shows is available for theater's manager only.")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(theater.Halls) == 0 {
+		return nil, fmt.Errorf("%w: theater has no halls", ErrHallNotFound)
+	}
+
+	loc, err := scheduleLocation(input.Timezone, *theater.Hours)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown time zone %q", ErrInvalidScheduleRequest, input.Timezone)
+	}
+	input.WeekStart = onDate(input.WeekStart, loc)
+
+	days, err := newScheduleDays(input)
+	if err != nil {
+		return nil, err
+	}
+
+	days = openDays(days, *theater.Hours, input.OpeningTime != "" || input.ClosingTime != "")
+	if len(days) == 0 {
+		return nil, fmt.Errorf("%w: theater is closed all week", ErrInvalidScheduleRequest)
+	}
+
+	movies := make([]scheduleMovie, 0, len(input.Movies))
+	for _, target := range input.Movies {
+		movie, err := s.movieService.Find(target.MovieID)
+		if err != nil {
+			switch {
+			case errors.Is(err, models.ErrNotFound):
+				return nil, fmt.Errorf("%w: %v", ErrMovieNotFound, target.MovieID)
+			default:
+				return nil, err
+			}
+		}
+
+		runtime, err := parseRuntime(movie.Runtime)
+		if err != nil {
+			return nil, err
+		}
+
+		rating, _ := strconv.ParseFloat(movie.ImdbRating, 64)
+
+		movies = append(movies, scheduleMovie{
+			ID:         movie.ImdbID,
+			Title:      movie.Title,
+			Runtime:    runtime,
+			Screenings: target.Screenings,
+			Priority:   target.Priority,
+			Rating:     rating,
+		})
+	}
+
+	from, until := days[0].Open.Add(-scheduleMargin), days[len(days)-1].Close.Add(scheduleMargin)
+
+	halls := make([]scheduleHall, 0, len(theater.Halls))
+	for _, h := range theater.Halls {
+		hall, err := s.models.Halls.FindByCodeWithSchedule(theaterId, h.Code, from, until)
+		if err != nil {
+			return nil, err
+		}
+		halls = append(halls, scheduleHall{
+			Code:       hall.Code,
+			Turnaround: hall.Schedule.Turnaround,
+			Shows:      hall.Schedule.Shows,
+		})
+	}
+
+	schedule := optimizeSchedule(days, halls, movies, time.Now())
+	schedule.WeekStart = input.WeekStart
+
+	return schedule, nil
+}
+
+// CommitSchedule schedules a set of shows, such as a proposed schedule, all
+// at once. Every show is checked against the theater's operating hours, its
+// hall's schedule and the other shows of the set; a single conflict
+// schedules no show and returns ErrScheduleConflict along with the report.
+func (s *ShowService) CommitSchedule(user *models.User, theaterId int, inputs []CreateShowInput) (*ScheduleReport, error) {
+	theater, err := findManagedTheater(s.models, user, theaterId, "scheduling shows is available for theater's manager only.")
+	if err != nil {
+		return nil, err
+	}
+
+	runtimes := make(map[string]time.Duration)
+	titles := make(map[string]string)
+	shows := make([]models.Show, 0, len(inputs))
+	for _, input := range inputs {
+		if _, ok := runtimes[input.MovieID]; !ok {
+			movie, err := s.movieService.Find(input.MovieID)
+			if err != nil {
+				switch {
+				case errors.Is(err, models.ErrNotFound):
+					return nil, fmt.Errorf("%w: %v", ErrMovieNotFound, input.MovieID)
+				default:
+					return nil, err
+				}
+			}
+
+			runtime, err := parseRuntime(movie.Runtime)
+			if err != nil {
+				return nil, err
+			}
+			runtimes[input.MovieID], titles[input.MovieID] = runtime, movie.Title
+		}
+
+		if input.EndTime.Sub(input.StartTime) < runtimes[input.MovieID] {
+			return nil, fmt.Errorf("%w (%v at %v)", ErrInvalidShowDuration, titles[input.MovieID], input.StartTime)
+		}
+
+		show := models.Show{
+			MovieID:    input.MovieID,
+			MovieTitle: titles[input.MovieID],
+			TheaterID:  theater.ID,
+			HallCode:   input.HallCode,
+			StartTime:  input.StartTime,
+			EndTime:    input.EndTime,
+			PriceTier:  input.PriceTier,
+		}
+		if show.PriceTier == "" {
+			show.PriceTier = defaultPriceTier(show.StartTime)
+		}
+
+		shows = append(shows, show)
+	}
+
+	// every hall's shows are planned against that hall's schedule, in order
+	// of start time, and the occurrences reported in the order given.
+	report := &ScheduleReport{
+		Mode:        ScheduleAllOrNothing,
+		Occurrences: make([]Occurrence, len(shows)),
+	}
+
+	byHall := make(map[string][]int)
+	for i, show := range shows {
+		byHall[show.HallCode] = append(byHall[show.HallCode], i)
+	}
+
+	for code, indexes := range byHall {
+		slices.SortFunc(indexes, func(a, b int) int {
+			return shows[a].StartTime.Compare(shows[b].StartTime)
+		})
+
+		hallShows := make([]models.Show, 0, len(indexes))
+		for _, i := range indexes {
+			hallShows = append(hallShows, shows[i])
+		}
+
+		from, to := hallShows[0].StartTime.Add(-scheduleMargin), hallShows[len(hallShows)-1].EndTime.Add(scheduleMargin)
+
+		hall, err := s.models.Halls.FindByCodeWithSchedule(theaterId, code, from, to)
+		if err != nil {
+			switch {
+			case errors.Is(err, models.ErrNotFound):
+				return nil, fmt.Errorf("%w: %v", ErrHallNotFound, code)
+			default:
+				return nil, err
+			}
+		}
+
+		hallReport := planShows(*hall.Schedule, *theater.Hours, hallShows, ScheduleBestEffort, time.Now())
+		for j, i := range indexes {
+			report.Occurrences[i] = hallReport.Occurrences[j]
+		}
+		report.Created += hallReport.Created
+		report.Conflicts += hallReport.Conflicts
+	}
+
+	if report.Conflicts > 0 {
+		report.skipCreated()
+
+		return report, fmt.Errorf("%w: %v of %v shows conflict", ErrScheduleConflict, report.Conflicts, len(shows))
+	}
+
+	created := make([]*models.Show, 0, len(shows))
+	for i := range shows {
+		created = append(created, &shows[i])
+	}
+
+	if err := s.models.Shows.CreateMany(created); err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, ErrHallNotFound
+		case errors.Is(err, models.ErrInvalidSchedule):
+			report.skipCreated()
+			return report, fmt.Errorf("%w: hall was booked meanwhile, %v", ErrScheduleConflict, err)
+		default:
+			return nil, err
+		}
+	}
+
+	for i := range shows {
+		report.Occurrences[i].ShowID = shows[i].ID
+	}
+
+	return report, nil
+}
+
+// scheduleDay is when a theater is open on a day of the week, and the prime
+// time of that day. Closing and prime time at or before their start are on
+// the next day.
+type scheduleDay struct {
+	Open       time.Time
+	Close      time.Time
+	PrimeFrom  time.Time
+	PrimeUntil time.Time
+}
+
+func newScheduleDays(input ScheduleRequest) ([]scheduleDay, error) {
+	clocks := []string{input.OpeningTime, input.ClosingTime, input.PrimeTimeFrom, input.PrimeTimeUntil}
+	defaults := []string{defaultOpeningTime, defaultClosingTime, defaultPrimeTimeFrom, defaultPrimeTimeUntil}
+
+	parsed := make([]time.Time, len(clocks))
+	for i, clock := range clocks {
+		if clock == "" {
+			clock = defaults[i]
+		}
+
+		t, err := time.Parse("15:04", clock)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid time %q", ErrInvalidScheduleRequest, clock)
+		}
+		parsed[i] = t
+	}
+
+	at := func(day, clock time.Time) time.Time {
+		return time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, day.Location())
+	}
+
+	days := make([]scheduleDay, 0, 7)
+	for d := range 7 {
+		day := input.WeekStart.AddDate(0, 0, d)
+
+		sd := scheduleDay{
+			Open:       at(day, parsed[0]),
+			Close:      at(day, parsed[1]),
+			PrimeFrom:  at(day, parsed[2]),
+			PrimeUntil: at(day, parsed[3]),
+		}
+		if !sd.Close.After(sd.Open) {
+			sd.Close = sd.Close.AddDate(0, 0, 1)
+		}
+		if !sd.PrimeUntil.After(sd.PrimeFrom) {
+			sd.PrimeUntil = sd.PrimeUntil.AddDate(0, 0, 1)
+		}
+
+		days = append(days, sd)
+	}
+
+	return days, nil
+}
+
+// openDays fits the days to the theater's operating hours, leaving out the
+// days it is closed. The days take the theater's weekly hours, if it has any,
+// unless keepHours; otherwise they are only cut short where the theater
+// opens later or closes earlier.
+func openDays(days []scheduleDay, hours models.OperatingHours, keepHours bool) []scheduleDay {
+	open := make([]scheduleDay, 0, len(days))
+	for _, day := range days {
+		opens, closes, ok := hours.On(day.Open)
+		if !ok {
+			continue
+		}
+
+		if keepHours || len(hours.Weekly) == 0 {
+			day.Open, day.Close = later(day.Open, opens), earlier(day.Close, closes)
+		} else {
+			day.Open, day.Close = opens, closes
+		}
+
+		if day.Close.After(day.Open) {
+			open = append(open, day)
+		}
+	}
+
+	return open
+}
+
+type scheduleHall struct {
+	Code       string
+	Turnaround time.Duration
+	Shows      []models.Show
+}
+
+type scheduleMovie struct {
+	ID         string
+	Title      string
+	Runtime    time.Duration
+	Screenings int
+	Priority   int
+	Rating     float64
+}
+
+// optimizeSchedule places the screenings of the movies in the halls, greedily
+// and by popularity: higher priority first, then higher rating. First every
+// movie in turn takes as many shows starting in prime time as it needs and
+// the halls have room for, then the remaining screenings are placed anywhere
+// within opening hours. A movie's screenings are spread over the days with the
+// fewest of them, each at the earliest start any hall has free that day.
+func optimizeSchedule(days []scheduleDay, halls []scheduleHall, movies []scheduleMovie, now time.Time) *ProposedSchedule {
+	halls = slices.Clone(halls)
+	for i := range halls {
+		halls[i].Shows = slices.Clone(halls[i].Shows)
+	}
+
+	movies = slices.Clone(movies)
+	slices.SortStableFunc(movies, func(a, b scheduleMovie) int {
+		return cmp.Or(cmp.Compare(b.Priority, a.Priority), cmp.Compare(b.Rating, a.Rating))
+	})
+
+	schedule := &ProposedSchedule{
+		Shows:       []ProposedShow{},
+		Unscheduled: []UnscheduledMovie{},
+	}
+
+	perDay := make([][]int, len(movies))
+	for i := range movies {
+		perDay[i] = make([]int, len(days))
+	}
+
+	place := func(m int, primeTime bool) bool {
+		movie := movies[m]
+
+		order := make([]int, len(days))
+		for d := range order {
+			order[d] = d
+		}
+		slices.SortStableFunc(order, func(a, b int) int {
+			return cmp.Compare(perDay[m][a], perDay[m][b])
+		})
+
+		for _, d := range order {
+			from, until := days[d].Open, days[d].Close
+			if primeTime {
+				from, until = later(from, days[d].PrimeFrom), earlier(until, days[d].PrimeUntil)
+			}
+			from = later(from, now)
+
+			best, bestStart := -1, time.Time{}
+			for h := range halls {
+				start, ok := earliestStart(halls[h].Shows, from, until, days[d].Close, movie.Runtime, halls[h].Turnaround)
+				if ok && (best < 0 || start.Before(bestStart)) {
+					best, bestStart = h, start
+				}
+			}
+			if best < 0 {
+				continue
+			}
+
+			show := models.Show{
+				MovieID:    movie.ID,
+				MovieTitle: movie.Title,
+				HallCode:   halls[best].Code,
+				StartTime:  bestStart,
+				EndTime:    bestStart.Add(movie.Runtime),
+			}
+			halls[best].Shows = append(halls[best].Shows, show)
+
+			schedule.Shows = append(schedule.Shows, ProposedShow{
+				MovieID:    show.MovieID,
+				MovieTitle: show.MovieTitle,
+				HallCode:   show.HallCode,
+				StartTime:  show.StartTime,
+				EndTime:    show.EndTime,
+				PrimeTime:  !bestStart.Before(days[d].PrimeFrom) && !bestStart.After(days[d].PrimeUntil),
+			})
+			perDay[m][d]++
+
+			return true
+		}
+
+		return false
+	}
+
+	remaining := make([]int, len(movies))
+	for m, movie := range movies {
+		remaining[m] = movie.Screenings
+	}
+
+	for _, primeTime := range []bool{true, false} {
+		for m := range movies {
+			for remaining[m] > 0 && place(m, primeTime) {
+				remaining[m]--
+			}
+		}
+	}
+
+	for m, movie := range movies {
+		if remaining[m] > 0 {
+			schedule.Unscheduled = append(schedule.Unscheduled, UnscheduledMovie{
+				MovieID: movie.ID,
+				Title:   movie.Title,
+				Missing: remaining[m],
+			})
+		}
+	}
+
+	slices.SortFunc(schedule.Shows, func(a, b ProposedShow) int {
+		return cmp.Or(a.StartTime.Compare(b.StartTime), cmp.Compare(a.HallCode, b.HallCode))
+	})
+
+	for _, show := range schedule.Shows {
+		if show.PrimeTime {
+			schedule.PrimeTimeShows++
+		}
+	}
+
+	return schedule
+}
+
+// earliestStart finds the earliest start, on the scheduleSlot grid, from from
+// to until, of a show of runtime ending by close and clear by turnaround of
+// the shows of a hall. Only the start of the window and the turnaround after
+// each show can be the earliest start.
+func earliestStart(shows []models.Show, from, until, close time.Time, runtime, turnaround time.Duration) (time.Time, bool) {
+	candidates := []time.Time{from}
+	for _, show := range shows {
+		candidates = append(candidates, show.EndTime.Add(turnaround))
+	}
+	slices.SortFunc(candidates, time.Time.Compare)
+
+	for _, start := range candidates {
+		if rounded := start.Truncate(scheduleSlot); rounded.Before(start) {
+			start = rounded.Add(scheduleSlot)
+		}
+
+		if start.Before(from) {
+			continue
+		}
+		if start.After(until) || start.Add(runtime).After(close) {
+			break
+		}
+
+		end := start.Add(runtime)
+		free := !slices.ContainsFunc(shows, func(show models.Show) bool {
+			return show.StartTime.Before(end.Add(turnaround)) && start.Before(show.EndTime.Add(turnaround))
+		})
+		if free {
+			return start, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func later(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func earlier(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// ScheduleRequest asks for a week of shows from the date of WeekStart, with
+// Screenings of each movie. Opening and prime time
+// hours are "15:04" times, 10:00 to 00:00 and 18:00 to 22:00 by default,
+// opening hours defaulting to the theater's own when it has any.
+type ScheduleRequest struct {
+	WeekStart time.Time
+	// Timezone is the time zone of WeekStart and the times of day, the
+	// theater's if empty.
+	Timezone       string
+	Movies         []ScheduleTarget
+	OpeningTime    string
+	ClosingTime    string
+	PrimeTimeFrom  string
+	PrimeTimeUntil string
+}
+
+// ScheduleTarget is how many screenings a movie should get. Movies with a
+// higher Priority, or with the same priority and a higher IMDb rating, count
+// as more popular.
+type ScheduleTarget struct {
+	MovieID    string
+	Screenings int
+	Priority   int
+}
+
+// ProposedSchedule is a week of shows proposed for a theater, and the
+// screenings there was no room for.
+type ProposedSchedule struct {
+	WeekStart      time.Time          `json:"week_start"`
+	Shows          []ProposedShow     `json:"shows"`
+	PrimeTimeShows int                `json:"prime_time_shows"`
+	Unscheduled    []UnscheduledMovie `json:"unscheduled"`
+}
+
+type ProposedShow struct {
+	MovieID    string    `json:"movie_id"`
+	MovieTitle string    `json:"movie_title"`
+	HallCode   string    `json:"hall_code"`
+	StartTime  time.Time `json:"start_time"`
+	EndTime    time.Time `json:"end_time"`
+	PrimeTime  bool      `json:"prime_time"`
+}
+
+type UnscheduledMovie struct {
+	MovieID string `json:"movie_id"`
+	Title   string `json:"title"`
+	Missing int    `json:"missing"`
+}
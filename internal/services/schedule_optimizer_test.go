@@ -0,0 +1,207 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewScheduleDays(t *testing.T) {
+	monday := time.Date(2026, time.November, 2, 0, 0, 0, 0, time.UTC)
+
+	days, err := newScheduleDays(ScheduleRequest{WeekStart: monday})
+	assert.NoError(t, err)
+	assert.Len(t, days, 7)
+
+	assert.Equal(t, time.Date(2026, time.November, 2, 10, 0, 0, 0, time.UTC), days[0].Open)
+	assert.Equal(t, time.Date(2026, time.November, 3, 0, 0, 0, 0, time.UTC), days[0].Close)
+	assert.Equal(t, time.Date(2026, time.November, 8, 18, 0, 0, 0, time.UTC), days[6].PrimeFrom)
+	assert.Equal(t, time.Date(2026, time.November, 8, 22, 0, 0, 0, time.UTC), days[6].PrimeUntil)
+
+	days, err = newScheduleDays(ScheduleRequest{WeekStart: monday, OpeningTime: "12:00", ClosingTime: "02:00"})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2026, time.November, 3, 2, 0, 0, 0, time.UTC), days[0].Close)
+
+	_, err = newScheduleDays(ScheduleRequest{WeekStart: monday, PrimeTimeFrom: "7pm"})
+	assert.ErrorIs(t, err, ErrInvalidScheduleRequest)
+}
+
+func TestOpenDays(t *testing.T) {
+	monday := time.Date(2026, time.November, 2, 0, 0, 0, 0, time.UTC)
+	at := func(day, hour int) time.Time {
+		return time.Date(2026, time.November, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	days, err := newScheduleDays(ScheduleRequest{WeekStart: monday})
+	assert.NoError(t, err)
+
+	hours := models.OperatingHours{
+		Weekly: []models.OpeningHours{
+			{Weekday: "mon", Opens: "12:00", Closes: "23:00"},
+			{Weekday: "tue", Opens: "12:00", Closes: "23:00"},
+			{Weekday: "fri", Opens: "12:00", Closes: "02:00"},
+		},
+		Closures: []models.Closure{{From: "2026-11-03", Until: "2026-11-03"}},
+	}
+
+	// the theater's hours replace the default ones.
+	open := openDays(days, hours, false)
+	assert.Len(t, open, 2)
+	assert.Equal(t, at(2, 12), open[0].Open)
+	assert.Equal(t, at(2, 23), open[0].Close)
+	assert.Equal(t, at(6, 12), open[1].Open)
+	assert.Equal(t, at(7, 2), open[1].Close)
+
+	// requested hours are only cut short.
+	open = openDays(days, hours, true)
+	assert.Len(t, open, 2)
+	assert.Equal(t, at(2, 12), open[0].Open)
+	assert.Equal(t, at(7, 0), open[1].Close)
+
+	// a theater without weekly hours is open every day.
+	assert.Equal(t, days, openDays(days, models.OperatingHours{}, false))
+}
+
+func TestEarliestStart(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2026, time.November, 2, hour, minute, 0, 0, time.UTC)
+	}
+
+	shows := []models.Show{
+		{StartTime: at(10, 0), EndTime: at(12, 0)},
+		{StartTime: at(14, 0), EndTime: at(16, 0)},
+	}
+
+	tests := []struct {
+		name        string
+		from, until time.Time
+		runtime     time.Duration
+		want        time.Time
+		wantOk      bool
+	}{
+		{
+			name:    "start of the window",
+			from:    at(8, 0),
+			until:   at(23, 0),
+			runtime: time.Hour,
+			want:    at(8, 0),
+			wantOk:  true,
+		},
+		{
+			name:    "after a show and its turnaround, rounded to the slot",
+			from:    at(11, 0),
+			until:   at(23, 0),
+			runtime: 90 * time.Minute,
+			want:    at(12, 15),
+			wantOk:  true,
+		},
+		{
+			name:    "gap too short for the movie",
+			from:    at(11, 0),
+			until:   at(23, 0),
+			runtime: 2 * time.Hour,
+			want:    at(16, 15),
+			wantOk:  true,
+		},
+		{
+			name:    "window start rounded up",
+			from:    at(17, 2),
+			until:   at(23, 0),
+			runtime: time.Hour,
+			want:    at(17, 5),
+			wantOk:  true,
+		},
+		{
+			name:    "no room before closing",
+			from:    at(22, 0),
+			until:   at(23, 0),
+			runtime: 3 * time.Hour,
+			wantOk:  false,
+		},
+		{
+			name:    "no start within the window",
+			from:    at(11, 0),
+			until:   at(12, 0),
+			runtime: time.Hour,
+			wantOk:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := earliestStart(shows, tt.from, tt.until, at(23, 59), tt.runtime, 15*time.Minute)
+			assert.Equal(t, tt.wantOk, ok)
+			if tt.wantOk {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestOptimizeSchedule(t *testing.T) {
+	monday := time.Date(2026, time.November, 2, 0, 0, 0, 0, time.UTC)
+	now := monday.Add(-time.Hour)
+
+	days, err := newScheduleDays(ScheduleRequest{
+		WeekStart:      monday,
+		OpeningTime:    "16:00",
+		ClosingTime:    "21:00",
+		PrimeTimeFrom:  "18:00",
+		PrimeTimeUntil: "19:00",
+	})
+	assert.NoError(t, err)
+
+	// a single hall with room for a 16:00 and an 18:00 show a day, but
+	// Monday's 18:00 is taken.
+	halls := []scheduleHall{{
+		Code:       "A",
+		Turnaround: 15 * time.Minute,
+		Shows: []models.Show{{
+			StartTime: monday.Add(18 * time.Hour),
+			EndTime:   monday.Add(21 * time.Hour),
+		}},
+	}}
+
+	movies := []scheduleMovie{
+		{ID: "tt2", Title: "Niche", Runtime: 90 * time.Minute, Screenings: 7, Rating: 9.0},
+		{ID: "tt1", Title: "Blockbuster", Runtime: 90 * time.Minute, Screenings: 8, Priority: 10, Rating: 7.5},
+	}
+
+	schedule := optimizeSchedule(days, halls, movies, now)
+
+	primeTime := map[string]int{}
+	total := map[string]int{}
+	for _, show := range schedule.Shows {
+		total[show.MovieID]++
+		if show.PrimeTime {
+			primeTime[show.MovieID]++
+		}
+	}
+
+	// the popular movie takes every free prime time slot.
+	assert.Equal(t, 6, primeTime["tt1"])
+	assert.Equal(t, 0, primeTime["tt2"])
+	assert.Equal(t, 6, schedule.PrimeTimeShows)
+
+	assert.Equal(t, 8, total["tt1"])
+	assert.Equal(t, 5, total["tt2"])
+	assert.Equal(t, []UnscheduledMovie{{MovieID: "tt2", Title: "Niche", Missing: 2}}, schedule.Unscheduled)
+
+	// no two shows overlap, turnaround included, and all are within hours.
+	existing := halls[0].Shows[0]
+	for i, a := range schedule.Shows {
+		day := days[a.StartTime.Sub(monday)/(24*time.Hour)]
+		assert.False(t, a.StartTime.Before(day.Open))
+		assert.False(t, a.EndTime.After(day.Close))
+		assert.False(t, a.StartTime.Before(existing.EndTime) && existing.StartTime.Before(a.EndTime.Add(15*time.Minute)))
+
+		for _, b := range schedule.Shows[i+1:] {
+			assert.False(t, b.StartTime.Before(a.EndTime.Add(15*time.Minute)), "%v overlaps %v", b.StartTime, a.EndTime)
+		}
+	}
+
+	// the hall's existing shows are left alone.
+	assert.Len(t, halls[0].Shows, 1)
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+)
+
+var (
+	ErrInvalidSeatCategory = errors.New("invalid seat category")
+	ErrSeatCategoryRule    = errors.New("seat selection breaks a seat category rule")
+)
+
+// SeatCategoryAssignment sets the category of some seats of a row.
+type SeatCategoryAssignment struct {
+	Row         string
+	SeatNumbers []int
+	Category    string
+}
+
+// applySeatCategories sets the categories of the assigned seats and returns
+// the seats that were changed. Assigning a seat that doesn't exist fails.
+func applySeatCategories(seats []models.Seat, assignments []SeatCategoryAssignment) ([]models.Seat, error) {
+	index := make(map[string]int, len(seats))
+	for i, seat := range seats {
+		index[fmt.Sprint(seat.Row, "-", seat.SeatNumber)] = i
+	}
+
+	changed := []models.Seat{}
+	for _, assignment := range assignments {
+		if !slices.Contains(models.SeatCategories, assignment.Category) {
+			return nil, fmt.Errorf("%w: unknown category %q", ErrInvalidSeatCategory, assignment.Category)
+		}
+
+		for _, number := range assignment.SeatNumbers {
+			i, ok := index[fmt.Sprint(assignment.Row, "-", number)]
+			if !ok {
+				return nil, fmt.Errorf("%w: seat %v%d doesn't exist", ErrInvalidSeatCategory, assignment.Row, number)
+			}
+
+			seats[i].Category = assignment.Category
+			changed = append(changed, seats[i])
+		}
+	}
+
+	return changed, nil
+}
+
+// isGeneralSeat reports whether a seat can be sold to anyone on its own.
+// Accessible and couple seats are left out of automatic seat picking.
+func isGeneralSeat(seat models.ShowSeat) bool {
+	return seat.Category == "" ||
+		seat.Category == models.CategoryStandard ||
+		seat.Category == models.CategoryVIP
+}
+
+// checkSeatCategoryRules makes sure a selection of show seats respects the
+// seat categories:
+//   - companion seats are only sold along with a wheelchair space, one per
+//     wheelchair space
+//   - couple seats are sold in pairs; the couple seats of a row pair up left
+//     to right within each run of adjacent couple seats
+func checkSeatCategoryRules(seats []models.ShowSeat, selectedIds []int) error {
+	selected := []models.ShowSeat{}
+	for _, seat := range seats {
+		if slices.Contains(selectedIds, seat.ID) {
+			selected = append(selected, seat)
+		}
+	}
+
+	wheelchairs := 0
+	for _, seat := range selected {
+		if seat.Category == models.CategoryWheelchair {
+			wheelchairs++
+		}
+	}
+
+	partners := couplePartners(seats)
+
+	companions := 0
+	for _, seat := range selected {
+		switch seat.Category {
+		case models.CategoryCompanion:
+			companions++
+			if companions > wheelchairs {
+				return fmt.Errorf("%w: companion seat %v%d requires a wheelchair space of its own", ErrSeatCategoryRule, seat.Row, seat.SeatNumber)
+			}
+		case models.CategoryCouple:
+			partner, ok := partners[seat.ID]
+			if !ok || !slices.Contains(selectedIds, partner) {
+				return fmt.Errorf("%w: couple seat %v%d must be booked with its pair", ErrSeatCategoryRule, seat.Row, seat.SeatNumber)
+			}
+		}
+	}
+
+	return nil
+}
+
+// couplePartners maps every paired couple seat to the other seat of its pair.
+func couplePartners(seats []models.ShowSeat) map[int]int {
+	rows := make(map[string][]models.ShowSeat)
+	for _, seat := range seats {
+		if seat.Category == models.CategoryCouple {
+			rows[seat.Row] = append(rows[seat.Row], seat)
+		}
+	}
+
+	partners := make(map[int]int)
+	for _, row := range rows {
+		slices.SortFunc(row, func(a, b models.ShowSeat) int {
+			return cmp.Compare(a.Column, b.Column)
+		})
+
+		for i := 0; i+1 < len(row); i++ {
+			if row[i+1].Column != row[i].Column+1 {
+				continue
+			}
+			partners[row[i].ID] = row[i+1].ID
+			partners[row[i+1].ID] = row[i].ID
+			i++
+		}
+	}
+
+	return partners
+}
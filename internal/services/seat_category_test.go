@@ -0,0 +1,87 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckSeatCategoryRules(t *testing.T) {
+	// row A: 1 wheelchair, 2 companion, 3..4 standard, 5..9 couple, 10 companion
+	seats := withCategories(showSeats(1, 10), map[string]string{
+		"A1":  models.CategoryWheelchair,
+		"A2":  models.CategoryCompanion,
+		"A5":  models.CategoryCouple,
+		"A6":  models.CategoryCouple,
+		"A7":  models.CategoryCouple,
+		"A8":  models.CategoryCouple,
+		"A9":  models.CategoryCouple,
+		"A10": models.CategoryCompanion,
+	})
+
+	id := func(labels ...string) []int {
+		ids := []int{}
+		for _, seat := range seats {
+			for _, label := range labels {
+				if seatLabel(seat) == label {
+					ids = append(ids, seat.ID)
+				}
+			}
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name     string
+		selected []int
+		wantErr  bool
+	}{
+		{name: "standard seats", selected: id("A3", "A4"), wantErr: false},
+		{name: "wheelchair space alone", selected: id("A1"), wantErr: false},
+		{name: "companion with wheelchair space", selected: id("A1", "A2"), wantErr: false},
+		{name: "companion alone", selected: id("A2"), wantErr: true},
+		{name: "companion with a standard seat", selected: id("A2", "A3"), wantErr: true},
+		{name: "two companions with one wheelchair space", selected: id("A1", "A2", "A10"), wantErr: true},
+		{name: "couple pair", selected: id("A5", "A6"), wantErr: false},
+		{name: "second couple pair", selected: id("A7", "A8"), wantErr: false},
+		{name: "couple seats across pairs", selected: id("A6", "A7"), wantErr: true},
+		{name: "half a couple pair", selected: id("A5"), wantErr: true},
+		{name: "unpaired couple seat", selected: id("A9"), wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkSeatCategoryRules(seats, tc.selected)
+			if tc.wantErr {
+				assert.ErrorIs(t, err, ErrSeatCategoryRule)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestApplySeatCategories(t *testing.T) {
+	seats := newSeating(2, 4).Seats
+
+	changed, err := applySeatCategories(seats, []SeatCategoryAssignment{
+		{Row: "B", SeatNumbers: []int{1, 2}, Category: models.CategoryVIP},
+	})
+	assert.Nil(t, err)
+	assert.Len(t, changed, 2)
+	for _, seat := range changed {
+		assert.Equal(t, "B", seat.Row)
+		assert.Equal(t, models.CategoryVIP, seat.Category)
+	}
+
+	_, err = applySeatCategories(seats, []SeatCategoryAssignment{
+		{Row: "C", SeatNumbers: []int{1}, Category: models.CategoryVIP},
+	})
+	assert.ErrorIs(t, err, ErrInvalidSeatCategory)
+
+	_, err = applySeatCategories(seats, []SeatCategoryAssignment{
+		{Row: "A", SeatNumbers: []int{1}, Category: "balcony"},
+	})
+	assert.ErrorIs(t, err, ErrInvalidSeatCategory)
+}
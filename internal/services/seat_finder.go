@@ -0,0 +1,111 @@
+package services
+
+import (
+	"cmp"
+	"math"
+	"slices"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+)
+
+const (
+	// rowDistanceWeight makes moving a row away from the center cost more
+	// than moving a seat sideways.
+	rowDistanceWeight = 1.5
+	// orphanPenalty outweighs any distance so a block leaving a lone empty
+	// seat is only suggested when nothing else fits.
+	orphanPenalty = 1000
+)
+
+// findBestSeats returns the best block of count adjacent available general
+// seats in the same row, or nil if there's none. Seats are adjacent when
+// they're in neighbouring columns, so an aisle splits a row into blocks.
+// Blocks closer to the center of the hall score better, and blocks that
+// would strand a single empty seat next to them are avoided.
+func findBestSeats(seats []models.ShowSeat, count int) []models.ShowSeat {
+	if count <= 0 {
+		return nil
+	}
+
+	rows := make(map[string][]models.ShowSeat)
+	for _, seat := range seats {
+		rows[seat.Row] = append(rows[seat.Row], seat)
+	}
+
+	labels := make([]string, 0, len(rows))
+	for label := range rows {
+		labels = append(labels, label)
+	}
+	slices.SortFunc(labels, compareRowLabels)
+
+	var (
+		best      []models.ShowSeat
+		bestScore = math.Inf(1)
+		centerRow = float64(len(labels)-1) / 2
+	)
+
+	for rowIndex, label := range labels {
+		row := rows[label]
+		slices.SortFunc(row, func(a, b models.ShowSeat) int {
+			return cmp.Compare(a.Column, b.Column)
+		})
+
+		centerSeat := float64(row[0].Column+row[len(row)-1].Column) / 2
+		rowDistance := math.Abs(float64(rowIndex)-centerRow) * rowDistanceWeight
+
+		for start := 0; start+count <= len(row); start++ {
+			block := row[start : start+count]
+			if !isAdjacentAndAvailable(block) {
+				continue
+			}
+
+			blockCenter := float64(block[0].Column+block[count-1].Column) / 2
+			score := rowDistance + math.Abs(blockCenter-centerSeat)
+			score += float64(orphansAround(row, start, start+count)) * orphanPenalty
+
+			if score < bestScore {
+				best, bestScore = block, score
+			}
+		}
+	}
+
+	return slices.Clone(best)
+}
+
+func isAdjacentAndAvailable(block []models.ShowSeat) bool {
+	for i, seat := range block {
+		if !seat.IsAvailable() || !isGeneralSeat(seat) {
+			return false
+		}
+		if i > 0 && seat.Column != block[i-1].Column+1 {
+			return false
+		}
+	}
+	return true
+}
+
+// orphansAround counts the single available seats that would be left
+// stranded on either side of the block row[start:end].
+func orphansAround(row []models.ShowSeat, start, end int) int {
+	isFree := func(i, next int) bool {
+		return i >= 0 && i < len(row) && row[i].IsAvailable() && isGeneralSeat(row[i]) &&
+			math.Abs(float64(row[i].Column-row[next].Column)) == 1
+	}
+
+	orphans := 0
+	if isFree(start-1, start) && !isFree(start-2, start-1) {
+		orphans++
+	}
+	if isFree(end, end-1) && !isFree(end+1, end) {
+		orphans++
+	}
+	return orphans
+}
+
+// compareRowLabels orders rows the way they're labeled: A..Z, then AA, AB...
+func compareRowLabels(a, b string) int {
+	if len(a) != len(b) {
+		return cmp.Compare(len(a), len(b))
+	}
+	return cmp.Compare(a, b)
+}
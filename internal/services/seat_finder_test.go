@@ -0,0 +1,171 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// showSeats lays out a show over a newSeating grid, marking the listed seats
+// (e.g. "C5") as sold.
+func showSeats(rows, seatsPerRow int, sold ...string) []models.ShowSeat {
+	seating := newSeating(rows, seatsPerRow)
+	seats := make([]models.ShowSeat, len(seating.Seats))
+
+	for i, seat := range seating.Seats {
+		seats[i] = models.ShowSeat{
+			ID:         i + 1,
+			Row:        seat.Row,
+			SeatNumber: seat.SeatNumber,
+			Column:     seat.Column,
+			Category:   models.CategoryStandard,
+			Status:     models.SeatAvailable,
+		}
+	}
+
+	for _, label := range sold {
+		for i := range seats {
+			if seatLabel(seats[i]) == label {
+				seats[i].Status = models.SeatSold
+			}
+		}
+	}
+
+	return seats
+}
+
+// withCategories sets the categories of the listed seats.
+func withCategories(seats []models.ShowSeat, categories map[string]string) []models.ShowSeat {
+	for i := range seats {
+		if category, ok := categories[seatLabel(seats[i])]; ok {
+			seats[i].Category = category
+		}
+	}
+	return seats
+}
+
+func seatLabel(seat models.ShowSeat) string {
+	return fmt.Sprint(seat.Row, seat.SeatNumber)
+}
+
+func TestFindBestSeats(t *testing.T) {
+	allBut := func(row string, free ...int) []string {
+		sold := []string{}
+		for n := 1; n <= 10; n++ {
+			if !slices.Contains(free, n) {
+				sold = append(sold, fmt.Sprint(row, n))
+			}
+		}
+		return sold
+	}
+
+	tests := []struct {
+		name  string
+		seats []models.ShowSeat
+		count int
+		want  []string
+	}{
+		{
+			name:  "empty hall picks the middle",
+			seats: showSeats(5, 10),
+			count: 2,
+			want:  []string{"C5", "C6"},
+		},
+		{
+			name:  "odd group centered",
+			seats: showSeats(5, 10),
+			count: 3,
+			want:  []string{"C4", "C5", "C6"},
+		},
+		{
+			name:  "partly taken center shifts sideways",
+			seats: showSeats(5, 10, "C5"),
+			count: 2,
+			want:  []string{"C6", "C7"},
+		},
+		{
+			name:  "taken center moves to the next row",
+			seats: showSeats(5, 10, "C5", "C6"),
+			count: 2,
+			want:  []string{"B5", "B6"},
+		},
+		{
+			name:  "avoids stranding a single seat",
+			seats: showSeats(1, 10, allBut("A", 3, 4, 5, 6, 7, 8)...),
+			count: 3,
+			want:  []string{"A3", "A4", "A5"},
+		},
+		{
+			name:  "moves rows rather than strand a seat",
+			seats: showSeats(3, 10, append(allBut("B", 5, 6, 7), "A5", "A6", "C5", "C6")...),
+			count: 2,
+			want:  []string{"A3", "A4"},
+		},
+		{
+			name:  "falls back to orphaning when nothing else fits",
+			seats: showSeats(1, 10, allBut("A", 5, 6, 7)...),
+			count: 2,
+			want:  []string{"A5", "A6"},
+		},
+		{
+			name:  "seats split by the aisle of sold seats aren't adjacent",
+			seats: showSeats(1, 10, allBut("A", 2, 3, 5, 6)...),
+			count: 3,
+			want:  nil,
+		},
+		{
+			name: "skips accessible and couple seats",
+			seats: withCategories(showSeats(3, 10), map[string]string{
+				"B5": models.CategoryWheelchair,
+				"B6": models.CategoryCompanion,
+				"A5": models.CategoryCouple,
+				"A6": models.CategoryCouple,
+				"C5": models.CategoryVIP,
+				"C6": models.CategoryVIP,
+			}),
+			count: 2,
+			want:  []string{"C5", "C6"},
+		},
+		{
+			name:  "group larger than a row",
+			seats: showSeats(2, 4),
+			count: 5,
+			want:  nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := findBestSeats(tc.seats, tc.count)
+
+			var labels []string
+			for _, seat := range got {
+				labels = append(labels, seatLabel(seat))
+			}
+			assert.Equal(t, tc.want, labels)
+		})
+	}
+}
+
+func TestFindBestSeats_Aisle(t *testing.T) {
+	seating, err := newLayoutSeating(HallLayout{Grid: "sss_sss"})
+	assert.Nil(t, err)
+
+	seats := make([]models.ShowSeat, len(seating.Seats))
+	for i, seat := range seating.Seats {
+		seats[i] = models.ShowSeat{
+			ID:         i + 1,
+			Row:        seat.Row,
+			SeatNumber: seat.SeatNumber,
+			Column:     seat.Column,
+			Category:   seat.Category,
+			Status:     models.SeatAvailable,
+		}
+	}
+
+	assert.Nil(t, findBestSeats(seats, 4), "an aisle splits the row")
+	assert.Len(t, findBestSeats(seats, 3), 3)
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+)
+
+var (
+	ErrNoAdjacentSeats = errors.New("not enough adjacent seats available")
+)
+
+type SeatService struct {
+	models *models.Model
+}
+
+func (s *SeatService) List(theaterId, showId int) ([]models.ShowSeat, error) {
+	if _, err := findTheaterShow(s.models, theaterId, showId); err != nil {
+		return nil, err
+	}
+
+	return s.models.ShowSeats.FindByShow(showId)
+}
+
+// SeatMap lays out the seats of a show as a grid along with their status.
+func (s *SeatService) SeatMap(theaterId, showId int) (*SeatMap, error) {
+	seats, err := s.List(theaterId, showId)
+	if err != nil {
+		return nil, err
+	}
+
+	return newShowSeatMap(seats), nil
+}
+
+// Suggest finds the best block of count adjacent available seats of a show.
+func (s *SeatService) Suggest(theaterId, showId, count int) ([]models.ShowSeat, error) {
+	seats, err := s.List(theaterId, showId)
+	if err != nil {
+		return nil, err
+	}
+
+	best := findBestSeats(seats, count)
+	if best == nil {
+		return nil, ErrNoAdjacentSeats
+	}
+
+	return best, nil
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"fmt"
+	"html"
+	"slices"
+	"strings"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+)
+
+const (
+	CellSeat  = "seat"
+	CellGap   = "gap"
+	CellAisle = "aisle"
+)
+
+// SeatMap is a hall's seating laid out as a grid. Every row has one cell per
+// column: a seat, a gap where a row has no seat, or an aisle where no row
+// has a seat.
+type SeatMap struct {
+	Columns   int          `json:"columns"`
+	Capacity  int          `json:"capacity"`
+	Available int          `json:"available"`
+	Rows      []SeatMapRow `json:"rows"`
+}
+
+type SeatMapRow struct {
+	Label string        `json:"label"`
+	Cells []SeatMapCell `json:"cells"`
+}
+
+// SeatMapCell is a grid cell. Seat cells of a show's seat map carry the
+// seat's status; seat cells of a hall's seat map don't.
+type SeatMapCell struct {
+	Kind       string `json:"kind"`
+	SeatID     int    `json:"seat_id,omitempty"`
+	SeatNumber *int   `json:"seat_number,omitempty"`
+	Category   string `json:"category,omitempty"`
+	Status     string `json:"status,omitempty"`
+}
+
+func newHallSeatMap(seats []models.Seat) *SeatMap {
+	cells := make([]seatMapSeat, len(seats))
+	for i, seat := range seats {
+		cells[i] = seatMapSeat{seat.ID, seat.Row, seat.SeatNumber, seat.Column, seat.Category, ""}
+	}
+	return buildSeatMap(cells)
+}
+
+func newShowSeatMap(seats []models.ShowSeat) *SeatMap {
+	cells := make([]seatMapSeat, len(seats))
+	for i, seat := range seats {
+		cells[i] = seatMapSeat{seat.ID, seat.Row, seat.SeatNumber, seat.Column, seat.Category, seat.Status}
+	}
+	return buildSeatMap(cells)
+}
+
+type seatMapSeat struct {
+	id       int
+	row      string
+	number   int
+	column   int
+	category string
+	status   string
+}
+
+func buildSeatMap(seats []seatMapSeat) *SeatMap {
+	seatMap := &SeatMap{
+		Capacity: len(seats),
+		Rows:     []SeatMapRow{},
+	}
+
+	if len(seats) == 0 {
+		return seatMap
+	}
+
+	first, last := seats[0].column, seats[0].column
+	rows := make(map[string][]seatMapSeat)
+	for _, seat := range seats {
+		first, last = min(first, seat.column), max(last, seat.column)
+		rows[seat.row] = append(rows[seat.row], seat)
+		if seat.status == models.SeatAvailable {
+			seatMap.Available++
+		}
+	}
+
+	seatMap.Columns = last - first + 1
+	occupied := make([]bool, seatMap.Columns)
+	for _, seat := range seats {
+		occupied[seat.column-first] = true
+	}
+
+	labels := make([]string, 0, len(rows))
+	for label := range rows {
+		labels = append(labels, label)
+	}
+	slices.SortFunc(labels, compareRowLabels)
+
+	for _, label := range labels {
+		row := SeatMapRow{
+			Label: label,
+			Cells: make([]SeatMapCell, seatMap.Columns),
+		}
+
+		for column := range row.Cells {
+			row.Cells[column].Kind = CellGap
+			if !occupied[column] {
+				row.Cells[column].Kind = CellAisle
+			}
+		}
+
+		for _, seat := range rows[label] {
+			row.Cells[seat.column-first] = SeatMapCell{
+				Kind:       CellSeat,
+				SeatID:     seat.id,
+				SeatNumber: &seat.number,
+				Category:   seat.category,
+				Status:     seat.status,
+			}
+		}
+
+		seatMap.Rows = append(seatMap.Rows, row)
+	}
+
+	return seatMap
+}
+
+const (
+	svgCellSize  = 24
+	svgCellSpace = 4
+	svgMargin    = 32
+)
+
+var svgSeatColors = map[string]string{
+	"":                   "#90a4ae",
+	models.SeatAvailable: "#4caf50",
+	models.SeatHeld:      "#ffb300",
+	models.SeatSold:      "#9e9e9e",
+}
+
+// svgCategoryStrokes outlines the seats of special categories.
+var svgCategoryStrokes = map[string]string{
+	models.CategoryVIP:        "#8e24aa",
+	models.CategoryWheelchair: "#1e88e5",
+	models.CategoryCompanion:  "#00acc1",
+	models.CategoryCouple:     "#e91e63",
+}
+
+// SVG draws the seat map with the screen at the top, row labels on the left
+// and seats colored by their status and outlined by their category.
+func (m *SeatMap) SVG() []byte {
+	step := svgCellSize + svgCellSpace
+	width := 2*svgMargin + m.Columns*step
+	height := 2*svgMargin + len(m.Rows)*step + step
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="10">`,
+		width, height, width, height)
+	fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="6" rx="3" fill="#607d8b"/>`,
+		svgMargin, svgMargin/2, m.Columns*step-svgCellSpace)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="middle">SCREEN</text>`,
+		width/2, svgMargin/2+18)
+
+	for i, row := range m.Rows {
+		y := svgMargin + step + i*step
+
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="middle">%s</text>`,
+			svgMargin/2, y+svgCellSize/2+4, html.EscapeString(row.Label))
+
+		for column, cell := range row.Cells {
+			if cell.Kind != CellSeat {
+				continue
+			}
+
+			stroke := "none"
+			if color, ok := svgCategoryStrokes[cell.Category]; ok {
+				stroke = color
+			}
+
+			x := svgMargin + column*step
+			fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" rx="4" fill="%s" stroke="%s" stroke-width="2" data-seat-id="%d" data-category="%s" data-status="%s"><title>%s%d %s</title></rect>`,
+				x, y, svgCellSize, svgCellSize, svgSeatColors[cell.Status], stroke, cell.SeatID,
+				html.EscapeString(cell.Category), html.EscapeString(cell.Status),
+				html.EscapeString(row.Label), *cell.SeatNumber, html.EscapeString(cell.Category))
+		}
+	}
+
+	b.WriteString(`</svg>`)
+
+	return []byte(b.String())
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewShowSeatMap(t *testing.T) {
+	seats := []models.ShowSeat{}
+	for _, seat := range showSeats(2, 6, "A2") {
+		// an aisle runs between seats 3 and 5, and row B is a seat short
+		if seat.SeatNumber == 4 || seat.Row == "B" && seat.SeatNumber == 6 {
+			continue
+		}
+		seats = append(seats, seat)
+	}
+
+	seatMap := newShowSeatMap(seats)
+
+	kinds := func(row SeatMapRow) string {
+		var b strings.Builder
+		for _, cell := range row.Cells {
+			switch {
+			case cell.Kind == CellAisle:
+				b.WriteByte('|')
+			case cell.Kind == CellGap:
+				b.WriteByte('_')
+			case cell.Status == models.SeatSold:
+				b.WriteByte('x')
+			default:
+				b.WriteByte('o')
+			}
+		}
+		return b.String()
+	}
+
+	assert.Equal(t, 6, seatMap.Columns)
+	assert.Equal(t, 9, seatMap.Capacity)
+	assert.Equal(t, 8, seatMap.Available)
+	assert.Len(t, seatMap.Rows, 2)
+	assert.Equal(t, "A", seatMap.Rows[0].Label)
+	assert.Equal(t, "oxo|oo", kinds(seatMap.Rows[0]))
+	assert.Equal(t, "ooo|o_", kinds(seatMap.Rows[1]))
+}
+
+func TestSeatMap_SVG(t *testing.T) {
+	svg := string(newShowSeatMap(showSeats(2, 3, "B3")).SVG())
+
+	assert.True(t, strings.HasPrefix(svg, "<svg "))
+	assert.True(t, strings.HasSuffix(svg, "</svg>"))
+	assert.Equal(t, 6, strings.Count(svg, "data-seat-id="))
+	assert.Equal(t, 1, strings.Count(svg, `data-status="sold"`))
+}
+
+func TestNewHallSeatMap_Empty(t *testing.T) {
+	seatMap := newHallSeatMap(nil)
+
+	assert.Equal(t, 0, seatMap.Columns)
+	assert.Empty(t, seatMap.Rows)
+	assert.NotEmpty(t, seatMap.SVG())
+}
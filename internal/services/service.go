@@ -3,6 +3,7 @@ package services
 import (
 	"errors"
 
+	"github.com/AhmadAbdelrazik/showtime/internal/config"
 	"github.com/AhmadAbdelrazik/showtime/internal/models"
 )
 
@@ -18,22 +19,62 @@ var (
 )
 
 type Service struct {
-	Theaters *TheaterService
-	Shows    *ShowService
-	Halls    *HallService
-	Users    *UserService
-	Movies   *MovieService
+	Theaters    *TheaterService
+	Shows       *ShowService
+	Halls       *HallService
+	Users       *UserService
+	Movies      *MovieService
+	Seats       *SeatService
+	Holds       *HoldService
+	Bookings    *BookingService
+	Tickets     *TicketService
+	Waitlist    *WaitlistService
+	Prices      *PriceService
+	Promos      *PromoCodeService
+	GiftCards   *GiftCardService
+	Loyalty     *LoyaltyService
+	Members     *MembershipService
+	Concessions *ConcessionService
+	Invoices    *InvoiceService
+	Taxes       *TaxService
+	Currencies  *CurrencyService
+	Reports     *ReportService
 }
 
-func New(model *models.Model, movieProvider MovieProvider) *Service {
+func New(model *models.Model, movieProvider MovieProvider, gateway PaymentGateway, cfg *config.Config) *Service {
 
+	currencyService := &CurrencyService{model, cfg.Currencies.Base}
 	movieService := &MovieService{model, movieProvider}
+	ticketSigner := NewTicketSigner([]byte(cfg.TicketSigningKey))
+	waitlistService := &WaitlistService{model, cfg.Waitlist.OfferDuration}
+	priceService := &PriceService{model, currencyService, cfg.TicketPrice}
+	promoService := &PromoCodeService{model, currencyService}
+	giftCardService := &GiftCardService{model, gateway, cfg.GiftCards.Validity, cfg.Currencies.Base}
+	loyaltyService := &LoyaltyService{model, currencyService}
+	membershipService := &MembershipService{model, gateway, cfg.Currencies.Base}
+	concessionService := &ConcessionService{model}
+	taxService := &TaxService{model}
 
 	return &Service{
-		Theaters: &TheaterService{model},
-		Shows:    &ShowService{model, movieService},
-		Halls:    &HallService{model},
-		Users:    &UserService{model},
-		Movies:   movieService,
+		Theaters:    &TheaterService{model, cfg.Currencies.Base},
+		Shows:       &ShowService{model, movieService, priceService},
+		Halls:       &HallService{model},
+		Users:       &UserService{model},
+		Movies:      movieService,
+		Seats:       &SeatService{model},
+		Holds:       &HoldService{model, waitlistService, cfg.Holds.Duration, cfg.Holds.MaxDuration},
+		Bookings:    &BookingService{model, waitlistService, priceService, promoService, loyaltyService, giftCardService, membershipService, concessionService, taxService, gateway, ticketSigner},
+		Tickets:     &TicketService{model, ticketSigner},
+		Waitlist:    waitlistService,
+		Prices:      priceService,
+		Promos:      promoService,
+		GiftCards:   giftCardService,
+		Loyalty:     loyaltyService,
+		Members:     membershipService,
+		Concessions: concessionService,
+		Invoices:    &InvoiceService{model},
+		Taxes:       taxService,
+		Currencies:  currencyService,
+		Reports:     &ReportService{model, currencyService},
 	}
 }
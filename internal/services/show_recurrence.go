@@ -0,0 +1,308 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+)
+
+const (
+	ScheduleAllOrNothing = "all_or_nothing"
+	ScheduleBestEffort   = "best_effort"
+)
+
+const (
+	OccurrenceCreated  = "created"
+	OccurrenceConflict = "conflict"
+	OccurrenceSkipped  = "skipped"
+)
+
+// maxOccurrences is the most shows a recurring schedule may create at once.
+const maxOccurrences = 500
+
+var (
+	ErrInvalidRecurrence = errors.New("invalid recurrence")
+	ErrScheduleConflict  = errors.New("schedule conflicts with other shows")
+)
+
+// CreateRecurring schedules a movie in a hall at the same times on every
+// matching day of a date range. Each occurrence is checked against the
+// theater's operating hours and the hall's schedule, including the
+// occurrences before it. In all or nothing mode a single conflict schedules
+// no show and returns ErrScheduleConflict along with the report; in best
+// effort mode the conflicting occurrences are left out.
+func (s *ShowService) CreateRecurring(user *models.User, theaterId int, input ShowRecurrence) (*ScheduleReport, error) {
+	movie, err := s.movieService.Find(input.MovieID)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, ErrMovieNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	runtime, err := parseRuntime(movie.Runtime)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Duration == 0 {
+		input.Duration = runtime
+	}
+	if input.Duration < runtime {
+		return nil, fmt.Errorf("%w (duration = %v)", ErrInvalidShowDuration, movie.Runtime)
+	}
+
+	hours, err := s.models.OperatingHours.Find(theaterId)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, ErrTheaterNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	loc, err := scheduleLocation(input.Timezone, hours)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown time zone %q", ErrInvalidRecurrence, input.Timezone)
+	}
+
+	input.From, input.Until = onDate(input.From, loc), onDate(input.Until, loc)
+	for i, date := range input.ExceptDates {
+		input.ExceptDates[i] = onDate(date, loc)
+	}
+
+	shows, err := expandRecurrence(input)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to := shows[0].StartTime.Add(-scheduleMargin), shows[len(shows)-1].EndTime.Add(scheduleMargin)
+
+	hall, err := s.models.Halls.FindByCodeWithSchedule(theaterId, input.HallCode, from, to)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, ErrHallNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	if !isHallManagerOrAdmin(user, hall) {
+		return nil, fmt.Errorf("%w: creating shows is available for theater's manager only.", ErrUnauthorized)
+	}
+
+	for i := range shows {
+		shows[i].MovieID = movie.ImdbID
+		shows[i].MovieTitle = movie.Title
+		shows[i].TheaterID = hall.TheaterID
+	}
+
+	report := planShows(*hall.Schedule, hours, shows, input.Mode, time.Now())
+	if report.Conflicts > 0 && input.Mode == ScheduleAllOrNothing {
+		return report, fmt.Errorf("%w: %v of %v occurrences conflict", ErrScheduleConflict, report.Conflicts, len(shows))
+	}
+
+	var created []*models.Show
+	for i := range shows {
+		if report.Occurrences[i].Status == OccurrenceCreated {
+			created = append(created, &shows[i])
+		}
+	}
+
+	if len(created) > 0 {
+		if err := s.models.Shows.CreateMany(created); err != nil {
+			switch {
+			case errors.Is(err, models.ErrNotFound):
+				return nil, ErrHallNotFound
+			case errors.Is(err, models.ErrInvalidSchedule):
+				report.skipCreated()
+				return report, fmt.Errorf("%w: hall was booked meanwhile, %v", ErrScheduleConflict, err)
+			default:
+				return nil, err
+			}
+		}
+	}
+
+	for i := range shows {
+		report.Occurrences[i].ShowID = shows[i].ID
+	}
+
+	return report, nil
+}
+
+// expandRecurrence lists the shows of a recurrence in order of start time.
+// Times are wall clock times in the location of From, so shows keep their
+// time across daylight saving changes.
+func expandRecurrence(r ShowRecurrence) ([]models.Show, error) {
+	loc := r.From.Location()
+
+	clocks := make([]time.Time, 0, len(r.Times))
+	for _, t := range r.Times {
+		clock, err := time.Parse("15:04", t)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid time %q", ErrInvalidRecurrence, t)
+		}
+		clocks = append(clocks, clock)
+	}
+
+	skipped := make(map[string]bool, len(r.ExceptDates))
+	for _, date := range r.ExceptDates {
+		skipped[date.Format(time.DateOnly)] = true
+	}
+
+	var shows []models.Show
+	for day := r.From; !day.After(r.Until); day = day.AddDate(0, 0, 1) {
+		if len(r.Weekdays) > 0 && !slices.Contains(r.Weekdays, day.Weekday()) {
+			continue
+		}
+		if skipped[day.Format(time.DateOnly)] {
+			continue
+		}
+
+		for _, clock := range clocks {
+			start := time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, loc)
+
+			show := models.Show{
+				HallCode:  r.HallCode,
+				StartTime: start,
+				EndTime:   start.Add(r.Duration),
+				PriceTier: r.PriceTier,
+			}
+			if show.PriceTier == "" {
+				show.PriceTier = defaultPriceTier(start)
+			}
+
+			shows = append(shows, show)
+		}
+
+		if len(shows) > maxOccurrences {
+			return nil, fmt.Errorf("%w: more than %v occurrences", ErrInvalidRecurrence, maxOccurrences)
+		}
+	}
+
+	if len(shows) == 0 {
+		return nil, fmt.Errorf("%w: no occurrences in the date range", ErrInvalidRecurrence)
+	}
+
+	slices.SortFunc(shows, func(a, b models.Show) int {
+		return a.StartTime.Compare(b.StartTime)
+	})
+
+	return shows, nil
+}
+
+// planShows checks every show, in order, against the theater's operating
+// hours, the hall's schedule and the shows accepted before it. In all or
+// nothing mode a conflict leaves every other show skipped.
+func planShows(schedule models.Schedule, hours models.OperatingHours, shows []models.Show, mode string, now time.Time) *ScheduleReport {
+	report := &ScheduleReport{
+		Mode:        mode,
+		Occurrences: make([]Occurrence, 0, len(shows)),
+	}
+
+	schedule.Shows = slices.Clone(schedule.Shows)
+
+	for _, show := range shows {
+		occurrence := Occurrence{
+			HallCode:  show.HallCode,
+			StartTime: show.StartTime,
+			EndTime:   show.EndTime,
+			Status:    OccurrenceCreated,
+		}
+
+		err := hours.Check(show.StartTime, show.EndTime)
+		if err == nil {
+			err = schedule.IsFree(show)
+		}
+		if err == nil && show.StartTime.Before(now) {
+			err = fmt.Errorf("%w: starts in the past", models.ErrInvalidSchedule)
+		}
+
+		if err != nil {
+			occurrence.Status = OccurrenceConflict
+			occurrence.Error = err.Error()
+			report.Conflicts++
+
+			var conflict *models.ScheduleConflictError
+			if errors.As(err, &conflict) {
+				occurrence.EarliestStart = &conflict.EarliestStart
+			}
+		} else {
+			schedule.Shows = append(schedule.Shows, show)
+			report.Created++
+		}
+
+		report.Occurrences = append(report.Occurrences, occurrence)
+	}
+
+	if mode == ScheduleAllOrNothing && report.Conflicts > 0 {
+		for i := range report.Occurrences {
+			if report.Occurrences[i].Status == OccurrenceCreated {
+				report.Occurrences[i].Status = OccurrenceSkipped
+			}
+		}
+		report.Created = 0
+	}
+
+	return report
+}
+
+// ShowRecurrence schedules a movie at Times, "15:04" wall clock times, on
+// every day from the date of From to that of Until, falling on one of
+// Weekdays, every day if empty, and not listed in ExceptDates. Duration
+// defaults to the movie's runtime.
+type ShowRecurrence struct {
+	MovieID     string
+	HallCode    string
+	From        time.Time
+	Until       time.Time
+	Times       []string
+	Weekdays    []time.Weekday
+	ExceptDates []time.Time
+	// Timezone is the time zone of the dates and Times, the theater's if
+	// empty.
+	Timezone  string
+	Duration  time.Duration
+	PriceTier string
+	Mode      string
+}
+
+// ScheduleReport tells for every show of a recurrence or a schedule whether
+// it was created, conflicts with another show, or was skipped because
+// another one conflicts.
+type ScheduleReport struct {
+	Mode        string       `json:"mode"`
+	Created     int          `json:"created"`
+	Conflicts   int          `json:"conflicts"`
+	Occurrences []Occurrence `json:"occurrences"`
+}
+
+// skipCreated marks the occurrences that were to be created skipped, when
+// none of them gets scheduled after all.
+func (r *ScheduleReport) skipCreated() {
+	for i := range r.Occurrences {
+		if r.Occurrences[i].Status == OccurrenceCreated {
+			r.Occurrences[i].Status = OccurrenceSkipped
+		}
+	}
+	r.Created = 0
+}
+
+// Occurrence is a show of a ScheduleReport. Conflicting occurrences give the
+// earliest they could start instead, when another show is in the way.
+type Occurrence struct {
+	HallCode      string     `json:"hall_code"`
+	StartTime     time.Time  `json:"start_time"`
+	EndTime       time.Time  `json:"end_time"`
+	Status        string     `json:"status"`
+	ShowID        int        `json:"show_id,omitempty"`
+	Error         string     `json:"error,omitempty"`
+	EarliestStart *time.Time `json:"earliest_start,omitempty"`
+}
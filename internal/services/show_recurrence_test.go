@@ -0,0 +1,217 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandRecurrence(t *testing.T) {
+	cairo, err := time.LoadLocation("Africa/Cairo")
+	assert.NoError(t, err)
+
+	date := func(day int) time.Time {
+		return time.Date(2026, time.November, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name       string
+		recurrence ShowRecurrence
+		wantStarts []time.Time
+		wantErr    error
+	}{
+		{
+			name: "daily at two times",
+			recurrence: ShowRecurrence{
+				From:  date(2),
+				Until: date(3),
+				Times: []string{"19:00", "14:00"},
+			},
+			wantStarts: []time.Time{
+				time.Date(2026, time.November, 2, 14, 0, 0, 0, time.UTC),
+				time.Date(2026, time.November, 2, 19, 0, 0, 0, time.UTC),
+				time.Date(2026, time.November, 3, 14, 0, 0, 0, time.UTC),
+				time.Date(2026, time.November, 3, 19, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "except mondays and a holiday",
+			recurrence: ShowRecurrence{
+				From:        date(1),
+				Until:       date(4),
+				Times:       []string{"20:30"},
+				Weekdays:    []time.Weekday{time.Sunday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday},
+				ExceptDates: []time.Time{date(3)},
+			},
+			wantStarts: []time.Time{
+				time.Date(2026, time.November, 1, 20, 30, 0, 0, time.UTC),
+				time.Date(2026, time.November, 4, 20, 30, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "wall clock time is kept across daylight saving time",
+			recurrence: ShowRecurrence{
+				From:  time.Date(2026, time.October, 29, 0, 0, 0, 0, cairo),
+				Until: time.Date(2026, time.October, 31, 0, 0, 0, 0, cairo),
+				Times: []string{"19:00"},
+			},
+			wantStarts: []time.Time{
+				time.Date(2026, time.October, 29, 19, 0, 0, 0, cairo),
+				time.Date(2026, time.October, 30, 19, 0, 0, 0, cairo),
+				time.Date(2026, time.October, 31, 19, 0, 0, 0, cairo),
+			},
+		},
+		{
+			name: "no occurrences",
+			recurrence: ShowRecurrence{
+				From:     date(2),
+				Until:    date(2),
+				Times:    []string{"19:00"},
+				Weekdays: []time.Weekday{time.Sunday},
+			},
+			wantErr: ErrInvalidRecurrence,
+		},
+		{
+			name: "too many occurrences",
+			recurrence: ShowRecurrence{
+				From:  date(1),
+				Until: date(1).AddDate(1, 0, 0),
+				Times: []string{"10:00", "14:00"},
+			},
+			wantErr: ErrInvalidRecurrence,
+		},
+		{
+			name: "invalid time",
+			recurrence: ShowRecurrence{
+				From:  date(2),
+				Until: date(2),
+				Times: []string{"25:00"},
+			},
+			wantErr: ErrInvalidRecurrence,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.recurrence.Duration = 2 * time.Hour
+
+			shows, err := expandRecurrence(tt.recurrence)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+
+			starts := make([]time.Time, 0, len(shows))
+			for _, show := range shows {
+				starts = append(starts, show.StartTime)
+				assert.Equal(t, 2*time.Hour, show.EndTime.Sub(show.StartTime))
+				assert.NotEmpty(t, show.PriceTier)
+			}
+			assert.Equal(t, tt.wantStarts, starts)
+		})
+	}
+}
+
+func TestPlanShows(t *testing.T) {
+	now := time.Date(2026, time.November, 1, 12, 0, 0, 0, time.UTC)
+	at := func(day, hour int) time.Time {
+		return time.Date(2026, time.November, day, hour, 0, 0, 0, time.UTC)
+	}
+	show := func(day, hour, hours int) models.Show {
+		return models.Show{StartTime: at(day, hour), EndTime: at(day, hour+hours)}
+	}
+
+	schedule := models.Schedule{
+		From:       at(1, 0),
+		To:         at(10, 0),
+		Turnaround: 15 * time.Minute,
+		Shows:      []models.Show{show(3, 18, 3)},
+	}
+
+	shows := []models.Show{
+		show(1, 10, 2), // in the past
+		show(2, 14, 3),
+		show(2, 16, 3), // overlaps the previous occurrence
+		show(3, 14, 3),
+		show(3, 19, 3), // overlaps the existing show
+	}
+
+	// conflicts with another show tell when the hall is free again.
+	minute := func(day, hour, minute int) *time.Time {
+		t := time.Date(2026, time.November, day, hour, minute, 0, 0, time.UTC)
+		return &t
+	}
+	wantEarliestStarts := []*time.Time{nil, nil, minute(2, 17, 15), nil, minute(3, 21, 15)}
+
+	tests := []struct {
+		name          string
+		mode          string
+		wantStatuses  []string
+		wantCreated   int
+		wantConflicts int
+	}{
+		{
+			name:          "best effort",
+			mode:          ScheduleBestEffort,
+			wantStatuses:  []string{OccurrenceConflict, OccurrenceCreated, OccurrenceConflict, OccurrenceCreated, OccurrenceConflict},
+			wantCreated:   2,
+			wantConflicts: 3,
+		},
+		{
+			name:          "all or nothing",
+			mode:          ScheduleAllOrNothing,
+			wantStatuses:  []string{OccurrenceConflict, OccurrenceSkipped, OccurrenceConflict, OccurrenceSkipped, OccurrenceConflict},
+			wantCreated:   0,
+			wantConflicts: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := planShows(schedule, models.OperatingHours{}, shows, tt.mode, now)
+
+			statuses := make([]string, 0, len(report.Occurrences))
+			for _, occurrence := range report.Occurrences {
+				statuses = append(statuses, occurrence.Status)
+				assert.Equal(t, occurrence.Status == OccurrenceConflict, occurrence.Error != "")
+			}
+
+			for i, want := range wantEarliestStarts {
+				assert.Equal(t, want, report.Occurrences[i].EarliestStart)
+			}
+
+			assert.Equal(t, tt.wantStatuses, statuses)
+			assert.Equal(t, tt.wantCreated, report.Created)
+			assert.Equal(t, tt.wantConflicts, report.Conflicts)
+			assert.Len(t, schedule.Shows, 1)
+		})
+	}
+}
+
+func TestParseRuntime(t *testing.T) {
+	tests := []struct {
+		runtime string
+		want    time.Duration
+		wantErr bool
+	}{
+		{runtime: "140 min", want: 140 * time.Minute},
+		{runtime: "2h20m", want: 140 * time.Minute},
+		{runtime: "N/A", wantErr: true},
+		{runtime: "0 min", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.runtime, func(t *testing.T) {
+			got, err := parseRuntime(tt.runtime)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
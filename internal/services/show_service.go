@@ -3,6 +3,8 @@ package services
 import (
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/AhmadAbdelrazik/showtime/internal/models"
@@ -12,23 +14,42 @@ var (
 	ErrInvalidShowDuration = errors.New("movie duration is longer than reserved time")
 )
 
+// scheduleMargin is how far around new shows the hall's schedule is loaded to
+// check them against, further than any show runs or any turnaround lasts.
+const scheduleMargin = 24 * time.Hour
+
 type ShowService struct {
 	models       *models.Model
 	movieService *MovieService
+	prices       *PriceService
 }
 
 func (s *ShowService) Search(filters models.ShowFilter) ([]models.Show, error) {
-	return s.models.Shows.Search(filters)
+	shows, err := s.models.Shows.Search(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.prices.attachPriceRanges(shows); err != nil {
+		return nil, err
+	}
+
+	return shows, nil
 }
 
+// Create schedules a show in a hall. The show must run within the theater's
+// operating hours, or models.ErrOutsideOperatingHours is returned, and may
+// not overlap another show of the hall or the turnaround after it; a
+// conflict returns a *models.ScheduleConflictError with the earliest valid
+// start.
 func (s *ShowService) Create(user *models.User, theaterId int, input CreateShowInput) error {
-	hall, err := s.models.Halls.FindByCode(theaterId, input.HallCode)
+	hall, err := s.models.Halls.FindByCodeWithSchedule(theaterId, input.HallCode, input.StartTime.Add(-scheduleMargin), input.EndTime.Add(scheduleMargin))
 	if err != nil {
 		switch {
 		case errors.Is(err, models.ErrNotFound):
 			return ErrHallNotFound
 		default:
-			return nil
+			return err
 		}
 	}
 
@@ -47,9 +68,9 @@ func (s *ShowService) Create(user *models.User, theaterId int, input CreateShowI
 		}
 	}
 
-	movieDuration, err := time.ParseDuration(movie.Runtime)
+	movieDuration, err := parseRuntime(movie.Runtime)
 	if err != nil {
-		panic(err)
+		return err
 	}
 
 	if input.EndTime.Sub(input.StartTime) < movieDuration {
@@ -62,6 +83,24 @@ func (s *ShowService) Create(user *models.User, theaterId int, input CreateShowI
 		HallCode:  input.HallCode,
 		StartTime: input.StartTime,
 		EndTime:   input.EndTime,
+		PriceTier: input.PriceTier,
+	}
+
+	if show.PriceTier == "" {
+		show.PriceTier = defaultPriceTier(show.StartTime)
+	}
+
+	hours, err := s.models.OperatingHours.Find(hall.TheaterID)
+	if err != nil {
+		return err
+	}
+
+	if err := hours.Check(show.StartTime, show.EndTime); err != nil {
+		return err
+	}
+
+	if err := hall.Schedule.IsFree(*show); err != nil {
+		return err
 	}
 
 	return s.models.Shows.Create(show)
@@ -82,7 +121,12 @@ func (s *ShowService) Find(theaterId, showId int) (*models.Show, error) {
 		return nil, ErrShowNotFound
 	}
 
-	return show, nil
+	shows := []models.Show{*show}
+	if err := s.prices.attachPriceRanges(shows); err != nil {
+		return nil, err
+	}
+
+	return &shows[0], nil
 }
 
 func (s *ShowService) Delete(user *models.User, theaterId, showId int) error {
@@ -112,9 +156,24 @@ func (s *ShowService) Delete(user *models.User, theaterId, showId int) error {
 	return nil
 }
 
+// parseRuntime parses a movie runtime as given by OMDb, e.g. "140 min", or as
+// a Go duration, e.g. "2h20m".
+func parseRuntime(runtime string) (time.Duration, error) {
+	if minutes, ok := strings.CutSuffix(runtime, " min"); ok {
+		n, err := strconv.Atoi(minutes)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid movie runtime %q", runtime)
+		}
+		return time.Duration(n) * time.Minute, nil
+	}
+
+	return time.ParseDuration(runtime)
+}
+
 type CreateShowInput struct {
 	MovieID   string
 	HallCode  string
 	StartTime time.Time
 	EndTime   time.Time
+	PriceTier string
 }
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+)
+
+var (
+	ErrInvalidTaxRules = errors.New("invalid tax rules")
+)
+
+type TaxService struct {
+	models *models.Model
+}
+
+// Rules returns the taxes and fees charged on the bookings of a city.
+func (s *TaxService) Rules(city string) ([]models.TaxRule, error) {
+	return s.models.TaxRules.FindByCity(city)
+}
+
+// ReplaceRules swaps the taxes and fees of a city for the given ones.
+func (s *TaxService) ReplaceRules(user *models.User, city string, rules []models.TaxRule) ([]models.TaxRule, error) {
+	if user.Role != "admin" {
+		return nil, fmt.Errorf("%w: taxes can be updated by admins only", ErrUnauthorized)
+	}
+
+	for i := range rules {
+		if err := validateTaxRule(&rules[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.models.TaxRules.Replace(city, rules); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// quote works out the taxes and fees of a booking at a theater.
+func (s *TaxService) quote(theater *models.Theater, base taxBase) (taxBreakdown, error) {
+	rules, err := s.models.TaxRules.FindByCity(theater.City)
+	if err != nil {
+		return taxBreakdown{}, err
+	}
+
+	return calculateTaxes(rules, base), nil
+}
+
+// validateTaxRule checks that a rule makes sense for its kind: taxes are a
+// rate, fees a rate or a fixed amount, and only taxes may be inclusive.
+func validateTaxRule(rule *models.TaxRule) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	if rule.Per == "" {
+		rule.Per = models.TaxPerBooking
+	}
+	if rule.AppliesTo == nil {
+		rule.AppliesTo = []string{}
+	}
+
+	switch {
+	case rule.Kind == models.TaxKindTax && (rule.Rate == 0 || rule.Amount != 0):
+		return fmt.Errorf("%w: %v must have a rate and no amount", ErrInvalidTaxRules, rule.Name)
+	case rule.Kind == models.TaxKindFee && (rule.Rate == 0) == (rule.Amount == 0):
+		return fmt.Errorf("%w: %v must have either a rate or an amount", ErrInvalidTaxRules, rule.Name)
+	case rule.Kind == models.TaxKindFee && rule.Inclusive:
+		return fmt.Errorf("%w: %v: fees can't be inclusive", ErrInvalidTaxRules, rule.Name)
+	case rule.Rate != 0 && len(rule.AppliesTo) == 0:
+		return fmt.Errorf("%w: %v must apply to tickets or concessions", ErrInvalidTaxRules, rule.Name)
+	}
+
+	return nil
+}
+
+// taxBase is what a booking's taxes and fees are worked out from: the price
+// of its tickets, after discounts, and of its concessions.
+type taxBase struct {
+	Tickets     int64
+	TicketCount int
+	Concessions int64
+}
+
+func (b taxBase) of(part string) int64 {
+	switch part {
+	case models.TaxOnTickets:
+		return b.Tickets
+	case models.TaxOnConcessions:
+		return b.Concessions
+	default:
+		return 0
+	}
+}
+
+// taxBreakdown is the taxes and fees of a booking. Added is what they add to
+// the amount due; Included is the part of the prices that is tax.
+type taxBreakdown struct {
+	Lines    []models.TaxLine
+	Added    int64
+	Included int64
+}
+
+// calculateTaxes breaks down the taxes and fees of a booking line by line.
+// Inclusive taxes are taken out of the prices: when several apply to the
+// same part, each is its rate of the price net of all of them. Exclusive
+// taxes and fees are added on top of the prices. Every line is rounded half
+// up to the minor unit on its own.
+func calculateTaxes(rules []models.TaxRule, base taxBase) taxBreakdown {
+	inclusive := map[string]int{}
+	for _, rule := range rules {
+		if rule.Inclusive {
+			for _, part := range rule.AppliesTo {
+				inclusive[part] += rule.Rate
+			}
+		}
+	}
+
+	breakdown := taxBreakdown{Lines: []models.TaxLine{}}
+	for _, rule := range rules {
+		line := models.TaxLine{
+			Name:      rule.Name,
+			Kind:      rule.Kind,
+			Rate:      rule.Rate,
+			Inclusive: rule.Inclusive,
+		}
+
+		for _, part := range rule.AppliesTo {
+			line.Base += base.of(part)
+		}
+
+		switch {
+		case rule.Inclusive:
+			for _, part := range rule.AppliesTo {
+				line.Amount += roundDiv(base.of(part)*int64(rule.Rate), int64(10_000+inclusive[part]))
+			}
+		case rule.Rate != 0:
+			line.Amount = roundDiv(line.Base*int64(rule.Rate), 10_000)
+		case rule.Per == models.TaxPerTicket:
+			line.Base = int64(base.TicketCount)
+			line.Amount = rule.Amount * int64(base.TicketCount)
+		default:
+			line.Base = 1
+			line.Amount = rule.Amount
+		}
+
+		if line.Amount == 0 {
+			continue
+		}
+
+		if rule.Inclusive {
+			breakdown.Included += line.Amount
+		} else {
+			breakdown.Added += line.Amount
+		}
+		breakdown.Lines = append(breakdown.Lines, line)
+	}
+
+	return breakdown
+}
+
+// roundDiv divides two non-negative numbers, rounding half up.
+func roundDiv(a, b int64) int64 {
+	return (2*a + b) / (2 * b)
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculateTaxes(t *testing.T) {
+	tickets := []string{models.TaxOnTickets}
+	both := []string{models.TaxOnTickets, models.TaxOnConcessions}
+
+	tests := []struct {
+		name         string
+		rules        []models.TaxRule
+		base         taxBase
+		wantAmounts  []int64
+		wantAdded    int64
+		wantIncluded int64
+	}{
+		{
+			name:         "no rules",
+			base:         taxBase{Tickets: 10000, TicketCount: 2},
+			wantAmounts:  []int64{},
+			wantAdded:    0,
+			wantIncluded: 0,
+		},
+		{
+			name:         "inclusive tax is taken out of the price",
+			rules:        []models.TaxRule{{Name: "VAT", Kind: models.TaxKindTax, Rate: 1400, AppliesTo: tickets, Inclusive: true}},
+			base:         taxBase{Tickets: 10000, TicketCount: 2},
+			wantAmounts:  []int64{1228},
+			wantIncluded: 1228,
+		},
+		{
+			name:        "exclusive tax is rounded half up",
+			rules:       []models.TaxRule{{Name: "City tax", Kind: models.TaxKindTax, Rate: 500, AppliesTo: tickets}},
+			base:        taxBase{Tickets: 3330, TicketCount: 1},
+			wantAmounts: []int64{167},
+			wantAdded:   167,
+		},
+		{
+			name:        "exclusive tax on tickets and concessions",
+			rules:       []models.TaxRule{{Name: "Sales tax", Kind: models.TaxKindTax, Rate: 1000, AppliesTo: both}},
+			base:        taxBase{Tickets: 10000, TicketCount: 2, Concessions: 4000},
+			wantAmounts: []int64{1400},
+			wantAdded:   1400,
+		},
+		{
+			name: "two inclusive taxes on the same part",
+			rules: []models.TaxRule{
+				{Name: "VAT", Kind: models.TaxKindTax, Rate: 1000, AppliesTo: tickets, Inclusive: true},
+				{Name: "Culture tax", Kind: models.TaxKindTax, Rate: 1000, AppliesTo: tickets, Inclusive: true},
+			},
+			base:         taxBase{Tickets: 12000, TicketCount: 1},
+			wantAmounts:  []int64{1000, 1000},
+			wantIncluded: 2000,
+		},
+		{
+			name: "fees per ticket and per booking",
+			rules: []models.TaxRule{
+				{Name: "Service fee", Kind: models.TaxKindFee, Amount: 150, Per: models.TaxPerTicket},
+				{Name: "Booking fee", Kind: models.TaxKindFee, Amount: 300, Per: models.TaxPerBooking},
+			},
+			base:        taxBase{Tickets: 10000, TicketCount: 3},
+			wantAmounts: []int64{450, 300},
+			wantAdded:   750,
+		},
+		{
+			name:        "taxes on a free part are skipped",
+			rules:       []models.TaxRule{{Name: "VAT", Kind: models.TaxKindTax, Rate: 1400, AppliesTo: tickets, Inclusive: true}},
+			base:        taxBase{Tickets: 0, TicketCount: 2, Concessions: 4000},
+			wantAmounts: []int64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breakdown := calculateTaxes(tt.rules, tt.base)
+
+			amounts := []int64{}
+			for _, line := range breakdown.Lines {
+				amounts = append(amounts, line.Amount)
+			}
+
+			assert.Equal(t, tt.wantAmounts, amounts)
+			assert.Equal(t, tt.wantAdded, breakdown.Added)
+			assert.Equal(t, tt.wantIncluded, breakdown.Included)
+		})
+	}
+}
+
+func TestValidateTaxRule(t *testing.T) {
+	tickets := []string{models.TaxOnTickets}
+
+	tests := []struct {
+		name    string
+		rule    models.TaxRule
+		wantErr bool
+	}{
+		{"inclusive tax", models.TaxRule{Name: "VAT", Kind: models.TaxKindTax, Rate: 1400, AppliesTo: tickets, Inclusive: true}, false},
+		{"fixed fee", models.TaxRule{Name: "Booking fee", Kind: models.TaxKindFee, Amount: 300}, false},
+		{"percentage fee", models.TaxRule{Name: "Service fee", Kind: models.TaxKindFee, Rate: 250, AppliesTo: tickets}, false},
+		{"tax without a rate", models.TaxRule{Name: "VAT", Kind: models.TaxKindTax, Amount: 300}, true},
+		{"fee with a rate and an amount", models.TaxRule{Name: "Fee", Kind: models.TaxKindFee, Rate: 250, Amount: 300, AppliesTo: tickets}, true},
+		{"inclusive fee", models.TaxRule{Name: "Fee", Kind: models.TaxKindFee, Amount: 300, Inclusive: true}, true},
+		{"rate applying to nothing", models.TaxRule{Name: "VAT", Kind: models.TaxKindTax, Rate: 1400}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTaxRule(&tt.rule)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidTaxRules)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -8,7 +8,8 @@ import (
 )
 
 type TheaterService struct {
-	models *models.Model
+	models   *models.Model
+	currency string
 }
 
 func (s *TheaterService) Search(filters models.TheaterFilter) ([]models.Theater, error) {
@@ -24,6 +25,10 @@ func (s *TheaterService) Create(user *models.User, theater *models.Theater) erro
 		return ErrUnauthorized
 	}
 
+	if theater.Currency == "" {
+		theater.Currency = s.currency
+	}
+
 	return s.models.Theaters.Create(theater)
 }
 
@@ -51,6 +56,22 @@ func (s *TheaterService) Update(user *models.User, theaterId int, input UpdateTh
 	if input.Address != nil {
 		theater.Address = *input.Address
 	}
+	// the amounts stored for the theater have no currency of their own, so
+	// it can only be changed before any of them is set.
+	if input.Currency != nil && *input.Currency != theater.Currency {
+		priced, err := s.models.Theaters.HasAmounts(theater.ID)
+		if err != nil {
+			return nil, err
+		}
+		if priced {
+			return nil, fmt.Errorf("%w: prices, promo codes, concessions or bookings are already in %v", ErrCurrencyInUse, theater.Currency)
+		}
+
+		theater.Currency = *input.Currency
+	}
+	if input.TurnaroundMinutes != nil {
+		theater.TurnaroundMinutes = *input.TurnaroundMinutes
+	}
 
 	if err := s.models.Theaters.Update(theater); err != nil {
 		switch {
@@ -82,8 +103,50 @@ func (s *TheaterService) Delete(user *models.User, theaterId int) error {
 	return s.models.Theaters.Delete(theaterId)
 }
 
+// UpdateRefundPolicy replaces the refund rules applied to cancellations of
+// the theater's bookings.
+func (s *TheaterService) UpdateRefundPolicy(user *models.User, theaterId int, rules []models.RefundRule) (*models.Theater, error) {
+	theater, err := findManagedTheater(s.models, user, theaterId, "refund policy can be updated by theater manager only")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.models.RefundPolicies.Replace(theaterId, rules); err != nil {
+		return nil, err
+	}
+
+	theater.RefundPolicy, err = s.models.RefundPolicies.Find(theaterId)
+	if err != nil {
+		return nil, err
+	}
+
+	return theater, nil
+}
+
+// UpdateOperatingHours replaces the opening hours, holiday hours and
+// closures shows of the theater are scheduled within. Shows already
+// scheduled are kept.
+func (s *TheaterService) UpdateOperatingHours(user *models.User, theaterId int, hours models.OperatingHours) (*models.Theater, error) {
+	if _, err := findManagedTheater(s.models, user, theaterId, "operating hours can be updated by theater manager only"); err != nil {
+		return nil, err
+	}
+
+	if err := s.models.OperatingHours.Replace(theaterId, hours); err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, ErrTheaterNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return s.models.Theaters.Find(theaterId)
+}
+
 type UpdateTheaterInput struct {
-	Name    *string
-	City    *string
-	Address *string
+	Name              *string
+	City              *string
+	Address           *string
+	Currency          *string
+	TurnaroundMinutes *int
 }
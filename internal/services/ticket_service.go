@@ -0,0 +1,127 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+)
+
+var (
+	ErrTicketNotFound       = errors.New("ticket not found")
+	ErrTicketNotValid       = errors.New("ticket is not valid for admission")
+	ErrTicketUsed           = errors.New("ticket already used")
+	ErrOutsideCheckinWindow = errors.New("check-in is not open for this show")
+)
+
+// checkinOpensBefore is how early before a show its tickets are admitted.
+const checkinOpensBefore = time.Hour
+
+type TicketService struct {
+	models *models.Model
+	signer *TicketSigner
+}
+
+// Find returns a ticket of a paid booking of the user along with its token.
+func (s *TicketService) Find(user *models.User, bookingId, ticketId int) (*models.Ticket, error) {
+	booking, err := s.models.Bookings.Find(bookingId)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, ErrBookingNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	if booking.UserID != user.ID && user.Role != "admin" {
+		return nil, fmt.Errorf("%w: booking belongs to another user", ErrUnauthorized)
+	}
+
+	if booking.Status != models.BookingPaid {
+		return nil, fmt.Errorf("%w: booking is %v", ErrTicketNotValid, booking.Status)
+	}
+
+	for _, ticket := range booking.Tickets {
+		if ticket.ID == ticketId {
+			ticket.Token = s.signer.Sign(ticket.ID, ticket.ShowID)
+			return &ticket, nil
+		}
+	}
+
+	return nil, ErrTicketNotFound
+}
+
+// CheckIn admits the holder of a ticket token to a show of the theater. Each
+// ticket is admitted once; replaying a token fails with ErrTicketUsed.
+func (s *TicketService) CheckIn(user *models.User, theaterId int, token string) (*models.Ticket, error) {
+	ticketId, showId, err := s.signer.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := findManagedTheater(s.models, user, theaterId, "check-in is available for theater's staff only"); err != nil {
+		return nil, err
+	}
+
+	ticket, err := s.models.Tickets.Find(ticketId)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, ErrTicketNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	if ticket.ShowID != showId {
+		return nil, ErrInvalidTicketToken
+	}
+
+	booking, err := s.models.Bookings.Find(ticket.BookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if booking.Status != models.BookingPaid {
+		return nil, fmt.Errorf("%w: booking is %v", ErrTicketNotValid, booking.Status)
+	}
+
+	show, err := s.models.Shows.Find(ticket.ShowID)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return nil, ErrShowNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	if show.TheaterID != theaterId {
+		return nil, fmt.Errorf("%w: ticket is for another theater", ErrTicketNotValid)
+	}
+
+	now := time.Now()
+	if now.Before(show.StartTime.Add(-checkinOpensBefore)) || now.After(show.EndTime) {
+		return nil, fmt.Errorf(
+			"%w: admission is from %v to %v",
+			ErrOutsideCheckinWindow,
+			show.StartTime.Add(-checkinOpensBefore),
+			show.EndTime,
+		)
+	}
+
+	if err := s.models.Tickets.MarkUsed(ticket); err != nil {
+		switch {
+		case errors.Is(err, models.ErrTicketUsed):
+			return nil, ErrTicketUsed
+		case errors.Is(err, models.ErrTicketNotPaid):
+			return nil, fmt.Errorf("%w: booking isn't paid", ErrTicketNotValid)
+		default:
+			return nil, err
+		}
+	}
+
+	return ticket, nil
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidTicketToken = errors.New("invalid ticket token")
+)
+
+// TicketSigner issues and verifies the tamper-proof tokens printed on
+// tickets. A token has the form "<ticket id>.<show id>.<signature>" where the
+// signature is an HMAC-SHA256 of the first two parts.
+type TicketSigner struct {
+	key []byte
+}
+
+func NewTicketSigner(key []byte) *TicketSigner {
+	return &TicketSigner{key}
+}
+
+func (s *TicketSigner) Sign(ticketId, showId int) string {
+	payload := fmt.Sprintf("%d.%d", ticketId, showId)
+	return payload + "." + s.signature(payload)
+}
+
+// Verify checks the token's signature and returns the ticket and show ids it
+// was issued for.
+func (s *TicketSigner) Verify(token string) (ticketId, showId int, err error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0, 0, ErrInvalidTicketToken
+	}
+
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(s.signature(payload))) {
+		return 0, 0, ErrInvalidTicketToken
+	}
+
+	ticketId, err = strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, ErrInvalidTicketToken
+	}
+
+	showId, err = strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, ErrInvalidTicketToken
+	}
+
+	return ticketId, showId, nil
+}
+
+func (s *TicketSigner) signature(payload string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTicketSigner_Verify(t *testing.T) {
+	signer := NewTicketSigner([]byte("secret-key"))
+	token := signer.Sign(42, 7)
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{name: "valid token", token: token, want: nil},
+		{name: "tampered ticket id", token: "43" + token[2:], want: ErrInvalidTicketToken},
+		{name: "tampered signature", token: token[:len(token)-1] + "A", want: ErrInvalidTicketToken},
+		{name: "signed with another key", token: NewTicketSigner([]byte("other")).Sign(42, 7), want: ErrInvalidTicketToken},
+		{name: "malformed token", token: "42.7", want: ErrInvalidTicketToken},
+		{name: "empty token", token: "", want: ErrInvalidTicketToken},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ticketId, showId, err := signer.Verify(tc.token)
+			if tc.want != nil {
+				assert.ErrorIs(t, err, tc.want)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, 42, ticketId)
+				assert.Equal(t, 7, showId)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/AhmadAbdelrazik/showtime/internal/models"
+)
+
+var (
+	ErrSeatsAvailable          = errors.New("seats are available for booking")
+	ErrAlreadyWaitlisted       = errors.New("already on the waitlist of this show")
+	ErrWaitlistEntryNotFound   = errors.New("waitlist entry not found")
+	ErrWaitlistEntryNotWaiting = errors.New("waitlist entry is closed")
+)
+
+type WaitlistService struct {
+	models        *models.Model
+	offerDuration time.Duration
+}
+
+// Join enrolls the user on the waitlist of a show that doesn't have enough
+// seats left for them.
+func (s *WaitlistService) Join(user *models.User, theaterId, showId, seatCount int) (*models.WaitlistEntry, error) {
+	show, err := findTheaterShow(s.models, theaterId, showId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !show.StartTime.After(time.Now()) {
+		return nil, ErrShowStarted
+	}
+
+	seats, err := s.models.ShowSeats.FindByShow(show.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if pickOfferSeats(seats, seatCount) != nil {
+		return nil, fmt.Errorf("%w: hold the seats instead of joining the waitlist", ErrSeatsAvailable)
+	}
+
+	entry := &models.WaitlistEntry{
+		ShowID:    show.ID,
+		UserID:    user.ID,
+		SeatCount: seatCount,
+	}
+
+	if err := s.models.Waitlist.Create(entry); err != nil {
+		switch {
+		case errors.Is(err, models.ErrDuplicate):
+			return nil, ErrAlreadyWaitlisted
+		default:
+			return nil, err
+		}
+	}
+
+	return entry, nil
+}
+
+// List returns the user's open waitlist entries and their positions.
+func (s *WaitlistService) List(user *models.User) ([]models.WaitlistEntry, error) {
+	return s.models.Waitlist.FindByUser(user.ID)
+}
+
+// Leave takes the user off a waitlist. Seats of a pending offer are passed
+// on to the next user in line.
+func (s *WaitlistService) Leave(user *models.User, entryId int) error {
+	entry, err := s.models.Waitlist.Find(entryId)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return ErrWaitlistEntryNotFound
+		default:
+			return err
+		}
+	}
+
+	if entry.UserID != user.ID && user.Role != "admin" {
+		return fmt.Errorf("%w: waitlist entry belongs to another user", ErrUnauthorized)
+	}
+
+	if err := s.models.Waitlist.Leave(entry); err != nil {
+		switch {
+		case errors.Is(err, models.ErrWaitlistEntryClosed):
+			return ErrWaitlistEntryNotWaiting
+		default:
+			return err
+		}
+	}
+
+	if entry.HoldID != nil {
+		s.OfferReleasedSeats(entry.ShowID)
+	}
+
+	return nil
+}
+
+// OfferReleasedSeats hands the available seats of the given shows to their
+// waitlists. Entries are served strictly first come first: an entry asking
+// for more seats than are left waits for more seats to be released, and so
+// do the entries behind it. Each offer is an exclusive hold that expires
+// after the offer duration.
+func (s *WaitlistService) OfferReleasedSeats(showIds ...int) {
+	slices.Sort(showIds)
+
+	for _, showId := range slices.Compact(showIds) {
+		if err := s.offer(showId); err != nil {
+			slog.Error("failed to offer released seats", "show", showId, "error", err)
+		}
+	}
+}
+
+func (s *WaitlistService) offer(showId int) error {
+	show, err := s.models.Shows.Find(showId)
+	if err != nil {
+		return err
+	}
+
+	if !show.StartTime.After(time.Now()) {
+		return nil
+	}
+
+	entries, err := s.models.Waitlist.FindWaiting(showId)
+	if err != nil || len(entries) == 0 {
+		return err
+	}
+
+	seats, err := s.models.ShowSeats.FindByShow(showId)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		offered := pickOfferSeats(seats, entry.SeatCount)
+		if offered == nil {
+			return nil
+		}
+
+		seatIds := make([]int, len(offered))
+		for i, seat := range offered {
+			seatIds[i] = seat.ID
+		}
+
+		hold := &models.Hold{
+			ShowID:    showId,
+			UserID:    entry.UserID,
+			ExpiresAt: time.Now().Add(s.offerDuration),
+		}
+
+		if err := s.models.Waitlist.Offer(&entry, hold, seatIds); err != nil {
+			switch {
+			case errors.Is(err, models.ErrWaitlistEntryClosed):
+				continue
+			case errors.Is(err, models.ErrSeatUnavailable):
+				// Someone else took the seats meanwhile; whoever releases
+				// seats next triggers another round.
+				return nil
+			default:
+				return err
+			}
+		}
+
+		slog.Info("offered waitlisted seats", "show", showId, "entry", entry.ID, "hold", hold.ID)
+
+		for i := range seats {
+			if slices.Contains(seatIds, seats[i].ID) {
+				seats[i].Status = models.SeatHeld
+			}
+		}
+	}
+
+	return nil
+}
+
+// pickOfferSeats prefers a block of adjacent seats, falling back to any
+// available general seats when the group can't sit together.
+func pickOfferSeats(seats []models.ShowSeat, count int) []models.ShowSeat {
+	if best := findBestSeats(seats, count); best != nil {
+		return best
+	}
+
+	picked := []models.ShowSeat{}
+	for _, seat := range seats {
+		if len(picked) == count {
+			break
+		}
+		if seat.IsAvailable() && isGeneralSeat(seat) {
+			picked = append(picked, seat)
+		}
+	}
+
+	if len(picked) < count {
+		return nil
+	}
+
+	return picked
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPickOfferSeats(t *testing.T) {
+	tests := []struct {
+		name  string
+		sold  []string
+		count int
+		want  []string
+	}{
+		{
+			name:  "adjacent block when possible",
+			sold:  []string{"A1", "A2", "A3", "A4", "A8", "A9", "A10"},
+			count: 3,
+			want:  []string{"A5", "A6", "A7"},
+		},
+		{
+			name:  "scattered seats when the group can't sit together",
+			sold:  []string{"A1", "A3", "A4", "A6", "A7", "A9", "A10"},
+			count: 3,
+			want:  []string{"A2", "A5", "A8"},
+		},
+		{
+			name:  "not enough seats left",
+			sold:  []string{"A1", "A2", "A3", "A4", "A5", "A6", "A7", "A8", "A9"},
+			count: 2,
+			want:  nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := pickOfferSeats(showSeats(1, 10, tc.sold...), tc.count)
+
+			var labels []string
+			for _, seat := range got {
+				labels = append(labels, seatLabel(seat))
+			}
+			assert.Equal(t, tc.want, labels)
+		})
+	}
+}
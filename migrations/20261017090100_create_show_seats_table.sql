@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS show_seats (
+  id SERIAL PRIMARY KEY,
+  show_id INT NOT NULL REFERENCES shows(id) ON DELETE CASCADE,
+  seat_id INT NOT NULL REFERENCES seats(id) ON DELETE CASCADE,
+  row VARCHAR(2) NOT NULL,
+  seat_number INT NOT NULL,
+  status VARCHAR(10) NOT NULL DEFAULT 'available',
+  user_id INT REFERENCES users(id) ON DELETE SET NULL,
+
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+  UNIQUE (show_id, seat_id),
+  CONSTRAINT show_seats_status_check CHECK (status IN ('available', 'held', 'sold')),
+  CONSTRAINT show_seats_owner_check CHECK (status = 'available' OR user_id IS NOT NULL)
+);
+
+CREATE INDEX show_seats_show_id_status_idx ON show_seats (show_id, status);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS show_seats;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS seat_holds (
+  id SERIAL PRIMARY KEY,
+  show_id INT NOT NULL REFERENCES shows(id) ON DELETE CASCADE,
+  user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  status VARCHAR(10) NOT NULL DEFAULT 'active',
+  expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+  CONSTRAINT seat_holds_status_check CHECK (status IN ('active', 'released', 'expired', 'converted'))
+);
+
+CREATE INDEX seat_holds_status_expires_at_idx ON seat_holds (status, expires_at);
+
+ALTER TABLE show_seats ADD COLUMN hold_id INT REFERENCES seat_holds(id) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE show_seats DROP COLUMN IF EXISTS hold_id;
+DROP TABLE IF EXISTS seat_holds;
+-- +goose StatementEnd
@@ -0,0 +1,50 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS bookings (
+  id SERIAL PRIMARY KEY,
+  user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  show_id INT NOT NULL REFERENCES shows(id) ON DELETE CASCADE,
+  hold_id INT NOT NULL REFERENCES seat_holds(id),
+  status VARCHAR(10) NOT NULL DEFAULT 'pending',
+  amount BIGINT NOT NULL,
+  payment_reference VARCHAR(100),
+
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+  CONSTRAINT bookings_status_check CHECK (status IN ('pending', 'paid', 'cancelled', 'refunded')),
+  CONSTRAINT bookings_amount_check CHECK (amount >= 0)
+);
+
+CREATE INDEX bookings_user_id_idx ON bookings (user_id);
+
+CREATE TABLE IF NOT EXISTS tickets (
+  id SERIAL PRIMARY KEY,
+  booking_id INT NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+  show_seat_id INT NOT NULL REFERENCES show_seats(id) ON DELETE CASCADE,
+  price BIGINT NOT NULL,
+
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+  UNIQUE (booking_id, show_seat_id)
+);
+
+CREATE TABLE IF NOT EXISTS booking_events (
+  id SERIAL PRIMARY KEY,
+  booking_id INT NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+  from_status VARCHAR(10),
+  to_status VARCHAR(10) NOT NULL,
+  reason VARCHAR(200) NOT NULL DEFAULT '',
+
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX booking_events_booking_id_idx ON booking_events (booking_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS booking_events;
+DROP TABLE IF EXISTS tickets;
+DROP TABLE IF EXISTS bookings;
+-- +goose StatementEnd
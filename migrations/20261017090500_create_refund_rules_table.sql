@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS refund_rules (
+  id SERIAL PRIMARY KEY,
+  theater_id INT NOT NULL REFERENCES theaters(id) ON DELETE CASCADE,
+  min_hours_before INT NOT NULL,
+  percent INT NOT NULL,
+
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+  UNIQUE (theater_id, min_hours_before),
+  CONSTRAINT refund_rules_hours_check CHECK (min_hours_before >= 0),
+  CONSTRAINT refund_rules_percent_check CHECK (percent BETWEEN 0 AND 100)
+);
+
+ALTER TABLE bookings ADD COLUMN refund_amount BIGINT NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE bookings DROP COLUMN IF EXISTS refund_amount;
+DROP TABLE IF EXISTS refund_rules;
+-- +goose StatementEnd
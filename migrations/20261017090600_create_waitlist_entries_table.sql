@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS waitlist_entries (
+  id SERIAL PRIMARY KEY,
+  show_id INT NOT NULL REFERENCES shows(id) ON DELETE CASCADE,
+  user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  seat_count INT NOT NULL,
+  status VARCHAR(10) NOT NULL DEFAULT 'waiting',
+  hold_id INT REFERENCES seat_holds(id) ON DELETE SET NULL,
+  offer_expires_at TIMESTAMP WITH TIME ZONE,
+
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+  CONSTRAINT waitlist_entries_seat_count_check CHECK (seat_count > 0),
+  CONSTRAINT waitlist_entries_status_check CHECK (status IN ('waiting', 'offered', 'fulfilled', 'lapsed', 'left'))
+);
+
+CREATE UNIQUE INDEX waitlist_entries_show_id_user_id_idx ON waitlist_entries (show_id, user_id)
+WHERE status IN ('waiting', 'offered');
+CREATE INDEX waitlist_entries_show_id_status_idx ON waitlist_entries (show_id, status, id);
+CREATE INDEX waitlist_entries_hold_id_idx ON waitlist_entries (hold_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS waitlist_entries;
+-- +goose StatementEnd
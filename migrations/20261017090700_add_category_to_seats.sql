@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE seats ADD COLUMN category VARCHAR(12) NOT NULL DEFAULT 'standard';
+ALTER TABLE seats ADD CONSTRAINT seats_category_check
+  CHECK (category IN ('standard', 'vip', 'wheelchair', 'companion', 'couple'));
+
+ALTER TABLE show_seats ADD COLUMN category VARCHAR(12) NOT NULL DEFAULT 'standard';
+ALTER TABLE show_seats ADD CONSTRAINT show_seats_category_check
+  CHECK (category IN ('standard', 'vip', 'wheelchair', 'companion', 'couple'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE show_seats DROP COLUMN IF EXISTS category;
+ALTER TABLE seats DROP COLUMN IF EXISTS category;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE seats ALTER COLUMN row TYPE VARCHAR(4);
+ALTER TABLE seats ADD COLUMN grid_column INT;
+UPDATE seats SET grid_column = seat_number;
+ALTER TABLE seats ALTER COLUMN grid_column SET NOT NULL;
+
+ALTER TABLE show_seats ALTER COLUMN row TYPE VARCHAR(4);
+ALTER TABLE show_seats ADD COLUMN grid_column INT;
+UPDATE show_seats SET grid_column = seat_number;
+ALTER TABLE show_seats ALTER COLUMN grid_column SET NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE show_seats DROP COLUMN IF EXISTS grid_column;
+ALTER TABLE show_seats ALTER COLUMN row TYPE VARCHAR(2);
+ALTER TABLE seats DROP COLUMN IF EXISTS grid_column;
+ALTER TABLE seats ALTER COLUMN row TYPE VARCHAR(2);
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS hall_layouts (
+  id SERIAL PRIMARY KEY,
+  hall_id INT NOT NULL REFERENCES halls(id) ON DELETE CASCADE,
+  version INT NOT NULL,
+  capacity INT NOT NULL,
+  note VARCHAR(200) NOT NULL DEFAULT '',
+  published_by INT REFERENCES users(id) ON DELETE SET NULL,
+
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+  UNIQUE (hall_id, version)
+);
+
+INSERT INTO hall_layouts(hall_id, version, capacity, note, published_by, created_at)
+SELECT h.id, h.seats_version, COUNT(s.id), 'initial layout', t.manager_id, h.created_at
+FROM halls AS h
+JOIN theaters AS t ON t.id = h.theater_id
+LEFT JOIN seats AS s ON s.hall_id = h.id AND s.version = h.seats_version
+AND s.deleted_at IS NULL
+GROUP BY h.id, t.manager_id;
+
+ALTER TABLE shows ADD COLUMN layout_version INT;
+UPDATE shows AS sh SET layout_version = h.seats_version
+FROM halls AS h WHERE h.id = sh.hall_id;
+ALTER TABLE shows ALTER COLUMN layout_version SET NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE shows DROP COLUMN IF EXISTS layout_version;
+DROP TABLE IF EXISTS hall_layouts;
+-- +goose StatementEnd
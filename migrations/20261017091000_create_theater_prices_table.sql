@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS theater_prices (
+  id SERIAL PRIMARY KEY,
+  theater_id INT NOT NULL REFERENCES theaters(id) ON DELETE CASCADE,
+  kind VARCHAR(12) NOT NULL,
+  name VARCHAR(20) NOT NULL,
+  value BIGINT NOT NULL,
+
+  UNIQUE (theater_id, kind, name),
+  CONSTRAINT theater_prices_kind_check CHECK (kind IN ('category', 'tier', 'ticket_type')),
+  CONSTRAINT theater_prices_value_check CHECK (value >= 0)
+);
+
+ALTER TABLE shows ADD COLUMN price_tier VARCHAR(10) NOT NULL DEFAULT 'evening';
+UPDATE shows SET price_tier = CASE
+  WHEN EXTRACT(ISODOW FROM start_time) IN (6, 7) THEN 'weekend'
+  WHEN EXTRACT(HOUR FROM start_time) < 17 THEN 'matinee'
+  ELSE 'evening'
+END;
+ALTER TABLE shows ADD CONSTRAINT shows_price_tier_check
+  CHECK (price_tier IN ('matinee', 'evening', 'weekend', 'holiday'));
+
+ALTER TABLE tickets ADD COLUMN category VARCHAR(20) NOT NULL DEFAULT 'standard';
+ALTER TABLE tickets ADD COLUMN ticket_type VARCHAR(10) NOT NULL DEFAULT 'adult';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE tickets DROP COLUMN IF EXISTS ticket_type;
+ALTER TABLE tickets DROP COLUMN IF EXISTS category;
+ALTER TABLE shows DROP CONSTRAINT IF EXISTS shows_price_tier_check;
+ALTER TABLE shows DROP COLUMN IF EXISTS price_tier;
+DROP TABLE IF EXISTS theater_prices;
+-- +goose StatementEnd
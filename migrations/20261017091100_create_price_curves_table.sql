@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS price_curve_points (
+  id SERIAL PRIMARY KEY,
+  theater_id INT NOT NULL REFERENCES theaters(id) ON DELETE CASCADE,
+  curve VARCHAR(10) NOT NULL,
+  threshold INT NOT NULL,
+  percent INT NOT NULL,
+
+  UNIQUE (theater_id, curve, threshold),
+  CONSTRAINT price_curve_points_curve_check CHECK (curve IN ('occupancy', 'lead_time')),
+  CONSTRAINT price_curve_points_percent_check CHECK (percent >= 0)
+);
+
+CREATE TABLE IF NOT EXISTS price_bounds (
+  id SERIAL PRIMARY KEY,
+  theater_id INT NOT NULL REFERENCES theaters(id) ON DELETE CASCADE,
+  category VARCHAR(20) NOT NULL,
+  floor_price BIGINT,
+  ceiling_price BIGINT,
+
+  UNIQUE (theater_id, category),
+  CONSTRAINT price_bounds_check CHECK (floor_price IS NULL OR ceiling_price IS NULL OR floor_price <= ceiling_price)
+);
+
+CREATE TABLE IF NOT EXISTS price_changes (
+  id SERIAL PRIMARY KEY,
+  show_id INT NOT NULL REFERENCES shows(id) ON DELETE CASCADE,
+  category VARCHAR(20) NOT NULL,
+  old_price BIGINT,
+  new_price BIGINT NOT NULL,
+  occupancy INT NOT NULL,
+  hours_before INT NOT NULL,
+
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX price_changes_show_id_category_idx ON price_changes (show_id, category, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS price_changes;
+DROP TABLE IF EXISTS price_bounds;
+DROP TABLE IF EXISTS price_curve_points;
+-- +goose StatementEnd
@@ -0,0 +1,48 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS promo_codes (
+  id SERIAL PRIMARY KEY,
+  code VARCHAR(30) NOT NULL UNIQUE,
+  theater_id INT REFERENCES theaters(id) ON DELETE CASCADE,
+  created_by INT REFERENCES users(id) ON DELETE SET NULL,
+  kind VARCHAR(10) NOT NULL,
+  value BIGINT NOT NULL,
+  movie_id VARCHAR(12) REFERENCES movies(imdb_id) ON DELETE CASCADE,
+  shows_from TIMESTAMP WITH TIME ZONE,
+  shows_until TIMESTAMP WITH TIME ZONE,
+  ticket_types TEXT[] NOT NULL DEFAULT '{}',
+  min_seats INT NOT NULL DEFAULT 1,
+  max_redemptions INT,
+  max_per_user INT,
+  redemptions INT NOT NULL DEFAULT 0,
+  active BOOLEAN NOT NULL DEFAULT TRUE,
+
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+  CONSTRAINT promo_codes_kind_check CHECK (kind IN ('percent', 'fixed')),
+  CONSTRAINT promo_codes_value_check CHECK (value > 0 AND (kind <> 'percent' OR value <= 100)),
+  CONSTRAINT promo_codes_redemptions_check CHECK (max_redemptions IS NULL OR redemptions <= max_redemptions)
+);
+
+CREATE TABLE IF NOT EXISTS promo_redemptions (
+  id SERIAL PRIMARY KEY,
+  promo_code_id INT NOT NULL REFERENCES promo_codes(id) ON DELETE CASCADE,
+  booking_id INT NOT NULL UNIQUE REFERENCES bookings(id) ON DELETE CASCADE,
+  user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  discount BIGINT NOT NULL,
+
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX promo_redemptions_promo_code_id_user_id_idx ON promo_redemptions (promo_code_id, user_id);
+
+ALTER TABLE bookings ADD COLUMN discount BIGINT NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE bookings DROP COLUMN IF EXISTS discount;
+DROP TABLE IF EXISTS promo_redemptions;
+DROP TABLE IF EXISTS promo_codes;
+-- +goose StatementEnd
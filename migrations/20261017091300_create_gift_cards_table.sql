@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS gift_cards (
+  id SERIAL PRIMARY KEY,
+  code VARCHAR(19) NOT NULL UNIQUE,
+  purchased_by INT REFERENCES users(id) ON DELETE SET NULL,
+  initial_balance BIGINT NOT NULL,
+  balance BIGINT NOT NULL,
+  payment_reference VARCHAR(100),
+  expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+  CONSTRAINT gift_cards_balance_check CHECK (balance >= 0 AND balance <= initial_balance)
+);
+
+CREATE TABLE IF NOT EXISTS gift_card_transactions (
+  id SERIAL PRIMARY KEY,
+  gift_card_id INT NOT NULL REFERENCES gift_cards(id) ON DELETE CASCADE,
+  booking_id INT REFERENCES bookings(id) ON DELETE SET NULL,
+  kind VARCHAR(10) NOT NULL,
+  amount BIGINT NOT NULL,
+
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+  CONSTRAINT gift_card_transactions_kind_check CHECK (kind IN ('issue', 'redeem', 'restore'))
+);
+
+CREATE INDEX gift_card_transactions_gift_card_id_idx ON gift_card_transactions (gift_card_id);
+CREATE INDEX gift_card_transactions_booking_id_idx ON gift_card_transactions (booking_id);
+
+ALTER TABLE bookings
+  ADD COLUMN gift_card_id INT REFERENCES gift_cards(id) ON DELETE SET NULL,
+  ADD COLUMN gift_card_amount BIGINT NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE bookings
+  DROP COLUMN IF EXISTS gift_card_amount,
+  DROP COLUMN IF EXISTS gift_card_id;
+DROP TABLE IF EXISTS gift_card_transactions;
+DROP TABLE IF EXISTS gift_cards;
+-- +goose StatementEnd
@@ -0,0 +1,60 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS loyalty_rules (
+  id INT PRIMARY KEY DEFAULT 1,
+  points_per_ticket INT NOT NULL,
+  point_value BIGINT NOT NULL,
+  free_ticket_points INT NOT NULL,
+
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+  CONSTRAINT loyalty_rules_single_row CHECK (id = 1),
+  CONSTRAINT loyalty_rules_values_check CHECK (points_per_ticket >= 0 AND point_value >= 0 AND free_ticket_points > 0)
+);
+
+INSERT INTO loyalty_rules(points_per_ticket, point_value, free_ticket_points)
+VALUES (10, 10, 500);
+
+CREATE TABLE IF NOT EXISTS loyalty_tiers (
+  name VARCHAR(10) PRIMARY KEY,
+  min_points INT NOT NULL,
+  earn_bonus INT NOT NULL DEFAULT 0,
+  discount INT NOT NULL DEFAULT 0,
+
+  CONSTRAINT loyalty_tiers_name_check CHECK (name IN ('silver', 'gold')),
+  CONSTRAINT loyalty_tiers_values_check CHECK (min_points > 0 AND earn_bonus >= 0 AND discount BETWEEN 0 AND 100)
+);
+
+INSERT INTO loyalty_tiers(name, min_points, earn_bonus, discount)
+VALUES ('silver', 1000, 25, 5), ('gold', 5000, 50, 10);
+
+CREATE TABLE IF NOT EXISTS loyalty_transactions (
+  id SERIAL PRIMARY KEY,
+  user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  booking_id INT REFERENCES bookings(id) ON DELETE SET NULL,
+  kind VARCHAR(10) NOT NULL,
+  points INT NOT NULL,
+
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+  CONSTRAINT loyalty_transactions_kind_check CHECK (kind IN ('earn', 'redeem', 'restore', 'reversal'))
+);
+
+CREATE INDEX loyalty_transactions_user_id_idx ON loyalty_transactions (user_id, created_at);
+
+ALTER TABLE bookings
+  ADD COLUMN loyalty_discount BIGINT NOT NULL DEFAULT 0,
+  ADD COLUMN points_redeemed INT NOT NULL DEFAULT 0,
+  ADD COLUMN points_earned INT NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE bookings
+  DROP COLUMN IF EXISTS points_earned,
+  DROP COLUMN IF EXISTS points_redeemed,
+  DROP COLUMN IF EXISTS loyalty_discount;
+DROP TABLE IF EXISTS loyalty_transactions;
+DROP TABLE IF EXISTS loyalty_tiers;
+DROP TABLE IF EXISTS loyalty_rules;
+-- +goose StatementEnd
@@ -0,0 +1,73 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS membership_plans (
+  id SERIAL PRIMARY KEY,
+  name VARCHAR(50) NOT NULL UNIQUE,
+  kind VARCHAR(10) NOT NULL,
+  tickets_per_period INT,
+  price BIGINT NOT NULL,
+  period_days INT NOT NULL DEFAULT 30,
+  active BOOLEAN NOT NULL DEFAULT TRUE,
+
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+  CONSTRAINT membership_plans_kind_check CHECK (kind IN ('tickets', 'unlimited')),
+  CONSTRAINT membership_plans_tickets_check CHECK ((kind = 'tickets') = (tickets_per_period IS NOT NULL) AND (tickets_per_period IS NULL OR tickets_per_period > 0)),
+  CONSTRAINT membership_plans_values_check CHECK (price >= 0 AND period_days > 0)
+);
+
+CREATE TABLE IF NOT EXISTS subscriptions (
+  id SERIAL PRIMARY KEY,
+  user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  plan_id INT NOT NULL REFERENCES membership_plans(id),
+  status VARCHAR(10) NOT NULL DEFAULT 'active',
+  payment_token VARCHAR(100) NOT NULL,
+  current_period_start TIMESTAMP WITH TIME ZONE NOT NULL,
+  current_period_end TIMESTAMP WITH TIME ZONE NOT NULL,
+  tickets_used INT NOT NULL DEFAULT 0,
+  cancel_at_period_end BOOLEAN NOT NULL DEFAULT FALSE,
+  failed_payments INT NOT NULL DEFAULT 0,
+
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+  CONSTRAINT subscriptions_status_check CHECK (status IN ('active', 'past_due', 'cancelled')),
+  CONSTRAINT subscriptions_tickets_used_check CHECK (tickets_used >= 0)
+);
+
+-- a user may only have one subscription that isn't cancelled.
+CREATE UNIQUE INDEX subscriptions_user_id_current_idx ON subscriptions (user_id)
+WHERE status <> 'cancelled';
+
+CREATE TABLE IF NOT EXISTS subscription_payments (
+  id SERIAL PRIMARY KEY,
+  subscription_id INT NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+  period_start TIMESTAMP WITH TIME ZONE NOT NULL,
+  period_end TIMESTAMP WITH TIME ZONE NOT NULL,
+  amount BIGINT NOT NULL,
+  status VARCHAR(10) NOT NULL,
+  payment_reference VARCHAR(100),
+  failure_reason VARCHAR(200),
+
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+  CONSTRAINT subscription_payments_status_check CHECK (status IN ('paid', 'failed'))
+);
+
+ALTER TABLE bookings
+  ADD COLUMN subscription_id INT REFERENCES subscriptions(id) ON DELETE SET NULL,
+  ADD COLUMN membership_tickets INT NOT NULL DEFAULT 0,
+  ADD COLUMN membership_discount BIGINT NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE bookings
+  DROP COLUMN IF EXISTS membership_discount,
+  DROP COLUMN IF EXISTS membership_tickets,
+  DROP COLUMN IF EXISTS subscription_id;
+DROP TABLE IF EXISTS subscription_payments;
+DROP TABLE IF EXISTS subscriptions;
+DROP TABLE IF EXISTS membership_plans;
+-- +goose StatementEnd
@@ -0,0 +1,54 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS concession_items (
+  id SERIAL PRIMARY KEY,
+  theater_id INT NOT NULL REFERENCES theaters(id) ON DELETE CASCADE,
+  name VARCHAR(50) NOT NULL,
+  description VARCHAR(200) NOT NULL DEFAULT '',
+  kind VARCHAR(10) NOT NULL,
+  price BIGINT NOT NULL,
+  available BOOLEAN NOT NULL DEFAULT TRUE,
+
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+  CONSTRAINT concession_items_theater_id_name_key UNIQUE (theater_id, name),
+  CONSTRAINT concession_items_kind_check CHECK (kind IN ('item', 'combo')),
+  CONSTRAINT concession_items_price_check CHECK (price >= 0)
+);
+
+CREATE TABLE IF NOT EXISTS concession_combo_items (
+  combo_id INT NOT NULL REFERENCES concession_items(id) ON DELETE CASCADE,
+  item_id INT NOT NULL,
+  quantity INT NOT NULL DEFAULT 1,
+
+  PRIMARY KEY (combo_id, item_id),
+  CONSTRAINT concession_combo_items_item_id_fkey FOREIGN KEY (item_id) REFERENCES concession_items(id) ON DELETE RESTRICT,
+  CONSTRAINT concession_combo_items_quantity_check CHECK (quantity > 0)
+);
+
+CREATE TABLE IF NOT EXISTS booking_concessions (
+  id SERIAL PRIMARY KEY,
+  booking_id INT NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+  show_id INT NOT NULL REFERENCES shows(id) ON DELETE CASCADE,
+  item_id INT REFERENCES concession_items(id) ON DELETE SET NULL,
+  name VARCHAR(50) NOT NULL,
+  quantity INT NOT NULL,
+  unit_price BIGINT NOT NULL,
+  collected_at TIMESTAMP WITH TIME ZONE,
+
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+  CONSTRAINT booking_concessions_quantity_check CHECK (quantity > 0)
+);
+
+CREATE INDEX booking_concessions_booking_id_idx ON booking_concessions (booking_id);
+CREATE INDEX booking_concessions_show_id_idx ON booking_concessions (show_id) WHERE collected_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS booking_concessions;
+DROP TABLE IF EXISTS concession_combo_items;
+DROP TABLE IF EXISTS concession_items;
+-- +goose StatementEnd
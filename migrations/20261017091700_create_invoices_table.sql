@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS invoice_sequences (
+  theater_id INT PRIMARY KEY REFERENCES theaters(id) ON DELETE CASCADE,
+  last_number INT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS invoices (
+  id SERIAL PRIMARY KEY,
+  booking_id INT NOT NULL UNIQUE REFERENCES bookings(id) ON DELETE CASCADE,
+  theater_id INT NOT NULL REFERENCES theaters(id) ON DELETE CASCADE,
+  number INT NOT NULL,
+
+  issued_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+  CONSTRAINT invoices_theater_id_number_key UNIQUE (theater_id, number)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS invoices;
+DROP TABLE IF EXISTS invoice_sequences;
+-- +goose StatementEnd
@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS tax_rules (
+  id SERIAL PRIMARY KEY,
+  city VARCHAR(30) NOT NULL,
+  name VARCHAR(50) NOT NULL,
+  kind VARCHAR(10) NOT NULL,
+  rate INT NOT NULL DEFAULT 0,
+  amount BIGINT NOT NULL DEFAULT 0,
+  per VARCHAR(10) NOT NULL DEFAULT 'booking',
+  applies_to TEXT[] NOT NULL DEFAULT '{}',
+  inclusive BOOLEAN NOT NULL DEFAULT FALSE,
+
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+  CONSTRAINT tax_rules_kind_check CHECK (kind IN ('tax', 'fee')),
+  CONSTRAINT tax_rules_per_check CHECK (per IN ('ticket', 'booking')),
+  CONSTRAINT tax_rules_rate_check CHECK (rate >= 0 AND rate <= 10000),
+  CONSTRAINT tax_rules_amount_check CHECK (amount >= 0),
+  CONSTRAINT tax_rules_inclusive_check CHECK (kind = 'tax' OR NOT inclusive)
+);
+
+CREATE INDEX tax_rules_city_idx ON tax_rules (LOWER(city));
+
+CREATE TABLE IF NOT EXISTS booking_taxes (
+  id SERIAL PRIMARY KEY,
+  booking_id INT NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+  name VARCHAR(50) NOT NULL,
+  kind VARCHAR(10) NOT NULL,
+  rate INT NOT NULL,
+  base BIGINT NOT NULL,
+  amount BIGINT NOT NULL,
+  inclusive BOOLEAN NOT NULL
+);
+
+CREATE INDEX booking_taxes_booking_id_idx ON booking_taxes (booking_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS booking_taxes;
+DROP TABLE IF EXISTS tax_rules;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE theaters ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+ALTER TABLE bookings ADD COLUMN currency CHAR(3);
+UPDATE bookings AS b SET currency = t.currency
+FROM shows AS s, halls AS h, theaters AS t
+WHERE s.id = b.show_id AND h.id = s.hall_id AND t.id = h.theater_id;
+ALTER TABLE bookings ALTER COLUMN currency SET NOT NULL;
+
+ALTER TABLE gift_cards ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE membership_plans ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+CREATE TABLE IF NOT EXISTS exchange_rates (
+  currency CHAR(3) PRIMARY KEY,
+  rate NUMERIC(20, 10) NOT NULL,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+  CONSTRAINT exchange_rates_rate_check CHECK (rate > 0)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS exchange_rates;
+ALTER TABLE membership_plans DROP COLUMN IF EXISTS currency;
+ALTER TABLE gift_cards DROP COLUMN IF EXISTS currency;
+ALTER TABLE bookings DROP COLUMN IF EXISTS currency;
+ALTER TABLE theaters DROP COLUMN IF EXISTS currency;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE theaters ADD COLUMN turnaround_minutes INT NOT NULL DEFAULT 15
+  CONSTRAINT theaters_turnaround_minutes_check CHECK (turnaround_minutes >= 0);
+
+ALTER TABLE halls ADD COLUMN turnaround_minutes INT DEFAULT NULL
+  CONSTRAINT halls_turnaround_minutes_check CHECK (turnaround_minutes >= 0);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE halls DROP COLUMN IF EXISTS turnaround_minutes;
+ALTER TABLE theaters DROP COLUMN IF EXISTS turnaround_minutes;
+-- +goose StatementEnd
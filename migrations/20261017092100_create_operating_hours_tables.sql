@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE theaters ADD COLUMN timezone TEXT NOT NULL DEFAULT 'UTC';
+
+CREATE TABLE IF NOT EXISTS opening_hours (
+  id SERIAL PRIMARY KEY,
+  theater_id INT NOT NULL REFERENCES theaters(id) ON DELETE CASCADE,
+  weekday SMALLINT NOT NULL,
+  opens_at TIME NOT NULL,
+  closes_at TIME NOT NULL,
+
+  UNIQUE (theater_id, weekday),
+  CONSTRAINT opening_hours_weekday_check CHECK (weekday BETWEEN 0 AND 6)
+);
+
+CREATE TABLE IF NOT EXISTS holiday_hours (
+  id SERIAL PRIMARY KEY,
+  theater_id INT NOT NULL REFERENCES theaters(id) ON DELETE CASCADE,
+  date DATE NOT NULL,
+  name TEXT NOT NULL DEFAULT '',
+  opens_at TIME NOT NULL,
+  closes_at TIME NOT NULL,
+
+  UNIQUE (theater_id, date)
+);
+
+CREATE TABLE IF NOT EXISTS closures (
+  id SERIAL PRIMARY KEY,
+  theater_id INT NOT NULL REFERENCES theaters(id) ON DELETE CASCADE,
+  from_date DATE NOT NULL,
+  until_date DATE NOT NULL,
+  reason TEXT NOT NULL DEFAULT '',
+
+  CONSTRAINT closures_dates_check CHECK (until_date >= from_date)
+);
+
+CREATE INDEX closures_theater_id_from_date_idx ON closures (theater_id, from_date);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS closures;
+DROP TABLE IF EXISTS holiday_hours;
+DROP TABLE IF EXISTS opening_hours;
+ALTER TABLE theaters DROP COLUMN IF EXISTS timezone;
+-- +goose StatementEnd
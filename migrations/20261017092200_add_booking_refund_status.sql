@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE bookings ADD COLUMN refund_status TEXT DEFAULT NULL
+  CONSTRAINT bookings_refund_status_check CHECK (refund_status IN ('pending', 'settled'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE bookings DROP COLUMN IF EXISTS refund_status;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE gift_cards ADD COLUMN status VARCHAR(10) NOT NULL DEFAULT 'active'
+  CONSTRAINT gift_cards_status_check CHECK (status IN ('pending', 'active', 'void'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE gift_cards DROP COLUMN IF EXISTS status;
+-- +goose StatementEnd
@@ -0,0 +1,129 @@
+// Package money represents amounts of money as integers in the minor unit of
+// their ISO 4217 currency, e.g. cents for USD, so that no amount is ever
+// rounded by floating point arithmetic.
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+var (
+	ErrUnknownCurrency = errors.New("unknown currency")
+	ErrInvalidRate     = errors.New("invalid exchange rate")
+)
+
+// exponents lists the supported currencies with the number of digits of their
+// minor unit.
+var exponents = map[string]int{
+	"AED": 2, "AUD": 2, "BHD": 3, "BRL": 2, "CAD": 2, "CHF": 2, "CNY": 2,
+	"DKK": 2, "EGP": 2, "EUR": 2, "GBP": 2, "HKD": 2, "INR": 2, "JOD": 3,
+	"JPY": 0, "KRW": 0, "KWD": 3, "MAD": 2, "MXN": 2, "NOK": 2, "NZD": 2,
+	"OMR": 3, "QAR": 2, "SAR": 2, "SEK": 2, "SGD": 2, "TND": 3, "TRY": 2,
+	"USD": 2, "ZAR": 2,
+}
+
+// IsKnown reports whether the currency code is a supported ISO 4217 code.
+func IsKnown(currency string) bool {
+	_, ok := exponents[currency]
+	return ok
+}
+
+// Exponent returns the number of digits of the minor unit of a currency.
+// Unknown currencies are taken to have two.
+func Exponent(currency string) int {
+	if exp, ok := exponents[currency]; ok {
+		return exp
+	}
+	return 2
+}
+
+// Money is an amount in the minor unit of its currency.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// String formats the amount with its currency code, e.g. "EGP 1234.50".
+func (m Money) String() string {
+	return m.Currency + " " + m.Decimal()
+}
+
+// Decimal formats the amount with as many decimals as the minor unit of its
+// currency, e.g. "1234.50" for EGP or "1500" for JPY.
+func (m Money) Decimal() string {
+	exp := Exponent(m.Currency)
+
+	amount, sign := m.Amount, ""
+	if amount < 0 {
+		amount, sign = -amount, "-"
+	}
+
+	if exp == 0 {
+		return fmt.Sprintf("%v%d", sign, amount)
+	}
+
+	unit := pow10(exp)
+	return fmt.Sprintf("%v%d.%0*d", sign, amount/unit, exp, amount%unit)
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount    int64  `json:"amount"`
+		Currency  string `json:"currency"`
+		Formatted string `json:"formatted"`
+	}{m.Amount, m.Currency, m.String()})
+}
+
+// ParseRate parses an exchange rate written as a positive decimal number,
+// e.g. "48.35".
+func ParseRate(s string) (*big.Rat, error) {
+	rate, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok || rate.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidRate, s)
+	}
+	return rate, nil
+}
+
+// Convert converts money to another currency at rate units of that currency
+// per unit of the money's currency, rounding half away from zero to the
+// minor unit.
+func Convert(m Money, currency string, rate *big.Rat) Money {
+	amount := new(big.Rat).SetInt64(m.Amount)
+	amount.Mul(amount, rate)
+	amount.Mul(amount, new(big.Rat).SetFrac64(pow10(Exponent(currency)), pow10(Exponent(m.Currency))))
+
+	return Money{Amount: round(amount), Currency: currency}
+}
+
+// round rounds a rational number half away from zero.
+func round(r *big.Rat) int64 {
+	num, den := new(big.Int).Set(r.Num()), r.Denom()
+
+	neg := num.Sign() < 0
+	num.Abs(num)
+
+	// (2 * num + den) / (2 * den) rounds half up.
+	num.Mul(num, big.NewInt(2)).Add(num, den)
+	num.Quo(num, new(big.Int).Mul(den, big.NewInt(2)))
+
+	if neg {
+		num.Neg(num)
+	}
+	return num.Int64()
+}
+
+func pow10(exp int) int64 {
+	n := int64(1)
+	for range exp {
+		n *= 10
+	}
+	return n
+}